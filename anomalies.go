@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// defaultAnomalyThreshold is the number of scaled median absolute deviations above
+	// the median rate at which a counter increase is reported
+	defaultAnomalyThreshold = 3.0
+	// minAnomalyIntervals is the number of sample intervals required to establish a baseline
+	minAnomalyIntervals = 3
+	// minAnomalyDelta is the smallest counter increase reported, so a few drops on a flat
+	// history, where the median absolute deviation is 0, are not flagged
+	minAnomalyDelta = 10
+	// minAnomalyScaleRatio floors the deviation scale to a fraction of the baseline rate, so
+	// small fluctuations of a steady rate are not flagged either
+	minAnomalyScaleRatio = 0.1
+	// defaultAnomalyWindow is the counter history analyzed when no window is specified
+	defaultAnomalyWindow = 6 * time.Hour
+)
+
+// defaultAnomalyCounters lists the interface counters analyzed when none are specified
+var defaultAnomalyCounters = []string{"drops", "rx-miss", "rx-error", "tx-error"}
+
+// VPPCounterAnomalyInput represents the input for interface counter anomaly detection
+type VPPCounterAnomalyInput struct {
+	// NodeName specifies the Kubernetes node whose counter history is analyzed
+	NodeName string `json:"node_name,omitempty"`
+	// PodName restricts the analysis to snapshots of a specific calico-vpp pod
+	PodName string `json:"pod_name,omitempty"`
+	// Counters specifies the interface counters to analyze
+	Counters []string `json:"counters,omitempty"`
+	// Threshold specifies the sensitivity in scaled median absolute deviations
+	Threshold float64 `json:"threshold,omitempty"`
+	// WindowMinutes bounds the analyzed history to the most recent snapshots
+	WindowMinutes int `json:"window_minutes,omitempty"`
+}
+
+// counterAnomaly describes an anomalous counter increase between two samples
+type counterAnomaly struct {
+	Interface string
+	Counter   string
+	From      time.Time
+	To        time.Time
+	Delta     uint64
+	Rate      float64
+	Baseline  float64
+}
+
+// counterSample is the value of a counter at a point in time
+type counterSample struct {
+	At    time.Time
+	Value uint64
+}
+
+// median returns the median of a non-empty slice
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// detectCounterAnomalies flags the sample intervals whose per-second increase is far above
+// the median increase of the series. Counter resets (values going down) are skipped. The
+// deviation scale is floored to a fraction of the median rate, and increases smaller than
+// minAnomalyDelta are never flagged, so flat series don't turn every increase into an anomaly.
+func detectCounterAnomalies(iface, counter string, samples []counterSample, threshold float64) []counterAnomaly {
+	type interval struct {
+		from, to time.Time
+		delta    uint64
+		rate     float64
+	}
+
+	var intervals []interval
+	for i := 1; i < len(samples); i++ {
+		prev, cur := samples[i-1], samples[i]
+		seconds := cur.At.Sub(prev.At).Seconds()
+		if cur.Value < prev.Value || seconds <= 0 {
+			continue
+		}
+		delta := cur.Value - prev.Value
+		intervals = append(intervals, interval{prev.At, cur.At, delta, float64(delta) / seconds})
+	}
+	if len(intervals) < minAnomalyIntervals {
+		return nil
+	}
+
+	rates := make([]float64, len(intervals))
+	for i, iv := range intervals {
+		rates[i] = iv.rate
+	}
+	baseline := median(rates)
+	deviations := make([]float64, len(rates))
+	for i, rate := range rates {
+		deviations[i] = math.Abs(rate - baseline)
+	}
+	scale := math.Max(1.4826*median(deviations), minAnomalyScaleRatio*baseline)
+
+	var anomalies []counterAnomaly
+	for _, iv := range intervals {
+		if iv.delta >= minAnomalyDelta && iv.rate > baseline && iv.rate > baseline+threshold*scale {
+			anomalies = append(anomalies, counterAnomaly{
+				Interface: iface,
+				Counter:   counter,
+				From:      iv.from,
+				To:        iv.to,
+				Delta:     iv.delta,
+				Rate:      iv.rate,
+				Baseline:  baseline,
+			})
+		}
+	}
+	return anomalies
+}
+
+// handleInterfaceAnomalies implements anomaly detection on the stored interface counter history
+func (s *VPPMCPServer) handleInterfaceAnomalies(ctx context.Context, input VPPCounterAnomalyInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received interface anomaly request for node: %s, pod: %s", input.NodeName, input.PodName)
+
+	if input.NodeName == "" && input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: node_name or pod_name is required. Please specify the node whose counter history should be analyzed.",
+				},
+			},
+		}, nil, fmt.Errorf("node_name or pod_name is required")
+	}
+
+	counters := input.Counters
+	if len(counters) == 0 {
+		counters = defaultAnomalyCounters
+	}
+	threshold := input.Threshold
+	if threshold <= 0 {
+		threshold = defaultAnomalyThreshold
+	}
+	window := defaultAnomalyWindow
+	if input.WindowMinutes > 0 {
+		window = time.Duration(input.WindowMinutes) * time.Minute
+	}
+
+	// Collect the counter series of every interface from the snapshot history of the window
+	history := loadSnapshotHistory(s.artifacts, input.NodeName, input.PodName, time.Now().Add(-window), 0)
+	series := make(map[string]map[string][]counterSample)
+	samples := 0
+	for _, snapshot := range history {
+		output, ok := snapshot.VPP["show int"]
+		if !ok {
+			continue
+		}
+		samples++
+		for iface, values := range parseVppInterfaceCounters(output) {
+			if series[iface] == nil {
+				series[iface] = make(map[string][]counterSample)
+			}
+			for _, counter := range counters {
+				// A missing counter means VPP has not incremented it yet
+				series[iface][counter] = append(series[iface][counter], counterSample{At: snapshot.TakenAt, Value: values[counter]})
+			}
+		}
+	}
+
+	if samples < minAnomalyIntervals+1 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Not enough counter history: found %d samples, at least %d are required. "+
+						"Wait for the snapshot scheduler to record more samples.", samples, minAnomalyIntervals+1),
+				},
+			},
+		}, nil, nil
+	}
+
+	var anomalies []counterAnomaly
+	for iface, bySeries := range series {
+		for counter, values := range bySeries {
+			anomalies = append(anomalies, detectCounterAnomalies(iface, counter, values, threshold)...)
+		}
+	}
+	sort.Slice(anomalies, func(i, j int) bool {
+		if !anomalies[i].From.Equal(anomalies[j].From) {
+			return anomalies[i].From.Before(anomalies[j].From)
+		}
+		return anomalies[i].Interface+anomalies[i].Counter < anomalies[j].Interface+anomalies[j].Counter
+	})
+
+	var text strings.Builder
+	text.WriteString("VPP Interface Counter Anomalies:\n\n")
+	if len(anomalies) == 0 {
+		text.WriteString("No anomalous counter increases detected.\n")
+	}
+	for _, a := range anomalies {
+		text.WriteString(fmt.Sprintf("- [%s -> %s] %s %s: +%d (%.2f/s, baseline %.2f/s)\n",
+			a.From.Format(time.RFC3339), a.To.Format(time.RFC3339), a.Interface, a.Counter, a.Delta, a.Rate, a.Baseline))
+	}
+	text.WriteString(fmt.Sprintf("\nAnalysis Parameters:\n- Node: %s\n- Samples: %d (%s to %s, window %s)\n- Counters: %s\n- Threshold: %.1f\n",
+		input.NodeName, samples, history[0].TakenAt.Format(time.RFC3339), history[len(history)-1].TakenAt.Format(time.RFC3339),
+		window, strings.Join(counters, ", "), threshold))
+	if input.PodName != "" {
+		text.WriteString(fmt.Sprintf("- Pod: %s\n", input.PodName))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// counterSeries returns samples taken every minute whose value increases by the given deltas
+func counterSeries(start time.Time, deltas ...uint64) []counterSample {
+	samples := []counterSample{{At: start}}
+	for i, delta := range deltas {
+		samples = append(samples, counterSample{At: start.Add(time.Duration(i+1) * time.Minute), Value: samples[i].Value + delta})
+	}
+	return samples
+}
+
+func TestDetectCounterAnomalies(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		samples []counterSample
+		want    []uint64
+	}{
+		{
+			name:    "a few drops on a flat history",
+			samples: counterSeries(start, 0, 0, 0, 3, 0, 0),
+		},
+		{
+			name:    "burst on a flat history",
+			samples: counterSeries(start, 0, 0, 0, 500, 0, 0),
+			want:    []uint64{500},
+		},
+		{
+			name:    "fluctuation of a steady rate",
+			samples: counterSeries(start, 6000, 6000, 6000, 6600, 6000, 6000),
+		},
+		{
+			name:    "spike above a steady rate",
+			samples: counterSeries(start, 6000, 6000, 6000, 60000, 6000, 6000),
+			want:    []uint64{60000},
+		},
+		{
+			name:    "not enough intervals",
+			samples: counterSeries(start, 0, 500),
+		},
+		{
+			name: "counter reset",
+			samples: []counterSample{
+				{At: start, Value: 1000},
+				{At: start.Add(time.Minute), Value: 1000},
+				{At: start.Add(2 * time.Minute), Value: 0},
+				{At: start.Add(3 * time.Minute), Value: 0},
+				{At: start.Add(4 * time.Minute), Value: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := detectCounterAnomalies("eth0", "drops", tt.samples, defaultAnomalyThreshold)
+			var got []uint64
+			for _, anomaly := range anomalies {
+				got = append(got, anomaly.Delta)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("detectCounterAnomalies() deltas = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSnapshotHistory(t *testing.T) {
+	artifacts, err := NewArtifactStore(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, snapshot := range []*Snapshot{
+		{Pod: "calico-vpp-node-a", Node: "worker-1", TakenAt: now.Add(-3 * time.Hour)},
+		{Pod: "calico-vpp-node-a", Node: "worker-1", TakenAt: now.Add(-2 * time.Minute)},
+		{Pod: "calico-vpp-node-b", Node: "worker-2", TakenAt: now.Add(-90 * time.Second)},
+		{Pod: "calico-vpp-node-a", Node: "worker-1", TakenAt: now.Add(-time.Minute)},
+	} {
+		artifact, err := saveSnapshot(artifacts, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		artifact.CreatedAt = snapshot.TakenAt.Add(time.Duration(i) * time.Millisecond)
+	}
+
+	if got := loadSnapshotHistory(artifacts, "worker-1", "", now.Add(-time.Hour), 0); len(got) != 2 || !got[0].TakenAt.Before(got[1].TakenAt) {
+		t.Errorf("loadSnapshotHistory() of the last hour = %d snapshots, want the 2 recent ones of worker-1, oldest first", len(got))
+	}
+	if got := loadSnapshotHistory(artifacts, "", "calico-vpp-node-a", time.Time{}, 0); len(got) != 3 {
+		t.Errorf("loadSnapshotHistory() of the pod = %d snapshots, want 3", len(got))
+	}
+	if got := loadSnapshotHistory(artifacts, "", "calico-vpp-node-a", time.Time{}, 1); len(got) != 1 || !got[0].TakenAt.Equal(now.Add(-time.Minute)) {
+		t.Errorf("loadSnapshotHistory() of the latest snapshot = %v, want the one of a minute ago", got)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultAPITraceDuration = 30 * time.Second
+	maxAPITraceDuration     = 5 * time.Minute
+
+	// apiTraceFile is the file the API trace is saved to, in the /tmp directory of the vpp container
+	apiTraceFile = "vpp-mcp-api-trace.api"
+)
+
+// VPPAPITraceInput represents the input for the binary API trace tool
+type VPPAPITraceInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// DurationSeconds specifies how long the API calls are traced
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// handleAPITrace traces the binary API messages received by VPP for a while, saves the
+// trace and returns its dump
+func (s *VPPMCPServer) handleAPITrace(ctx context.Context, input VPPAPITraceInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received API trace request for pod: %s", input.PodName)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	duration := defaultAPITraceDuration
+	if input.DurationSeconds > 0 {
+		duration = time.Duration(input.DurationSeconds) * time.Second
+	}
+	if input.DurationSeconds < 0 || duration > maxAPITraceDuration {
+		err := fmt.Errorf("invalid duration_seconds %d: must be between 1 and %d", input.DurationSeconds, int(maxAPITraceDuration.Seconds()))
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 1: Start tracing the API messages
+	log.Printf("Starting API trace on pod %s", input.PodName)
+	if _, err := s.ExecutePodVPPCommand(ctx, input.PodName, "api trace on"); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error starting API trace: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 2: Let the agent talk to VPP
+	log.Printf("Tracing API messages for %s...", duration)
+	if dryRunFromContext(ctx) == nil {
+		time.Sleep(duration)
+	}
+
+	// Step 3: Save the trace, then stop tracing even if the save failed
+	_, saveErr := s.ExecutePodVPPCommand(ctx, input.PodName, "api trace save "+apiTraceFile)
+	_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, "api trace off")
+	if saveErr != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error saving API trace: %v", saveErr),
+				},
+			},
+		}, nil, saveErr
+	}
+
+	// Step 4: Dump the saved trace
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, "api trace dump "+apiTraceFile)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error dumping API trace: %v", err),
+				},
+			},
+		}, nil, nil
+	}
+
+	output := result["output"].(string)
+	if strings.TrimSpace(output) == "" {
+		output = "(no API message traced)"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("VPP Binary API Trace:\n\n%s\n\nTrace Parameters:\n- Duration: %s\n- Pod: %s\n\n**Important**: API trace saved at /tmp/%s in the vpp container\n",
+					output, duration, input.PodName, apiTraceFile),
+			},
+		},
+	}, nil, nil
+}
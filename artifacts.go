@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// Artifact kinds stored on the server host
+	artifactKindSnapshot = "snapshot"
+	artifactKindPcap     = "pcap"
+	artifactKindReport   = "report"
+	artifactKindOutput   = "output"
+	artifactKindTrace    = "trace"
+	artifactKindMRT      = "mrt"
+
+	defaultArtifactMaxBytes   = 512 * 1024 * 1024
+	defaultArtifactTTL        = 24 * time.Hour
+	artifactCollectInterval   = 5 * time.Minute
+	artifactFileNameSeparator = "_"
+)
+
+// Artifact describes a file kept in the server-side artifact store
+type Artifact struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Pod       string    `json:"pod,omitempty"`
+	Path      string    `json:"-"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ArtifactStore keeps snapshots, fetched pcaps, large traces, MRT dumps and reports on the server host
+// and garbage-collects them by age and total size
+type ArtifactStore struct {
+	mu        sync.Mutex
+	dir       string
+	maxBytes  int64
+	ttl       time.Duration
+	lastID    int64
+	artifacts map[string]*Artifact
+}
+
+// NewArtifactStore creates the store directory and indexes any artifacts left by a previous run
+func NewArtifactStore(dir string, maxBytes int64, ttl time.Duration) (*ArtifactStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory %s: %v", dir, err)
+	}
+
+	store := &ArtifactStore{
+		dir:       dir,
+		maxBytes:  maxBytes,
+		ttl:       ttl,
+		artifacts: make(map[string]*Artifact),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact directory %s: %v", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		id, name, found := strings.Cut(entry.Name(), artifactFileNameSeparator)
+		kind, _, hasKind := strings.Cut(id, "-")
+		if !found || !hasKind {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		store.artifacts[id] = &Artifact{
+			ID:        id,
+			Kind:      kind,
+			Name:      name,
+			Path:      filepath.Join(dir, entry.Name()),
+			Size:      info.Size(),
+			CreatedAt: info.ModTime(),
+		}
+	}
+
+	store.collect()
+	return store, nil
+}
+
+// nextID returns a unique, time-ordered artifact ID for the given kind
+func (a *ArtifactStore) nextID(kind string) string {
+	id := time.Now().UnixNano()
+	if id <= a.lastID {
+		id = a.lastID + 1
+	}
+	a.lastID = id
+	return fmt.Sprintf("%s-%d", kind, id)
+}
+
+// Save writes data as a new artifact and runs garbage collection, which never evicts
+// the new artifact. Data larger than the size budget of the store is rejected.
+func (a *ArtifactStore) Save(kind, pod, name string, data []byte) (*Artifact, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.maxBytes > 0 && int64(len(data)) > a.maxBytes {
+		return nil, fmt.Errorf("artifact %s of %s exceeds the artifact store size limit of %s", name, formatBytes(int64(len(data))), formatBytes(a.maxBytes))
+	}
+
+	name = filepath.Base(name)
+	id := a.nextID(kind)
+	path := filepath.Join(a.dir, id+artifactFileNameSeparator+name)
+	if err := os.WriteFile(path, data, 0o640); err != nil {
+		return nil, fmt.Errorf("failed to write artifact %s: %v", path, err)
+	}
+
+	artifact := &Artifact{
+		ID:        id,
+		Kind:      kind,
+		Name:      name,
+		Pod:       pod,
+		Path:      path,
+		Size:      int64(len(data)),
+		CreatedAt: time.Now(),
+	}
+	a.artifacts[id] = artifact
+	log.Printf("Stored artifact %s (%d bytes) at %s", id, artifact.Size, path)
+
+	a.collectLocked(id)
+	return artifact, nil
+}
+
+// Get returns the artifact with the given ID
+func (a *ArtifactStore) Get(id string) (*Artifact, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	artifact, ok := a.artifacts[id]
+	return artifact, ok
+}
+
+// Read returns the content of the artifact with the given ID
+func (a *ArtifactStore) Read(id string) ([]byte, error) {
+	artifact, ok := a.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("artifact %s not found", id)
+	}
+	return os.ReadFile(artifact.Path)
+}
+
+// List returns all artifacts, newest first
+func (a *ArtifactStore) List() []*Artifact {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	list := make([]*Artifact, 0, len(a.artifacts))
+	for _, artifact := range a.artifacts {
+		list = append(list, artifact)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list
+}
+
+// totalSize returns the combined size of all artifacts
+func (a *ArtifactStore) totalSize() int64 {
+	var total int64
+	for _, artifact := range a.artifacts {
+		total += artifact.Size
+	}
+	return total
+}
+
+// remove deletes an artifact from disk and from the index, reporting whether it was removed
+func (a *ArtifactStore) remove(artifact *Artifact, reason string) bool {
+	if err := os.Remove(artifact.Path); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove artifact %s: %v", artifact.ID, err)
+		return false
+	}
+	delete(a.artifacts, artifact.ID)
+	log.Printf("Removed artifact %s (%s)", artifact.ID, reason)
+	return true
+}
+
+// collect runs garbage collection
+func (a *ArtifactStore) collect() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.collectLocked("")
+}
+
+// collectLocked removes expired artifacts, then the oldest ones until the store fits its size budget.
+// The artifact with ID keep, if any, is never removed.
+func (a *ArtifactStore) collectLocked(keep string) {
+	now := time.Now()
+	var remaining []*Artifact
+	for _, artifact := range a.artifacts {
+		if artifact.ID == keep {
+			continue
+		}
+		if a.ttl > 0 && now.Sub(artifact.CreatedAt) > a.ttl {
+			a.remove(artifact, "expired")
+			continue
+		}
+		remaining = append(remaining, artifact)
+	}
+
+	if a.maxBytes <= 0 {
+		return
+	}
+	sort.Slice(remaining, func(i, j int) bool {
+		return remaining[i].CreatedAt.Before(remaining[j].CreatedAt)
+	})
+	total := a.totalSize()
+	for _, artifact := range remaining {
+		if total <= a.maxBytes {
+			break
+		}
+		if a.remove(artifact, "size limit exceeded") {
+			total -= artifact.Size
+		}
+	}
+}
+
+// Run periodically garbage-collects the store until the context is cancelled
+func (a *ArtifactStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.collect()
+		}
+	}
+}
+
+// formatBytes renders a byte count in a human-readable form
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// handleListArtifacts implements listing the artifacts kept in the server-side store
+func (s *VPPMCPServer) handleListArtifacts(ctx context.Context, input EmptyInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received artifact_list request")
+
+	artifacts := s.artifacts.List()
+	if len(artifacts) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("No artifacts stored in %s", s.artifacts.dir),
+				},
+			},
+		}, nil, nil
+	}
+
+	var text strings.Builder
+	text.WriteString("Stored Artifacts:\n\n")
+	for _, artifact := range artifacts {
+		text.WriteString(fmt.Sprintf("- %s\n  Kind: %s\n  Name: %s\n  Size: %s\n  Created: %s (%s ago)\n",
+			artifact.ID, artifact.Kind, artifact.Name, formatBytes(artifact.Size),
+			artifact.CreatedAt.Format(time.RFC3339), time.Since(artifact.CreatedAt).Round(time.Second)))
+		if artifact.Pod != "" {
+			text.WriteString(fmt.Sprintf("  Pod: %s\n", artifact.Pod))
+		}
+	}
+
+	s.artifacts.mu.Lock()
+	total := s.artifacts.totalSize()
+	s.artifacts.mu.Unlock()
+	text.WriteString(fmt.Sprintf("\nTotal: %d artifacts, %s of %s budget\nRetention: %s\nDirectory: %s",
+		len(artifacts), formatBytes(total), formatBytes(s.artifacts.maxBytes), s.artifacts.ttl, s.artifacts.dir))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestArtifactStoreSizeLimit(t *testing.T) {
+	artifacts, err := NewArtifactStore(t.TempDir(), 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := artifacts.Save(artifactKindSnapshot, "calico-vpp-node-a", "worker-1.json", []byte("123456"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := artifacts.Save(artifactKindSnapshot, "calico-vpp-node-a", "worker-1.json", []byte("12345678901")); err == nil {
+		t.Error("Save() of an artifact larger than the store succeeded")
+	}
+	if _, ok := artifacts.Get(first.ID); !ok {
+		t.Error("Save() of an oversized artifact evicted the stored ones")
+	}
+
+	second, err := artifacts.Save(artifactKindSnapshot, "calico-vpp-node-a", "worker-1.json", []byte("1234567890"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := artifacts.Get(second.ID); !ok {
+		t.Error("Save() evicted the artifact it saved")
+	}
+	if _, ok := artifacts.Get(first.ID); ok {
+		t.Error("Save() kept the oldest artifact beyond the size limit")
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultBGPMonitorDuration = 30 * time.Second
+	maxBGPMonitorDuration     = 10 * time.Minute
+	// bgpMonitorMaxEvents bounds the events listed in the result, the counters cover all events
+	bgpMonitorMaxEvents = 200
+)
+
+// bgpMonitorEventRegexp matches an event of 'gobgp monitor global rib', e.g.
+// "2024-05-06T10:00:00Z [ROUTE] 10.0.1.0/26 via 192.168.0.2 aspath [65001] attrs [{Origin: i}]"
+var bgpMonitorEventRegexp = regexp.MustCompile(`^(\S+) \[(ROUTE|DELROUTE)\] (\S+) via (\S+) aspath \[([^\]]*)\]`)
+
+// BGPMonitorInput represents the input for monitoring the BGP global RIB
+type BGPMonitorInput struct {
+	// PodName specifies the name of the Kubernetes pod running the agent container with gobgp
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// DurationSeconds specifies how long the RIB is monitored
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// DryRun returns the command the call would execute without running it
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// bgpEvent is a route update of the global RIB
+type bgpEvent struct {
+	Time     string `json:"time"`
+	Action   string `json:"action"`
+	Prefix   string `json:"prefix"`
+	NextHop  string `json:"next_hop"`
+	ASPath   string `json:"as_path,omitempty"`
+	Withdraw bool   `json:"withdraw"`
+}
+
+// bgpPrefixChanges counts the events of one prefix
+type bgpPrefixChanges struct {
+	Prefix    string `json:"prefix"`
+	Adds      int    `json:"adds"`
+	Withdraws int    `json:"withdraws"`
+}
+
+// flapping reports whether the prefix was both withdrawn and advertised, or advertised more than once
+func (c bgpPrefixChanges) flapping() bool {
+	return (c.Adds > 0 && c.Withdraws > 0) || c.Adds > 1 || c.Withdraws > 1
+}
+
+// parseBGPMonitorEvents reads the events of 'gobgp monitor global rib' output
+func parseBGPMonitorEvents(output string) []bgpEvent {
+	var events []bgpEvent
+	for _, line := range strings.Split(output, "\n") {
+		match := bgpMonitorEventRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		event := bgpEvent{Time: match[1], Prefix: match[3], NextHop: match[4], ASPath: match[5], Action: "add"}
+		if match[2] == "DELROUTE" {
+			event.Action, event.Withdraw = "withdraw", true
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// countBGPPrefixChanges counts the events of each prefix, the most changed prefixes first
+func countBGPPrefixChanges(events []bgpEvent) []bgpPrefixChanges {
+	byPrefix := make(map[string]*bgpPrefixChanges)
+	for _, event := range events {
+		changes, ok := byPrefix[event.Prefix]
+		if !ok {
+			changes = &bgpPrefixChanges{Prefix: event.Prefix}
+			byPrefix[event.Prefix] = changes
+		}
+		if event.Withdraw {
+			changes.Withdraws++
+		} else {
+			changes.Adds++
+		}
+	}
+	counts := make([]bgpPrefixChanges, 0, len(byPrefix))
+	for _, changes := range byPrefix {
+		counts = append(counts, *changes)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if ci, cj := counts[i].Adds+counts[i].Withdraws, counts[j].Adds+counts[j].Withdraws; ci != cj {
+			return ci > cj
+		}
+		return counts[i].Prefix < counts[j].Prefix
+	})
+	return counts
+}
+
+// handleBGPMonitor runs 'gobgp monitor global rib' for a bounded duration and reports the route
+// additions and withdrawals observed in that window, flagging the flapping prefixes
+func (s *VPPMCPServer) handleBGPMonitor(ctx context.Context, input BGPMonitorInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received BGP monitor request for pod: %s, duration: %ds", input.PodName, input.DurationSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: Pod name is required. Please specify the Kubernetes pod name or node name.",
+				},
+			},
+		}, nil, fmt.Errorf("pod name is required")
+	}
+
+	duration := defaultBGPMonitorDuration
+	if input.DurationSeconds > 0 {
+		duration = time.Duration(input.DurationSeconds) * time.Second
+	}
+	var err error
+	switch {
+	case input.DurationSeconds < 0 || duration > maxBGPMonitorDuration:
+		err = fmt.Errorf("invalid duration_seconds %d: must be between 1 and %d", input.DurationSeconds, int(maxBGPMonitorDuration.Seconds()))
+	default:
+		err = validatePodName(input.PodName)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	command := "monitor global rib"
+	container := execContainer(ctx, agentContainerName)
+	args := gobgpArgs(command)
+
+	if recorder := dryRunFromContext(ctx); recorder != nil {
+		recorder.record(s.executor.CommandLine(input.PodName, container, args))
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("BGP Monitor: gobgp %s would run for %s on pod %s\n", command, duration, input.PodName),
+				},
+			},
+		}, nil, nil
+	}
+
+	release, err := s.acquireExecSlot(ctx)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	defer release()
+
+	// gobgp monitor never exits by itself: the end of the window kills it, keeping the events printed so far
+	start := time.Now()
+	monitorCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	output, err := s.executor.Exec(monitorCtx, input.PodName, container, args)
+	if err != nil && !(errors.Is(monitorCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error executing gobgp command on pod %s: %v\nCommand attempted: gobgp %s", input.PodName, err, command),
+				},
+			},
+		}, nil, err
+	}
+	elapsed := time.Since(start).Round(time.Second)
+
+	events := parseBGPMonitorEvents(string(output))
+	counts := countBGPPrefixChanges(events)
+	var flapping []bgpPrefixChanges
+	adds, withdraws := 0, 0
+	for _, changes := range counts {
+		adds += changes.Adds
+		withdraws += changes.Withdraws
+		if changes.flapping() {
+			flapping = append(flapping, changes)
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("BGP Monitor of pod %s (%s):\n\n", input.PodName, elapsed))
+	text.WriteString(fmt.Sprintf("Events: %d (%d added, %d withdrawn) on %d prefix(es)\n", len(events), adds, withdraws, len(counts)))
+	if len(events) == 0 {
+		text.WriteString("\nNo route was added or withdrawn during the window: the global RIB is stable.\n")
+	}
+	if len(flapping) > 0 {
+		text.WriteString(fmt.Sprintf("\nFlapping prefixes (%d):\n", len(flapping)))
+		for _, changes := range flapping {
+			text.WriteString(fmt.Sprintf("- %s: %d added, %d withdrawn\n", changes.Prefix, changes.Adds, changes.Withdraws))
+		}
+	}
+	if len(events) > 0 {
+		text.WriteString("\nEvents:\n")
+		for i, event := range events {
+			if i == bgpMonitorMaxEvents {
+				text.WriteString(fmt.Sprintf("... and %d more events\n", len(events)-i))
+				break
+			}
+			line := fmt.Sprintf("- %s %-8s %s via %s", event.Time, event.Action, event.Prefix, event.NextHop)
+			if event.ASPath != "" {
+				line += " aspath [" + event.ASPath + "]"
+			}
+			text.WriteString(line + "\n")
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"pod":              input.PodName,
+			"duration_seconds": int(elapsed.Seconds()),
+			"events":           events,
+			"prefixes":         counts,
+			"flapping":         flapping,
+		},
+	}, nil, nil
+}
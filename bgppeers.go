@@ -0,0 +1,535 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	bgpStateEstablished    = "Establ"
+	defaultBGPFlapInterval = 30 * time.Second
+	maxBGPFlapInterval     = 10 * time.Minute
+	// bgpUptimeSlack absorbs the one second resolution of the uptime and the time taken to sample the pods
+	bgpUptimeSlack = 5 * time.Second
+)
+
+// bgpPeer is a BGP neighbor as listed by 'gobgp neighbor'
+type bgpPeer struct {
+	Address string `json:"address"`
+	AS      string `json:"as"`
+	// UpDown is the uptime of an established session, or the time since the session went down
+	UpDown   string        `json:"up_down"`
+	Since    time.Duration `json:"-"`
+	State    string        `json:"state"`
+	Received int           `json:"received"`
+	Accepted int           `json:"accepted"`
+}
+
+// parseBGPUpDown reads the Up/Down column of 'gobgp neighbor', e.g. "00:10:23" or "2d 03:04:05".
+// It reports false for "never" and unknown values.
+func parseBGPUpDown(value string) (time.Duration, bool) {
+	var days int
+	if d, clock, ok := strings.Cut(value, "d "); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, false
+		}
+		days, value = n, clock
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	var clock [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, false
+		}
+		clock[i] = n
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(clock[0])*time.Hour + time.Duration(clock[1])*time.Minute + time.Duration(clock[2])*time.Second, true
+}
+
+// parseBGPNeighbors reads the peers of 'gobgp neighbor' output, e.g.
+// "192.168.0.2  65000 00:10:23 Establ      |        5         5"
+func parseBGPNeighbors(output string) []bgpPeer {
+	var peers []bgpPeer
+	for _, line := range strings.Split(output, "\n") {
+		left, right, ok := strings.Cut(line, "|")
+		fields := strings.Fields(left)
+		if !ok || len(fields) < 4 || fields[0] == "Peer" {
+			continue
+		}
+		peer := bgpPeer{Address: fields[0], AS: fields[1], State: fields[len(fields)-1]}
+		peer.UpDown = strings.Join(fields[2:len(fields)-1], " ")
+		peer.Since, _ = parseBGPUpDown(peer.UpDown)
+		if counts := strings.Fields(right); len(counts) == 2 {
+			peer.Received, _ = strconv.Atoi(counts[0])
+			peer.Accepted, _ = strconv.Atoi(counts[1])
+		}
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+// knownUptime reports whether the Up/Down column of a peer could be read
+func knownUptime(peer bgpPeer) bool {
+	_, ok := parseBGPUpDown(peer.UpDown)
+	return ok
+}
+
+// bgpPeerSample holds the peers of one pod at a point in time
+type bgpPeerSample struct {
+	Pod     string
+	Node    string
+	TakenAt time.Time
+	Peers   []bgpPeer
+	Err     error
+}
+
+// resolveBGPPods returns the calico-vpp pods named, or every calico-vpp pod when names is empty
+func (s *VPPMCPServer) resolveBGPPods(ctx context.Context, names []string) ([]VPPPod, error) {
+	if s.bypassesKubernetes() {
+		if len(names) == 0 {
+			return nil, fmt.Errorf("pod_names is required when commands run without Kubernetes")
+		}
+		pods := make([]VPPPod, 0, len(names))
+		for _, name := range names {
+			pods = append(pods, VPPPod{Name: name})
+		}
+		return pods, nil
+	}
+
+	k8sClient, err := newKubeClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+	pods, err := listVPPPods(ctx, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		if len(pods) == 0 {
+			return nil, fmt.Errorf("no calico-vpp pod found")
+		}
+		return pods, nil
+	}
+	podByName := make(map[string]VPPPod)
+	for _, pod := range pods {
+		podByName[pod.Name] = pod
+	}
+	selected := make([]VPPPod, 0, len(names))
+	for _, name := range names {
+		pod, ok := podByName[name]
+		if !ok {
+			return nil, fmt.Errorf("pod %s is not a calico-vpp pod", name)
+		}
+		selected = append(selected, pod)
+	}
+	return selected, nil
+}
+
+// sampleBGPPeers runs 'gobgp neighbor' on every pod at the same time
+func (s *VPPMCPServer) sampleBGPPeers(ctx context.Context, pods []VPPPod) []*bgpPeerSample {
+	samples := make([]*bgpPeerSample, len(pods))
+	var wg sync.WaitGroup
+	for i, pod := range pods {
+		samples[i] = &bgpPeerSample{Pod: pod.Name, Node: pod.Node}
+		wg.Add(1)
+		go func(sample *bgpPeerSample) {
+			defer wg.Done()
+			result, err := s.ExecutePodGoBGPCommand(ctx, sample.Pod, "neighbor")
+			sample.TakenAt = time.Now()
+			if err != nil {
+				sample.Err = err
+				return
+			}
+			sample.Peers = parseBGPNeighbors(result["output"].(string))
+		}(samples[i])
+	}
+	wg.Wait()
+	return samples
+}
+
+// BGPPeerFlapsInput represents the input for detecting flapping BGP peers
+type BGPPeerFlapsInput struct {
+	// PodNames lists the calico-vpp pods to check (default: every calico-vpp pod)
+	PodNames []string `json:"pod_names,omitempty"`
+	// NodeNames selects the calico-vpp pods running on Kubernetes nodes, in addition to PodNames
+	NodeNames []string `json:"node_names,omitempty"`
+	// IntervalSeconds specifies the time between the two samples of the peers
+	IntervalSeconds int `json:"interval_seconds,omitempty"`
+	// FromSnapshots compares the peers with the latest stored snapshot of each pod instead of sampling them twice
+	FromSnapshots bool `json:"from_snapshots,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pods
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// bgpPeerFlap is a change of a BGP session between two samples
+type bgpPeerFlap struct {
+	Pod    string `json:"pod"`
+	Node   string `json:"node,omitempty"`
+	Peer   string `json:"peer"`
+	Event  string `json:"event"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// compareBGPPeerSamples returns the sessions of a pod that restarted, went down, came up,
+// appeared or disappeared between two samples
+func compareBGPPeerSamples(before, after *bgpPeerSample) []bgpPeerFlap {
+	elapsed := after.TakenAt.Sub(before.TakenAt)
+	previous := make(map[string]bgpPeer)
+	for _, peer := range before.Peers {
+		previous[peer.Address] = peer
+	}
+	describe := func(peer bgpPeer) string {
+		return fmt.Sprintf("%s %s", peer.State, peer.UpDown)
+	}
+
+	var flaps []bgpPeerFlap
+	for _, peer := range after.Peers {
+		flap := bgpPeerFlap{Pod: after.Pod, Node: after.Node, Peer: peer.Address, After: describe(peer)}
+		old, ok := previous[peer.Address]
+		delete(previous, peer.Address)
+		switch {
+		case !ok:
+			flap.Event, flap.Before = "added", "absent"
+		case old.State == bgpStateEstablished && peer.State != bgpStateEstablished:
+			flap.Event = "went down"
+		case old.State != bgpStateEstablished && peer.State == bgpStateEstablished:
+			flap.Event = "came up"
+		case old.State == bgpStateEstablished && knownUptime(old) && knownUptime(peer) && peer.Since+bgpUptimeSlack < old.Since+elapsed:
+			// An uninterrupted session has been up for its previous uptime plus the time between the samples
+			flap.Event = "restarted"
+		default:
+			continue
+		}
+		if flap.Before == "" {
+			flap.Before = describe(old)
+		}
+		flaps = append(flaps, flap)
+	}
+	for _, peer := range before.Peers {
+		if old, ok := previous[peer.Address]; ok {
+			flaps = append(flaps, bgpPeerFlap{Pod: after.Pod, Node: after.Node, Peer: old.Address, Event: "removed", Before: describe(old), After: "absent"})
+		}
+	}
+	return flaps
+}
+
+// handleBGPPeerFlaps samples the BGP neighbors of several pods twice, or compares them with the
+// latest stored snapshots, and flags the sessions that restarted in between
+func (s *VPPMCPServer) handleBGPPeerFlaps(ctx context.Context, input BGPPeerFlapsInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received BGP peer flap request for pods: %v, interval: %ds, from snapshots: %t", input.PodNames, input.IntervalSeconds, input.FromSnapshots)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	interval := defaultBGPFlapInterval
+	if input.IntervalSeconds > 0 {
+		interval = time.Duration(input.IntervalSeconds) * time.Second
+	}
+	var err error
+	switch {
+	case input.IntervalSeconds < 0 || interval > maxBGPFlapInterval:
+		err = fmt.Errorf("invalid interval_seconds %d: must be between 1 and %d", input.IntervalSeconds, int(maxBGPFlapInterval.Seconds()))
+	case input.FromSnapshots && input.IntervalSeconds != 0:
+		err = fmt.Errorf("interval_seconds and from_snapshots are exclusive")
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	pods, err := s.resolveBGPPods(ctx, input.PodNames)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Take the first sample from the snapshots, or from the pods before waiting for the interval
+	var baseline []*bgpPeerSample
+	if input.FromSnapshots {
+		for _, pod := range pods {
+			sample := &bgpPeerSample{Pod: pod.Name, Node: pod.Node}
+			history := loadSnapshotHistory(s.artifacts, "", pod.Name, time.Time{}, 1)
+			if len(history) == 0 {
+				sample.Err = fmt.Errorf("no stored snapshot, take one with vpp_snapshot")
+			} else if output, ok := history[len(history)-1].GoBGP["neighbor"]; !ok {
+				sample.Err = fmt.Errorf("gobgp neighbor not recorded in snapshot %s", history[len(history)-1].ID)
+			} else {
+				sample.TakenAt = history[len(history)-1].TakenAt
+				sample.Peers = parseBGPNeighbors(output)
+			}
+			baseline = append(baseline, sample)
+		}
+	} else {
+		baseline = s.sampleBGPPeers(ctx, pods)
+		if dryRunFromContext(ctx) == nil {
+			select {
+			case <-ctx.Done():
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{
+							Text: fmt.Sprintf("Error: peer flap detection interrupted: %v", ctx.Err()),
+						},
+					},
+				}, nil, ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+	}
+	current := s.sampleBGPPeers(ctx, pods)
+
+	var text strings.Builder
+	text.WriteString("BGP Peer Flap Report:\n\n")
+	var flaps []bgpPeerFlap
+	peers, failed := 0, 0
+	for i, sample := range current {
+		label := "pod " + sample.Pod
+		if sample.Node != "" {
+			label += fmt.Sprintf(" (node %s)", sample.Node)
+		}
+		if err := baseline[i].Err; err != nil {
+			failed++
+			text.WriteString(fmt.Sprintf("- %s: baseline unavailable: %v\n", label, err))
+			continue
+		}
+		if sample.Err != nil {
+			failed++
+			text.WriteString(fmt.Sprintf("- %s: gobgp neighbor failed: %v\n", label, sample.Err))
+			continue
+		}
+		peers += len(sample.Peers)
+		podFlaps := compareBGPPeerSamples(baseline[i], sample)
+		flaps = append(flaps, podFlaps...)
+		if len(podFlaps) == 0 {
+			text.WriteString(fmt.Sprintf("- %s: %d peer(s), all stable over %s\n", label, len(sample.Peers), sample.TakenAt.Sub(baseline[i].TakenAt).Round(time.Second)))
+			continue
+		}
+		text.WriteString(fmt.Sprintf("- %s: %d of %d peer(s) changed over %s\n", label, len(podFlaps), len(sample.Peers), sample.TakenAt.Sub(baseline[i].TakenAt).Round(time.Second)))
+		for _, flap := range podFlaps {
+			text.WriteString(fmt.Sprintf("  - %s %s (was %s, now %s)\n", flap.Peer, flap.Event, flap.Before, flap.After))
+		}
+	}
+
+	baselineSource := fmt.Sprintf("two samples %s apart", interval)
+	if input.FromSnapshots {
+		baselineSource = "latest stored snapshot of each pod"
+	}
+	text.WriteString(fmt.Sprintf("\nSummary: %d session change(s) on %d peer(s) of %d pod(s) (%d failed)\nBaseline: %s\n",
+		len(flaps), peers, len(pods), failed, baselineSource))
+	if len(flaps) > 0 {
+		text.WriteString("\n**Note**: A restarted session was reset and re-established between the samples: its routes were withdrawn meanwhile. Check the agent logs of the pod for the reason.\n")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"pods":  len(pods),
+			"peers": peers,
+			"flaps": flaps,
+		},
+	}, nil, nil
+}
+
+// bgpRouteStatRegexp matches a counter of the route statistics of 'gobgp neighbor <ip>', e.g. "Advertised:   5"
+var bgpRouteStatRegexp = regexp.MustCompile(`^\s*(Advertised|Received|Accepted):\s+(\d+)\s*$`)
+
+// parseBGPRouteStats sums the route counters of 'gobgp neighbor <ip>' output over its address families
+func parseBGPRouteStats(output string) (advertised, received, accepted int, ok bool) {
+	for _, line := range strings.Split(output, "\n") {
+		match := bgpRouteStatRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(match[2])
+		switch match[1] {
+		case "Advertised":
+			advertised += n
+		case "Received":
+			received += n
+		case "Accepted":
+			accepted += n
+		}
+		ok = true
+	}
+	return advertised, received, accepted, ok
+}
+
+// BGPRouteCountsInput represents the input for reporting the route counts of the BGP peers
+type BGPRouteCountsInput struct {
+	// PodNames lists the calico-vpp pods to report (default: every calico-vpp pod)
+	PodNames []string `json:"pod_names,omitempty"`
+	// NodeNames selects the calico-vpp pods running on Kubernetes nodes, in addition to PodNames
+	NodeNames []string `json:"node_names,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pods
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// bgpPeerRoutes holds the route counts of one peer of a pod
+type bgpPeerRoutes struct {
+	Pod        string `json:"pod"`
+	Node       string `json:"node,omitempty"`
+	Peer       string `json:"peer"`
+	State      string `json:"state"`
+	Received   int    `json:"received"`
+	Accepted   int    `json:"accepted"`
+	Advertised int    `json:"advertised"`
+	// statistics reports whether the route statistics of the peer could be read, Advertised is unknown otherwise
+	statistics bool
+	// Outliers describes what is wrong with the counts of the peer
+	Outliers []string `json:"outliers,omitempty"`
+}
+
+// flagBGPRouteOutliers describes the peers whose counts stand out: established sessions exchanging
+// no route, routes rejected by policy, and peers accepting far fewer routes than the other peers
+// of the cluster
+func flagBGPRouteOutliers(routes []*bgpPeerRoutes) {
+	var accepted []int
+	for _, r := range routes {
+		if r.State == bgpStateEstablished {
+			accepted = append(accepted, r.Accepted)
+		}
+	}
+	sort.Ints(accepted)
+	median := 0
+	if len(accepted) > 0 {
+		median = accepted[len(accepted)/2]
+	}
+
+	for _, r := range routes {
+		if r.State != bgpStateEstablished {
+			r.Outliers = append(r.Outliers, "session not established")
+			continue
+		}
+		switch {
+		case r.Received == 0:
+			r.Outliers = append(r.Outliers, "receives no route")
+		case r.Accepted < r.Received:
+			r.Outliers = append(r.Outliers, fmt.Sprintf("%d route(s) rejected by import policy", r.Received-r.Accepted))
+		}
+		if r.statistics && r.Advertised == 0 {
+			r.Outliers = append(r.Outliers, "advertises no route")
+		}
+		if r.Received > 0 && r.Accepted*2 < median {
+			r.Outliers = append(r.Outliers, fmt.Sprintf("accepts %d route(s), half the cluster median of %d", r.Accepted, median))
+		}
+	}
+}
+
+// handleBGPRouteCounts reports the routes received, accepted and advertised by every BGP peer of
+// several pods, highlighting the outliers such as one node receiving no route
+func (s *VPPMCPServer) handleBGPRouteCounts(ctx context.Context, input BGPRouteCountsInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received BGP route count request for pods: %v", input.PodNames)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	pods, err := s.resolveBGPPods(ctx, input.PodNames)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// List the peers of every pod, then read the route statistics of each peer
+	samples := s.sampleBGPPeers(ctx, pods)
+	podRoutes := make([][]*bgpPeerRoutes, len(samples))
+	var wg sync.WaitGroup
+	for i, sample := range samples {
+		if sample.Err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, sample *bgpPeerSample) {
+			defer wg.Done()
+			for _, peer := range sample.Peers {
+				r := &bgpPeerRoutes{Pod: sample.Pod, Node: sample.Node, Peer: peer.Address, State: peer.State, Received: peer.Received, Accepted: peer.Accepted}
+				result, err := s.ExecutePodGoBGPCommand(ctx, sample.Pod, "neighbor "+peer.Address)
+				if err == nil {
+					if advertised, received, accepted, ok := parseBGPRouteStats(result["output"].(string)); ok {
+						r.Advertised, r.Received, r.Accepted, r.statistics = advertised, received, accepted, true
+					}
+				} else {
+					log.Printf("Failed to read the route statistics of peer %s of pod %s: %v", peer.Address, sample.Pod, err)
+				}
+				podRoutes[i] = append(podRoutes[i], r)
+			}
+		}(i, sample)
+	}
+	wg.Wait()
+
+	var routes []*bgpPeerRoutes
+	for _, r := range podRoutes {
+		routes = append(routes, r...)
+	}
+	flagBGPRouteOutliers(routes)
+
+	var text strings.Builder
+	text.WriteString("BGP Route Counts per Peer:\n\n")
+	text.WriteString(fmt.Sprintf("%-40s %-16s %-8s %10s %10s %10s\n", "Pod", "Peer", "State", "Received", "Accepted", "Advertised"))
+	outliers, failed := 0, 0
+	for i, sample := range samples {
+		if sample.Err != nil {
+			failed++
+			text.WriteString(fmt.Sprintf("%-40s gobgp neighbor failed: %v\n", sample.Pod, sample.Err))
+			continue
+		}
+		if len(podRoutes[i]) == 0 {
+			text.WriteString(fmt.Sprintf("%-40s no peer\n", sample.Pod))
+		}
+		for _, r := range podRoutes[i] {
+			marker := ""
+			if len(r.Outliers) > 0 {
+				outliers++
+				marker = "  <- " + strings.Join(r.Outliers, ", ")
+			}
+			text.WriteString(fmt.Sprintf("%-40s %-16s %-8s %10d %10d %10d%s\n", r.Pod, r.Peer, r.State, r.Received, r.Accepted, r.Advertised, marker))
+		}
+	}
+	text.WriteString(fmt.Sprintf("\nSummary: %d peer(s) on %d pod(s) (%d failed), %d outlier(s)\n", len(routes), len(pods), failed, outliers))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"peers":    routes,
+			"outliers": outliers,
+		},
+	}, nil, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseBGPUpDown(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"00:10:23", 10*time.Minute + 23*time.Second, true},
+		{"2d 03:04:05", 51*time.Hour + 4*time.Minute + 5*time.Second, true},
+		{"never", 0, false},
+		{"10:23", 0, false},
+		{"xd 00:00:01", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseBGPUpDown(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseBGPUpDown(%q) = %s, %t, want %s, %t", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseBGPNeighbors(t *testing.T) {
+	output := `Peer            AS  Up/Down State       |#Received  Accepted
+192.168.0.2  65000 00:10:23 Establ      |        5         4
+192.168.0.3  65000 2d 03:04:05 Establ   |       12        12
+192.168.0.4  65001   never Active      |        0         0
+`
+	want := []bgpPeer{
+		{Address: "192.168.0.2", AS: "65000", UpDown: "00:10:23", Since: 10*time.Minute + 23*time.Second, State: "Establ", Received: 5, Accepted: 4},
+		{Address: "192.168.0.3", AS: "65000", UpDown: "2d 03:04:05", Since: 51*time.Hour + 4*time.Minute + 5*time.Second, State: "Establ", Received: 12, Accepted: 12},
+		{Address: "192.168.0.4", AS: "65001", UpDown: "never", State: "Active"},
+	}
+
+	if got := parseBGPNeighbors(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBGPNeighbors() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompareBGPPeerSamples(t *testing.T) {
+	taken := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	peer := func(address, state, upDown string) bgpPeer {
+		since, _ := parseBGPUpDown(upDown)
+		return bgpPeer{Address: address, State: state, UpDown: upDown, Since: since}
+	}
+	before := &bgpPeerSample{Pod: "calico-vpp-node-a", Node: "node-a", TakenAt: taken, Peers: []bgpPeer{
+		peer("192.168.0.2", "Establ", "00:10:00"),
+		peer("192.168.0.3", "Establ", "00:10:00"),
+		peer("192.168.0.4", "Establ", "00:10:00"),
+		peer("192.168.0.5", "Active", "never"),
+		peer("192.168.0.6", "Establ", "01:00:00"),
+	}}
+	after := &bgpPeerSample{Pod: "calico-vpp-node-a", Node: "node-a", TakenAt: taken.Add(30 * time.Second), Peers: []bgpPeer{
+		// Up for the previous uptime plus the interval: uninterrupted
+		peer("192.168.0.2", "Establ", "00:10:30"),
+		// Up for less than the interval: reset in between
+		peer("192.168.0.3", "Establ", "00:00:12"),
+		peer("192.168.0.4", "Idle", "00:00:05"),
+		peer("192.168.0.5", "Establ", "00:00:20"),
+		peer("192.168.0.7", "Establ", "00:00:25"),
+	}}
+
+	want := []bgpPeerFlap{
+		{Pod: "calico-vpp-node-a", Node: "node-a", Peer: "192.168.0.3", Event: "restarted", Before: "Establ 00:10:00", After: "Establ 00:00:12"},
+		{Pod: "calico-vpp-node-a", Node: "node-a", Peer: "192.168.0.4", Event: "went down", Before: "Establ 00:10:00", After: "Idle 00:00:05"},
+		{Pod: "calico-vpp-node-a", Node: "node-a", Peer: "192.168.0.5", Event: "came up", Before: "Active never", After: "Establ 00:00:20"},
+		{Pod: "calico-vpp-node-a", Node: "node-a", Peer: "192.168.0.7", Event: "added", Before: "absent", After: "Establ 00:00:25"},
+		{Pod: "calico-vpp-node-a", Node: "node-a", Peer: "192.168.0.6", Event: "removed", Before: "Establ 01:00:00", After: "absent"},
+	}
+	if got := compareBGPPeerSamples(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("compareBGPPeerSamples() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseBGPRouteStats(t *testing.T) {
+	output := `BGP neighbor is 192.168.0.2, remote AS 65000
+  BGP version 4, remote router ID 192.168.0.2
+  BGP state = BGP_FSM_ESTABLISHED, up for 00:10:23
+  Route statistics:
+    Advertised:             3
+    Received:               5
+    Accepted:               4
+  Route statistics:
+    Advertised:             1
+    Received:               2
+    Accepted:               2
+`
+	advertised, received, accepted, ok := parseBGPRouteStats(output)
+	if advertised != 4 || received != 7 || accepted != 6 || !ok {
+		t.Errorf("parseBGPRouteStats() = %d, %d, %d, %t, want 4, 7, 6, true", advertised, received, accepted, ok)
+	}
+
+	if _, _, _, ok := parseBGPRouteStats("BGP neighbor is 192.168.0.2, remote AS 65000\n"); ok {
+		t.Error("parseBGPRouteStats() of output without statistics reported ok")
+	}
+}
+
+func TestFlagBGPRouteOutliers(t *testing.T) {
+	routes := []*bgpPeerRoutes{
+		{Peer: "healthy-1", State: "Establ", Received: 10, Accepted: 10, Advertised: 3, statistics: true},
+		{Peer: "healthy-2", State: "Establ", Received: 10, Accepted: 10, Advertised: 3, statistics: true},
+		{Peer: "rejecting", State: "Establ", Received: 10, Accepted: 4, Advertised: 3, statistics: true},
+		{Peer: "silent", State: "Establ", Received: 0, Accepted: 0, Advertised: 0, statistics: true},
+		{Peer: "no-statistics", State: "Establ", Received: 10, Accepted: 10},
+		{Peer: "down", State: "Active"},
+	}
+	flagBGPRouteOutliers(routes)
+
+	want := map[string][]string{
+		"healthy-1":     nil,
+		"healthy-2":     nil,
+		"rejecting":     {"6 route(s) rejected by import policy", "accepts 4 route(s), half the cluster median of 10"},
+		"silent":        {"receives no route", "advertises no route"},
+		"no-statistics": nil,
+		"down":          {"session not established"},
+	}
+	for _, r := range routes {
+		if !reflect.DeepEqual(r.Outliers, want[r.Peer]) {
+			t.Errorf("outliers of %s = %q, want %q", r.Peer, r.Outliers, want[r.Peer])
+		}
+	}
+}
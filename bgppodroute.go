@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// BGPPodRouteInput represents the input for finding the route of a workload pod IP on every node
+type BGPPodRouteInput struct {
+	// PodIP specifies the IP address of the workload pod
+	PodIP string `json:"pod_ip"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pods
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// bgpPodRoute is the route of a workload pod IP on one node, in the BGP RIB and in the VPP FIB
+type bgpPodRoute struct {
+	Pod  string `json:"pod"`
+	Node string `json:"node"`
+	// Owner reports whether the workload pod runs on this node
+	Owner         bool     `json:"owner"`
+	RIBPrefix     string   `json:"rib_prefix,omitempty"`
+	RIBNextHop    string   `json:"rib_next_hop,omitempty"`
+	FIBPrefix     string   `json:"fib_prefix,omitempty"`
+	FIBForwarding []string `json:"fib_forwarding,omitempty"`
+	Issues        []string `json:"issues,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// longestBGPRIBMatch returns the longest prefix of 'gobgp global rib <addr>' output and the next hop of its best path
+func longestBGPRIBMatch(output string) (string, string) {
+	var prefix, nextHop string
+	bits := -1
+	for candidate, hop := range parseBGPRIBBestPaths(output) {
+		if parsed, err := netip.ParsePrefix(candidate); err == nil && parsed.Bits() > bits {
+			prefix, nextHop, bits = candidate, hop, parsed.Bits()
+		}
+	}
+	return prefix, nextHop
+}
+
+// checkBGPPodRoute describes why a node cannot reach the workload pod: no covering prefix in
+// the RIB or the FIB, only a default route, or a FIB entry dropping the packets
+func checkBGPPodRoute(route *bgpPodRoute, address netip.Addr) {
+	defaultRoute := func(prefix string) bool {
+		parsed, err := netip.ParsePrefix(prefix)
+		return err == nil && parsed.Bits() == 0
+	}
+	if route.Owner {
+		hostPrefix := netip.PrefixFrom(address, address.BitLen()).String()
+		if route.FIBPrefix != hostPrefix {
+			route.Issues = append(route.Issues, fmt.Sprintf("no host route %s in the FIB: the pod tap is not wired in VPP", hostPrefix))
+		}
+		if route.RIBPrefix == "" {
+			route.Issues = append(route.Issues, "the node does not originate a prefix covering the pod IP in BGP")
+		}
+		return
+	}
+
+	switch {
+	case route.RIBPrefix == "":
+		route.Issues = append(route.Issues, "no prefix covering the pod IP in the BGP RIB")
+	case defaultRoute(route.RIBPrefix):
+		route.Issues = append(route.Issues, "only the default route covers the pod IP in the BGP RIB")
+	case localNextHop(route.RIBNextHop):
+		route.Issues = append(route.Issues, fmt.Sprintf("the node originates %s itself although the pod runs on another node", route.RIBPrefix))
+	}
+	switch {
+	case route.FIBPrefix == "":
+		route.Issues = append(route.Issues, "no route covering the pod IP in the VPP FIB: packets are dropped")
+	case defaultRoute(route.FIBPrefix):
+		route.Issues = append(route.Issues, "only the default route covers the pod IP in the VPP FIB")
+	}
+	for _, adjacency := range route.FIBForwarding {
+		if strings.Contains(adjacency, "drop") {
+			route.Issues = append(route.Issues, fmt.Sprintf("the FIB entry %s drops the packets", route.FIBPrefix))
+			break
+		}
+	}
+}
+
+// handleBGPPodRoute finds the node running the workload pod with the given IP, then looks up the
+// covering prefix in the BGP RIB and the VPP FIB of every node, reporting the nodes without a
+// route to the pod
+func (s *VPPMCPServer) handleBGPPodRoute(ctx context.Context, input BGPPodRouteInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received BGP pod route request for pod IP: %s", input.PodIP)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	// Step 1: Find the node of the workload pod and the calico-vpp pods
+	address, err := netip.ParseAddr(input.PodIP)
+	if err != nil {
+		err = fmt.Errorf("invalid pod_ip %q: expected an IPv4 or IPv6 address such as 10.0.0.1 or 2001:db8::1", input.PodIP)
+	} else if s.bypassesKubernetes() {
+		err = fmt.Errorf("finding the node of a pod IP requires the Kubernetes API")
+	}
+	address = address.Unmap()
+	var k8sClient *KubeClient
+	if err == nil {
+		if k8sClient, err = newKubeClient(); err != nil {
+			err = fmt.Errorf("failed to create Kubernetes client: %v", err)
+		}
+	}
+	var owner string
+	if err == nil {
+		owner, err = lookupPodIPNode(ctx, k8sClient, address)
+	}
+	var pods []VPPPod
+	if err == nil {
+		pods, err = listVPPPods(ctx, k8sClient)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 2: Look up the pod IP in the RIB and the FIB of every node
+	family, fib := "4", "show ip fib"
+	if address.Is6() {
+		family, fib = "6", "show ip6 fib"
+	}
+	routes := make([]*bgpPodRoute, len(pods))
+	var wg sync.WaitGroup
+	for i, pod := range pods {
+		routes[i] = &bgpPodRoute{Pod: pod.Name, Node: pod.Node, Owner: pod.Node == owner}
+		wg.Add(1)
+		go func(route *bgpPodRoute) {
+			defer wg.Done()
+			rib, err := s.ExecutePodGoBGPCommand(ctx, route.Pod, fmt.Sprintf("global rib -a %s %s", family, address))
+			if err != nil {
+				route.Error = fmt.Sprintf("gobgp global rib failed: %v", err)
+				return
+			}
+			fibResult, err := s.ExecutePodVPPCommand(ctx, route.Pod, fmt.Sprintf("%s %s", fib, address))
+			if err != nil {
+				route.Error = fmt.Sprintf("vppctl %s failed: %v", fib, err)
+				return
+			}
+			route.RIBPrefix, route.RIBNextHop = longestBGPRIBMatch(rib["output"].(string))
+			// The default table carries the pod routes
+			for _, lookup := range parseFibLookup(fibResult["output"].(string)) {
+				if lookup.FibIndex == "0" {
+					route.FIBPrefix, route.FIBForwarding = lookup.Prefix, lookup.Forwarding
+				}
+			}
+		}(routes[i])
+	}
+	wg.Wait()
+
+	if dryRunFromContext(ctx) != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("BGP Pod Route: pod IP %s runs on node %s, its route would be looked up on %d pod(s)\n", address, owner, len(pods)),
+				},
+			},
+		}, nil, nil
+	}
+
+	// Step 3: Report the nodes without a route to the pod
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("BGP Route of Pod IP %s (running on node %s):\n\n", address, owner))
+	unreachable, failed, ownerFound := 0, 0, false
+	for _, route := range routes {
+		ownerFound = ownerFound || route.Owner
+		label := fmt.Sprintf("Node %s (pod %s)", route.Node, route.Pod)
+		if route.Owner {
+			label += " [owner]"
+		}
+		text.WriteString(label + ":\n")
+		if route.Error != "" {
+			failed++
+			text.WriteString(fmt.Sprintf("  %s\n\n", route.Error))
+			continue
+		}
+		checkBGPPodRoute(route, address)
+		if len(route.Issues) > 0 && !route.Owner {
+			unreachable++
+		}
+		rib := "none"
+		if route.RIBPrefix != "" {
+			rib = fmt.Sprintf("%s via %s", route.RIBPrefix, route.RIBNextHop)
+		}
+		fibEntry := "none"
+		if route.FIBPrefix != "" {
+			fibEntry = route.FIBPrefix
+		}
+		text.WriteString(fmt.Sprintf("  RIB: %s\n  FIB: %s\n", rib, fibEntry))
+		for _, adjacency := range route.FIBForwarding {
+			text.WriteString(fmt.Sprintf("    -> %s\n", adjacency))
+		}
+		for _, issue := range route.Issues {
+			text.WriteString(fmt.Sprintf("  ! %s\n", issue))
+		}
+		text.WriteString("\n")
+	}
+	if !ownerFound {
+		text.WriteString(fmt.Sprintf("No calico-vpp pod runs on node %s: the pod is not wired in VPP\n\n", owner))
+	}
+	text.WriteString(fmt.Sprintf("Summary: %d node(s), %d without a usable route to the pod, %d failed\n", len(routes), unreachable, failed))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"pod_ip":     address.String(),
+			"owner_node": owner,
+			"nodes":      routes,
+		},
+	}, nil, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestLongestBGPRIBMatch(t *testing.T) {
+	output := `   Network              Next Hop             AS_PATH              Age        Attrs
+*> 0.0.0.0/0            192.168.0.254        65100                00:20:00   [{Origin: i}]
+*> 10.0.0.0/16          192.168.0.3                               00:15:00   [{Origin: i}]
+*> 10.0.1.0/26          192.168.0.2                               00:10:23   [{Origin: i}]
+*  10.0.1.0/26          192.168.0.4                               00:10:20   [{Origin: i}]
+`
+	if prefix, nextHop := longestBGPRIBMatch(output); prefix != "10.0.1.0/26" || nextHop != "192.168.0.2" {
+		t.Errorf("longestBGPRIBMatch() = %q, %q, want 10.0.1.0/26, 192.168.0.2", prefix, nextHop)
+	}
+	if prefix, nextHop := longestBGPRIBMatch("Network not in table\n"); prefix != "" || nextHop != "" {
+		t.Errorf("longestBGPRIBMatch() without route = %q, %q, want none", prefix, nextHop)
+	}
+}
+
+func TestCheckBGPPodRoute(t *testing.T) {
+	address := netip.MustParseAddr("10.0.1.5")
+
+	tests := []struct {
+		name  string
+		route bgpPodRoute
+		want  []string
+	}{
+		{
+			name:  "owner with host route and originated block",
+			route: bgpPodRoute{Owner: true, RIBPrefix: "10.0.1.0/26", RIBNextHop: "0.0.0.0", FIBPrefix: "10.0.1.5/32"},
+		},
+		{
+			name:  "owner without host route",
+			route: bgpPodRoute{Owner: true, FIBPrefix: "10.0.1.0/26"},
+			want: []string{
+				"no host route 10.0.1.5/32 in the FIB: the pod tap is not wired in VPP",
+				"the node does not originate a prefix covering the pod IP in BGP",
+			},
+		},
+		{
+			name:  "remote node with a route",
+			route: bgpPodRoute{RIBPrefix: "10.0.1.0/26", RIBNextHop: "192.168.0.2", FIBPrefix: "10.0.1.0/26", FIBForwarding: []string{"via 192.168.0.2 uplink"}},
+		},
+		{
+			name:  "remote node with the default route only",
+			route: bgpPodRoute{RIBPrefix: "0.0.0.0/0", RIBNextHop: "192.168.0.254", FIBPrefix: "0.0.0.0/0"},
+			want: []string{
+				"only the default route covers the pod IP in the BGP RIB",
+				"only the default route covers the pod IP in the VPP FIB",
+			},
+		},
+		{
+			name:  "remote node originating the block",
+			route: bgpPodRoute{RIBPrefix: "10.0.1.0/26", RIBNextHop: "0.0.0.0", FIBPrefix: "10.0.1.0/26", FIBForwarding: []string{"dpo-drop ip4"}},
+			want: []string{
+				"the node originates 10.0.1.0/26 itself although the pod runs on another node",
+				"the FIB entry 10.0.1.0/26 drops the packets",
+			},
+		},
+		{
+			name:  "remote node without any route",
+			route: bgpPodRoute{},
+			want: []string{
+				"no prefix covering the pod IP in the BGP RIB",
+				"no route covering the pod IP in the VPP FIB: packets are dropped",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := tt.route
+			checkBGPPodRoute(&route, address)
+			if !reflect.DeepEqual(route.Issues, tt.want) {
+				t.Errorf("checkBGPPodRoute() issues = %q, want %q", route.Issues, tt.want)
+			}
+		})
+	}
+}
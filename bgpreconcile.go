@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	calicoBGPPeersPath          = "/apis/crd.projectcalico.org/v1/bgppeers"
+	calicoBGPConfigurationsPath = "/apis/crd.projectcalico.org/v1/bgpconfigurations"
+	kubernetesNodesPath         = "/api/v1/nodes"
+	// calicoIPv4AddressAnnotation and calicoIPv6AddressAnnotation hold the BGP addresses of a node
+	calicoIPv4AddressAnnotation = "projectcalico.org/IPv4Address"
+	calicoIPv6AddressAnnotation = "projectcalico.org/IPv6Address"
+)
+
+// BGPPeerReconcileInput represents the input for reconciling the Calico BGP configuration with the live BGP peers
+type BGPPeerReconcileInput struct {
+	// PodNames lists the calico-vpp pods to check (default: every calico-vpp pod)
+	PodNames []string `json:"pod_names,omitempty"`
+	// NodeNames selects the calico-vpp pods running on Kubernetes nodes, in addition to PodNames
+	NodeNames []string `json:"node_names,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pods
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// calicoBGPPeerList is the subset of a crd.projectcalico.org/v1 BGPPeerList read by the reconciliation
+type calicoBGPPeerList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Node         string `json:"node"`
+			PeerIP       string `json:"peerIP"`
+			ASNumber     uint32 `json:"asNumber"`
+			NodeSelector string `json:"nodeSelector"`
+			PeerSelector string `json:"peerSelector"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// calicoBGPConfigurationList is the subset of a crd.projectcalico.org/v1 BGPConfigurationList read by the reconciliation
+type calicoBGPConfigurationList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			NodeToNodeMeshEnabled *bool `json:"nodeToNodeMeshEnabled"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// kubeNodeList is the subset of a NodeList read by the reconciliation
+type kubeNodeList struct {
+	Items []struct {
+		Metadata struct {
+			Name        string            `json:"name"`
+			Labels      map[string]string `json:"labels"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+		Status struct {
+			Addresses []struct {
+				Type    string `json:"type"`
+				Address string `json:"address"`
+			} `json:"addresses"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// calicoNode is a node with the addresses its BGP sessions use
+type calicoNode struct {
+	Name      string
+	Labels    map[string]string
+	Addresses []string
+}
+
+// getRawJSON reads a resource of the Kubernetes API by path and decodes its JSON into out
+func (k *KubeClient) getRawJSON(ctx context.Context, path string, out any) error {
+	return k.listRawJSON(ctx, path, "", out)
+}
+
+// listRawJSON lists the resources of the Kubernetes API under path matching a label selector,
+// every resource when it is empty, and decodes the JSON list into out
+func (k *KubeClient) listRawJSON(ctx context.Context, path, labelSelector string, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+
+	request := k.clientset.CoreV1().RESTClient().Get().AbsPath(path)
+	if labelSelector != "" {
+		request = request.Param("labelSelector", labelSelector)
+	}
+	data, err := request.DoRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	return nil
+}
+
+// normalizeBGPAddress returns the canonical form of an address, a CIDR or an address:port, or ""
+func normalizeBGPAddress(value string) string {
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return addr.Unmap().String()
+	}
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		return prefix.Addr().Unmap().String()
+	}
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap().String()
+	}
+	return ""
+}
+
+// listCalicoNodes returns the nodes of the cluster with their BGP addresses: the Calico address
+// annotations, or the InternalIP addresses of nodes without them
+func listCalicoNodes(ctx context.Context, k *KubeClient) ([]calicoNode, error) {
+	var list kubeNodeList
+	if err := k.getRawJSON(ctx, kubernetesNodesPath, &list); err != nil {
+		return nil, err
+	}
+	nodes := make([]calicoNode, 0, len(list.Items))
+	for _, item := range list.Items {
+		node := calicoNode{Name: item.Metadata.Name, Labels: item.Metadata.Labels}
+		for _, annotation := range []string{calicoIPv4AddressAnnotation, calicoIPv6AddressAnnotation} {
+			if address := normalizeBGPAddress(item.Metadata.Annotations[annotation]); address != "" {
+				node.Addresses = append(node.Addresses, address)
+			}
+		}
+		if len(node.Addresses) == 0 {
+			for _, address := range item.Status.Addresses {
+				if normalized := normalizeBGPAddress(address.Address); address.Type == "InternalIP" && normalized != "" {
+					node.Addresses = append(node.Addresses, normalized)
+				}
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// resolveNodeBGPAddress returns the BGP address of a Kubernetes node, the IPv4 address when the
+// node has both, so tools can designate a mesh peer by its node name
+func (s *VPPMCPServer) resolveNodeBGPAddress(ctx context.Context, name string) (string, error) {
+	if s.bypassesKubernetes() {
+		return "", fmt.Errorf("invalid neighbor %q: node names are resolved through the Kubernetes API, pass the neighbor IP address", name)
+	}
+	k8sClient, err := newKubeClient()
+	if err != nil {
+		return "", fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+	nodes, err := listCalicoNodes(ctx, k8sClient)
+	if err != nil {
+		return "", err
+	}
+	for _, node := range nodes {
+		if node.Name != name {
+			continue
+		}
+		if len(node.Addresses) == 0 {
+			return "", fmt.Errorf("node %s has no BGP address", name)
+		}
+		return node.Addresses[0], nil
+	}
+	return "", fmt.Errorf("invalid neighbor %q: neither an IP address nor a Kubernetes node", name)
+}
+
+// matchCalicoSelector evaluates a Calico selector against the labels of a node or a pod. Only "all()",
+// has(k), !has(k), k == 'v' and k != 'v' terms joined by && are supported: ok is false for any
+// other selector.
+func matchCalicoSelector(selector string, labels map[string]string) (match, ok bool) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" || selector == "all()" {
+		return true, true
+	}
+	if strings.Contains(selector, "||") {
+		return false, false
+	}
+	match = true
+	for _, term := range strings.Split(selector, "&&") {
+		term = strings.TrimSpace(term)
+		var termMatch bool
+		switch {
+		case term == "all()":
+			termMatch = true
+		case strings.HasPrefix(term, "!has(") && strings.HasSuffix(term, ")"):
+			_, found := labels[strings.TrimSpace(term[len("!has("):len(term)-1])]
+			termMatch = !found
+		case strings.HasPrefix(term, "has(") && strings.HasSuffix(term, ")"):
+			_, termMatch = labels[strings.TrimSpace(term[len("has("):len(term)-1])]
+		default:
+			operator := "=="
+			key, value, found := strings.Cut(term, "==")
+			if !found {
+				operator = "!="
+				if key, value, found = strings.Cut(term, "!="); !found {
+					return false, false
+				}
+			}
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			unquoted, err := strconv.Unquote(strings.ReplaceAll(value, "'", `"`))
+			if err != nil || key == "" || strings.ContainsAny(key, "()!") {
+				return false, false
+			}
+			actual, present := labels[key]
+			termMatch = present && actual == unquoted
+			if operator == "!=" {
+				termMatch = !termMatch
+			}
+		}
+		match = match && termMatch
+	}
+	return match, true
+}
+
+// bgpExpectedPeer is a peer the Calico configuration expects on a node
+type bgpExpectedPeer struct {
+	Address string `json:"address"`
+	// AS is the AS number configured for the peer, 0 when the configuration does not set it
+	AS     uint32 `json:"as,omitempty"`
+	Source string `json:"source"`
+}
+
+// bgpPeerMismatch is a live peer whose AS differs from the configured one
+type bgpPeerMismatch struct {
+	Address    string `json:"address"`
+	Source     string `json:"source"`
+	ExpectedAS uint32 `json:"expected_as"`
+	ActualAS   string `json:"actual_as"`
+}
+
+// bgpNodeReconciliation is the result of the reconciliation of one node
+type bgpNodeReconciliation struct {
+	Pod        string            `json:"pod"`
+	Node       string            `json:"node"`
+	Expected   int               `json:"expected"`
+	Live       int               `json:"live"`
+	Missing    []bgpExpectedPeer `json:"missing,omitempty"`
+	Unexpected []bgpPeer         `json:"unexpected,omitempty"`
+	Mismatched []bgpPeerMismatch `json:"as_mismatches,omitempty"`
+	// Unevaluated lists the BGPPeer resources whose selectors could not be evaluated: their
+	// peers are not expected, so they may show up as unexpected
+	Unevaluated []string `json:"unevaluated,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// calicoExpectedPeers returns the peers the Calico configuration expects on a node, keyed by
+// address, and the BGPPeer resources that could not be evaluated
+func calicoExpectedPeers(node string, nodes []calicoNode, peers calicoBGPPeerList, mesh bool) (map[string]bgpExpectedPeer, []string) {
+	expected := make(map[string]bgpExpectedPeer)
+	var unevaluated []string
+	var self calicoNode
+	for _, n := range nodes {
+		if n.Name == node {
+			self = n
+		}
+	}
+	if mesh {
+		for _, n := range nodes {
+			if n.Name == node {
+				continue
+			}
+			for _, address := range n.Addresses {
+				expected[address] = bgpExpectedPeer{Address: address, Source: "node-to-node mesh (" + n.Name + ")"}
+			}
+		}
+	}
+
+	for _, peer := range peers.Items {
+		spec := peer.Spec
+		source := "BGPPeer " + peer.Metadata.Name
+		applies, ok := spec.Node == node, true
+		if spec.Node == "" {
+			applies, ok = matchCalicoSelector(spec.NodeSelector, self.Labels)
+		}
+		if !ok {
+			unevaluated = append(unevaluated, fmt.Sprintf("%s (nodeSelector %q)", peer.Metadata.Name, spec.NodeSelector))
+			continue
+		}
+		if !applies {
+			continue
+		}
+		if spec.PeerSelector == "" {
+			if address := normalizeBGPAddress(spec.PeerIP); address != "" {
+				expected[address] = bgpExpectedPeer{Address: address, AS: spec.ASNumber, Source: source}
+			}
+			continue
+		}
+		for _, n := range nodes {
+			match, ok := matchCalicoSelector(spec.PeerSelector, n.Labels)
+			if !ok {
+				unevaluated = append(unevaluated, fmt.Sprintf("%s (peerSelector %q)", peer.Metadata.Name, spec.PeerSelector))
+				break
+			}
+			if !match || n.Name == node {
+				continue
+			}
+			for _, address := range n.Addresses {
+				expected[address] = bgpExpectedPeer{Address: address, AS: spec.ASNumber, Source: source + " (" + n.Name + ")"}
+			}
+		}
+	}
+	return expected, unevaluated
+}
+
+// reconcileBGPPeers compares the expected peers of a node with its live peers
+func reconcileBGPPeers(r *bgpNodeReconciliation, expected map[string]bgpExpectedPeer, live []bgpPeer) {
+	r.Expected, r.Live = len(expected), len(live)
+	seen := make(map[string]bool)
+	for _, peer := range live {
+		address := normalizeBGPAddress(peer.Address)
+		seen[address] = true
+		want, ok := expected[address]
+		switch {
+		case !ok:
+			r.Unexpected = append(r.Unexpected, peer)
+		case want.AS != 0 && peer.AS != strconv.FormatUint(uint64(want.AS), 10):
+			r.Mismatched = append(r.Mismatched, bgpPeerMismatch{Address: peer.Address, Source: want.Source, ExpectedAS: want.AS, ActualAS: peer.AS})
+		}
+	}
+	for address, want := range expected {
+		if !seen[address] {
+			r.Missing = append(r.Missing, want)
+		}
+	}
+	sort.Slice(r.Missing, func(i, j int) bool { return r.Missing[i].Address < r.Missing[j].Address })
+}
+
+// handleBGPPeerReconcile compares the Calico BGPPeer and BGPConfiguration resources with the
+// 'gobgp neighbor' list of every node, reporting the configured peers missing on a node and the
+// live peers no resource accounts for
+func (s *VPPMCPServer) handleBGPPeerReconcile(ctx context.Context, input BGPPeerReconcileInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received BGP peer reconciliation request for pods: %v", input.PodNames)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	var err error
+	if s.bypassesKubernetes() {
+		err = fmt.Errorf("reconciling BGP peers requires the Kubernetes API to read the Calico resources")
+	}
+	var k8sClient *KubeClient
+	if err == nil {
+		if k8sClient, err = newKubeClient(); err != nil {
+			err = fmt.Errorf("failed to create Kubernetes client: %v", err)
+		}
+	}
+
+	// Step 1: Read the Calico BGP configuration and the nodes
+	var peers calicoBGPPeerList
+	var configurations calicoBGPConfigurationList
+	var nodes []calicoNode
+	if err == nil {
+		err = k8sClient.getRawJSON(ctx, calicoBGPPeersPath, &peers)
+	}
+	if err == nil {
+		err = k8sClient.getRawJSON(ctx, calicoBGPConfigurationsPath, &configurations)
+	}
+	if err == nil {
+		nodes, err = listCalicoNodes(ctx, k8sClient)
+	}
+	var pods []VPPPod
+	if err == nil {
+		pods, err = s.resolveBGPPods(ctx, input.PodNames)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	mesh := true
+	for _, configuration := range configurations.Items {
+		if configuration.Metadata.Name == "default" && configuration.Spec.NodeToNodeMeshEnabled != nil {
+			mesh = *configuration.Spec.NodeToNodeMeshEnabled
+		}
+	}
+
+	// Step 2: List the live peers of every node
+	samples := s.sampleBGPPeers(ctx, pods)
+	if dryRunFromContext(ctx) != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("BGP Peer Reconciliation: the peers of %d pod(s) would be compared with %d BGPPeer resource(s)\n", len(pods), len(peers.Items)),
+				},
+			},
+		}, nil, nil
+	}
+
+	// Step 3: Compare them with the configuration
+	var text strings.Builder
+	text.WriteString("BGP Peer Reconciliation:\n\n")
+	meshState := "enabled"
+	if !mesh {
+		meshState = "disabled"
+	}
+	text.WriteString(fmt.Sprintf("Node-to-node mesh: %s, BGPPeer resources: %d, nodes: %d\n\n", meshState, len(peers.Items), len(nodes)))
+	reconciliations := make([]bgpNodeReconciliation, 0, len(samples))
+	missing, unexpected, mismatched := 0, 0, 0
+	for _, sample := range samples {
+		r := bgpNodeReconciliation{Pod: sample.Pod, Node: sample.Node}
+		text.WriteString(fmt.Sprintf("Node %s (pod %s):\n", sample.Node, sample.Pod))
+		if sample.Err != nil {
+			r.Error = sample.Err.Error()
+			reconciliations = append(reconciliations, r)
+			text.WriteString(fmt.Sprintf("  gobgp neighbor failed: %v\n\n", sample.Err))
+			continue
+		}
+		expected, unevaluated := calicoExpectedPeers(sample.Node, nodes, peers, mesh)
+		r.Unevaluated = unevaluated
+		reconcileBGPPeers(&r, expected, sample.Peers)
+		reconciliations = append(reconciliations, r)
+		missing += len(r.Missing)
+		unexpected += len(r.Unexpected)
+		mismatched += len(r.Mismatched)
+
+		text.WriteString(fmt.Sprintf("  %d expected peer(s), %d live peer(s)\n", r.Expected, r.Live))
+		for _, peer := range r.Missing {
+			text.WriteString(fmt.Sprintf("  - missing %s, configured by %s\n", peer.Address, peer.Source))
+		}
+		for _, peer := range r.Unexpected {
+			text.WriteString(fmt.Sprintf("  + unexpected %s (AS %s, %s), not configured by any resource\n", peer.Address, peer.AS, peer.State))
+		}
+		for _, mismatch := range r.Mismatched {
+			text.WriteString(fmt.Sprintf("  ! %s has AS %s, %s configures AS %d\n", mismatch.Address, mismatch.ActualAS, mismatch.Source, mismatch.ExpectedAS))
+		}
+		for _, name := range r.Unevaluated {
+			text.WriteString(fmt.Sprintf("  ? selector not evaluated: %s\n", name))
+		}
+		text.WriteString("\n")
+	}
+	text.WriteString(fmt.Sprintf("Summary: %d node(s), %d missing peer(s), %d unexpected peer(s), %d AS mismatch(es)\n", len(samples), missing, unexpected, mismatched))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"node_to_node_mesh": mesh,
+			"nodes":             reconciliations,
+		},
+	}, nil, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeBGPAddress(t *testing.T) {
+	tests := map[string]string{
+		"192.168.0.2":           "192.168.0.2",
+		"::ffff:192.168.0.2":    "192.168.0.2",
+		"192.168.0.2/32":        "192.168.0.2",
+		"192.168.0.2:179":       "192.168.0.2",
+		"[fd00::2]:179":         "fd00::2",
+		"fd00:0:0:0:0:0:0:2":    "fd00::2",
+		"not an address":        "",
+		"192.168.0.300":         "",
+		"192.168.0.2/33":        "",
+		"calico-vpp-node-abcde": "",
+	}
+
+	for value, want := range tests {
+		if got := normalizeBGPAddress(value); got != want {
+			t.Errorf("normalizeBGPAddress(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestMatchCalicoSelector(t *testing.T) {
+	labels := map[string]string{"rack": "r1", "role": "edge"}
+
+	tests := []struct {
+		selector  string
+		wantMatch bool
+		wantOK    bool
+	}{
+		{"", true, true},
+		{"all()", true, true},
+		{"has(rack)", true, true},
+		{"!has(rack)", false, true},
+		{"rack == 'r1'", true, true},
+		{`rack == "r2"`, false, true},
+		{"rack != 'r2'", true, true},
+		{"missing != 'x'", true, true},
+		{"has(rack) && role == 'edge'", true, true},
+		{"has(rack) && role == 'core'", false, true},
+		{"rack == 'r1' || rack == 'r2'", false, false},
+		{"rack in {'r1', 'r2'}", false, false},
+		{"rack == r1", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			match, ok := matchCalicoSelector(tt.selector, labels)
+			if match != tt.wantMatch || ok != tt.wantOK {
+				t.Errorf("matchCalicoSelector(%q) = %t, %t, want %t, %t", tt.selector, match, ok, tt.wantMatch, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCalicoExpectedPeers(t *testing.T) {
+	nodes := []calicoNode{
+		{Name: "node-a", Labels: map[string]string{"rack": "r1"}, Addresses: []string{"192.168.0.1"}},
+		{Name: "node-b", Labels: map[string]string{"rack": "r1", "route-reflector": "true"}, Addresses: []string{"192.168.0.2", "fd00::2"}},
+		{Name: "node-c", Labels: map[string]string{"rack": "r2"}, Addresses: []string{"192.168.0.3"}},
+	}
+
+	var peers calicoBGPPeerList
+	if err := json.Unmarshal([]byte(`{"items": [
+		{"metadata": {"name": "tor-r1"}, "spec": {"nodeSelector": "rack == 'r1'", "peerIP": "10.1.0.1:179", "asNumber": 65100}},
+		{"metadata": {"name": "tor-r2"}, "spec": {"nodeSelector": "rack == 'r2'", "peerIP": "10.2.0.1", "asNumber": 65200}},
+		{"metadata": {"name": "node-a-only"}, "spec": {"node": "node-a", "peerIP": "10.9.0.1"}},
+		{"metadata": {"name": "reflectors"}, "spec": {"nodeSelector": "all()", "peerSelector": "has(route-reflector)"}},
+		{"metadata": {"name": "complex"}, "spec": {"nodeSelector": "rack in {'r1'}", "peerIP": "10.3.0.1"}}
+	]}`), &peers); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, unevaluated := calicoExpectedPeers("node-a", nodes, peers, false)
+	want := map[string]bgpExpectedPeer{
+		"10.1.0.1":    {Address: "10.1.0.1", AS: 65100, Source: "BGPPeer tor-r1"},
+		"10.9.0.1":    {Address: "10.9.0.1", Source: "BGPPeer node-a-only"},
+		"192.168.0.2": {Address: "192.168.0.2", Source: "BGPPeer reflectors (node-b)"},
+		"fd00::2":     {Address: "fd00::2", Source: "BGPPeer reflectors (node-b)"},
+	}
+	if !reflect.DeepEqual(expected, want) {
+		t.Errorf("calicoExpectedPeers() = %+v, want %+v", expected, want)
+	}
+	if wantUnevaluated := []string{`complex (nodeSelector "rack in {'r1'}")`}; !reflect.DeepEqual(unevaluated, wantUnevaluated) {
+		t.Errorf("calicoExpectedPeers() unevaluated = %q, want %q", unevaluated, wantUnevaluated)
+	}
+
+	// With the node-to-node mesh, every other node is a peer, the route reflector keeps its source
+	expected, _ = calicoExpectedPeers("node-c", nodes, calicoBGPPeerList{}, true)
+	want = map[string]bgpExpectedPeer{
+		"192.168.0.1": {Address: "192.168.0.1", Source: "node-to-node mesh (node-a)"},
+		"192.168.0.2": {Address: "192.168.0.2", Source: "node-to-node mesh (node-b)"},
+		"fd00::2":     {Address: "fd00::2", Source: "node-to-node mesh (node-b)"},
+	}
+	if !reflect.DeepEqual(expected, want) {
+		t.Errorf("calicoExpectedPeers() with the mesh = %+v, want %+v", expected, want)
+	}
+}
+
+func TestReconcileBGPPeers(t *testing.T) {
+	expected := map[string]bgpExpectedPeer{
+		"10.1.0.1":    {Address: "10.1.0.1", AS: 65100, Source: "BGPPeer tor-r1"},
+		"10.9.0.1":    {Address: "10.9.0.1", Source: "BGPPeer node-a-only"},
+		"192.168.0.2": {Address: "192.168.0.2", Source: "node-to-node mesh (node-b)"},
+		"192.168.0.3": {Address: "192.168.0.3", Source: "node-to-node mesh (node-c)"},
+	}
+	live := []bgpPeer{
+		{Address: "10.1.0.1", AS: "65101", State: "Establ"},
+		{Address: "10.9.0.1", AS: "65000", State: "Establ"},
+		{Address: "::ffff:192.168.0.2", AS: "65000", State: "Establ"},
+		{Address: "10.4.0.1", AS: "65400", State: "Active"},
+	}
+
+	r := &bgpNodeReconciliation{Pod: "calico-vpp-node-a", Node: "node-a"}
+	reconcileBGPPeers(r, expected, live)
+
+	want := &bgpNodeReconciliation{
+		Pod:        "calico-vpp-node-a",
+		Node:       "node-a",
+		Expected:   4,
+		Live:       4,
+		Missing:    []bgpExpectedPeer{{Address: "192.168.0.3", Source: "node-to-node mesh (node-c)"}},
+		Unexpected: []bgpPeer{{Address: "10.4.0.1", AS: "65400", State: "Active"}},
+		Mismatched: []bgpPeerMismatch{{Address: "10.1.0.1", Source: "BGPPeer tor-r1", ExpectedAS: 65100, ActualAS: "65101"}},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("reconcileBGPPeers() = %+v, want %+v", r, want)
+	}
+}
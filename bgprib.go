@@ -0,0 +1,426 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var (
+	// bgpCommunityRegexp matches a standard BGP community, e.g. "65000:100"
+	bgpCommunityRegexp = regexp.MustCompile(`^\d{1,5}:\d{1,5}$`)
+	// bgpRouteAgeRegexp matches the first field of the Age column of a RIB route, e.g. "00:10:23" or the "2d" of "2d 03:04:05"
+	bgpRouteAgeRegexp = regexp.MustCompile(`^(\d+d|\d+:\d\d:\d\d)$`)
+	// bgpCommunitiesRegexp matches the communities attribute of a RIB route, e.g. "{Communities: 65000:100, 65000:200}"
+	bgpCommunitiesRegexp = regexp.MustCompile(`\{Communities: ([^}]*)\}`)
+)
+
+// BGPRIBInput represents the input for the BGP RIB tools
+type BGPRIBInput struct {
+	// PodName specifies the name of the Kubernetes pod running the agent container with gobgp
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// Community keeps the routes carrying this standard community, e.g. 65000:100
+	Community string `json:"community,omitempty"`
+	// ASN keeps the routes whose AS path contains this AS number
+	ASN string `json:"asn,omitempty"`
+	// Neighbor keeps the routes received from this BGP neighbor, before import policies
+	Neighbor string `json:"neighbor,omitempty"`
+	// SnapshotID specifies a stored snapshot to read the output from instead of the live pod
+	SnapshotID string `json:"snapshot_id,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+	// Format selects the output format: raw, markdown (default) or json
+	Format string `json:"format,omitempty"`
+	// Container overrides the container the command runs in (default: agent)
+	Container string `json:"container,omitempty"`
+}
+
+// bgpRouteMatches reports whether a route line of 'gobgp global rib' output, e.g.
+// "*> 10.0.1.0/26  192.168.0.2  65001 65002  00:10:23  [{Origin: i} {Communities: 65000:100}]",
+// carries the community and has the AS number in its path. Empty criteria match every route.
+func bgpRouteMatches(line, community, asn string) bool {
+	path, attrs, _ := strings.Cut(line, "[")
+	if community != "" {
+		match := bgpCommunitiesRegexp.FindStringSubmatch(attrs)
+		if match == nil {
+			return false
+		}
+		found := false
+		for _, value := range strings.Split(match[1], ",") {
+			if strings.TrimSpace(value) == community {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if asn != "" {
+		// The AS path sits between the next hop and the age, sets written as {65001,65002}
+		fields := strings.Fields(path)
+		if len(fields) < 3 {
+			return false
+		}
+		found := false
+		for _, field := range fields[3:] {
+			if bgpRouteAgeRegexp.MatchString(field) {
+				break
+			}
+			for _, number := range strings.FieldsFunc(field, func(r rune) bool { return r < '0' || r > '9' }) {
+				if number == asn {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// handleGoBGPRIB shows the IPv4 or IPv6 RIB of a pod. The neighbor filter is translated into
+// 'gobgp neighbor <ip> adj-in'; the community and AS filters select the route lines of the output.
+func (s *VPPMCPServer) handleGoBGPRIB(ctx context.Context, input BGPRIBInput, family string) (*mcp.CallToolResult, any, error) {
+	filtered := input.Community != "" || input.ASN != ""
+	var err error
+	if input.Community != "" && !bgpCommunityRegexp.MatchString(input.Community) {
+		err = fmt.Errorf("invalid community %q: must be a standard community such as 65000:100", input.Community)
+	}
+	if err == nil && input.ASN != "" {
+		if n, parseErr := strconv.ParseUint(input.ASN, 10, 32); parseErr != nil {
+			err = fmt.Errorf("invalid asn %q: must be an AS number", input.ASN)
+		} else {
+			input.ASN = strconv.FormatUint(n, 10)
+		}
+	}
+	if err == nil && input.Neighbor != "" {
+		if _, parseErr := netip.ParseAddr(input.Neighbor); parseErr != nil {
+			err = fmt.Errorf("invalid neighbor %q: must be an IP address", input.Neighbor)
+		}
+	}
+	if err == nil && filtered && input.SnapshotID != "" {
+		err = fmt.Errorf("community and asn filters only apply to the live RIB")
+	}
+	if err == nil && filtered && input.Format == outputFormatJSON {
+		err = fmt.Errorf("community and asn filters apply to the text output, use format raw or markdown")
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	command := "global rib -a " + family
+	description := fmt.Sprintf("BGP IPv%s RIB Information", family)
+	if input.Neighbor != "" {
+		command = fmt.Sprintf("neighbor %s adj-in -a %s", input.Neighbor, family)
+		description = fmt.Sprintf("BGP IPv%s Routes Received from %s", family, input.Neighbor)
+	}
+	commandInput := BGPCommandInput{
+		PodName:        input.PodName,
+		SnapshotID:     input.SnapshotID,
+		TimeoutSeconds: input.TimeoutSeconds,
+		DryRun:         input.DryRun,
+		Format:         input.Format,
+		Container:      input.Container,
+	}
+	if !filtered {
+		return s.HandleGoBGPCommand(ctx, commandInput, command, description)
+	}
+
+	var filters []string
+	if input.Community != "" {
+		filters = append(filters, "community "+input.Community)
+	}
+	if input.ASN != "" {
+		filters = append(filters, "AS "+input.ASN+" in path")
+	}
+	keep := func(line string) bool {
+		// Keep the header and the routes matching every filter
+		if !strings.HasPrefix(strings.TrimSpace(line), "*") {
+			return true
+		}
+		return bgpRouteMatches(line, input.Community, input.ASN)
+	}
+	return s.handleGoBGPCommandWithFilter(ctx, commandInput, command, description, keep, strings.Join(filters, ", "))
+}
+
+// BGPRIBSummaryInput represents the input for the BGP RIB summary tool
+type BGPRIBSummaryInput struct {
+	// PodName specifies the name of the Kubernetes pod running the agent container with gobgp
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// Family selects the RIB to summarize: 4 or 6 (default: 4)
+	Family string `json:"family,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+	// Format selects the output format: raw, markdown (default) or json
+	Format string `json:"format,omitempty"`
+	// Container overrides the container the command runs in (default: agent)
+	Container string `json:"container,omitempty"`
+}
+
+// handleGoBGPRIBSummary counts the destinations and paths of the IPv4 or IPv6 RIB with
+// 'gobgp global rib summary', without dumping the whole table
+func (s *VPPMCPServer) handleGoBGPRIBSummary(ctx context.Context, input BGPRIBSummaryInput) (*mcp.CallToolResult, any, error) {
+	family := input.Family
+	if family == "" {
+		family = "4"
+	}
+	if family != "4" && family != "6" {
+		err := fmt.Errorf("invalid family %q: must be 4 or 6", input.Family)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	commandInput := BGPCommandInput{
+		PodName:        input.PodName,
+		TimeoutSeconds: input.TimeoutSeconds,
+		DryRun:         input.DryRun,
+		Format:         input.Format,
+		Container:      input.Container,
+	}
+	return s.HandleGoBGPCommand(ctx, commandInput, "global rib summary -a "+family, fmt.Sprintf("BGP IPv%s RIB Summary", family))
+}
+
+// bgpDivergenceMaxPrefixes bounds the prefixes listed per kind of divergence, the counters cover all prefixes
+const bgpDivergenceMaxPrefixes = 50
+
+// parseBGPRIBBestPaths returns the next hop of the best path of every prefix of 'gobgp global rib' output
+func parseBGPRIBBestPaths(output string) map[string]string {
+	best := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.HasSuffix(fields[0], "*>") {
+			continue
+		}
+		best[fields[1]] = fields[2]
+	}
+	return best
+}
+
+// localNextHop reports whether a next hop designates a route originated by the node itself
+func localNextHop(nextHop string) bool {
+	return nextHop == "0.0.0.0" || nextHop == "::"
+}
+
+// BGPRIBDivergenceInput represents the input for comparing the BGP RIB of several nodes
+type BGPRIBDivergenceInput struct {
+	// PodNames lists the calico-vpp pods to compare (default: every calico-vpp pod)
+	PodNames []string `json:"pod_names,omitempty"`
+	// NodeNames selects the calico-vpp pods running on Kubernetes nodes, in addition to PodNames
+	NodeNames []string `json:"node_names,omitempty"`
+	// Family selects the RIB to compare: 4, 6 or both (default: both)
+	Family string `json:"family,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pods
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// bgpRIBDivergence is a prefix whose presence or next hop differs between the nodes
+type bgpRIBDivergence struct {
+	Prefix string `json:"prefix"`
+	// MissingOn lists the pods whose RIB lacks the prefix
+	MissingOn []string `json:"missing_on,omitempty"`
+	// NextHops lists the pods by next hop of the best path, local routes excluded
+	NextHops map[string][]string `json:"next_hops,omitempty"`
+}
+
+// compareBGPRIBs returns the prefixes missing from some RIBs and those reached through different
+// next hops, sorted by prefix. ribs maps each pod to the best paths of its RIB.
+func compareBGPRIBs(ribs map[string]map[string]string) []bgpRIBDivergence {
+	pods := make([]string, 0, len(ribs))
+	prefixes := make(map[string]bool)
+	for pod, rib := range ribs {
+		pods = append(pods, pod)
+		for prefix := range rib {
+			prefixes[prefix] = true
+		}
+	}
+	sort.Strings(pods)
+	sorted := make([]string, 0, len(prefixes))
+	for prefix := range prefixes {
+		sorted = append(sorted, prefix)
+	}
+	sort.Strings(sorted)
+
+	var divergences []bgpRIBDivergence
+	for _, prefix := range sorted {
+		divergence := bgpRIBDivergence{Prefix: prefix, NextHops: make(map[string][]string)}
+		for _, pod := range pods {
+			nextHop, ok := ribs[pod][prefix]
+			switch {
+			case !ok:
+				divergence.MissingOn = append(divergence.MissingOn, pod)
+			case !localNextHop(nextHop):
+				divergence.NextHops[nextHop] = append(divergence.NextHops[nextHop], pod)
+			}
+		}
+		if len(divergence.NextHops) < 2 {
+			divergence.NextHops = nil
+		}
+		if len(divergence.MissingOn) > 0 || divergence.NextHops != nil {
+			divergences = append(divergences, divergence)
+		}
+	}
+	return divergences
+}
+
+// handleBGPRIBDivergence fetches the IPv4 and IPv6 RIB of every node and reports the prefixes
+// whose presence or next hop differs between the nodes
+func (s *VPPMCPServer) handleBGPRIBDivergence(ctx context.Context, input BGPRIBDivergenceInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received BGP RIB divergence request for pods: %v, family: %s", input.PodNames, input.Family)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	var families []string
+	switch input.Family {
+	case "", "both":
+		families = []string{"4", "6"}
+	case "4", "6":
+		families = []string{input.Family}
+	default:
+		err := fmt.Errorf("invalid family '%s'. Use '4', '6' or 'both'", input.Family)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	pods, err := s.resolveBGPPods(ctx, input.PodNames)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("BGP RIB Divergence across %d pod(s):\n", len(pods)))
+	structured := make(map[string]any)
+	total := 0
+	for _, family := range families {
+		// Fetch the RIB of every pod at the same time
+		ribs := make(map[string]map[string]string)
+		failures := make(map[string]error)
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, pod := range pods {
+			wg.Add(1)
+			go func(pod string) {
+				defer wg.Done()
+				result, err := s.ExecutePodGoBGPCommand(ctx, pod, "global rib -a "+family)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					failures[pod] = err
+					return
+				}
+				ribs[pod] = parseBGPRIBBestPaths(result["output"].(string))
+			}(pod.Name)
+		}
+		wg.Wait()
+
+		divergences := compareBGPRIBs(ribs)
+		total += len(divergences)
+		structured["ipv"+family] = divergences
+
+		text.WriteString(fmt.Sprintf("\n=== IPv%s ===\n", family))
+		for _, pod := range pods {
+			if err, ok := failures[pod.Name]; ok {
+				text.WriteString(fmt.Sprintf("- pod %s: gobgp global rib failed: %v\n", pod.Name, err))
+			} else if dryRunFromContext(ctx) == nil {
+				text.WriteString(fmt.Sprintf("- pod %s: %d prefix(es)\n", pod.Name, len(ribs[pod.Name])))
+			}
+		}
+		var missing, nextHops []bgpRIBDivergence
+		for _, divergence := range divergences {
+			if len(divergence.MissingOn) > 0 {
+				missing = append(missing, divergence)
+			}
+			if divergence.NextHops != nil {
+				nextHops = append(nextHops, divergence)
+			}
+		}
+		if len(divergences) == 0 {
+			text.WriteString("Every compared RIB holds the same prefixes through the same next hops.\n")
+			continue
+		}
+		if len(missing) > 0 {
+			text.WriteString(fmt.Sprintf("\nPrefixes missing on some pods (%d):\n", len(missing)))
+			for i, divergence := range missing {
+				if i == bgpDivergenceMaxPrefixes {
+					text.WriteString(fmt.Sprintf("... and %d more prefixes\n", len(missing)-i))
+					break
+				}
+				text.WriteString(fmt.Sprintf("- %s missing on %s\n", divergence.Prefix, strings.Join(divergence.MissingOn, ", ")))
+			}
+		}
+		if len(nextHops) > 0 {
+			text.WriteString(fmt.Sprintf("\nPrefixes with different next hops (%d):\n", len(nextHops)))
+			for i, divergence := range nextHops {
+				if i == bgpDivergenceMaxPrefixes {
+					text.WriteString(fmt.Sprintf("... and %d more prefixes\n", len(nextHops)-i))
+					break
+				}
+				var hops []string
+				for nextHop, hopPods := range divergence.NextHops {
+					hops = append(hops, fmt.Sprintf("via %s on %s", nextHop, strings.Join(hopPods, ", ")))
+				}
+				sort.Strings(hops)
+				text.WriteString(fmt.Sprintf("- %s: %s\n", divergence.Prefix, strings.Join(hops, "; ")))
+			}
+		}
+	}
+
+	if total > 0 {
+		text.WriteString("\n**Note**: A prefix missing on some nodes, or learned through different next hops, usually points to a partial peering " +
+			"(see bgp_show_neighbors and bgp_peer_flaps) or an import/export policy problem (see bgp_show_adj_rib_in and bgp_show_adj_rib_out).\n")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: structured,
+	}, nil, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBGPRouteMatches(t *testing.T) {
+	const (
+		tagged   = "*> 10.0.1.0/26     192.168.0.2   65001 65002   00:10:23   [{Origin: i} {Communities: 65000:100, 65000:200}]"
+		asSet    = "*> 10.0.2.0/26     192.168.0.3   65001 {65003,65004}   2d 03:04:05   [{Origin: i}]"
+		local    = "*> 10.0.3.0/26     0.0.0.0       00:10:23   [{Origin: i} {Communities: 65000:100}]"
+		ageLikeA = "*> 10.0.4.0/26     192.168.0.4   65005   00:00:12   [{Origin: i}]"
+	)
+
+	tests := []struct {
+		name      string
+		line      string
+		community string
+		asn       string
+		want      bool
+	}{
+		{name: "no criteria", line: asSet, want: true},
+		{name: "community carried", line: tagged, community: "65000:200", want: true},
+		{name: "community not carried", line: tagged, community: "65000:300"},
+		{name: "no communities", line: asSet, community: "65000:100"},
+		{name: "as in the path", line: tagged, asn: "65002", want: true},
+		{name: "as in a set", line: asSet, asn: "65004", want: true},
+		{name: "as not in the path", line: tagged, asn: "65003"},
+		{name: "local route has no path", line: local, asn: "65000"},
+		{name: "age is not part of the path", line: ageLikeA, asn: "12"},
+		{name: "both criteria", line: tagged, community: "65000:100", asn: "65001", want: true},
+		{name: "one of both criteria", line: tagged, community: "65000:100", asn: "65004"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bgpRouteMatches(tt.line, tt.community, tt.asn); got != tt.want {
+				t.Errorf("bgpRouteMatches(%q, %q, %q) = %t, want %t", tt.line, tt.community, tt.asn, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBGPRIBBestPaths(t *testing.T) {
+	output := `   Network              Next Hop             AS_PATH              Age        Attrs
+*> 10.0.1.0/26          192.168.0.2                               00:10:23   [{Origin: i}]
+*  10.0.1.0/26          192.168.0.3                               00:10:20   [{Origin: i}]
+*> 10.0.2.0/26          0.0.0.0                                   00:12:00   [{Origin: i}]
+*> fd00::/122           fd00:1::2                                 00:10:23   [{Origin: i}]
+`
+	want := map[string]string{
+		"10.0.1.0/26": "192.168.0.2",
+		"10.0.2.0/26": "0.0.0.0",
+		"fd00::/122":  "fd00:1::2",
+	}
+	if got := parseBGPRIBBestPaths(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBGPRIBBestPaths() = %v, want %v", got, want)
+	}
+}
+
+func TestCompareBGPRIBs(t *testing.T) {
+	ribs := map[string]map[string]string{
+		"calico-vpp-node-a": {
+			"10.0.1.0/26": "0.0.0.0",
+			"10.0.2.0/26": "192.168.0.3",
+			"10.0.3.0/26": "192.168.0.4",
+		},
+		"calico-vpp-node-b": {
+			"10.0.1.0/26": "192.168.0.2",
+			"10.0.2.0/26": "192.168.0.3",
+			"10.0.3.0/26": "192.168.0.5",
+		},
+		"calico-vpp-node-c": {
+			"10.0.2.0/26": "0.0.0.0",
+			"10.0.3.0/26": "192.168.0.4",
+		},
+	}
+
+	want := []bgpRIBDivergence{
+		{Prefix: "10.0.1.0/26", MissingOn: []string{"calico-vpp-node-c"}},
+		{Prefix: "10.0.3.0/26", NextHops: map[string][]string{
+			"192.168.0.4": {"calico-vpp-node-a", "calico-vpp-node-c"},
+			"192.168.0.5": {"calico-vpp-node-b"},
+		}},
+	}
+	if got := compareBGPRIBs(ribs); !reflect.DeepEqual(got, want) {
+		t.Errorf("compareBGPRIBs() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// calicoBlockAffinitiesPath lists the Calico BlockAffinity resources
+	calicoBlockAffinitiesPath = "/apis/crd.projectcalico.org/v1/blockaffinities"
+	// calicoIPAMBlocksPath lists the Calico IPAMBlock resources
+	calicoIPAMBlocksPath = "/apis/crd.projectcalico.org/v1/ipamblocks"
+
+	// blockAffinityConfirmed is the state of an affinity whose block is in use by its node
+	blockAffinityConfirmed = "confirmed"
+)
+
+// fibTableEntryRegexp matches the first line of an entry of a whole FIB table, e.g. "10.0.1.0/26"
+var fibTableEntryRegexp = regexp.MustCompile(`^(\S+/\d+)(\s|$)`)
+
+// calicoBlockAffinityList is the subset of a BlockAffinityList read by the affinity check
+type calicoBlockAffinityList struct {
+	Items []struct {
+		Spec struct {
+			State   string `json:"state"`
+			Node    string `json:"node"`
+			CIDR    string `json:"cidr"`
+			Deleted string `json:"deleted"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// calicoIPAMBlockList is the subset of an IPAMBlockList read by the affinity check
+type calicoIPAMBlockList struct {
+	Items []struct {
+		Spec struct {
+			CIDR        string  `json:"cidr"`
+			Affinity    *string `json:"affinity"`
+			Allocations []*int  `json:"allocations"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// BlockAffinitiesInput represents the input for checking the routes of the Calico IPAM blocks
+type BlockAffinitiesInput struct {
+	// PodNames lists the calico-vpp pods whose routes are checked (default: every calico-vpp pod)
+	PodNames []string `json:"pod_names,omitempty"`
+	// NodeNames selects the calico-vpp pods running on Kubernetes nodes, in addition to PodNames
+	NodeNames []string `json:"node_names,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pods
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// blockAffinity is an IPAM block affine to a node, with the routing problems found for it
+type blockAffinity struct {
+	CIDR  string `json:"cidr"`
+	Node  string `json:"node"`
+	State string `json:"state"`
+	// Allocated counts the addresses of the block in use, -1 without an IPAMBlock
+	Allocated int      `json:"allocated"`
+	Issues    []string `json:"issues,omitempty"`
+}
+
+// orphanBlock is an IPAM block without a confirmed affinity that nodes still originate in BGP
+type orphanBlock struct {
+	CIDR         string   `json:"cidr"`
+	Affinity     string   `json:"affinity,omitempty"`
+	AdvertisedBy []string `json:"advertised_by"`
+}
+
+// blockRouting is the BGP RIB and the default VPP FIB table of one node
+type blockRouting struct {
+	Pod   string
+	Node  string
+	RIB   map[string]string
+	FIB   map[string][]string
+	Error string
+}
+
+// normalizePrefix returns the canonical form of a CIDR so that the prefixes of Calico, gobgp
+// and VPP compare equal, or "" when it is invalid
+func normalizePrefix(cidr string) string {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return ""
+	}
+	return netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()).Masked().String()
+}
+
+// parseFibTable extracts the entries of the default table from 'show ip fib index 0' output,
+// mapping every prefix to the adjacencies of its forwarding chain
+func parseFibTable(output string) map[string][]string {
+	entries := make(map[string][]string)
+	var prefix string
+	defaultTable := true
+	for _, line := range strings.Split(output, "\n") {
+		if match := fibTableRegexp.FindStringSubmatch(line); match != nil {
+			defaultTable, prefix = match[2] == "0", ""
+			continue
+		}
+		if match := fibTableEntryRegexp.FindStringSubmatch(line); match != nil {
+			prefix = ""
+			if normalized := normalizePrefix(match[1]); defaultTable && normalized != "" {
+				prefix = normalized
+				entries[prefix] = []string{}
+			}
+			continue
+		}
+		// The load-balance line only groups the adjacencies listed below it
+		if match := fibPathRegexp.FindStringSubmatch(strings.TrimSpace(line)); prefix != "" && match != nil && !strings.HasPrefix(match[1], "dpo-load-balance") {
+			entries[prefix] = append(entries[prefix], match[1])
+		}
+	}
+	return entries
+}
+
+// checkBlockRoutes describes why the routes of a block do not follow its affinity: its node does
+// not originate it, another node originates it, or the other nodes route it to another node or
+// do not program it in VPP. nodeByAddress maps the BGP addresses to their node.
+func checkBlockRoutes(block *blockAffinity, routings []*blockRouting, nodeByAddress map[string]string) {
+	for _, routing := range routings {
+		if routing.Error != "" {
+			continue
+		}
+		nextHop, routed := routing.RIB[block.CIDR]
+		if routing.Node == block.Node {
+			switch {
+			case !routed:
+				block.Issues = append(block.Issues, fmt.Sprintf("node %s does not advertise its block in BGP", routing.Node))
+			case !localNextHop(nextHop):
+				block.Issues = append(block.Issues, fmt.Sprintf("node %s does not originate its block but learns it via %s", routing.Node, nextHop))
+			}
+			continue
+		}
+
+		switch {
+		case !routed:
+			block.Issues = append(block.Issues, fmt.Sprintf("no route to the block in the BGP RIB of node %s", routing.Node))
+		case localNextHop(nextHop):
+			block.Issues = append(block.Issues, fmt.Sprintf("node %s advertises the block itself: stale affinity", routing.Node))
+		default:
+			if owner := nodeByAddress[normalizeBGPAddress(nextHop)]; owner != "" && owner != block.Node {
+				block.Issues = append(block.Issues, fmt.Sprintf("node %s routes the block to node %s via %s: stale affinity", routing.Node, owner, nextHop))
+			}
+		}
+		forwarding, programmed := routing.FIB[block.CIDR]
+		if !programmed {
+			block.Issues = append(block.Issues, fmt.Sprintf("the block is not programmed in the VPP FIB of node %s", routing.Node))
+			continue
+		}
+		for _, adjacency := range forwarding {
+			if strings.Contains(adjacency, "drop") {
+				block.Issues = append(block.Issues, fmt.Sprintf("the VPP FIB of node %s drops the block", routing.Node))
+				break
+			}
+		}
+	}
+}
+
+// handleBlockAffinities lists the Calico IPAM block affinities per node and checks on every
+// node that each block is originated by its own node only, routed to it by the other nodes
+// and programmed in their VPP FIB, catching the routes left behind by stale affinities
+func (s *VPPMCPServer) handleBlockAffinities(ctx context.Context, input BlockAffinitiesInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received block affinities request for pods: %v", input.PodNames)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	// Step 1: Read the affinities, the IPAM blocks, the nodes and the calico-vpp pods
+	var err error
+	if s.bypassesKubernetes() {
+		err = fmt.Errorf("reading the IPAM block affinities requires the Kubernetes API, not available with the %s backend", backendLocal)
+	}
+	var k8sClient *KubeClient
+	if err == nil {
+		if k8sClient, err = newKubeClient(); err != nil {
+			err = fmt.Errorf("failed to create Kubernetes client: %v", err)
+		}
+	}
+	var affinities calicoBlockAffinityList
+	if err == nil {
+		err = k8sClient.getRawJSON(ctx, calicoBlockAffinitiesPath, &affinities)
+	}
+	var ipamBlocks calicoIPAMBlockList
+	if err == nil {
+		err = k8sClient.getRawJSON(ctx, calicoIPAMBlocksPath, &ipamBlocks)
+	}
+	var nodes []calicoNode
+	if err == nil {
+		nodes, err = listCalicoNodes(ctx, k8sClient)
+	}
+	var pods []VPPPod
+	if err == nil {
+		pods, err = s.resolveBGPPods(ctx, input.PodNames)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 2: Match every affinity with its IPAM block
+	nodeByAddress := make(map[string]string)
+	knownNodes := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		knownNodes[node.Name] = true
+		for _, address := range node.Addresses {
+			nodeByAddress[address] = node.Name
+		}
+	}
+	type ipamBlock struct {
+		affinity  string
+		allocated int
+	}
+	blocksByCIDR := make(map[string]ipamBlock, len(ipamBlocks.Items))
+	for _, item := range ipamBlocks.Items {
+		block := ipamBlock{}
+		if item.Spec.Affinity != nil {
+			block.affinity = strings.TrimPrefix(*item.Spec.Affinity, "host:")
+		}
+		for _, allocation := range item.Spec.Allocations {
+			if allocation != nil {
+				block.allocated++
+			}
+		}
+		blocksByCIDR[normalizePrefix(item.Spec.CIDR)] = block
+	}
+
+	var blocks []*blockAffinity
+	confirmed := make(map[string]bool)
+	for _, item := range affinities.Items {
+		block := &blockAffinity{CIDR: normalizePrefix(item.Spec.CIDR), Node: item.Spec.Node, State: item.Spec.State, Allocated: -1}
+		if item.Spec.Deleted == "true" {
+			block.State = "deleted"
+		}
+		switch {
+		case block.CIDR == "":
+			block.CIDR = item.Spec.CIDR
+			block.Issues = append(block.Issues, "invalid block CIDR")
+		case block.State != blockAffinityConfirmed:
+			block.Issues = append(block.Issues, fmt.Sprintf("the affinity is %s: its routes are not checked", block.State))
+		default:
+			confirmed[block.CIDR] = true
+		}
+		if !knownNodes[block.Node] {
+			block.Issues = append(block.Issues, fmt.Sprintf("node %s no longer exists: stale affinity", block.Node))
+		}
+		if ipam, ok := blocksByCIDR[block.CIDR]; !ok {
+			block.Issues = append(block.Issues, "no IPAMBlock matches the affinity")
+		} else {
+			block.Allocated = ipam.allocated
+			if ipam.affinity != block.Node {
+				block.Issues = append(block.Issues, fmt.Sprintf("the IPAMBlock is affine to %q instead", ipam.affinity))
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Node != blocks[j].Node {
+			return blocks[i].Node < blocks[j].Node
+		}
+		return blocks[i].CIDR < blocks[j].CIDR
+	})
+
+	// Only fetch the routes of the address families with blocks
+	families := make(map[string]bool)
+	for cidr := range blocksByCIDR {
+		if prefix, err := netip.ParsePrefix(cidr); err == nil && prefix.Addr().Is6() {
+			families["6"] = true
+		} else if err == nil {
+			families["4"] = true
+		}
+	}
+	for cidr := range confirmed {
+		if netip.MustParsePrefix(cidr).Addr().Is6() {
+			families["6"] = true
+		} else {
+			families["4"] = true
+		}
+	}
+
+	// Step 3: Fetch the BGP RIB and the VPP FIB of every node
+	routings := make([]*blockRouting, len(pods))
+	var wg sync.WaitGroup
+	for i, pod := range pods {
+		routings[i] = &blockRouting{Pod: pod.Name, Node: pod.Node, RIB: make(map[string]string), FIB: make(map[string][]string)}
+		wg.Add(1)
+		go func(routing *blockRouting) {
+			defer wg.Done()
+			for _, family := range []string{"4", "6"} {
+				if !families[family] {
+					continue
+				}
+				fib := "show ip fib index 0"
+				if family == "6" {
+					fib = "show ip6 fib index 0"
+				}
+				rib, err := s.ExecutePodGoBGPCommand(ctx, routing.Pod, "global rib -a "+family)
+				if err != nil {
+					routing.Error = fmt.Sprintf("gobgp global rib failed: %v", err)
+					return
+				}
+				fibResult, err := s.ExecutePodVPPCommand(ctx, routing.Pod, fib)
+				if err != nil {
+					routing.Error = fmt.Sprintf("vppctl %s failed: %v", fib, err)
+					return
+				}
+				for prefix, nextHop := range parseBGPRIBBestPaths(rib["output"].(string)) {
+					if normalized := normalizePrefix(prefix); normalized != "" {
+						routing.RIB[normalized] = nextHop
+					}
+				}
+				for prefix, forwarding := range parseFibTable(fibResult["output"].(string)) {
+					routing.FIB[prefix] = forwarding
+				}
+			}
+		}(routings[i])
+	}
+	wg.Wait()
+
+	if dryRunFromContext(ctx) != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("IPAM Block Affinities: %d block(s) would be checked on %d pod(s)\n", len(blocks), len(pods)),
+				},
+			},
+		}, nil, nil
+	}
+
+	// Step 4: Check the routes of the confirmed blocks and of the blocks without an affinity
+	for _, block := range blocks {
+		if confirmed[block.CIDR] {
+			checkBlockRoutes(block, routings, nodeByAddress)
+		}
+	}
+	var orphans []orphanBlock
+	for cidr, ipam := range blocksByCIDR {
+		if confirmed[cidr] {
+			continue
+		}
+		orphan := orphanBlock{CIDR: cidr, Affinity: ipam.affinity}
+		for _, routing := range routings {
+			if nextHop, routed := routing.RIB[cidr]; routing.Error == "" && routed && localNextHop(nextHop) {
+				orphan.AdvertisedBy = append(orphan.AdvertisedBy, routing.Node)
+			}
+		}
+		if len(orphan.AdvertisedBy) > 0 {
+			orphans = append(orphans, orphan)
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].CIDR < orphans[j].CIDR
+	})
+
+	// Step 5: Report the affinities per node
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("IPAM Block Affinities checked on %d pod(s):\n\n", len(pods)))
+	failed := 0
+	for _, routing := range routings {
+		if routing.Error != "" {
+			failed++
+			text.WriteString(fmt.Sprintf("Node %s (pod %s): %s\n", routing.Node, routing.Pod, routing.Error))
+		}
+	}
+	if failed > 0 {
+		text.WriteString("\n")
+	}
+	withIssues := 0
+	node := ""
+	for _, block := range blocks {
+		if block.Node != node {
+			if node != "" {
+				text.WriteString("\n")
+			}
+			node = block.Node
+			text.WriteString(fmt.Sprintf("Node %s:\n", node))
+		}
+		line := fmt.Sprintf("  %s %s", block.CIDR, block.State)
+		if block.Allocated >= 0 {
+			line += fmt.Sprintf(", %d address(es) in use", block.Allocated)
+		}
+		text.WriteString(line + "\n")
+		if len(block.Issues) > 0 {
+			withIssues++
+		}
+		for _, issue := range block.Issues {
+			text.WriteString(fmt.Sprintf("    ! %s\n", issue))
+		}
+	}
+	if len(blocks) == 0 {
+		text.WriteString("No block affinity found\n")
+	}
+	if len(orphans) > 0 {
+		text.WriteString("\nBlocks without a confirmed affinity still advertised:\n")
+		for _, orphan := range orphans {
+			affinity := "no affinity"
+			if orphan.Affinity != "" {
+				affinity = "IPAMBlock affine to " + orphan.Affinity
+			}
+			text.WriteString(fmt.Sprintf("  ! %s (%s) advertised by %s\n", orphan.CIDR, affinity, strings.Join(orphan.AdvertisedBy, ", ")))
+		}
+	}
+	text.WriteString(fmt.Sprintf("\nSummary: %d block(s), %d with issues, %d advertised without an affinity, %d pod(s) failed\n", len(blocks), withIssues, len(orphans), failed))
+	if withIssues > 0 || len(orphans) > 0 {
+		text.WriteString("\n**Note**: A block routed to another node than its affinity usually means the affinity moved while the previous node " +
+			"kept advertising the block (see bgp_show_global_info and bgp_rib_divergence), so the pods allocated from it are unreachable.\n")
+	}
+
+	if blocks == nil {
+		blocks = []*blockAffinity{}
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"blocks":        blocks,
+			"orphan_blocks": orphans,
+			"failed":        failed,
+		},
+	}, nil, nil
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// calicoFelixConfigurationsPath lists the Calico FelixConfiguration resources
+	calicoFelixConfigurationsPath = "/apis/crd.projectcalico.org/v1/felixconfigurations"
+
+	// calicoVPPConfigMap is the ConfigMap holding the calico-vpp agent configuration
+	calicoVPPConfigMap = "calico-vpp-config"
+
+	// calicoVPPFeatureGatesKey is the calico-vpp-config key enabling the optional features, e.g. IPsec
+	calicoVPPFeatureGatesKey = "CALICOVPP_FEATURE_GATES"
+)
+
+// calicoConfigurationList is the subset of a FelixConfigurationList or BGPConfigurationList read
+// by the configuration tools: the spec is kept as is so that no setting is lost
+type calicoConfigurationList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec map[string]any `json:"spec"`
+	} `json:"items"`
+}
+
+// calicoKeySetting is a dataplane-relevant setting of a Calico configuration along with the
+// value Calico uses when it is not set
+type calicoKeySetting struct {
+	Name    string
+	Default string
+}
+
+// felixKeySettings are the FelixConfiguration settings that change how the dataplane is programmed
+var felixKeySettings = []calicoKeySetting{
+	{Name: "wireguardEnabled", Default: "false"},
+	{Name: "wireguardEnabledV6", Default: "false"},
+	{Name: "ipipEnabled", Default: "detected from the IP pools"},
+	{Name: "vxlanEnabled", Default: "detected from the IP pools"},
+	{Name: "bpfEnabled", Default: "false"},
+	{Name: "ipv6Support", Default: "true"},
+	{Name: "defaultEndpointToHostAction", Default: "Drop"},
+	{Name: "logSeverityScreen", Default: "Info"},
+}
+
+// bgpKeySettings are the BGPConfiguration settings that change the routes exchanged by the nodes
+var bgpKeySettings = []calicoKeySetting{
+	{Name: "asNumber", Default: "64512"},
+	{Name: "nodeToNodeMeshEnabled", Default: "true"},
+	{Name: "listenPort", Default: "179"},
+	{Name: "serviceClusterIPs", Default: "not advertised"},
+	{Name: "serviceExternalIPs", Default: "not advertised"},
+	{Name: "serviceLoadBalancerIPs", Default: "not advertised"},
+	{Name: "logSeverityScreen", Default: "Info"},
+}
+
+// CalicoConfigurationInput represents the input for reading the Calico FelixConfiguration or
+// BGPConfiguration resources
+type CalicoConfigurationInput struct {
+	// Name restricts the output to one resource, e.g. default or node.<node name>
+	Name string `json:"name,omitempty"`
+}
+
+// calicoSetting is the value of a key setting of a Calico configuration
+type calicoSetting struct {
+	Name    string `json:"name"`
+	Value   any    `json:"value,omitempty"`
+	Default string `json:"default,omitempty"`
+	Set     bool   `json:"set"`
+}
+
+// calicoConfiguration is one FelixConfiguration or BGPConfiguration resource
+type calicoConfiguration struct {
+	Name        string          `json:"name"`
+	KeySettings []calicoSetting `json:"key_settings"`
+	Spec        map[string]any  `json:"spec"`
+}
+
+// formatCalicoValue formats a setting of a Calico configuration spec on one line
+func formatCalicoValue(value any) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// listCalicoConfigurations reads the Calico configuration resources under path, only the one
+// named name when it is not empty, and extracts their key settings
+func listCalicoConfigurations(ctx context.Context, k *KubeClient, kind, path, name string, keySettings []calicoKeySetting) ([]calicoConfiguration, error) {
+	var list calicoConfigurationList
+	if err := k.getRawJSON(ctx, path, &list); err != nil {
+		return nil, err
+	}
+
+	configurations := []calicoConfiguration{}
+	for _, item := range list.Items {
+		if name != "" && item.Metadata.Name != name {
+			continue
+		}
+		configuration := calicoConfiguration{
+			Name:        item.Metadata.Name,
+			KeySettings: make([]calicoSetting, 0, len(keySettings)),
+			Spec:        item.Spec,
+		}
+		if configuration.Spec == nil {
+			configuration.Spec = map[string]any{}
+		}
+		for _, keySetting := range keySettings {
+			value, set := configuration.Spec[keySetting.Name]
+			setting := calicoSetting{Name: keySetting.Name, Value: value, Set: set}
+			if !set {
+				setting.Default = keySetting.Default
+			}
+			configuration.KeySettings = append(configuration.KeySettings, setting)
+		}
+		configurations = append(configurations, configuration)
+	}
+	if name != "" && len(configurations) == 0 {
+		return nil, fmt.Errorf("%s %q not found", kind, name)
+	}
+	sort.Slice(configurations, func(i, j int) bool {
+		return configurations[i].Name < configurations[j].Name
+	})
+	return configurations, nil
+}
+
+// describeCalicoConfigurations writes the key settings of every configuration followed by the
+// other settings of its spec
+func describeCalicoConfigurations(text *strings.Builder, kind string, configurations []calicoConfiguration, keySettings []calicoKeySetting) {
+	if len(configurations) == 0 {
+		text.WriteString(fmt.Sprintf("No %s resource found: Calico uses its defaults\n\n", kind))
+		return
+	}
+
+	isKeySetting := make(map[string]bool, len(keySettings))
+	for _, keySetting := range keySettings {
+		isKeySetting[keySetting.Name] = true
+	}
+	for _, configuration := range configurations {
+		text.WriteString(fmt.Sprintf("%s %s:\n", kind, configuration.Name))
+		for _, setting := range configuration.KeySettings {
+			if setting.Set {
+				text.WriteString(fmt.Sprintf("  %s: %s\n", setting.Name, formatCalicoValue(setting.Value)))
+			} else {
+				text.WriteString(fmt.Sprintf("  %s: unset (default: %s)\n", setting.Name, setting.Default))
+			}
+		}
+
+		var others []string
+		for name := range configuration.Spec {
+			if !isKeySetting[name] {
+				others = append(others, name)
+			}
+		}
+		sort.Strings(others)
+		if len(others) > 0 {
+			text.WriteString("  Other settings:\n")
+			for _, name := range others {
+				text.WriteString(fmt.Sprintf("    %s: %s\n", name, formatCalicoValue(configuration.Spec[name])))
+			}
+		}
+		text.WriteString("\n")
+	}
+}
+
+// getCalicoVPPFeatureGates reads the feature gates of the calico-vpp agent, where IPsec between
+// the nodes is enabled, from the calico-vpp-config ConfigMap
+func getCalicoVPPFeatureGates(ctx context.Context, k *KubeClient) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+
+	configMap, err := k.clientset.CoreV1().ConfigMaps(vppNamespace).Get(ctx, calicoVPPConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s ConfigMap: %v", calicoVPPConfigMap, err)
+	}
+	featureGates := map[string]any{}
+	data := strings.TrimSpace(configMap.Data[calicoVPPFeatureGatesKey])
+	if data == "" {
+		return featureGates, nil
+	}
+	if err := json.Unmarshal([]byte(data), &featureGates); err != nil {
+		return nil, fmt.Errorf("failed to parse %s JSON: %v", calicoVPPFeatureGatesKey, err)
+	}
+	return featureGates, nil
+}
+
+// newCalicoConfigurationClient returns the Kubernetes client used to read the Calico
+// configuration resources, which are only available through the Kubernetes API
+func (s *VPPMCPServer) newCalicoConfigurationClient(kind string) (*KubeClient, error) {
+	if s.bypassesKubernetes() {
+		return nil, fmt.Errorf("the %s resources are only available through the Kubernetes API, not with the %s backend", kind, backendLocal)
+	}
+	k8sClient, err := newKubeClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+	return k8sClient, nil
+}
+
+// handleGetFelixConfiguration reports the FelixConfiguration resources, highlighting the
+// encryption and encapsulation settings, along with the calico-vpp feature gates
+func (s *VPPMCPServer) handleGetFelixConfiguration(ctx context.Context, input CalicoConfigurationInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received FelixConfiguration request for: %q", input.Name)
+
+	const kind = "FelixConfiguration"
+	k8sClient, err := s.newCalicoConfigurationClient(kind)
+	var configurations []calicoConfiguration
+	if err == nil {
+		configurations, err = listCalicoConfigurations(ctx, k8sClient, kind, calicoFelixConfigurationsPath, input.Name, felixKeySettings)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	var text strings.Builder
+	describeCalicoConfigurations(&text, kind, configurations, felixKeySettings)
+
+	// IPsec is not a Felix setting with calico-vpp: the agent enables it from its feature gates
+	structured := map[string]any{
+		"configurations": configurations,
+	}
+	text.WriteString(fmt.Sprintf("calico-vpp feature gates (%s in ConfigMap %s):\n", calicoVPPFeatureGatesKey, calicoVPPConfigMap))
+	featureGates, err := getCalicoVPPFeatureGates(ctx, k8sClient)
+	switch {
+	case err != nil:
+		log.Printf("Failed to read the calico-vpp feature gates: %v", err)
+		text.WriteString(fmt.Sprintf("  unavailable: %v\n", err))
+		structured["feature_gates_error"] = err.Error()
+	case len(featureGates) == 0:
+		text.WriteString("  none set: every optional feature, IPsec included, is disabled\n")
+		structured["feature_gates"] = featureGates
+	default:
+		names := make([]string, 0, len(featureGates))
+		for name := range featureGates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			text.WriteString(fmt.Sprintf("  %s: %s\n", name, formatCalicoValue(featureGates[name])))
+		}
+		structured["feature_gates"] = featureGates
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: structured,
+	}, nil, nil
+}
+
+// handleGetBGPConfiguration reports the BGPConfiguration resources, highlighting the AS number,
+// the node-to-node mesh and the service advertisements
+func (s *VPPMCPServer) handleGetBGPConfiguration(ctx context.Context, input CalicoConfigurationInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received BGPConfiguration request for: %q", input.Name)
+
+	const kind = "BGPConfiguration"
+	k8sClient, err := s.newCalicoConfigurationClient(kind)
+	var configurations []calicoConfiguration
+	if err == nil {
+		configurations, err = listCalicoConfigurations(ctx, k8sClient, kind, calicoBGPConfigurationsPath, input.Name, bgpKeySettings)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	var text strings.Builder
+	describeCalicoConfigurations(&text, kind, configurations, bgpKeySettings)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"configurations": configurations,
+		},
+	}, nil, nil
+}
@@ -0,0 +1,751 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultCaptureJobDuration = 30 * time.Second
+	maxCaptureJobDuration     = 10 * time.Minute
+	// captureJobRetention is how long the results of finished capture jobs are kept
+	captureJobRetention = time.Hour
+
+	// Types of capture jobs, matching the synchronous capture tools
+	captureTypeTrace    = "trace"
+	captureTypePcap     = "pcap"
+	captureTypeDispatch = "dispatch"
+
+	// States of capture jobs
+	captureStateRunning   = "running"
+	captureStateCompleted = "completed"
+	captureStateStopped   = "stopped"
+	captureStateFailed    = "failed"
+)
+
+// CaptureStartInput represents the input for starting a background capture job
+type CaptureStartInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Type specifies the capture: trace, pcap or dispatch
+	Type string `json:"type"`
+	// Interface specifies the interface type (trace, dispatch) or name (pcap) to capture from
+	Interface string `json:"interface,omitempty"`
+	// Count specifies the number of packets to capture
+	Count int `json:"count,omitempty"`
+	// DurationSeconds specifies how long the capture runs unless stopped earlier
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// CaptureJobInput represents the input of the tools acting on a capture job
+type CaptureJobInput struct {
+	// JobID specifies the capture job returned by capture_start
+	JobID string `json:"job_id"`
+}
+
+// CaptureStatusInput represents the input for the capture job status tool
+type CaptureStatusInput struct {
+	// JobID specifies the capture job to report (default: every job)
+	JobID string `json:"job_id,omitempty"`
+}
+
+// captureCommands lists the vppctl commands of a capture
+type captureCommands struct {
+	// reset clears the state of a previous capture; its errors are ignored
+	reset []string
+	start string
+	// collect ends the capture and returns its output
+	collect string
+	// cleanup runs after collect; its errors are ignored
+	cleanup []string
+	// title, target and note describe the capture in its result
+	title  string
+	target string
+	note   string
+}
+
+// newCaptureCommands returns the commands of a capture of the given type on target, which
+// is a VPP input node for traces and dispatch traces and an interface name for pcaps
+func newCaptureCommands(captureType, target string, count int) captureCommands {
+	switch captureType {
+	case captureTypePcap:
+		return captureCommands{
+			reset:   []string{"pcap trace off"},
+			start:   fmt.Sprintf("pcap trace tx rx max %d intfc %s file trace.pcap", count, target),
+			collect: "pcap trace off",
+			title:   "VPP PCAP Capture Results",
+			target:  "Interface: " + target,
+			note:    "PCAP file saved at /tmp/trace.pcap",
+		}
+	case captureTypeDispatch:
+		return captureCommands{
+			reset:   []string{"pcap dispatch trace off"},
+			start:   fmt.Sprintf("pcap dispatch trace on max %d buffer-trace %s %d", count, target, count),
+			collect: "pcap dispatch trace off",
+			title:   "VPP Dispatch Trace Results",
+			target:  "VPP Input Node: " + target,
+			note:    "Dispatch PCAP file saved at /tmp/dispatch.pcap",
+		}
+	default:
+		return captureCommands{
+			reset:   []string{"clear trace"},
+			start:   fmt.Sprintf("trace add %s %d", target, count),
+			collect: fmt.Sprintf("show trace max %d", count),
+			cleanup: []string{"clear trace"},
+			title:   "VPP Trace Capture Results",
+			target:  "VPP Input Node: " + target,
+			note:    "Trace is not saved to any file",
+		}
+	}
+}
+
+// captureJob is a capture running in the background
+type captureJob struct {
+	ID       string
+	Type     string
+	Pod      string
+	Count    int
+	Duration time.Duration
+	Started  time.Time
+	commands captureCommands
+
+	// The fields below are guarded by the mutex of the owning captureJobs
+	state    string
+	finished time.Time
+	output   string
+	err      error
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// summary describes the job in one line
+func (j *captureJob) summary(now time.Time) string {
+	switch j.state {
+	case captureStateRunning:
+		remaining := j.Duration - now.Sub(j.Started)
+		if remaining < 0 {
+			remaining = 0
+		}
+		return fmt.Sprintf("%s: %s on pod %s, running for %s (%s remaining)",
+			j.ID, j.Type, j.Pod, now.Sub(j.Started).Round(time.Second), remaining.Round(time.Second))
+	case captureStateFailed:
+		return fmt.Sprintf("%s: %s on pod %s, failed at %s: %v", j.ID, j.Type, j.Pod, j.finished.Format(time.RFC3339), j.err)
+	default:
+		return fmt.Sprintf("%s: %s on pod %s, %s at %s after %s",
+			j.ID, j.Type, j.Pod, j.state, j.finished.Format(time.RFC3339), j.finished.Sub(j.Started).Round(time.Second))
+	}
+}
+
+// result renders the output of a finished job like the synchronous capture tools
+func (j *captureJob) result() string {
+	if j.state == captureStateFailed {
+		return fmt.Sprintf("Error executing %s capture job %s: %v", j.Type, j.ID, j.err)
+	}
+	return fmt.Sprintf("%s:\n\n%s\n\nCapture Parameters:\n- Job: %s (%s)\n- %s\n- Count: %d\n- Capture Duration: %s\n- Pod: %s\n\n**Important**: %s\n\n",
+		j.commands.title, j.output, j.ID, j.state, j.commands.target, j.Count, j.finished.Sub(j.Started).Round(time.Second), j.Pod, j.commands.note)
+}
+
+// captureJobs tracks the background capture jobs
+type captureJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*captureJob
+	seq  int
+}
+
+// newCaptureJobs creates an empty capture job registry
+func newCaptureJobs() *captureJobs {
+	return &captureJobs{jobs: make(map[string]*captureJob)}
+}
+
+// add registers a job under a new ID and forgets the jobs finished for longer than the retention
+func (c *captureJobs) add(job *captureJob) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, old := range c.jobs {
+		if old.state != captureStateRunning && time.Since(old.finished) > captureJobRetention {
+			delete(c.jobs, id)
+		}
+	}
+	c.seq++
+	job.ID = fmt.Sprintf("capture-%d", c.seq)
+	c.jobs[job.ID] = job
+}
+
+// get returns the job with the given ID
+func (c *captureJobs) get(id string) (*captureJob, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	job, ok := c.jobs[id]
+	return job, ok
+}
+
+// finish records the outcome of a job
+func (c *captureJobs) finish(job *captureJob, state, output string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	job.state, job.output, job.err, job.finished = state, output, err, time.Now()
+}
+
+// runCaptureJob waits for the job to complete or be stopped, then collects its output.
+// It releases the capture lock of the pod once done.
+func (s *VPPMCPServer) runCaptureJob(ctx context.Context, job *captureJob, unlock func()) {
+	defer close(job.done)
+	defer unlock()
+
+	timer := time.NewTimer(job.Duration)
+	defer timer.Stop()
+	state := captureStateCompleted
+	select {
+	case <-timer.C:
+	case <-job.stop:
+		state = captureStateStopped
+	}
+
+	log.Printf("Collecting capture job %s on pod %s", job.ID, job.Pod)
+	result, err := s.ExecutePodVPPCommand(ctx, job.Pod, job.commands.collect)
+	for _, command := range job.commands.cleanup {
+		_, _ = s.ExecutePodVPPCommand(ctx, job.Pod, command)
+	}
+	if err != nil {
+		s.captures.finish(job, captureStateFailed, "", err)
+		return
+	}
+	s.captures.finish(job, state, result["output"].(string), nil)
+}
+
+// handleCaptureStart validates a capture, starts it and returns its job ID without waiting for it
+func (s *VPPMCPServer) handleCaptureStart(ctx context.Context, input CaptureStartInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received capture start request for pod: %s, type: %s", input.PodName, input.Type)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	duration := defaultCaptureJobDuration
+	if input.DurationSeconds > 0 {
+		duration = time.Duration(input.DurationSeconds) * time.Second
+	}
+	count := input.Count
+	if count == 0 {
+		count = 500
+	}
+
+	var err error
+	switch {
+	case input.Type != captureTypeTrace && input.Type != captureTypePcap && input.Type != captureTypeDispatch:
+		err = fmt.Errorf("invalid type '%s'. Use '%s', '%s' or '%s'", input.Type, captureTypeTrace, captureTypePcap, captureTypeDispatch)
+	case input.DurationSeconds < 0 || duration > maxCaptureJobDuration:
+		err = fmt.Errorf("invalid duration_seconds %d: must be between 1 and %d", input.DurationSeconds, int(maxCaptureJobDuration.Seconds()))
+	case count < 0:
+		err = fmt.Errorf("invalid count %d: must not be negative", count)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Pcaps capture from an interface name, traces from the input node of an interface type
+	target := input.Interface
+	if input.Type == captureTypePcap {
+		if target == "" {
+			target = "any"
+		} else if target != "any" {
+			err = validateInterfaceName(target)
+		}
+	} else {
+		var k8sClient *KubeClient
+		if !s.bypassesKubernetes() {
+			k8sClient, err = newKubeClient()
+		}
+		if err == nil {
+			target, _, err = mapInterfaceTypeToVppInputNode(k8sClient, input.Interface)
+		}
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	commands := newCaptureCommands(input.Type, target, count)
+
+	// Dry runs record the whole capture at once
+	if dryRunFromContext(ctx) != nil {
+		for _, command := range append(append(append(commands.reset, commands.start), commands.collect), commands.cleanup...) {
+			_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, command)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Capture job not started (dry run): %s on pod %s", input.Type, input.PodName),
+				},
+			},
+		}, nil, nil
+	}
+
+	// Fail fast instead of waiting for the running capture on the pod
+	unlock, ok := s.captureLocks.tryLock(input.PodName)
+	if !ok {
+		err := fmt.Errorf("a capture is already running on pod %s. Check capture_status, or wait for it to complete", input.PodName)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// The job outlives the tool call: its commands must not be cancelled with the request
+	jobCtx := withExecTimeout(context.Background(), input.TimeoutSeconds)
+	for _, command := range commands.reset {
+		_, _ = s.ExecutePodVPPCommand(jobCtx, input.PodName, command)
+	}
+	if _, err := s.ExecutePodVPPCommand(jobCtx, input.PodName, commands.start); err != nil {
+		unlock()
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error starting %s capture: %v", input.Type, err),
+				},
+			},
+		}, nil, err
+	}
+
+	job := &captureJob{
+		Type:     input.Type,
+		Pod:      input.PodName,
+		Count:    count,
+		Duration: duration,
+		Started:  time.Now(),
+		commands: commands,
+		state:    captureStateRunning,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	s.captures.add(job)
+	go s.runCaptureJob(jobCtx, job, unlock)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Capture job %s started: %s on pod %s (%s), for %s or until %d packets are captured.\n\n"+
+					"Check it with capture_status, end it early with capture_stop, and read the captured packets with capture_result.",
+					job.ID, job.Type, job.Pod, commands.target, duration, count),
+			},
+		},
+	}, nil, nil
+}
+
+// handleCaptureStatus reports the state of a capture job, or of every job
+func (s *VPPMCPServer) handleCaptureStatus(ctx context.Context, input CaptureStatusInput) (*mcp.CallToolResult, any, error) {
+	s.captures.mu.Lock()
+	defer s.captures.mu.Unlock()
+
+	now := time.Now()
+	var lines []string
+	if input.JobID != "" {
+		job, ok := s.captures.jobs[input.JobID]
+		if !ok {
+			err := fmt.Errorf("unknown capture job %s", input.JobID)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		lines = append(lines, job.summary(now))
+	} else {
+		for _, job := range s.captures.jobs {
+			lines = append(lines, job.summary(now))
+		}
+		sort.Strings(lines)
+	}
+
+	text := "Capture Jobs:\n\n"
+	if len(lines) == 0 {
+		text += "No capture job. Start one with capture_start."
+	} else {
+		text += "- " + strings.Join(lines, "\n- ")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text,
+			},
+		},
+	}, nil, nil
+}
+
+// handleCaptureStop ends a running capture job early and returns its result
+func (s *VPPMCPServer) handleCaptureStop(ctx context.Context, input CaptureJobInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received capture stop request for job: %s", input.JobID)
+
+	job, ok := s.captures.get(input.JobID)
+	if !ok {
+		err := fmt.Errorf("unknown capture job %s", input.JobID)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	job.stopOnce.Do(func() { close(job.stop) })
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: capture job %s is stopping but its output was not collected yet: %v. Read it later with capture_result.", job.ID, ctx.Err()),
+				},
+			},
+		}, nil, nil
+	}
+
+	return s.handleCaptureResult(ctx, input)
+}
+
+// handleCaptureResult returns the output of a finished capture job
+func (s *VPPMCPServer) handleCaptureResult(ctx context.Context, input CaptureJobInput) (*mcp.CallToolResult, any, error) {
+	s.captures.mu.Lock()
+	defer s.captures.mu.Unlock()
+
+	job, ok := s.captures.jobs[input.JobID]
+	if !ok {
+		err := fmt.Errorf("unknown capture job %s", input.JobID)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	text := job.result()
+	if job.state == captureStateRunning {
+		text = fmt.Sprintf("Capture job %s is still running.\n\n- %s\n\nStop it early with capture_stop, or call capture_result again once it completed.",
+			job.ID, job.summary(time.Now()))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text,
+			},
+		},
+	}, nil, nil
+}
+
+// VPPTracePcapInput represents the input for capturing a packet trace and a pcap at the same time
+type VPPTracePcapInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Interface specifies the interface type whose input node is traced
+	Interface string `json:"interface,omitempty"`
+	// PcapInterface specifies the interface name the pcap captures from
+	PcapInterface string `json:"pcap_interface,omitempty"`
+	// Count specifies the number of packets to capture
+	Count int `json:"count,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// handleTracePcap runs a packet trace and a pcap capture over the same window, so graph
+// traces can be correlated with the packets on the wire
+func (s *VPPMCPServer) handleTracePcap(ctx context.Context, input VPPTracePcapInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received trace and pcap capture request for pod: %s", input.PodName)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	count := input.Count
+	if count == 0 {
+		count = 500
+	}
+	pcapInterface := input.PcapInterface
+	if pcapInterface == "" {
+		pcapInterface = "any"
+	}
+
+	var err error
+	if count < 0 {
+		err = fmt.Errorf("invalid count %d: must not be negative", count)
+	} else if pcapInterface != "any" {
+		err = validateInterfaceName(pcapInterface)
+	}
+	var node string
+	if err == nil {
+		var k8sClient *KubeClient
+		if !s.bypassesKubernetes() {
+			k8sClient, err = newKubeClient()
+		}
+		if err == nil {
+			node, _, err = mapInterfaceTypeToVppInputNode(k8sClient, input.Interface)
+		}
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	trace := newCaptureCommands(captureTypeTrace, node, count)
+	pcap := newCaptureCommands(captureTypePcap, pcapInterface, count)
+
+	// Serialize the captures on the pod
+	unlock, err := s.lockCapture(ctx, input.PodName)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	defer unlock()
+
+	// Step 1: Reset both captures, then start them back to back
+	for _, command := range append(trace.reset, pcap.reset...) {
+		_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, command)
+	}
+	for _, command := range []string{trace.start, pcap.start} {
+		log.Printf("Starting capture: %s", command)
+		if _, err := s.ExecutePodVPPCommand(ctx, input.PodName, command); err != nil {
+			_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, pcap.collect)
+			_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, "clear trace")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error starting capture: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
+	// Step 2: Capture over the same window
+	log.Printf("Capturing packets for %s or until %d packets captured...", defaultCaptureJobDuration, count)
+	if dryRunFromContext(ctx) == nil {
+		time.Sleep(defaultCaptureJobDuration)
+	}
+
+	// Step 3: Stop the pcap first so it doesn't capture the trace retrieval, then collect the trace
+	pcapResult, pcapErr := s.ExecutePodVPPCommand(ctx, input.PodName, pcap.collect)
+	traceResult, traceErr := s.ExecutePodVPPCommand(ctx, input.PodName, trace.collect)
+	for _, command := range trace.cleanup {
+		_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, command)
+	}
+	if err := errors.Join(traceErr, pcapErr); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error collecting captures: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	traceOutput := traceResult["output"].(string)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("VPP Trace Results:\n\n%s\n\nVPP PCAP Capture Results:\n\n%s\n\nCapture Parameters:\n- %s\n- %s\n- Count: %d\n- Capture Duration: %s\n- Pod: %s\n\n"+
+					"**Important**: %s; decode it with pcap_decode to correlate the packets on the wire with the graph traces\n\n",
+					traceOutput, pcapResult["output"].(string), trace.target, pcap.target, count, defaultCaptureJobDuration, input.PodName, pcap.note),
+			},
+		},
+		StructuredContent: map[string]any{
+			"pod":        input.PodName,
+			"input_node": node,
+			"pcap_file":  "/tmp/trace.pcap",
+			"packets":    parseTraceRecords(traceOutput),
+		},
+	}, nil, nil
+}
+
+// traceStatusMaxPackets bounds the packets read from the trace buffer by vpp_capture_status
+const traceStatusMaxPackets = 1000
+
+// VPPCaptureStatusInput represents the input for checking the captures active on a pod
+type VPPCaptureStatusInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// runningOn returns the summaries of the jobs running on a pod
+func (c *captureJobs) runningOn(pod string, now time.Time) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var summaries []string
+	for _, job := range c.jobs {
+		if job.Pod == pod && job.state == captureStateRunning {
+			summaries = append(summaries, job.summary(now))
+		}
+	}
+	sort.Strings(summaries)
+	return summaries
+}
+
+// formatTraceOccupancy describes how many packets the trace buffer of each thread holds
+func formatTraceOccupancy(records []traceRecord) string {
+	if len(records) == 0 {
+		return "empty"
+	}
+	type threadCount struct {
+		name    string
+		packets int
+	}
+	var threads []int
+	counts := make(map[int]*threadCount)
+	for _, record := range records {
+		count, ok := counts[record.Thread]
+		if !ok {
+			count = &threadCount{name: record.ThreadName}
+			counts[record.Thread] = count
+			threads = append(threads, record.Thread)
+		}
+		count.packets++
+	}
+	sort.Ints(threads)
+	var parts []string
+	for _, thread := range threads {
+		parts = append(parts, fmt.Sprintf("thread %d %s: %d", thread, counts[thread].name, counts[thread].packets))
+	}
+	total := fmt.Sprintf("%d packets", len(records))
+	if len(records) >= traceStatusMaxPackets {
+		total = fmt.Sprintf("at least %d packets", len(records))
+	}
+	return fmt.Sprintf("%s (%s)", total, strings.Join(parts, ", "))
+}
+
+// handleVPPCaptureStatus reports the pcap, dispatch and buffer traces active on a pod, so
+// captures left running, e.g. by a cancelled tool call, can be found and cleaned up
+func (s *VPPMCPServer) handleVPPCaptureStatus(ctx context.Context, input VPPCaptureStatusInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received capture status request for pod: %s", input.PodName)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("VPP Capture Status of pod %s:\n\n", input.PodName))
+	for _, section := range []struct {
+		title   string
+		command string
+	}{
+		{"Pcap Trace", "pcap trace status"},
+		{"Pcap Dispatch Trace", "pcap dispatch trace status"},
+	} {
+		result, err := s.ExecutePodVPPCommand(ctx, input.PodName, section.command)
+		if err != nil {
+			text.WriteString(fmt.Sprintf("=== %s ===\nError executing '%s': %v\n\n", section.title, section.command, err))
+			continue
+		}
+		text.WriteString(fmt.Sprintf("=== %s ===\n%s\n\n", section.title, strings.TrimSpace(result["output"].(string))))
+	}
+
+	showCmd := fmt.Sprintf("show trace max %d", traceStatusMaxPackets)
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, showCmd)
+	if err != nil {
+		text.WriteString(fmt.Sprintf("=== Trace Buffer ===\nError executing '%s': %v\n\n", showCmd, err))
+	} else {
+		text.WriteString(fmt.Sprintf("=== Trace Buffer ===\n%s\n\n", formatTraceOccupancy(parseTraceRecords(result["output"].(string)))))
+	}
+
+	// Captures of this server holding the pod
+	text.WriteString("=== Captures of this server ===\n")
+	if unlock, ok := s.captureLocks.tryLock(input.PodName); ok {
+		unlock()
+		text.WriteString("No capture tool call is running on the pod\n")
+	} else {
+		text.WriteString("A capture tool call is running on the pod\n")
+	}
+	for _, summary := range s.captures.runningOn(input.PodName, time.Now()) {
+		text.WriteString("- " + summary + "\n")
+	}
+
+	text.WriteString("\n**Note**: A pcap or dispatch trace reported as active, or a filled trace buffer, without a capture of this server " +
+		"was left behind, e.g. by a cancelled tool call or by someone running vppctl. Stop it with 'pcap trace off', " +
+		"'pcap dispatch trace off' or 'clear trace' through vpp_exec.\n")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
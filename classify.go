@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// pcapProtocols maps the protocol names accepted by vpp_pcap to IP protocol numbers
+var pcapProtocols = map[string]int{
+	"icmp":  1,
+	"tcp":   6,
+	"udp":   17,
+	"sctp":  132,
+	"icmp6": 58,
+}
+
+// VPPPcapInput represents the input for the VPP pcap tool
+type VPPPcapInput struct {
+	VPPCaptureInput
+	// SrcIP keeps only the packets from this address
+	SrcIP string `json:"src_ip,omitempty"`
+	// DstIP keeps only the packets to this address
+	DstIP string `json:"dst_ip,omitempty"`
+	// Protocol keeps only the packets of this IP protocol: tcp, udp, icmp, icmp6, sctp or a number
+	Protocol string `json:"protocol,omitempty"`
+	// SrcPort keeps only the packets from this TCP, UDP or SCTP port
+	SrcPort int `json:"src_port,omitempty"`
+	// DstPort keeps only the packets to this TCP, UDP or SCTP port
+	DstPort int `json:"dst_port,omitempty"`
+	// MaxBytesPerPacket truncates the captured packets to this many bytes
+	MaxBytesPerPacket int `json:"max_bytes_per_packet,omitempty"`
+	// MaxFileMB caps the size of the capture file by lowering the packet count
+	MaxFileMB int `json:"max_file_mb,omitempty"`
+	// KeepFiles writes a timestamped capture file and keeps only this many of them
+	KeepFiles int `json:"keep_files,omitempty"`
+	// Direction selects the packets captured: rx, tx or both (default: both, none with Drop)
+	Direction string `json:"direction,omitempty"`
+	// Drop also captures the packets dropped by VPP
+	Drop bool `json:"drop,omitempty"`
+	// PodIP captures on the interface of the workload pod with this IP
+	PodIP string `json:"pod_ip,omitempty"`
+}
+
+// pcapDirections returns the directions of 'pcap trace' for the capture, e.g. "rx drop".
+// Without direction, drop captures keep only the dropped packets.
+func pcapDirections(input VPPPcapInput) (string, error) {
+	var directions []string
+	switch input.Direction {
+	case "":
+		if !input.Drop {
+			directions = []string{"tx", "rx"}
+		}
+	case "both":
+		directions = []string{"tx", "rx"}
+	case "rx", "tx":
+		directions = []string{input.Direction}
+	default:
+		return "", fmt.Errorf("invalid direction '%s'. Use 'rx', 'tx' or 'both'", input.Direction)
+	}
+	if input.Drop {
+		directions = append(directions, "drop")
+	}
+	return strings.Join(directions, " "), nil
+}
+
+// hasFilter reports whether the capture is narrowed to a flow
+func (input VPPPcapInput) hasFilter() bool {
+	return input.SrcIP != "" || input.DstIP != "" || input.Protocol != "" || input.SrcPort != 0 || input.DstPort != 0
+}
+
+// parsePcapProtocol returns the IP protocol number of a protocol name or number
+func parsePcapProtocol(protocol string) (int, error) {
+	if number, ok := pcapProtocols[strings.ToLower(protocol)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(protocol)
+	if err != nil || number < 0 || number > 255 {
+		return 0, fmt.Errorf("invalid protocol %q: use tcp, udp, icmp, icmp6, sctp or a protocol number", protocol)
+	}
+	return number, nil
+}
+
+// pcapClassifyFilter translates the 5-tuple of a pcap capture into the arguments of
+// 'classify filter pcap', e.g. "mask l3 ip4 src proto match l3 ip4 src 10.0.0.1 proto 6".
+// Without address, the filter matches IPv4 packets.
+func pcapClassifyFilter(input VPPPcapInput) (string, error) {
+	family := "ip4"
+	var addrs []netip.Addr
+	for _, value := range []string{input.SrcIP, input.DstIP} {
+		if value == "" {
+			continue
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid address %q: expected e.g. 10.0.0.1 or 2001:db8::1", value)
+		}
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 2 && addrs[0].Is4() != addrs[1].Is4() {
+		return "", fmt.Errorf("src_ip and dst_ip must be of the same address family")
+	}
+	if len(addrs) > 0 && !addrs[0].Is4() {
+		family = "ip6"
+	}
+
+	var mask, match []string
+	if input.SrcIP != "" {
+		mask = append(mask, "src")
+		match = append(match, "src", input.SrcIP)
+	}
+	if input.DstIP != "" {
+		mask = append(mask, "dst")
+		match = append(match, "dst", input.DstIP)
+	}
+
+	protocol := 0
+	if input.Protocol != "" {
+		var err error
+		if protocol, err = parsePcapProtocol(input.Protocol); err != nil {
+			return "", err
+		}
+		mask = append(mask, "proto")
+		match = append(match, "proto", strconv.Itoa(protocol))
+	}
+
+	var l4Mask, l4Match []string
+	for _, port := range []struct {
+		name  string
+		value int
+	}{{"src_port", input.SrcPort}, {"dst_port", input.DstPort}} {
+		if port.value == 0 {
+			continue
+		}
+		if port.value < 0 || port.value > 65535 {
+			return "", fmt.Errorf("invalid %s %d: must be between 1 and 65535", port.name, port.value)
+		}
+		l4Mask = append(l4Mask, port.name)
+		l4Match = append(l4Match, port.name, strconv.Itoa(port.value))
+	}
+	if len(l4Mask) > 0 && protocol != pcapProtocols["tcp"] && protocol != pcapProtocols["udp"] && protocol != pcapProtocols["sctp"] {
+		return "", fmt.Errorf("src_port and dst_port require protocol tcp, udp or sctp")
+	}
+
+	var filter []string
+	filter = append(filter, "mask")
+	if len(mask) > 0 {
+		filter = append(append(filter, "l3", family), mask...)
+	}
+	if len(l4Mask) > 0 {
+		filter = append(append(filter, "l4"), l4Mask...)
+	}
+	filter = append(filter, "match")
+	if len(match) > 0 {
+		filter = append(append(filter, "l3", family), match...)
+	}
+	if len(l4Match) > 0 {
+		filter = append(append(filter, "l4"), l4Match...)
+	}
+	return strings.Join(filter, " "), nil
+}
+
+// pcapFilterLine describes the flow a pcap capture is narrowed to in its capture parameters
+func pcapFilterLine(input VPPPcapInput) string {
+	if !input.hasFilter() {
+		return ""
+	}
+	var parts []string
+	if input.Protocol != "" {
+		parts = append(parts, "protocol "+input.Protocol)
+	}
+	for _, endpoint := range []struct {
+		name string
+		ip   string
+		port int
+	}{{"src", input.SrcIP, input.SrcPort}, {"dst", input.DstIP, input.DstPort}} {
+		switch {
+		case endpoint.ip != "" && endpoint.port != 0:
+			parts = append(parts, fmt.Sprintf("%s %s port %d", endpoint.name, endpoint.ip, endpoint.port))
+		case endpoint.ip != "":
+			parts = append(parts, endpoint.name+" "+endpoint.ip)
+		case endpoint.port != 0:
+			parts = append(parts, fmt.Sprintf("%s port %d", endpoint.name, endpoint.port))
+		}
+	}
+	return "\n- Filter: " + strings.Join(parts, ", ")
+}
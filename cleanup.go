@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// captureFileRegexp matches the capture files written to /tmp by the capture tools and by vppctl
+var captureFileRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*\.pcap$`)
+
+// VPPCaptureCleanupInput represents the input for cleaning up the capture files of a pod
+type VPPCaptureCleanupInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// OlderThanMinutes keeps the capture files modified within this many minutes
+	OlderThanMinutes int `json:"older_than_minutes,omitempty"`
+	// Delete removes the stale files instead of only listing them
+	Delete bool `json:"delete,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// captureFile is a capture file of the /tmp directory of the vpp container
+type captureFile struct {
+	Path     string
+	Size     int64
+	Modified time.Time
+}
+
+// parseStatFiles reads the files of 'stat -c "%s %Y %n"' output
+func parseStatFiles(output string) []captureFile {
+	var files []captureFile
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		modified, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		files = append(files, captureFile{Path: fields[2], Size: size, Modified: time.Unix(modified, 0)})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Modified.Before(files[j].Modified) })
+	return files
+}
+
+// listCaptureFiles returns the pcap and API trace files of the /tmp directory of the vpp container, oldest first
+func (s *VPPMCPServer) listCaptureFiles(ctx context.Context, podName string) ([]captureFile, error) {
+	output, err := s.execPodArgs(ctx, podName, []string{"ls", "-1", "/tmp"})
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, name := range strings.Split(output, "\n") {
+		if name = strings.TrimSpace(name); captureFileRegexp.MatchString(name) || name == apiTraceFile {
+			paths = append(paths, "/tmp/"+name)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	output, err = s.execPodArgs(ctx, podName, append([]string{"stat", "-c", "%s %Y %n"}, paths...))
+	if err != nil {
+		return nil, err
+	}
+	return parseStatFiles(output), nil
+}
+
+// handleCaptureCleanup lists the capture files left in the /tmp directory of the vpp container
+// by previous captures, and deletes those older than the given age
+func (s *VPPMCPServer) handleCaptureCleanup(ctx context.Context, input VPPCaptureCleanupInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received capture cleanup request for pod: %s, older than: %d minutes, delete: %t", input.PodName, input.OlderThanMinutes, input.Delete)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+	if input.OlderThanMinutes < 0 {
+		err := fmt.Errorf("invalid older_than_minutes %d: must not be negative", input.OlderThanMinutes)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Don't delete the file of a running capture
+	if input.Delete && dryRunFromContext(ctx) == nil {
+		unlock, ok := s.captureLocks.tryLock(input.PodName)
+		if !ok {
+			err := fmt.Errorf("a capture is running on pod %s, retry once it completed", input.PodName)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		defer unlock()
+	}
+
+	files, err := s.listCaptureFiles(ctx, input.PodName)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error listing the capture files: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-time.Duration(input.OlderThanMinutes) * time.Minute)
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("VPP Capture Files of pod %s:\n\n", input.PodName))
+	var stale []string
+	var staleBytes, totalBytes int64
+	for _, file := range files {
+		totalBytes += file.Size
+		marker := "  "
+		if !file.Modified.After(cutoff) {
+			marker = "* "
+			stale = append(stale, file.Path)
+			staleBytes += file.Size
+		}
+		text.WriteString(fmt.Sprintf("%s%-40s %10s  modified %s ago\n", marker, file.Path, formatBytes(file.Size), now.Sub(file.Modified).Round(time.Second)))
+	}
+	if len(files) == 0 {
+		text.WriteString("No capture file found in /tmp\n")
+	}
+	text.WriteString(fmt.Sprintf("\nTotal: %d file(s), %s. Stale (*, older than %d minutes): %d file(s), %s\n",
+		len(files), formatBytes(totalBytes), input.OlderThanMinutes, len(stale), formatBytes(staleBytes)))
+
+	switch {
+	case len(stale) == 0:
+	case !input.Delete:
+		text.WriteString("\nCall again with delete set to remove the stale files.\n")
+	default:
+		if _, err := s.execPodArgs(ctx, input.PodName, append([]string{"rm", "-f"}, stale...)); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error deleting the stale capture files: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		text.WriteString(fmt.Sprintf("\nDeleted %d stale file(s), reclaiming %s.\n", len(stale), formatBytes(staleBytes)))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
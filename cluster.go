@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const maxClusterCaptureDuration = 5 * time.Minute
+
+// VPPClusterCaptureInput represents the input for capturing on several pods at the same time
+type VPPClusterCaptureInput struct {
+	// PodNames lists the calico-vpp pods to capture on (default: every calico-vpp pod)
+	PodNames []string `json:"pod_names,omitempty"`
+	// NodeNames selects the calico-vpp pods running on Kubernetes nodes, in addition to PodNames
+	NodeNames []string `json:"node_names,omitempty"`
+	// Type specifies the capture: trace or pcap
+	Type string `json:"type"`
+	// Interface specifies the interface type (trace) or name (pcap) to capture from
+	Interface string `json:"interface,omitempty"`
+	// Count specifies the number of packets to capture per pod
+	Count int `json:"count,omitempty"`
+	// DurationSeconds specifies how long the captures run
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pods
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// clusterCapture is the capture of one pod of a cluster capture
+type clusterCapture struct {
+	Pod    string
+	Node   string
+	Output string
+	Err    error
+}
+
+// runCapture runs a capture on a pod for the given duration and returns its output
+func (s *VPPMCPServer) runCapture(ctx context.Context, pod string, commands captureCommands, duration time.Duration) (string, error) {
+	unlock, err := s.lockCapture(ctx, pod)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	for _, command := range commands.reset {
+		_, _ = s.ExecutePodVPPCommand(ctx, pod, command)
+	}
+	defer func() {
+		for _, command := range commands.cleanup {
+			_, _ = s.ExecutePodVPPCommand(ctx, pod, command)
+		}
+	}()
+	if _, err := s.ExecutePodVPPCommand(ctx, pod, commands.start); err != nil {
+		return "", fmt.Errorf("%s failed: %v", commands.start, err)
+	}
+
+	if dryRunFromContext(ctx) == nil {
+		select {
+		case <-ctx.Done():
+			_, _ = s.ExecutePodVPPCommand(context.WithoutCancel(ctx), pod, commands.collect)
+			return "", ctx.Err()
+		case <-time.After(duration):
+		}
+	}
+
+	result, err := s.ExecutePodVPPCommand(ctx, pod, commands.collect)
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %v", commands.collect, err)
+	}
+	return result["output"].(string), nil
+}
+
+// handleClusterCapture starts the same trace or pcap on several pods for the same window, so
+// both ends of a failing flow are captured in one operation
+func (s *VPPMCPServer) handleClusterCapture(ctx context.Context, input VPPClusterCaptureInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received cluster capture request for pods: %v, type: %s", input.PodNames, input.Type)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	count := input.Count
+	if count == 0 {
+		count = 500
+	}
+	duration := defaultCaptureJobDuration
+	if input.DurationSeconds > 0 {
+		duration = time.Duration(input.DurationSeconds) * time.Second
+	}
+
+	var err error
+	switch {
+	case input.Type != captureTypeTrace && input.Type != captureTypePcap:
+		err = fmt.Errorf("invalid type '%s'. Use '%s' or '%s'", input.Type, captureTypeTrace, captureTypePcap)
+	case input.DurationSeconds < 0 || duration > maxClusterCaptureDuration:
+		err = fmt.Errorf("invalid duration_seconds %d: must be between 1 and %d", input.DurationSeconds, int(maxClusterCaptureDuration.Seconds()))
+	case count < 0:
+		err = fmt.Errorf("invalid count %d: must not be negative", count)
+	case len(input.PodNames) == 0 && s.bypassesKubernetes():
+		err = fmt.Errorf("pod_names is required when commands run without Kubernetes")
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	var k8sClient *KubeClient
+	if !s.bypassesKubernetes() {
+		k8sClient, err = newKubeClient()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
+	// Resolve the capture target, as vpp_trace and vpp_pcap do
+	target := input.Interface
+	if input.Type == captureTypePcap {
+		if target == "" {
+			target = "any"
+		} else if target != "any" {
+			err = validateInterfaceName(target)
+		}
+	} else {
+		target, _, err = mapInterfaceTypeToVppInputNode(k8sClient, input.Interface)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Resolve the pods, every calico-vpp pod by default
+	var captures []*clusterCapture
+	if k8sClient != nil {
+		pods, err := listVPPPods(ctx, k8sClient)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		podByName := make(map[string]VPPPod)
+		for _, pod := range pods {
+			podByName[pod.Name] = pod
+			if len(input.PodNames) == 0 {
+				captures = append(captures, &clusterCapture{Pod: pod.Name, Node: pod.Node})
+			}
+		}
+		for _, name := range input.PodNames {
+			pod, ok := podByName[name]
+			if !ok {
+				err := fmt.Errorf("pod %s is not a calico-vpp pod", name)
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{
+							Text: fmt.Sprintf("Error: %v", err),
+						},
+					},
+				}, nil, err
+			}
+			captures = append(captures, &clusterCapture{Pod: pod.Name, Node: pod.Node})
+		}
+	} else {
+		for _, name := range input.PodNames {
+			captures = append(captures, &clusterCapture{Pod: name})
+		}
+	}
+	if len(captures) == 0 {
+		err := fmt.Errorf("no calico-vpp pod found")
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Capture on all pods at the same time so they observe the same window
+	commands := newCaptureCommands(input.Type, target, count)
+	var wg sync.WaitGroup
+	for _, capture := range captures {
+		wg.Add(1)
+		go func(capture *clusterCapture) {
+			defer wg.Done()
+			capture.Output, capture.Err = s.runCapture(ctx, capture.Pod, commands, duration)
+		}(capture)
+	}
+	wg.Wait()
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Cluster %s:\n\n", commands.title))
+	failed := 0
+	for _, capture := range captures {
+		if capture.Node != "" {
+			text.WriteString(fmt.Sprintf("=== Pod %s (node %s) ===\n", capture.Pod, capture.Node))
+		} else {
+			text.WriteString(fmt.Sprintf("=== Pod %s ===\n", capture.Pod))
+		}
+		if capture.Err != nil {
+			failed++
+			text.WriteString(fmt.Sprintf("Capture failed: %v\n\n", capture.Err))
+			continue
+		}
+		text.WriteString(capture.Output + "\n\n")
+	}
+	text.WriteString(fmt.Sprintf("Capture Parameters:\n- Pods: %d (%d failed)\n- %s\n- Count: %d per pod\n- Capture Duration: %s\n\n**Important**: %s, in every pod\n",
+		len(captures), failed, commands.target, count, duration, commands.note))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
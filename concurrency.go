@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// defaultMaxConcurrentExecs bounds the commands running at the same time across all tool calls
+const defaultMaxConcurrentExecs = 16
+
+// podLocks serializes captures per pod. Traces, pcaps and dispatch traces share global
+// VPP state, so two captures on the same pod would clobber each other.
+type podLocks struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+// newPodLocks creates an empty set of per-pod locks
+func newPodLocks() *podLocks {
+	return &podLocks{locks: make(map[string]chan struct{})}
+}
+
+// lock waits until the pod is free or the context is cancelled. The returned function
+// releases the pod.
+func (p *podLocks) lock(ctx context.Context, pod string) (func(), error) {
+	p.mu.Lock()
+	lock, ok := p.locks[pod]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		p.locks[pod] = lock
+	}
+	p.mu.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for the running capture on pod %s to complete: %v", pod, ctx.Err())
+	}
+}
+
+// tryLock takes the pod if it is free, without waiting. The returned function releases the pod.
+func (p *podLocks) tryLock(pod string) (func(), bool) {
+	p.mu.Lock()
+	lock, ok := p.locks[pod]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		p.locks[pod] = lock
+	}
+	p.mu.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, true
+	default:
+		return nil, false
+	}
+}
+
+// acquireExecSlot waits for one of the exec slots bounding concurrent commands. The returned
+// function frees the slot. Without a bound, it returns immediately.
+func (s *VPPMCPServer) acquireExecSlot(ctx context.Context) (func(), error) {
+	if s.execSlots == nil {
+		return func() {}, nil
+	}
+	select {
+	case s.execSlots <- struct{}{}:
+		return func() { <-s.execSlots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for a free exec slot (%d commands already running): %v", cap(s.execSlots), ctx.Err())
+	}
+}
+
+// lockCapture serializes the captures on a pod. Dry runs don't touch the pod and skip the lock.
+func (s *VPPMCPServer) lockCapture(ctx context.Context, pod string) (func(), error) {
+	if dryRunFromContext(ctx) != nil {
+		return func() {}, nil
+	}
+	return s.captureLocks.lock(ctx, pod)
+}
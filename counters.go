@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// VPPErrorsInput represents the input for the error counter tool
+type VPPErrorsInput struct {
+	VPPCommandInput
+	// Sort lists the counters by count, highest first
+	Sort bool `json:"sort,omitempty"`
+	// MinCount hides the counters below this count
+	MinCount int `json:"min_count,omitempty"`
+}
+
+// sortErrorCounters orders the counter lines of 'show errors' output by count, highest first,
+// and drops those below minCount. Lines that don't start with a count, such as the column
+// header, are kept on top.
+func sortErrorCounters(output string, sortByCount bool, minCount int) string {
+	type counterLine struct {
+		count uint64
+		line  string
+	}
+
+	var header []string
+	var counters []counterLine
+	hidden := 0
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		count, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			header = append(header, line)
+			continue
+		}
+		if count < uint64(minCount) {
+			hidden++
+			continue
+		}
+		counters = append(counters, counterLine{count: count, line: line})
+	}
+
+	if sortByCount {
+		sort.SliceStable(counters, func(i, j int) bool { return counters[i].count > counters[j].count })
+	}
+
+	lines := header
+	for _, counter := range counters {
+		lines = append(lines, counter.line)
+	}
+	if hidden > 0 {
+		lines = append(lines, fmt.Sprintf("(%d counter(s) below %d hidden)", hidden, minCount))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleShowErrors shows the error counters, optionally sorted and thresholded
+func (s *VPPMCPServer) handleShowErrors(ctx context.Context, input VPPErrorsInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received error counters request for pod: %s, sort: %t, min_count: %d", input.PodName, input.Sort, input.MinCount)
+
+	if input.MinCount < 0 {
+		err := fmt.Errorf("invalid min_count %d: must not be negative", input.MinCount)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	return s.handleVPPCommandWithTransform(ctx, input.VPPCommandInput, "show errors", "VPP Error Counters", func(output string) string {
+		if !input.Sort && input.MinCount == 0 {
+			return output
+		}
+		return sortErrorCounters(output, input.Sort, input.MinCount)
+	})
+}
+
+// runtimeThreadRegexp matches the header of a thread in 'show run' output, e.g. "Thread 1 vpp_wk_0 (lcore 2)"
+var runtimeThreadRegexp = regexp.MustCompile(`^Thread (\d+) `)
+
+// VPPRuntimeInput represents the input for the runtime statistics tool
+type VPPRuntimeInput struct {
+	VPPCommandInput
+	// Thread restricts the output to one thread (0 is the main thread, 1 the first worker)
+	Thread *int `json:"thread,omitempty"`
+	// MaxNodes keeps only the busiest nodes of every thread
+	MaxNodes int `json:"max_nodes,omitempty"`
+}
+
+// runtimeNodeVectors returns the vector count of a node line of 'show run' output, whose last
+// five columns are Calls, Vectors, Suspends, Clocks and Vectors/Call
+func runtimeNodeVectors(line string) (float64, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 7 {
+		return 0, false
+	}
+	for _, field := range fields[len(fields)-5:] {
+		if _, err := strconv.ParseFloat(field, 64); err != nil {
+			return 0, false
+		}
+	}
+	vectors, _ := strconv.ParseFloat(fields[len(fields)-4], 64)
+	return vectors, true
+}
+
+// filterRuntime keeps the threads of 'show run' output matching thread (nil keeps all) and,
+// when maxNodes is set, the maxNodes nodes of every thread that processed the most vectors
+func filterRuntime(output string, thread *int, maxNodes int) string {
+	type nodeLine struct {
+		vectors float64
+		line    string
+	}
+
+	var lines []string
+	var nodes []nodeLine
+	nodesAt := -1
+	keep, found := thread == nil, false
+	flushNodes := func() {
+		if nodesAt < 0 {
+			return
+		}
+		sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].vectors > nodes[j].vectors })
+		if len(nodes) > maxNodes {
+			nodes = nodes[:maxNodes]
+		}
+		var kept []string
+		for _, node := range nodes {
+			kept = append(kept, node.line)
+		}
+		lines = append(lines[:nodesAt], append(kept, lines[nodesAt:]...)...)
+		nodes, nodesAt = nil, -1
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if match := runtimeThreadRegexp.FindStringSubmatch(line); match != nil {
+			flushNodes()
+			index, _ := strconv.Atoi(match[1])
+			keep = thread == nil || index == *thread
+			found = found || keep
+		}
+		if !keep {
+			continue
+		}
+		if vectors, ok := runtimeNodeVectors(line); ok && maxNodes > 0 {
+			if nodesAt < 0 {
+				nodesAt = len(lines)
+			}
+			nodes = append(nodes, nodeLine{vectors: vectors, line: line})
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flushNodes()
+
+	if thread != nil && !found {
+		return fmt.Sprintf("(thread %d not found in the runtime statistics)", *thread)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleShowRun shows the runtime statistics, optionally restricted to a thread and its busiest nodes
+func (s *VPPMCPServer) handleShowRun(ctx context.Context, input VPPRuntimeInput) (*mcp.CallToolResult, any, error) {
+	var err error
+	if input.Thread != nil && *input.Thread < 0 {
+		err = fmt.Errorf("invalid thread %d: must not be negative", *input.Thread)
+	} else if input.MaxNodes < 0 {
+		err = fmt.Errorf("invalid max_nodes %d: must not be negative", input.MaxNodes)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	return s.handleVPPCommandWithTransform(ctx, input.VPPCommandInput, "show run", "VPP Runtime Statistics", func(output string) string {
+		if input.Thread == nil && input.MaxNodes == 0 {
+			return output
+		}
+		return filterRuntime(output, input.Thread, input.MaxNodes)
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestSortErrorCounters(t *testing.T) {
+	output := `   Count                  Node                              Reason               Severity
+         5             ip4-input               ip4 ttl <= 1                          error
+       120            ip4-glean               ARP requests throttled                 info
+         1          ethernet-input            no error                               info
+        42           arp-reply                ARP replies sent                       info
+`
+
+	tests := []struct {
+		name        string
+		sortByCount bool
+		minCount    int
+		want        string
+	}{
+		{
+			name: "keeps the order without sort",
+			want: `   Count                  Node                              Reason               Severity
+         5             ip4-input               ip4 ttl <= 1                          error
+       120            ip4-glean               ARP requests throttled                 info
+         1          ethernet-input            no error                               info
+        42           arp-reply                ARP replies sent                       info`,
+		},
+		{
+			name:        "sorts by count with the header on top",
+			sortByCount: true,
+			want: `   Count                  Node                              Reason               Severity
+       120            ip4-glean               ARP requests throttled                 info
+        42           arp-reply                ARP replies sent                       info
+         5             ip4-input               ip4 ttl <= 1                          error
+         1          ethernet-input            no error                               info`,
+		},
+		{
+			name:        "hides the counters below min_count",
+			sortByCount: true,
+			minCount:    10,
+			want: `   Count                  Node                              Reason               Severity
+       120            ip4-glean               ARP requests throttled                 info
+        42           arp-reply                ARP replies sent                       info
+(2 counter(s) below 10 hidden)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sortErrorCounters(output, tt.sortByCount, tt.minCount); got != tt.want {
+				t.Errorf("sortErrorCounters() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
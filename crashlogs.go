@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultCrashLogTail bounds the log lines fetched from the previous instance of each container
+const defaultCrashLogTail = 300
+
+// crashSignals names the signals commonly ending a crashed container
+var crashSignals = map[int32]string{
+	6:  "SIGABRT",
+	7:  "SIGBUS",
+	9:  "SIGKILL",
+	11: "SIGSEGV",
+	15: "SIGTERM",
+}
+
+// CrashLogsInput represents the input for retrieving the logs of crashed containers
+type CrashLogsInput struct {
+	// PodName specifies the name of the Kubernetes calico-vpp pod
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// Tail keeps the last lines of the logs of each previous container instance
+	Tail int `json:"tail,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each log request
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// containerCrash is the last termination of a restarted container and the logs of its previous instance
+type containerCrash struct {
+	Container    string `json:"container"`
+	RestartCount int32  `json:"restart_count"`
+	Reason       string `json:"reason,omitempty"`
+	ExitCode     int32  `json:"exit_code"`
+	Signal       string `json:"signal,omitempty"`
+	Message      string `json:"message,omitempty"`
+	StartedAt    string `json:"started_at,omitempty"`
+	FinishedAt   string `json:"finished_at,omitempty"`
+	Logs         string `json:"logs,omitempty"`
+	LogsError    string `json:"logs_error,omitempty"`
+}
+
+// describeExitCode names the signal of an exit code above 128, e.g. "SIGSEGV" for 139
+func describeExitCode(exitCode, signal int32) string {
+	if signal == 0 && exitCode > 128 {
+		signal = exitCode - 128
+	}
+	if signal == 0 {
+		return ""
+	}
+	if name, ok := crashSignals[signal]; ok {
+		return name
+	}
+	return fmt.Sprintf("signal %d", signal)
+}
+
+// formatKubeTime formats a Kubernetes timestamp, or returns "" for an unset one
+func formatKubeTime(t metav1.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// handleGetCrashLogs reports the last termination of the vpp and agent containers of a pod and
+// fetches the logs of their previous instance with 'kubectl logs --previous' when they
+// restarted, so crashes can be investigated after Kubernetes restarted the container
+func (s *VPPMCPServer) handleGetCrashLogs(ctx context.Context, input CrashLogsInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received crash logs request for pod: %s, tail: %d", input.PodName, input.Tail)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: Pod name is required. Please specify the Kubernetes pod name or node name.",
+				},
+			},
+		}, nil, fmt.Errorf("pod name is required")
+	}
+
+	tail := input.Tail
+	if tail == 0 {
+		tail = defaultCrashLogTail
+	}
+	err := validatePodName(input.PodName)
+	switch {
+	case err != nil:
+	case input.Tail < 0 || input.Tail > maxContainerLogTail:
+		err = fmt.Errorf("invalid tail %d: must be between 1 and %d", input.Tail, maxContainerLogTail)
+	case s.bypassesKubernetes():
+		err = fmt.Errorf("container restarts and logs are only available through Kubernetes, not with the %s backend", backendLocal)
+	}
+	var k8sClient *KubeClient
+	if err == nil {
+		if k8sClient, err = newKubeClient(); err != nil {
+			err = fmt.Errorf("failed to create Kubernetes client: %v", err)
+		}
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 1: Read the restart count and last termination of the containers
+	getCtx, cancel := context.WithTimeout(ctx, k8sClient.timeout)
+	pod, err := k8sClient.CoreV1().Pods(vppNamespace).Get(getCtx, input.PodName, metav1.GetOptions{})
+	cancel()
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error validating pod: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 2: Fetch the logs of the previous instance of the restarted containers
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Crash Logs of pod %s (node %s):\n\n", input.PodName, pod.Spec.NodeName))
+	var crashes []containerCrash
+	for _, container := range []string{vppContainerName, agentContainerName} {
+		var found bool
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != container {
+				continue
+			}
+			found = true
+			terminated := status.LastTerminationState.Terminated
+			if status.RestartCount == 0 || terminated == nil {
+				text.WriteString(fmt.Sprintf("Container %s: no restart\n\n", container))
+				break
+			}
+
+			crash := containerCrash{
+				Container:    container,
+				RestartCount: status.RestartCount,
+				Reason:       terminated.Reason,
+				ExitCode:     terminated.ExitCode,
+				Signal:       describeExitCode(terminated.ExitCode, terminated.Signal),
+				Message:      strings.TrimSpace(terminated.Message),
+				StartedAt:    formatKubeTime(terminated.StartedAt),
+				FinishedAt:   formatKubeTime(terminated.FinishedAt),
+			}
+			logs, err := s.fetchContainerLogs(ctx, input.PodName, container, logOptions{Tail: tail, Previous: true})
+			if err != nil {
+				crash.LogsError = err.Error()
+			}
+			crash.Logs = logs
+			crashes = append(crashes, crash)
+
+			line := fmt.Sprintf("Container %s: %d restart(s), last terminated", container, crash.RestartCount)
+			if crash.Reason != "" {
+				line += " (" + crash.Reason + ")"
+			}
+			line += fmt.Sprintf(" with exit code %d", crash.ExitCode)
+			if crash.Signal != "" {
+				line += " [" + crash.Signal + "]"
+			}
+			if crash.FinishedAt != "" {
+				line += " at " + crash.FinishedAt
+			}
+			text.WriteString(line + "\n")
+			if crash.Message != "" {
+				text.WriteString(fmt.Sprintf("Termination message: %s\n", crash.Message))
+			}
+			switch {
+			case dryRunFromContext(ctx) != nil:
+				text.WriteString("The logs of the previous instance would be fetched\n\n")
+			case crash.LogsError != "":
+				text.WriteString(fmt.Sprintf("Logs of the previous instance unavailable: %s\n\n", crash.LogsError))
+			case crash.Logs == "":
+				text.WriteString("The previous instance logged nothing\n\n")
+			default:
+				text.WriteString(fmt.Sprintf("Last %d log lines of the previous instance:\n%s\n\n", tail, crash.Logs))
+			}
+			break
+		}
+		if !found {
+			text.WriteString(fmt.Sprintf("Container %s: not found in the pod status\n\n", container))
+		}
+	}
+	if len(crashes) == 0 {
+		text.WriteString("No container of the pod restarted: there is no previous instance to investigate.\n")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"pod":     input.PodName,
+			"node":    pod.Spec.NodeName,
+			"crashes": crashes,
+		},
+	}, nil, nil
+}
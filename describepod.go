@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultDescribePodEvents bounds the events reported for a pod, most recent first
+const defaultDescribePodEvents = 20
+
+// DescribePodInput represents the input for describing a calico-vpp pod
+type DescribePodInput struct {
+	// PodName specifies the name of the Kubernetes calico-vpp pod
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// Events keeps the most recent events of the pod
+	Events int `json:"events,omitempty"`
+}
+
+// podConditionDescription is one condition of a pod, e.g. Ready or ContainersReady
+type podConditionDescription struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"last_transition_time,omitempty"`
+}
+
+// containerDescription is the status and the resources of one container of a pod
+type containerDescription struct {
+	Name         string `json:"name"`
+	Init         bool   `json:"init,omitempty"`
+	Image        string `json:"image,omitempty"`
+	State        string `json:"state"`
+	StateReason  string `json:"state_reason,omitempty"`
+	StateSince   string `json:"state_since,omitempty"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restart_count"`
+	// LastTermination describes the previous instance of a restarted container
+	LastTermination string            `json:"last_termination,omitempty"`
+	Requests        map[string]string `json:"requests,omitempty"`
+	Limits          map[string]string `json:"limits,omitempty"`
+}
+
+// podEventDescription is one Kubernetes event involving a pod
+type podEventDescription struct {
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Source  string `json:"source,omitempty"`
+	Count   int32  `json:"count,omitempty"`
+	Last    string `json:"last,omitempty"`
+	last    time.Time
+}
+
+// podDescription is the Kubernetes API view of a calico-vpp pod
+type podDescription struct {
+	Name       string                    `json:"name"`
+	Node       string                    `json:"node"`
+	Phase      string                    `json:"phase"`
+	Reason     string                    `json:"reason,omitempty"`
+	Message    string                    `json:"message,omitempty"`
+	PodIP      string                    `json:"pod_ip,omitempty"`
+	HostIP     string                    `json:"host_ip,omitempty"`
+	QOSClass   string                    `json:"qos_class,omitempty"`
+	StartTime  string                    `json:"start_time,omitempty"`
+	Conditions []podConditionDescription `json:"conditions"`
+	Containers []containerDescription    `json:"containers"`
+	Events     []podEventDescription     `json:"events"`
+}
+
+// describeResourceList renders the quantities of a resource list, e.g. {"cpu": "500m", "memory": "2Gi"}
+func describeResourceList(resources corev1.ResourceList) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	described := make(map[string]string, len(resources))
+	for name, quantity := range resources {
+		described[string(name)] = quantity.String()
+	}
+	return described
+}
+
+// describeContainerState returns the state of a container, its reason and the time it was entered
+func describeContainerState(state corev1.ContainerState) (string, string, string) {
+	switch {
+	case state.Running != nil:
+		return "running", "", formatKubeTime(state.Running.StartedAt)
+	case state.Waiting != nil:
+		return "waiting", state.Waiting.Reason, ""
+	case state.Terminated != nil:
+		return "terminated", state.Terminated.Reason, formatKubeTime(state.Terminated.FinishedAt)
+	}
+	return "unknown", "", ""
+}
+
+// describeTermination summarizes a container termination, e.g. "Error, exit code 139 [SIGSEGV] at 2024-01-01T00:00:00Z"
+func describeTermination(terminated *corev1.ContainerStateTerminated) string {
+	if terminated == nil {
+		return ""
+	}
+	var parts []string
+	if terminated.Reason != "" {
+		parts = append(parts, terminated.Reason)
+	}
+	exit := fmt.Sprintf("exit code %d", terminated.ExitCode)
+	if signal := describeExitCode(terminated.ExitCode, terminated.Signal); signal != "" {
+		exit += " [" + signal + "]"
+	}
+	if finished := formatKubeTime(terminated.FinishedAt); finished != "" {
+		exit += " at " + finished
+	}
+	return strings.Join(append(parts, exit), ", ")
+}
+
+// describeContainers merges the spec and the status of the containers of a pod, init containers first
+func describeContainers(pod *corev1.Pod) []containerDescription {
+	specs := make(map[string]corev1.Container, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		specs[container.Name] = container
+	}
+	var containers []containerDescription
+	add := func(status corev1.ContainerStatus, init bool) {
+		container := containerDescription{
+			Name:            status.Name,
+			Init:            init,
+			Image:           status.Image,
+			Ready:           status.Ready,
+			RestartCount:    status.RestartCount,
+			LastTermination: describeTermination(status.LastTerminationState.Terminated),
+		}
+		container.State, container.StateReason, container.StateSince = describeContainerState(status.State)
+		if spec, ok := specs[status.Name]; ok {
+			container.Requests = describeResourceList(spec.Resources.Requests)
+			container.Limits = describeResourceList(spec.Resources.Limits)
+		}
+		containers = append(containers, container)
+	}
+	for _, status := range pod.Status.InitContainerStatuses {
+		add(status, true)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		add(status, false)
+	}
+	return containers
+}
+
+// describePodEvents returns the most recent events of a pod first, keeping at most limit events
+func describePodEvents(events []corev1.Event, limit int) []podEventDescription {
+	described := make([]podEventDescription, 0, len(events))
+	for _, event := range events {
+		// Events recorded through the events.k8s.io API only set the event time
+		last := event.LastTimestamp.Time
+		if last.IsZero() {
+			last = event.EventTime.Time
+		}
+		if last.IsZero() {
+			last = event.FirstTimestamp.Time
+		}
+		source := event.Source.Component
+		if source == "" {
+			source = event.ReportingController
+		}
+		description := podEventDescription{
+			Type:    event.Type,
+			Reason:  event.Reason,
+			Message: strings.TrimSpace(event.Message),
+			Source:  source,
+			Count:   event.Count,
+			last:    last,
+		}
+		if !last.IsZero() {
+			description.Last = last.UTC().Format(time.RFC3339)
+		}
+		described = append(described, description)
+	}
+	sort.SliceStable(described, func(i, j int) bool {
+		return described[i].last.After(described[j].last)
+	})
+	if len(described) > limit {
+		described = described[:limit]
+	}
+	return described
+}
+
+// handleDescribePod reads a calico-vpp pod and its events from the Kubernetes API, and reports
+// the pod conditions, the container statuses, restart counts and resources, and the recent events
+func (s *VPPMCPServer) handleDescribePod(ctx context.Context, input DescribePodInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received describe pod request for pod: %s", input.PodName)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: Pod name is required. Please specify the Kubernetes pod name or node name.",
+				},
+			},
+		}, nil, fmt.Errorf("pod name is required")
+	}
+
+	limit := input.Events
+	if limit == 0 {
+		limit = defaultDescribePodEvents
+	}
+	err := validatePodName(input.PodName)
+	switch {
+	case err != nil:
+	case input.Events < 0:
+		err = fmt.Errorf("invalid events %d: must be positive", input.Events)
+	case s.bypassesKubernetes():
+		err = fmt.Errorf("describing a pod requires the Kubernetes API, not available with the %s backend", backendLocal)
+	}
+	var k8sClient *KubeClient
+	if err == nil {
+		if k8sClient, err = newKubeClient(); err != nil {
+			err = fmt.Errorf("failed to create Kubernetes client: %v", err)
+		}
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 1: Read the pod and the events involving it
+	apiCtx, cancel := context.WithTimeout(ctx, k8sClient.timeout)
+	defer cancel()
+	pod, err := k8sClient.CoreV1().Pods(vppNamespace).Get(apiCtx, input.PodName, metav1.GetOptions{})
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error validating pod: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	var eventsError string
+	events, err := k8sClient.CoreV1().Events(vppNamespace).List(apiCtx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod,involvedObject.name=" + input.PodName,
+	})
+	if err != nil {
+		eventsError = fmt.Sprintf("failed to list the events of pod %s: %v", input.PodName, err)
+		events = &corev1.EventList{}
+	}
+
+	// Step 2: Describe the pod
+	description := podDescription{
+		Name:       pod.Name,
+		Node:       pod.Spec.NodeName,
+		Phase:      string(pod.Status.Phase),
+		Reason:     pod.Status.Reason,
+		Message:    pod.Status.Message,
+		PodIP:      pod.Status.PodIP,
+		HostIP:     pod.Status.HostIP,
+		QOSClass:   string(pod.Status.QOSClass),
+		Conditions: []podConditionDescription{},
+		Containers: describeContainers(pod),
+		Events:     describePodEvents(events.Items, limit),
+	}
+	if pod.Status.StartTime != nil {
+		description.StartTime = formatKubeTime(*pod.Status.StartTime)
+	}
+	for _, condition := range pod.Status.Conditions {
+		description.Conditions = append(description.Conditions, podConditionDescription{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: formatKubeTime(condition.LastTransitionTime),
+		})
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Pod %s (node %s):\n", description.Name, description.Node))
+	status := description.Phase
+	if description.Reason != "" {
+		status += fmt.Sprintf(" (%s)", description.Reason)
+	}
+	text.WriteString(fmt.Sprintf("  Status: %s\n", status))
+	if description.Message != "" {
+		text.WriteString(fmt.Sprintf("  Message: %s\n", description.Message))
+	}
+	if description.StartTime != "" {
+		text.WriteString(fmt.Sprintf("  Started: %s\n", description.StartTime))
+	}
+	text.WriteString(fmt.Sprintf("  IP: %s, host IP: %s, QoS class: %s\n\n", description.PodIP, description.HostIP, description.QOSClass))
+
+	text.WriteString("Conditions:\n")
+	for _, condition := range description.Conditions {
+		line := fmt.Sprintf("  %s=%s", condition.Type, condition.Status)
+		if condition.Reason != "" {
+			line += fmt.Sprintf(" (%s)", condition.Reason)
+		}
+		if condition.Message != "" {
+			line += ": " + condition.Message
+		}
+		text.WriteString(line + "\n")
+	}
+
+	text.WriteString("\nContainers:\n")
+	for _, container := range description.Containers {
+		name := container.Name
+		if container.Init {
+			name += " [init]"
+		}
+		state := container.State
+		if container.StateReason != "" {
+			state += fmt.Sprintf(" (%s)", container.StateReason)
+		}
+		if container.StateSince != "" {
+			state += " since " + container.StateSince
+		}
+		text.WriteString(fmt.Sprintf("  %s: %s, ready=%t, restarts=%d\n", name, state, container.Ready, container.RestartCount))
+		if container.Image != "" {
+			text.WriteString(fmt.Sprintf("    Image: %s\n", container.Image))
+		}
+		if container.LastTermination != "" {
+			text.WriteString(fmt.Sprintf("    Last termination: %s\n", container.LastTermination))
+		}
+		for _, resources := range []struct {
+			label      string
+			quantities map[string]string
+		}{{"Requests", container.Requests}, {"Limits", container.Limits}} {
+			if len(resources.quantities) == 0 {
+				continue
+			}
+			names := make([]string, 0, len(resources.quantities))
+			for name := range resources.quantities {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for i, name := range names {
+				names[i] = name + "=" + resources.quantities[name]
+			}
+			text.WriteString(fmt.Sprintf("    %s: %s\n", resources.label, strings.Join(names, ", ")))
+		}
+	}
+
+	text.WriteString("\nRecent events:\n")
+	switch {
+	case eventsError != "":
+		text.WriteString(fmt.Sprintf("  %s\n", eventsError))
+	case len(description.Events) == 0:
+		text.WriteString("  none (Kubernetes only keeps events for one hour by default)\n")
+	}
+	for _, event := range description.Events {
+		line := fmt.Sprintf("  %s %s %s", event.Last, event.Type, event.Reason)
+		if event.Count > 1 {
+			line += fmt.Sprintf(" (x%d)", event.Count)
+		}
+		if event.Source != "" {
+			line += " from " + event.Source
+		}
+		text.WriteString(fmt.Sprintf("%s: %s\n", line, event.Message))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: description,
+	}, nil, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// dryRunParameterLine documents the dry_run parameter of tools running commands
+const dryRunParameterLine = "- dry_run: Return the exact commands the call would execute without running them (default: false)"
+
+// dryRunKey is the context key of the recorder of a dry-run tool call
+type dryRunKey struct{}
+
+// dryRunRecorder collects the commands a dry-run tool call would have executed
+type dryRunRecorder struct {
+	mu       sync.Mutex
+	commands []string
+}
+
+// record appends a command line to the recorder
+func (r *dryRunRecorder) record(commandLine string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, commandLine)
+}
+
+// dryRunFromContext returns the recorder of a dry-run tool call, or nil when commands must run
+func dryRunFromContext(ctx context.Context) *dryRunRecorder {
+	recorder, _ := ctx.Value(dryRunKey{}).(*dryRunRecorder)
+	return recorder
+}
+
+// newDryRunMiddleware returns a middleware turning tool calls with dry_run set, or every
+// tool call when always is true, into dry runs. The commands recorded by the executors
+// replace the result of the call.
+func newDryRunMiddleware(always bool) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+			if method != "tools/call" || !ok {
+				return next(ctx, method, req)
+			}
+
+			var target struct {
+				DryRun bool `json:"dry_run"`
+			}
+			// Malformed arguments are reported by the tool handler itself
+			_ = json.Unmarshal(params.Arguments, &target)
+			if !always && !target.DryRun {
+				return next(ctx, method, req)
+			}
+
+			recorder := &dryRunRecorder{}
+			result, err := next(context.WithValue(ctx, dryRunKey{}, recorder), method, req)
+			toolResult, ok := result.(*mcp.CallToolResult)
+			if err != nil || !ok {
+				return result, err
+			}
+
+			// Calls rejected before reaching an executor keep their own result
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			if len(recorder.commands) == 0 {
+				return toolResult, nil
+			}
+
+			var text strings.Builder
+			text.WriteString(fmt.Sprintf("Dry run of %s: no command was executed. The call would run:\n\n", params.Name))
+			for i, command := range recorder.commands {
+				text.WriteString(fmt.Sprintf("%d. %s\n", i+1, command))
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: text.String(),
+					},
+				},
+			}, nil
+		}
+	}
+}
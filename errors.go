@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Categories of tool failures surfaced in structured content
+const (
+	errorCategoryPodNotFound      = "pod-not-found"
+	errorCategoryVPPRestarting    = "vpp-restarting"
+	errorCategoryPermissionDenied = "permission-denied"
+	errorCategoryTimeout          = "timeout"
+	errorCategoryParseError       = "parse-error"
+	errorCategoryInvalidArgument  = "invalid-argument"
+	errorCategoryQuotaExceeded    = "quota-exceeded"
+	errorCategoryUnknown          = "unknown"
+)
+
+// Codes of tool failures surfaced in structured content, stable for automated triage.
+// Categories refine codes: each category maps to one code, except the failures of a command
+// (vpp-restarting, parse-error, unknown), which keep the code of the command that failed.
+const (
+	errorCodePodNotFound     = "POD_NOT_FOUND"
+	errorCodeExecTimeout     = "EXEC_TIMEOUT"
+	errorCodeVPPCtlError     = "VPPCTL_ERROR"
+	errorCodeGoBGPError      = "GOBGP_ERROR"
+	errorCodeKubeAPIError    = "KUBE_API_ERROR"
+	errorCodeInvalidArgument = "INVALID_ARGUMENT"
+	errorCodeQuotaExceeded   = "QUOTA_EXCEEDED"
+	errorCodeInternal        = "INTERNAL_ERROR"
+)
+
+// attemptedCommandRegexp extracts the command reported by failed results that didn't come
+// from an exec, e.g. kubectl logs
+var attemptedCommandRegexp = regexp.MustCompile(`(?m)^Command attempted: (.+)$`)
+
+// invalidArgumentRegexp matches the argument validation errors of the tools, e.g.
+// invalid port 0: ... or invalid interface name "x", and not VPP output such as "invalid interface"
+var invalidArgumentRegexp = regexp.MustCompile(`(?i)\binvalid [a-z_ ]*[a-z_](:| ['"0-9-])`)
+
+// categoryCodes maps the categories that imply a code to it
+var categoryCodes = map[string]string{
+	errorCategoryPodNotFound:      errorCodePodNotFound,
+	errorCategoryTimeout:          errorCodeExecTimeout,
+	errorCategoryPermissionDenied: errorCodeKubeAPIError,
+	errorCategoryInvalidArgument:  errorCodeInvalidArgument,
+	errorCategoryQuotaExceeded:    errorCodeQuotaExceeded,
+}
+
+// errorCategoryPatterns maps lowercase message fragments to error categories.
+// The first matching entry wins, so more specific fragments come first.
+var errorCategoryPatterns = []struct {
+	fragment string
+	category string
+}{
+	{"invalid timeout_seconds", errorCategoryInvalidArgument},
+	{"deadline exceeded", errorCategoryTimeout},
+	{"timed out", errorCategoryTimeout},
+	{"timeout", errorCategoryTimeout},
+	{"signal: killed", errorCategoryTimeout},
+	{"forbidden", errorCategoryPermissionDenied},
+	{"unauthorized", errorCategoryPermissionDenied},
+	{"permission denied", errorCategoryPermissionDenied},
+	{"quota exceeded", errorCategoryQuotaExceeded},
+	{"cooldown", errorCategoryQuotaExceeded},
+	{"already running", errorCategoryQuotaExceeded},
+	{"container not found", errorCategoryVPPRestarting},
+	{"is not running", errorCategoryVPPRestarting},
+	{"containercreating", errorCategoryVPPRestarting},
+	{"cli.sock", errorCategoryVPPRestarting},
+	{"connection refused", errorCategoryVPPRestarting},
+	{"(notfound)", errorCategoryPodNotFound},
+	{"pods \"", errorCategoryPodNotFound},
+	{"no calico-vpp pod", errorCategoryPodNotFound},
+	{"not a calico-vpp pod", errorCategoryPodNotFound},
+	{"unknown input", errorCategoryParseError},
+	{"parse error", errorCategoryParseError},
+	{"failed to parse", errorCategoryParseError},
+	{"is required", errorCategoryInvalidArgument},
+	{"not allowed", errorCategoryInvalidArgument},
+	{"must be between", errorCategoryInvalidArgument},
+	{"must not be", errorCategoryInvalidArgument},
+}
+
+// ToolError is the structured content of a failed tool call
+type ToolError struct {
+	Code     string `json:"code"`
+	Category string `json:"category"`
+	Message  string `json:"message"`
+	// Command is the vppctl, gobgp or kubectl command that failed, when one was run
+	Command string `json:"command,omitempty"`
+}
+
+// ExecError is the failure of a vppctl or gobgp command run in a pod
+type ExecError struct {
+	// Code is errorCodeVPPCtlError, errorCodeGoBGPError or errorCodeExecTimeout
+	Code string
+	// Command is the command that failed, e.g. "vppctl show int"
+	Command string
+	Err     error
+}
+
+func (e *ExecError) Error() string { return e.Err.Error() }
+
+func (e *ExecError) Unwrap() error { return e.Err }
+
+// execFailuresKey is the context key of the exec failures of a tool call
+type execFailuresKey struct{}
+
+// execFailures collects the exec failures of a tool call. The SDK turns the error returned
+// by a handler into text, so the structured error middleware finds the failure back here.
+type execFailures struct {
+	mu       sync.Mutex
+	failures []*ExecError
+}
+
+// matching returns the latest failure whose error is part of message
+func (f *execFailures) matching(message string) *ExecError {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.failures) - 1; i >= 0; i-- {
+		if text := f.failures[i].Error(); text != "" && strings.Contains(message, text) {
+			return f.failures[i]
+		}
+	}
+	return nil
+}
+
+// newExecError types the failure of a command run with cmdCtx, and records it for the
+// structured error of the tool call
+func newExecError(ctx, cmdCtx context.Context, code, command string, err error) *ExecError {
+	if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+		code = errorCodeExecTimeout
+	}
+	execErr := &ExecError{Code: code, Command: command, Err: err}
+	if failures, ok := ctx.Value(execFailuresKey{}).(*execFailures); ok {
+		failures.mu.Lock()
+		failures.failures = append(failures.failures, execErr)
+		failures.mu.Unlock()
+	}
+	return execErr
+}
+
+// classifyToolError returns the category of a tool failure message. Messages carrying
+// the output of a failed command are never invalid-argument: arguments are validated
+// before anything runs.
+func classifyToolError(message string, failure *ExecError) string {
+	lower := strings.ToLower(message)
+	for _, pattern := range errorCategoryPatterns {
+		if strings.Contains(lower, pattern.fragment) && (failure == nil || pattern.category != errorCategoryInvalidArgument) {
+			return pattern.category
+		}
+	}
+	if failure == nil && invalidArgumentRegexp.MatchString(message) {
+		return errorCategoryInvalidArgument
+	}
+	return errorCategoryUnknown
+}
+
+// attemptedCommand returns the command reported in a failure message, if any
+func attemptedCommand(message string) string {
+	if match := attemptedCommandRegexp.FindStringSubmatch(message); match != nil {
+		return strings.TrimSpace(match[1])
+	}
+	return ""
+}
+
+// newToolError builds the structured error of a failure message and of the failed
+// command it reports, if any
+func newToolError(message string, failure *ExecError) ToolError {
+	toolErr := ToolError{
+		Category: classifyToolError(message, failure),
+		Message:  message,
+	}
+	if failure != nil {
+		toolErr.Command = failure.Command
+		if failure.Code == errorCodeExecTimeout {
+			toolErr.Category = errorCategoryTimeout
+		}
+	} else {
+		toolErr.Command = attemptedCommand(message)
+	}
+
+	if code, ok := categoryCodes[toolErr.Category]; ok {
+		toolErr.Code = code
+		return toolErr
+	}
+	lower := strings.ToLower(message)
+	switch {
+	case failure != nil:
+		toolErr.Code = failure.Code
+	case strings.Contains(lower, "kubernetes") || strings.Contains(lower, "kubectl") || strings.Contains(lower, "validating pod"):
+		toolErr.Code = errorCodeKubeAPIError
+	default:
+		toolErr.Code = errorCodeInternal
+	}
+	return toolErr
+}
+
+// structuredErrorMiddleware marks failed tool calls as errors and attaches their
+// code and category in structured content, so clients can branch on the error type
+func structuredErrorMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		failures := &execFailures{}
+		result, err := next(context.WithValue(ctx, execFailuresKey{}, failures), method, req)
+		if method != "tools/call" || err != nil {
+			return result, err
+		}
+		toolResult, ok := result.(*mcp.CallToolResult)
+		if !ok || toolResult.StructuredContent != nil || len(toolResult.Content) == 0 {
+			return result, err
+		}
+
+		text, ok := toolResult.Content[0].(*mcp.TextContent)
+		if !ok || !(toolResult.IsError || strings.HasPrefix(text.Text, "Error")) {
+			return result, err
+		}
+
+		toolResult.IsError = true
+		toolResult.StructuredContent = map[string]ToolError{
+			"error": newToolError(text.Text, failures.matching(text.Text)),
+		}
+		return toolResult, nil
+	}
+}
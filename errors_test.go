@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestClassifyToolError(t *testing.T) {
+	failure := &ExecError{Code: errorCodeVPPCtlError, Command: "vppctl show int foo", Err: errors.New("invalid interface `foo'")}
+
+	tests := []struct {
+		message string
+		failure *ExecError
+		want    string
+	}{
+		{"Error executing VPP command on pod p: context deadline exceeded", nil, errorCategoryTimeout},
+		{"Error: pods \"calico-vpp-node-x\" is forbidden: User cannot get resource", nil, errorCategoryPermissionDenied},
+		{"Error: quota exceeded for vpp_trace on pod p", nil, errorCategoryQuotaExceeded},
+		{"Error: container not found (\"vpp\")", nil, errorCategoryVPPRestarting},
+		{"Error: pods \"calico-vpp-node-x\" not found", nil, errorCategoryPodNotFound},
+		{"Error: unknown input `foo'", nil, errorCategoryParseError},
+		{"Error: PodName is required", nil, errorCategoryInvalidArgument},
+		{"Error: invalid port 0: must be between 1 and 65535", nil, errorCategoryInvalidArgument},
+		{"Error: invalid timeout_seconds 900: must be at most 300 (server --max-exec-timeout)", nil, errorCategoryInvalidArgument},
+		{"Error: invalid interface name \"tap0 }\"", nil, errorCategoryInvalidArgument},
+		{"Error: invalid JSON: unexpected end of input", nil, errorCategoryInvalidArgument},
+		{"Error: show int foo: invalid interface", nil, errorCategoryUnknown},
+		{"Error executing VPP command on pod p: invalid interface `foo'", failure, errorCategoryUnknown},
+		{"Error: something else happened", nil, errorCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := classifyToolError(tt.message, tt.failure); got != tt.want {
+				t.Errorf("classifyToolError(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewToolError(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		failure *ExecError
+		want    ToolError
+	}{
+		{
+			name:    "failed vppctl command",
+			message: "Error: exit status 1",
+			failure: &ExecError{Code: errorCodeVPPCtlError, Command: "vppctl show foo", Err: errors.New("exit status 1")},
+			want:    ToolError{Code: errorCodeVPPCtlError, Category: errorCategoryUnknown, Command: "vppctl show foo"},
+		},
+		{
+			name:    "timed out gobgp command",
+			message: "Error: signal: killed",
+			failure: &ExecError{Code: errorCodeExecTimeout, Command: "gobgp neighbor", Err: errors.New("signal: killed")},
+			want:    ToolError{Code: errorCodeExecTimeout, Category: errorCategoryTimeout, Command: "gobgp neighbor"},
+		},
+		{
+			name:    "vpp restarting under gobgp",
+			message: "Error: connection refused",
+			failure: &ExecError{Code: errorCodeGoBGPError, Command: "gobgp global", Err: errors.New("connection refused")},
+			want:    ToolError{Code: errorCodeGoBGPError, Category: errorCategoryVPPRestarting, Command: "gobgp global"},
+		},
+		{
+			name:    "reported command without an exec",
+			message: "Error fetching the vpp container logs of pod p: EOF\nCommand attempted: kubectl logs p",
+			want:    ToolError{Code: errorCodeKubeAPIError, Category: errorCategoryUnknown, Command: "kubectl logs p"},
+		},
+		{
+			name:    "validation",
+			message: "Error: PodName is required",
+			want:    ToolError{Code: errorCodeInvalidArgument, Category: errorCategoryInvalidArgument},
+		},
+		{
+			name:    "internal",
+			message: "Error: something else happened",
+			want:    ToolError{Code: errorCodeInternal, Category: errorCategoryUnknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.Message = tt.message
+			if got := newToolError(tt.message, tt.failure); got != tt.want {
+				t.Errorf("newToolError() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecFailuresMatching(t *testing.T) {
+	failures := &execFailures{}
+	ctx := context.WithValue(context.Background(), execFailuresKey{}, failures)
+
+	cleanup := newExecError(ctx, context.Background(), errorCodeVPPCtlError, "vppctl pcap trace off", errors.New("pcap trace not active"))
+	failed := newExecError(ctx, context.Background(), errorCodeVPPCtlError, "vppctl show foo", errors.New("unknown input `foo'"))
+
+	if got := failures.matching("Error executing VPP command on pod p: unknown input `foo'"); got != failed {
+		t.Errorf("matching() = %v, want the show foo failure", got)
+	}
+	if got := failures.matching("Error: pcap trace not active"); got != cleanup {
+		t.Errorf("matching() = %v, want the pcap trace off failure", got)
+	}
+	if got := failures.matching("Error: PodName is required"); got != nil {
+		t.Errorf("matching() = %v, want none", got)
+	}
+
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-expired.Done()
+	if got := newExecError(context.Background(), expired, errorCodeGoBGPError, "gobgp neighbor", errors.New("signal: killed")); got.Code != errorCodeExecTimeout {
+		t.Errorf("newExecError() code = %q, want %q", got.Code, errorCodeExecTimeout)
+	}
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// Default containers of a calico-vpp pod
+	vppContainerName   = "vpp"
+	agentContainerName = "agent"
+
+	// Supported exec backends
+	backendKubectl  = "kubectl"
+	backendClientGo = "client-go"
+	backendLocal    = "local"
+)
+
+// Executor runs commands in the containers of a calico-vpp pod
+type Executor interface {
+	// ExecVPP runs a vppctl command in the vpp container and returns its stdout
+	ExecVPP(ctx context.Context, podName, command string) (string, error)
+	// ExecGoBGP runs a gobgp command in the agent container and returns its stdout
+	ExecGoBGP(ctx context.Context, podName, command string) (string, error)
+	// CopyFile returns the content of a file from a container of the pod
+	CopyFile(ctx context.Context, podName, containerName, path string) ([]byte, error)
+	// Exec runs an argument vector, without shell, in a container of the pod and returns its stdout
+	Exec(ctx context.Context, podName, containerName string, args []string) ([]byte, error)
+	// CommandLine returns the exact command line running args in a container of the pod
+	CommandLine(podName, containerName string, args []string) string
+	// VPPArgs returns the argument vector running a vppctl command
+	VPPArgs(command string) []string
+	// Logs returns the logs of a container of the pod
+	Logs(ctx context.Context, podName, containerName string, options logOptions) ([]byte, error)
+	// LogsCommandLine returns the exact command line fetching the logs of a container of the pod
+	LogsCommandLine(podName, containerName string, options logOptions) string
+}
+
+// logOptions selects the container log lines returned by Executor.Logs
+type logOptions struct {
+	// Tail keeps the last lines (0: every line)
+	Tail int
+	// Since keeps the lines written in the last duration (0: every line)
+	Since time.Duration
+	// Previous returns the logs of the previous instance of a restarted container
+	Previous bool
+}
+
+// newExecutor creates the executor for the given backend name. cliSocket is the
+// VPP CLI socket passed to vppctl (vppctl default when empty).
+func newExecutor(backend, cliSocket string) (Executor, error) {
+	switch backend {
+	case backendKubectl:
+		return &KubectlExecutor{cliSocket: cliSocket}, nil
+	case backendClientGo:
+		k8sClient, err := newKubeClient()
+		if err != nil {
+			return nil, err
+		}
+		return &ClientGoExecutor{client: k8sClient, cliSocket: cliSocket}, nil
+	case backendLocal:
+		return &LocalExecutor{cliSocket: cliSocket}, nil
+	default:
+		return nil, fmt.Errorf("invalid exec backend: %s. Use '%s', '%s' or '%s'", backend, backendKubectl, backendClientGo, backendLocal)
+	}
+}
+
+// bypassesKubernetes reports whether commands run locally, without going through the Kubernetes API
+func (s *VPPMCPServer) bypassesKubernetes() bool {
+	_, local := s.executor.(*LocalExecutor)
+	return local
+}
+
+// vppctlArgs returns the argument vector running a vppctl command against the given CLI socket
+func vppctlArgs(cliSocket, command string) []string {
+	args := []string{"vppctl"}
+	if cliSocket != "" {
+		args = append(args, "-s", cliSocket)
+	}
+	return append(args, strings.Fields(command)...)
+}
+
+// gobgpArgs returns the argument vector running a gobgp command
+func gobgpArgs(command string) []string {
+	return append([]string{"gobgp"}, strings.Fields(command)...)
+}
+
+// KubectlExecutor runs commands through "kubectl exec"
+type KubectlExecutor struct {
+	cliSocket string
+}
+
+// kubectlExecArgs returns the kubectl arguments running args in a container of the pod
+func kubectlExecArgs(podName, containerName string, args []string) []string {
+	cmdArgs := []string{
+		"exec",
+		"-n", vppNamespace,
+		podName,
+		"-c", containerName,
+		"--",
+	}
+	return append(cmdArgs, args...)
+}
+
+// kubectlLogsArgs returns the kubectl arguments fetching the logs of a container of the pod
+func kubectlLogsArgs(podName, containerName string, options logOptions) []string {
+	cmdArgs := []string{
+		"logs",
+		"-n", vppNamespace,
+		podName,
+		"-c", containerName,
+	}
+	if options.Tail > 0 {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("--tail=%d", options.Tail))
+	}
+	if options.Since > 0 {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("--since=%s", options.Since))
+	}
+	if options.Previous {
+		cmdArgs = append(cmdArgs, "--previous")
+	}
+	return cmdArgs
+}
+
+// exec runs args in a container of the pod and returns its stdout
+func (e *KubectlExecutor) exec(ctx context.Context, podName, containerName string, args []string) ([]byte, error) {
+	return e.run(ctx, kubectlExecArgs(podName, containerName, args))
+}
+
+// run runs kubectl with cmdArgs and returns its stdout
+func (e *KubectlExecutor) run(ctx context.Context, cmdArgs []string) ([]byte, error) {
+	log.Printf("Executing command: kubectl %s", strings.Join(cmdArgs, " "))
+
+	cmd := exec.CommandContext(ctx, "kubectl", cmdArgs...)
+
+	// Capture stdout and stderr separately
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	log.Printf("Starting command execution...")
+	err := cmd.Run()
+	log.Printf("Command completed with status: %v", err == nil)
+
+	errOutput := stderr.String()
+	if errOutput != "" {
+		log.Printf("Command stderr: %s", errOutput)
+	}
+
+	if err != nil {
+		return stdout.Bytes(), fmt.Errorf("%v - %s", err, strings.TrimSpace(errOutput))
+	}
+	return stdout.Bytes(), nil
+}
+
+// ExecVPP runs a vppctl command in the vpp container
+func (e *KubectlExecutor) ExecVPP(ctx context.Context, podName, command string) (string, error) {
+	output, err := e.exec(ctx, podName, execContainer(ctx, vppContainerName), vppctlArgs(e.cliSocket, command))
+	return string(output), err
+}
+
+// ExecGoBGP runs a gobgp command in the agent container
+func (e *KubectlExecutor) ExecGoBGP(ctx context.Context, podName, command string) (string, error) {
+	output, err := e.exec(ctx, podName, execContainer(ctx, agentContainerName), gobgpArgs(command))
+	return string(output), err
+}
+
+// CopyFile returns the content of a file from a container of the pod
+func (e *KubectlExecutor) CopyFile(ctx context.Context, podName, containerName, path string) ([]byte, error) {
+	return e.exec(ctx, podName, containerName, []string{"cat", path})
+}
+
+// Exec runs args in a container of the pod
+func (e *KubectlExecutor) Exec(ctx context.Context, podName, containerName string, args []string) ([]byte, error) {
+	return e.exec(ctx, podName, containerName, args)
+}
+
+// CommandLine returns the kubectl exec command line running args
+func (e *KubectlExecutor) CommandLine(podName, containerName string, args []string) string {
+	return "kubectl " + strings.Join(kubectlExecArgs(podName, containerName, args), " ")
+}
+
+// VPPArgs returns the argument vector running a vppctl command
+func (e *KubectlExecutor) VPPArgs(command string) []string {
+	return vppctlArgs(e.cliSocket, command)
+}
+
+// Logs returns the logs of a container of the pod through "kubectl logs"
+func (e *KubectlExecutor) Logs(ctx context.Context, podName, containerName string, options logOptions) ([]byte, error) {
+	return e.run(ctx, kubectlLogsArgs(podName, containerName, options))
+}
+
+// LogsCommandLine returns the kubectl logs command line fetching the logs of a container
+func (e *KubectlExecutor) LogsCommandLine(podName, containerName string, options logOptions) string {
+	return "kubectl " + strings.Join(kubectlLogsArgs(podName, containerName, options), " ")
+}
+
+// ClientGoExecutor runs commands through the Kubernetes API exec subresource
+type ClientGoExecutor struct {
+	client    *KubeClient
+	cliSocket string
+}
+
+// exec runs args in a container of the pod and returns its stdout
+func (e *ClientGoExecutor) exec(ctx context.Context, podName, containerName string, args []string) ([]byte, error) {
+	log.Printf("Executing command in %s/%s (container: %s): %s", vppNamespace, podName, containerName, strings.Join(args, " "))
+
+	req := e.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(vppNamespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: containerName,
+			Command:   args,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(e.client.config, "POST", req.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec stream: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+
+	errOutput := stderr.String()
+	if errOutput != "" {
+		log.Printf("Command stderr: %s", errOutput)
+	}
+
+	if err != nil {
+		return stdout.Bytes(), fmt.Errorf("%v - %s", err, strings.TrimSpace(errOutput))
+	}
+	return stdout.Bytes(), nil
+}
+
+// ExecVPP runs a vppctl command in the vpp container
+func (e *ClientGoExecutor) ExecVPP(ctx context.Context, podName, command string) (string, error) {
+	output, err := e.exec(ctx, podName, execContainer(ctx, vppContainerName), vppctlArgs(e.cliSocket, command))
+	return string(output), err
+}
+
+// ExecGoBGP runs a gobgp command in the agent container
+func (e *ClientGoExecutor) ExecGoBGP(ctx context.Context, podName, command string) (string, error) {
+	output, err := e.exec(ctx, podName, execContainer(ctx, agentContainerName), gobgpArgs(command))
+	return string(output), err
+}
+
+// CopyFile returns the content of a file from a container of the pod
+func (e *ClientGoExecutor) CopyFile(ctx context.Context, podName, containerName, path string) ([]byte, error) {
+	return e.exec(ctx, podName, containerName, []string{"cat", path})
+}
+
+// Exec runs args in a container of the pod
+func (e *ClientGoExecutor) Exec(ctx context.Context, podName, containerName string, args []string) ([]byte, error) {
+	return e.exec(ctx, podName, containerName, args)
+}
+
+// CommandLine returns the kubectl exec command line equivalent to the API exec request running args
+func (e *ClientGoExecutor) CommandLine(podName, containerName string, args []string) string {
+	return "kubectl " + strings.Join(kubectlExecArgs(podName, containerName, args), " ")
+}
+
+// VPPArgs returns the argument vector running a vppctl command
+func (e *ClientGoExecutor) VPPArgs(command string) []string {
+	return vppctlArgs(e.cliSocket, command)
+}
+
+// Logs returns the logs of a container of the pod through the Kubernetes API log subresource
+func (e *ClientGoExecutor) Logs(ctx context.Context, podName, containerName string, options logOptions) ([]byte, error) {
+	log.Printf("Fetching logs of %s/%s (container: %s)", vppNamespace, podName, containerName)
+
+	logOptions := &corev1.PodLogOptions{Container: containerName, Previous: options.Previous}
+	if options.Tail > 0 {
+		tail := int64(options.Tail)
+		logOptions.TailLines = &tail
+	}
+	if options.Since > 0 {
+		since := int64(options.Since.Seconds())
+		logOptions.SinceSeconds = &since
+	}
+	return e.client.CoreV1().Pods(vppNamespace).GetLogs(podName, logOptions).DoRaw(ctx)
+}
+
+// LogsCommandLine returns the kubectl logs command line equivalent to the API log request
+func (e *ClientGoExecutor) LogsCommandLine(podName, containerName string, options logOptions) string {
+	return "kubectl " + strings.Join(kubectlLogsArgs(podName, containerName, options), " ")
+}
+
+// LocalExecutor runs vppctl and gobgp directly on the host, for sidecar deployments
+// sharing the VPP CLI socket. The pod name is ignored.
+type LocalExecutor struct {
+	cliSocket string
+}
+
+// run executes a local binary and returns its stdout
+func (e *LocalExecutor) run(ctx context.Context, name string, args []string) ([]byte, error) {
+	log.Printf("Executing command: %s %s", name, strings.Join(args, " "))
+
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	// Capture stdout and stderr separately
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	log.Printf("Command completed with status: %v", err == nil)
+
+	errOutput := stderr.String()
+	if errOutput != "" {
+		log.Printf("Command stderr: %s", errOutput)
+	}
+
+	if err != nil {
+		return stdout.Bytes(), fmt.Errorf("%v - %s", err, strings.TrimSpace(errOutput))
+	}
+	return stdout.Bytes(), nil
+}
+
+// ExecVPP runs vppctl against the configured CLI socket
+func (e *LocalExecutor) ExecVPP(ctx context.Context, podName, command string) (string, error) {
+	output, err := e.run(ctx, "vppctl", vppctlArgs(e.cliSocket, command)[1:])
+	return string(output), err
+}
+
+// ExecGoBGP runs gobgp against the local gobgpd
+func (e *LocalExecutor) ExecGoBGP(ctx context.Context, podName, command string) (string, error) {
+	output, err := e.run(ctx, "gobgp", gobgpArgs(command)[1:])
+	return string(output), err
+}
+
+// CopyFile reads a file from the local filesystem
+func (e *LocalExecutor) CopyFile(ctx context.Context, podName, containerName, path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// Exec runs args on the host. The pod and container are ignored.
+func (e *LocalExecutor) Exec(ctx context.Context, podName, containerName string, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return e.run(ctx, args[0], args[1:])
+}
+
+// CommandLine returns the local command line running args. The pod and container are ignored.
+func (e *LocalExecutor) CommandLine(podName, containerName string, args []string) string {
+	return strings.Join(args, " ")
+}
+
+// VPPArgs returns the argument vector running a vppctl command
+func (e *LocalExecutor) VPPArgs(command string) []string {
+	return vppctlArgs(e.cliSocket, command)
+}
+
+// Logs is not supported: the containers of the pod are not visible from the host
+func (e *LocalExecutor) Logs(ctx context.Context, podName, containerName string, options logOptions) ([]byte, error) {
+	return nil, fmt.Errorf("container logs are only available through Kubernetes, not with the %s backend", backendLocal)
+}
+
+// LogsCommandLine returns the kubectl logs command line the Kubernetes backends would run
+func (e *LocalExecutor) LogsCommandLine(podName, containerName string, options logOptions) string {
+	return "kubectl " + strings.Join(kubectlLogsArgs(podName, containerName, options), " ")
+}
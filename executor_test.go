@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// fakeExecutor is an Executor returning canned outputs and recording the commands it runs
+type fakeExecutor struct {
+	mu sync.Mutex
+	// outputs maps a vppctl or gobgp command to its output
+	outputs map[string]string
+	// errors maps a vppctl or gobgp command to its failure
+	errors map[string]error
+	// commands lists the commands run, prefixed by vppctl or gobgp
+	commands []string
+}
+
+// run records a command and returns its canned output
+func (e *fakeExecutor) run(tool, command string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.commands = append(e.commands, tool+" "+command)
+	if err, ok := e.errors[command]; ok {
+		return "", err
+	}
+	return e.outputs[command], nil
+}
+
+// ran returns the commands run so far
+func (e *fakeExecutor) ran() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.commands...)
+}
+
+func (e *fakeExecutor) ExecVPP(ctx context.Context, podName, command string) (string, error) {
+	return e.run("vppctl", command)
+}
+
+func (e *fakeExecutor) ExecGoBGP(ctx context.Context, podName, command string) (string, error) {
+	return e.run("gobgp", command)
+}
+
+func (e *fakeExecutor) CopyFile(ctx context.Context, podName, containerName, path string) ([]byte, error) {
+	return nil, fmt.Errorf("no file %s in the fake executor", path)
+}
+
+func (e *fakeExecutor) Exec(ctx context.Context, podName, containerName string, args []string) ([]byte, error) {
+	output, err := e.run(args[0], strings.Join(args[1:], " "))
+	return []byte(output), err
+}
+
+func (e *fakeExecutor) CommandLine(podName, containerName string, args []string) string {
+	return fmt.Sprintf("%s/%s: %s", podName, containerName, strings.Join(args, " "))
+}
+
+func (e *fakeExecutor) VPPArgs(command string) []string {
+	return vppctlArgs("", command)
+}
+
+func (e *fakeExecutor) Logs(ctx context.Context, podName, containerName string, options logOptions) ([]byte, error) {
+	return nil, nil
+}
+
+func (e *fakeExecutor) LogsCommandLine(podName, containerName string, options logOptions) string {
+	return "logs " + podName + "/" + containerName
+}
+
+// resultText returns the text content of a tool result
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	if result == nil || len(result.Content) == 0 {
+		t.Fatalf("result has no content")
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("result content is %T, want text", result.Content[0])
+	}
+	return text.Text
+}
+
+func TestExecutePodVPPCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		output  string
+		err     error
+		wantErr bool
+	}{
+		{
+			name:    "returns the command output",
+			command: "show version",
+			output:  "vpp v24.02",
+		},
+		{
+			name:    "reports a failed command",
+			command: "show foo",
+			err:     fmt.Errorf("unknown input `foo'"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &fakeExecutor{outputs: map[string]string{tt.command: tt.output}}
+			if tt.err != nil {
+				executor.errors = map[string]error{tt.command: tt.err}
+			}
+			s := NewVPPMCPServer(executor)
+
+			result, err := s.ExecutePodVPPCommand(context.Background(), "calico-vpp-node-abcde", tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExecutePodVPPCommand() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got := executor.ran(); len(got) != 1 || got[0] != "vppctl "+tt.command {
+				t.Errorf("ran %q, want vppctl %s", got, tt.command)
+			}
+			if success := result["success"].(bool); success == tt.wantErr {
+				t.Errorf("success = %t, want %t", success, !tt.wantErr)
+			}
+			if !tt.wantErr && result["output"].(string) != tt.output {
+				t.Errorf("output = %q, want %q", result["output"], tt.output)
+			}
+		})
+	}
+}
+
+func TestHandleVPPCommand(t *testing.T) {
+	executor := &fakeExecutor{
+		outputs: map[string]string{"show version": "vpp v24.02"},
+		errors:  map[string]error{"show foo": fmt.Errorf("unknown input `foo'")},
+	}
+	s := NewVPPMCPServer(executor)
+	ctx := context.Background()
+
+	result, _, err := s.handleVPPCommand(ctx, VPPCommandInput{PodName: "calico-vpp-node-abcde"}, "show version", "VPP Version")
+	if err != nil {
+		t.Fatalf("handleVPPCommand() error = %v", err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "vpp v24.02") || !strings.Contains(text, "Command executed: vppctl show version") {
+		t.Errorf("handleVPPCommand() text = %q, want the output and the command", text)
+	}
+
+	result, _, _ = s.handleVPPCommand(ctx, VPPCommandInput{PodName: "calico-vpp-node-abcde"}, "show foo", "VPP Foo")
+	if text := resultText(t, result); !strings.Contains(text, "unknown input `foo'") || !strings.Contains(text, "Command attempted: vppctl show foo") {
+		t.Errorf("handleVPPCommand() text = %q, want the failure and the command", text)
+	}
+
+	if _, _, err := s.handleVPPCommand(ctx, VPPCommandInput{}, "show version", "VPP Version"); err == nil {
+		t.Error("handleVPPCommand() without a pod name succeeded")
+	}
+	if got := executor.ran(); len(got) != 2 {
+		t.Errorf("ran %q, want the two commands of the calls naming a pod", got)
+	}
+}
+
+func TestHandleVPPFIBCommand(t *testing.T) {
+	executor := &fakeExecutor{outputs: map[string]string{"show ip fib index 2": "ipv4-VRF:2"}}
+	s := NewVPPMCPServer(executor)
+	ctx := context.Background()
+
+	result, _, err := s.handleVPPFIBCommand(ctx, VPPFIBInput{PodName: "calico-vpp-node-abcde", FibIndex: "2"}, "show ip fib index %s", "VPP IPv4 FIB")
+	if err != nil {
+		t.Fatalf("handleVPPFIBCommand() error = %v", err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "ipv4-VRF:2") {
+		t.Errorf("handleVPPFIBCommand() text = %q, want the FIB output", text)
+	}
+
+	if _, _, err := s.handleVPPFIBCommand(ctx, VPPFIBInput{PodName: "calico-vpp-node-abcde"}, "show ip fib index %s", "VPP IPv4 FIB"); err == nil {
+		t.Error("handleVPPFIBCommand() without a fib index succeeded")
+	}
+	if got := executor.ran(); len(got) != 1 || got[0] != "vppctl show ip fib index 2" {
+		t.Errorf("ran %q, want vppctl show ip fib index 2", got)
+	}
+}
+
+func TestExecTimeout(t *testing.T) {
+	s := NewVPPMCPServer(&fakeExecutor{})
+	s.maxExecTimeout = time.Minute
+
+	if got := s.commandTimeout(withExecTimeout(context.Background(), 30), defaultVPPCommandTimeout); got != 30*time.Second {
+		t.Errorf("commandTimeout() = %s, want the requested 30s", got)
+	}
+	for _, seconds := range []int{600, 10000000000} {
+		if got := s.commandTimeout(withExecTimeout(context.Background(), seconds), defaultVPPCommandTimeout); got != time.Minute {
+			t.Errorf("commandTimeout() of %ds = %s, want the 1m maximum", seconds, got)
+		}
+	}
+
+	handler := newExecTimeoutMiddleware(time.Minute)(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+	})
+	for arguments, wantErr := range map[string]bool{
+		`{"pod_name": "calico-vpp-node-abcde"}`:                                 false,
+		`{"pod_name": "calico-vpp-node-abcde", "timeout_seconds": 60}`:          false,
+		`{"pod_name": "calico-vpp-node-abcde", "timeout_seconds": 600}`:         true,
+		`{"pod_name": "calico-vpp-node-abcde", "timeout_seconds": 10000000000}`: true,
+	} {
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "vpp_show_int", Arguments: json.RawMessage(arguments)}}
+		result, err := handler(context.Background(), "tools/call", req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		toolResult := result.(*mcp.CallToolResult)
+		if toolResult.IsError != wantErr {
+			t.Errorf("call with %s: IsError = %t, want %t", arguments, toolResult.IsError, wantErr)
+		}
+		if text := resultText(t, toolResult); wantErr && classifyToolError(text, nil) != errorCategoryInvalidArgument {
+			t.Errorf("call with %s: %q is not an invalid argument", arguments, text)
+		}
+	}
+}
+
+func TestHandleCommandFromSnapshot(t *testing.T) {
+	executor := &fakeExecutor{}
+	s := NewVPPMCPServer(executor)
+	artifacts, err := NewArtifactStore(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.artifacts = artifacts
+	snapshot := &Snapshot{
+		Pod:     "calico-vpp-node-abcde",
+		Node:    "worker-1",
+		TakenAt: time.Now(),
+		VPP:     map[string]string{"show version": "vpp v24.02"},
+		GoBGP:   map[string]string{"neighbor": "Peer 10.0.0.2"},
+	}
+	if _, err := saveSnapshot(artifacts, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	result, _, err := s.handleVPPCommand(ctx, VPPCommandInput{SnapshotID: snapshot.ID}, "show version", "VPP Version")
+	if err != nil {
+		t.Fatalf("handleVPPCommand() from a snapshot without a pod name error = %v", err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "vpp v24.02") || !strings.Contains(text, "Pod: calico-vpp-node-abcde") {
+		t.Errorf("handleVPPCommand() text = %q, want the recorded output and the pod of the snapshot", text)
+	}
+
+	result, _, _ = s.handleVPPCommand(ctx, VPPCommandInput{SnapshotID: snapshot.ID, Format: outputFormatRaw}, "show version", "VPP Version")
+	if text := resultText(t, result); text != "vpp v24.02" {
+		t.Errorf("handleVPPCommand() raw text = %q, want the recorded output only", text)
+	}
+	result, _, _ = s.handleVPPCommand(ctx, VPPCommandInput{SnapshotID: snapshot.ID, Format: outputFormatJSON}, "show version", "VPP Version")
+	if text := resultText(t, result); !strings.Contains(text, `"snapshot": "`+snapshot.ID+`"`) {
+		t.Errorf("handleVPPCommand() json text = %q, want the snapshot ID", text)
+	}
+
+	result, _, err = s.HandleGoBGPCommand(ctx, BGPCommandInput{SnapshotID: snapshot.ID}, "neighbor", "BGP Neighbor Information")
+	if err != nil {
+		t.Fatalf("HandleGoBGPCommand() from a snapshot without a pod name error = %v", err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "Peer 10.0.0.2") {
+		t.Errorf("HandleGoBGPCommand() text = %q, want the recorded output", text)
+	}
+
+	if _, _, err := s.handleVPPCommand(ctx, VPPCommandInput{PodName: "calico-vpp-node-fghij", SnapshotID: snapshot.ID}, "show version", "VPP Version"); err == nil {
+		t.Error("handleVPPCommand() from the snapshot of another pod succeeded")
+	}
+	if got := executor.ran(); len(got) != 0 {
+		t.Errorf("ran %q, want no command for calls answered from a snapshot", got)
+	}
+}
+
+func TestHandleTakeSnapshotLocal(t *testing.T) {
+	s := NewVPPMCPServer(&LocalExecutor{})
+	recorder := &dryRunRecorder{}
+	ctx := context.WithValue(context.Background(), dryRunKey{}, recorder)
+
+	// Local snapshots don't look the pod up, so the call succeeds without a cluster
+	result, _, err := s.handleTakeSnapshot(ctx, VPPCommandInput{PodName: "vpp-sidecar"})
+	if err != nil {
+		t.Fatalf("handleTakeSnapshot() with the local backend error = %v", err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "pod vpp-sidecar would be recorded") {
+		t.Errorf("handleTakeSnapshot() text = %q, want the dry run of the snapshot", text)
+	}
+	if len(recorder.commands) != len(snapshotVPPCommands)+len(snapshotGoBGPCommands) {
+		t.Errorf("recorded %q, want the snapshot commands", recorder.commands)
+	}
+}
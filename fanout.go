@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// fanOutPodName is the pod_name running a read-only tool on every calico-vpp pod
+	fanOutPodName = "all"
+
+	// fanOutParameterDescription documents the fan-out of the read-only tools
+	fanOutParameterDescription = ". Set pod_name to \"all\" to run on every calico-vpp pod in parallel, or list the nodes to run on in nodes"
+)
+
+// fanOutExcludedTools lists the read-only tools not fanned out: they already cover several
+// pods, take no pod, or run for a long time
+var fanOutExcludedTools = map[string]bool{
+	"vpp_node_conditions":  true,
+	"bgp_monitor":          true,
+	"bgp_mrt_dump":         true,
+	"bgp_peer_flaps":       true,
+	"bgp_route_counts":     true,
+	"bgp_rib_divergence":   true,
+	"bgp_peer_reconcile":   true,
+	"bgp_pod_route":        true,
+	"bgp_block_affinities": true,
+}
+
+// fanOutTool reports whether a tool can run on several calico-vpp pods in one call: only the
+// read-only show and BGP tools can
+func fanOutTool(name string) bool {
+	group := toolGroups[name]
+	return (group == toolGroupShow || group == toolGroupBGP) && !fanOutExcludedTools[name]
+}
+
+// fanOutResult is the result of a fanned-out tool call on one calico-vpp pod
+type fanOutResult struct {
+	Node       string `json:"node"`
+	Pod        string `json:"pod"`
+	Output     string `json:"output,omitempty"`
+	Structured any    `json:"structured,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// links holds the resource links of the pod result, such as stored artifacts
+	links []mcp.Content
+}
+
+// fanOutPods returns the calico-vpp pods a fanned-out call runs on: every pod, or the pods of
+// the given nodes
+func fanOutPods(ctx context.Context, nodes []string) ([]VPPPod, error) {
+	k8sClient, err := newKubeClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+	pods, err := listVPPPods(ctx, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Node < pods[j].Node
+	})
+	if len(nodes) == 0 {
+		if len(pods) == 0 {
+			return nil, fmt.Errorf("no calico-vpp pod found in namespace %s", vppNamespace)
+		}
+		return pods, nil
+	}
+
+	podByNode := make(map[string]VPPPod, len(pods))
+	for _, pod := range pods {
+		podByNode[pod.Node] = pod
+	}
+	selected := make([]VPPPod, 0, len(nodes))
+	for _, node := range nodes {
+		pod, ok := podByNode[node]
+		if !ok {
+			return nil, fmt.Errorf("no calico-vpp pod runs on node %q in namespace %s", node, vppNamespace)
+		}
+		selected = append(selected, pod)
+	}
+	return selected, nil
+}
+
+// fanOutMiddleware runs the read-only tools called with pod_name "all" or a nodes list on
+// every selected calico-vpp pod in parallel, and merges the results in one section per node
+func (s *VPPMCPServer) fanOutMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		request, ok := req.(*mcp.CallToolRequest)
+		if method != "tools/call" || !ok || request.Params == nil {
+			return next(ctx, method, req)
+		}
+
+		var args map[string]json.RawMessage
+		var target struct {
+			PodName string   `json:"pod_name"`
+			Nodes   []string `json:"nodes"`
+		}
+		// Malformed arguments are reported by the tool handler itself
+		if json.Unmarshal(request.Params.Arguments, &args) != nil || json.Unmarshal(request.Params.Arguments, &target) != nil {
+			return next(ctx, method, req)
+		}
+		if target.PodName != fanOutPodName && len(target.Nodes) == 0 {
+			return next(ctx, method, req)
+		}
+
+		var err error
+		var pods []VPPPod
+		switch {
+		case !fanOutTool(request.Params.Name):
+			err = fmt.Errorf("%s runs on a single pod: only the read-only show and BGP tools accept pod_name %q or nodes", request.Params.Name, fanOutPodName)
+		case target.PodName != "" && target.PodName != fanOutPodName:
+			err = fmt.Errorf("pod_name and nodes are mutually exclusive: specify only one of them")
+		case s.bypassesKubernetes():
+			err = fmt.Errorf("running a tool on several pods requires the Kubernetes API, not available with the %s backend", backendLocal)
+		default:
+			pods, err = fanOutPods(ctx, target.Nodes)
+		}
+		if err != nil {
+			log.Printf("Rejected %s call: %v", request.Params.Name, err)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		log.Printf("Running %s on %d calico-vpp pod(s)", request.Params.Name, len(pods))
+
+		// Run the tool on every pod, each call going through the quotas and the dry run
+		delete(args, "nodes")
+		results := make([]fanOutResult, len(pods))
+		var wg sync.WaitGroup
+		for i, pod := range pods {
+			podArgs := make(map[string]json.RawMessage, len(args))
+			for name, value := range args {
+				podArgs[name] = value
+			}
+			podArgs["pod_name"], _ = json.Marshal(pod.Name)
+			arguments, _ := json.Marshal(podArgs)
+
+			results[i] = fanOutResult{Node: pod.Node, Pod: pod.Name}
+			wg.Add(1)
+			go func(result *fanOutResult) {
+				defer wg.Done()
+				podResult, err := next(ctx, method, &mcp.CallToolRequest{
+					Session: request.Session,
+					Params:  &mcp.CallToolParamsRaw{Name: request.Params.Name, Arguments: arguments},
+					Extra:   request.Extra,
+				})
+				if err != nil {
+					result.Error = err.Error()
+					return
+				}
+				toolResult, ok := podResult.(*mcp.CallToolResult)
+				if !ok {
+					result.Error = fmt.Sprintf("unexpected result %T", podResult)
+					return
+				}
+				var text strings.Builder
+				for _, content := range toolResult.Content {
+					switch content := content.(type) {
+					case *mcp.TextContent:
+						text.WriteString(content.Text)
+					case *mcp.ResourceLink:
+						result.links = append(result.links, content)
+					}
+				}
+				result.Output = strings.TrimRight(text.String(), "\n")
+				if toolResult.IsError || strings.HasPrefix(result.Output, "Error") {
+					result.Error, result.Output = result.Output, ""
+					return
+				}
+				result.Structured = toolResult.StructuredContent
+			}(&results[i])
+		}
+		wg.Wait()
+
+		var text strings.Builder
+		var links []mcp.Content
+		failed := 0
+		for _, result := range results {
+			text.WriteString(fmt.Sprintf("=== Node %s (pod %s) ===\n", result.Node, result.Pod))
+			if result.Error != "" {
+				failed++
+				text.WriteString(result.Error + "\n\n")
+				continue
+			}
+			text.WriteString(result.Output + "\n\n")
+			links = append(links, result.links...)
+		}
+		text.WriteString(fmt.Sprintf("Summary: %s ran on %d pod(s), %d failed\n", request.Params.Name, len(results), failed))
+
+		return &mcp.CallToolResult{
+			Content: append([]mcp.Content{
+				&mcp.TextContent{
+					Text: text.String(),
+				},
+			}, links...),
+			StructuredContent: map[string]any{
+				"tool":   request.Params.Name,
+				"nodes":  results,
+				"failed": failed,
+			},
+			IsError: failed == len(results),
+		}, nil
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Output formats of command tools
+	outputFormatRaw      = "raw"
+	outputFormatMarkdown = "markdown"
+	outputFormatJSON     = "json"
+
+	// formatParameterLine documents the format parameter of command tools
+	formatParameterLine = "- format: Output format - raw (command output only)|markdown (output with command and pod details)|json (parsed data where a parser exists) (default: markdown)"
+)
+
+// vppOutputParsers maps vppctl commands to the parser of their output used by the json format
+var vppOutputParsers = map[string]func(string) any{
+	"show int":        func(output string) any { return parseVppInterfaceCounters(output) },
+	"show vlib graph": func(output string) any { return parseVlibGraph(output) },
+}
+
+// validateOutputFormat rejects unknown output formats. An empty format selects markdown.
+func validateOutputFormat(format string) error {
+	switch format {
+	case "", outputFormatRaw, outputFormatMarkdown, outputFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid format '%s'. Use '%s', '%s' or '%s'", format, outputFormatRaw, outputFormatMarkdown, outputFormatJSON)
+	}
+}
+
+// commandOutput is the output of a command run by a tool along with where it ran
+type commandOutput struct {
+	// Binary is vppctl or gobgp
+	Binary string `json:"-"`
+	// Command is the command run, without the binary
+	Command   string `json:"command"`
+	Node      string `json:"node,omitempty"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Filter    string `json:"filter,omitempty"`
+	// Data holds the parsed output when a parser exists
+	Data any `json:"data,omitempty"`
+	// Output holds the verbatim output when no parser exists
+	Output string `json:"output,omitempty"`
+	// Snapshot and TakenAt identify the stored snapshot the output was read from, if any
+	Snapshot string `json:"snapshot,omitempty"`
+	TakenAt  string `json:"taken_at,omitempty"`
+}
+
+// render returns the tool result text of the output in the given format
+func (o commandOutput) render(format, commandDescription string) string {
+	switch format {
+	case outputFormatRaw:
+		return o.Output
+
+	case outputFormatJSON:
+		if o.Binary == "vppctl" {
+			if parse, ok := vppOutputParsers[o.Command]; ok {
+				o.Data, o.Output = parse(o.Output), ""
+			}
+		} else if trimmed := strings.TrimSpace(o.Output); json.Valid([]byte(trimmed)) && trimmed != "" {
+			// gobgp commands run with -j return JSON
+			o.Data, o.Output = json.RawMessage(trimmed), ""
+		}
+		o.Command = o.Binary + " " + o.Command
+		data, err := json.MarshalIndent(o, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("Error: failed to encode output: %v", err)
+		}
+		return string(data)
+
+	default:
+		var text strings.Builder
+		verb := "executed"
+		if o.Snapshot != "" {
+			verb = "recorded"
+		}
+		text.WriteString(fmt.Sprintf("%s:\n\n%s\n\nCommand %s: %s %s\n", commandDescription, o.Output, verb, o.Binary, o.Command))
+		if o.Binary == "gobgp" || o.Snapshot != "" {
+			text.WriteString(fmt.Sprintf("Node: %s\n", o.Node))
+		}
+		text.WriteString(fmt.Sprintf("Pod: %s (container: %s)", o.Pod, o.Container))
+		if o.Snapshot != "" {
+			text.WriteString(fmt.Sprintf("\nSnapshot: %s (taken at %s)", o.Snapshot, o.TakenAt))
+		}
+		if o.Filter != "" {
+			text.WriteString(fmt.Sprintf("\nFilter: %s", o.Filter))
+		}
+		return text.String()
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BGP path attribute types read from the JSON output of gobgp (RFC 4271, RFC 1997, RFC 4760)
+const (
+	bgpAttrOrigin      = 1
+	bgpAttrASPath      = 2
+	bgpAttrNextHop     = 3
+	bgpAttrMED         = 4
+	bgpAttrLocalPref   = 5
+	bgpAttrCommunities = 8
+	bgpAttrMPReach     = 14
+)
+
+// bgpSessionStates names the session_state values of the JSON output of 'gobgp neighbor -j'
+var bgpSessionStates = map[int]string{
+	1: "idle",
+	2: "connect",
+	3: "active",
+	4: "opensent",
+	5: "openconfirm",
+	6: "established",
+}
+
+// bgpOrigins names the ORIGIN attribute values
+var bgpOrigins = map[int]string{0: "igp", 1: "egp", 2: "incomplete"}
+
+// gobgpJSONPeer is the subset of a peer of 'gobgp neighbor -j' read by the parser. gobgp v2
+// names the peer AS peer_as, v3 peer_asn.
+type gobgpJSONPeer struct {
+	Conf *struct {
+		NeighborAddress string `json:"neighbor_address"`
+		PeerAS          uint32 `json:"peer_as"`
+		PeerASN         uint32 `json:"peer_asn"`
+		Description     string `json:"description"`
+	} `json:"conf"`
+	State *struct {
+		NeighborAddress string          `json:"neighbor_address"`
+		PeerAS          uint32          `json:"peer_as"`
+		PeerASN         uint32          `json:"peer_asn"`
+		SessionState    json.RawMessage `json:"session_state"`
+		AdminState      json.RawMessage `json:"admin_state"`
+	} `json:"state"`
+	Timers *struct {
+		State *struct {
+			Uptime *struct {
+				Seconds int64 `json:"seconds"`
+			} `json:"uptime"`
+		} `json:"state"`
+	} `json:"timers"`
+	AfiSafis []struct {
+		State *struct {
+			Family *struct {
+				Afi  int `json:"afi"`
+				Safi int `json:"safi"`
+			} `json:"family"`
+			Received   uint64 `json:"received"`
+			Accepted   uint64 `json:"accepted"`
+			Advertised uint64 `json:"advertised"`
+		} `json:"state"`
+	} `json:"afi_safis"`
+}
+
+// gobgpJSONPath is the subset of a path of 'gobgp global rib -j' read by the parser
+type gobgpJSONPath struct {
+	Nlri struct {
+		Prefix string `json:"prefix"`
+	} `json:"nlri"`
+	Age        int64               `json:"age"`
+	Best       bool                `json:"best"`
+	Stale      bool                `json:"stale"`
+	Withdrawal bool                `json:"withdrawal"`
+	NeighborIP string              `json:"neighbor-ip"`
+	Attrs      []gobgpJSONPathAttr `json:"attrs"`
+}
+
+// gobgpJSONPathAttr holds the fields of the path attributes read by the parser, by attribute type
+type gobgpJSONPathAttr struct {
+	Type    int             `json:"type"`
+	Value   json.RawMessage `json:"value"`
+	ASPaths []struct {
+		ASNs []uint32 `json:"asns"`
+	} `json:"as_paths"`
+	NextHop     string   `json:"nexthop"`
+	Metric      *uint32  `json:"metric"`
+	Communities []uint32 `json:"communities"`
+}
+
+// bgpJSONFamily is the route counts of one address family of a peer
+type bgpJSONFamily struct {
+	Family     string `json:"family"`
+	Received   uint64 `json:"received"`
+	Accepted   uint64 `json:"accepted"`
+	Advertised uint64 `json:"advertised"`
+}
+
+// bgpJSONPeer is a BGP neighbor parsed from the JSON output of gobgp
+type bgpJSONPeer struct {
+	Address       string          `json:"address"`
+	AS            uint32          `json:"as"`
+	Description   string          `json:"description,omitempty"`
+	State         string          `json:"state"`
+	AdminState    string          `json:"admin_state,omitempty"`
+	EstablishedAt string          `json:"established_at,omitempty"`
+	Families      []bgpJSONFamily `json:"families,omitempty"`
+}
+
+// bgpJSONRoute is a path of the BGP RIB parsed from the JSON output of gobgp
+type bgpJSONRoute struct {
+	Prefix      string   `json:"prefix"`
+	Best        bool     `json:"best"`
+	NextHop     string   `json:"next_hop,omitempty"`
+	ASPath      []uint32 `json:"as_path,omitempty"`
+	Origin      string   `json:"origin,omitempty"`
+	MED         *uint32  `json:"med,omitempty"`
+	LocalPref   *uint32  `json:"local_pref,omitempty"`
+	Communities []string `json:"communities,omitempty"`
+	Neighbor    string   `json:"neighbor,omitempty"`
+	Age         int64    `json:"age,omitempty"`
+	Stale       bool     `json:"stale,omitempty"`
+}
+
+// jsonEnumName returns the name of an enum encoded as a number or as a string
+func jsonEnumName(raw json.RawMessage, names map[int]string) string {
+	var number int
+	if err := json.Unmarshal(raw, &number); err == nil {
+		if name, ok := names[number]; ok {
+			return name
+		}
+		return strconv.Itoa(number)
+	}
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return strings.ToLower(name)
+	}
+	return ""
+}
+
+// bgpFamilyName names an AFI/SAFI pair, e.g. "ipv4-unicast"
+func bgpFamilyName(afi, safi int) string {
+	afis := map[int]string{1: "ipv4", 2: "ipv6", 25: "l2vpn"}
+	safis := map[int]string{1: "unicast", 2: "multicast", 70: "evpn", 128: "mpls-vpn", 133: "flowspec"}
+	name, ok := afis[afi]
+	if !ok {
+		name = fmt.Sprintf("afi%d", afi)
+	}
+	if s, ok := safis[safi]; ok {
+		return name + "-" + s
+	}
+	return fmt.Sprintf("%s-safi%d", name, safi)
+}
+
+// convertGoBGPPeer converts a peer of the JSON output of gobgp
+func convertGoBGPPeer(p gobgpJSONPeer) bgpJSONPeer {
+	var peer bgpJSONPeer
+	if p.Conf != nil {
+		peer.Address, peer.AS, peer.Description = p.Conf.NeighborAddress, max(p.Conf.PeerASN, p.Conf.PeerAS), p.Conf.Description
+	}
+	if p.State != nil {
+		if peer.Address == "" {
+			peer.Address = p.State.NeighborAddress
+		}
+		if peer.AS == 0 {
+			peer.AS = max(p.State.PeerASN, p.State.PeerAS)
+		}
+		peer.State = jsonEnumName(p.State.SessionState, bgpSessionStates)
+		peer.AdminState = jsonEnumName(p.State.AdminState, map[int]string{0: "up", 1: "down", 2: "pfx_ct"})
+	}
+	if p.Timers != nil && p.Timers.State != nil && p.Timers.State.Uptime != nil && p.Timers.State.Uptime.Seconds > 0 && peer.State == "established" {
+		peer.EstablishedAt = time.Unix(p.Timers.State.Uptime.Seconds, 0).UTC().Format(time.RFC3339)
+	}
+	for _, afiSafi := range p.AfiSafis {
+		if afiSafi.State == nil || afiSafi.State.Family == nil {
+			continue
+		}
+		peer.Families = append(peer.Families, bgpJSONFamily{
+			Family:     bgpFamilyName(afiSafi.State.Family.Afi, afiSafi.State.Family.Safi),
+			Received:   afiSafi.State.Received,
+			Accepted:   afiSafi.State.Accepted,
+			Advertised: afiSafi.State.Advertised,
+		})
+	}
+	return peer
+}
+
+// convertGoBGPPath converts a path of the JSON output of gobgp. The prefix of the destination
+// is used when the NLRI carries none.
+func convertGoBGPPath(prefix string, p gobgpJSONPath) bgpJSONRoute {
+	route := bgpJSONRoute{Prefix: p.Nlri.Prefix, Best: p.Best, Neighbor: p.NeighborIP, Age: p.Age, Stale: p.Stale}
+	if route.Prefix == "" {
+		route.Prefix = prefix
+	}
+	for _, attr := range p.Attrs {
+		switch attr.Type {
+		case bgpAttrOrigin:
+			var origin int
+			if json.Unmarshal(attr.Value, &origin) == nil {
+				route.Origin = bgpOrigins[origin]
+			}
+		case bgpAttrASPath:
+			for _, segment := range attr.ASPaths {
+				route.ASPath = append(route.ASPath, segment.ASNs...)
+			}
+		case bgpAttrNextHop:
+			route.NextHop = attr.NextHop
+		case bgpAttrMPReach:
+			if route.NextHop == "" {
+				route.NextHop = attr.NextHop
+			}
+		case bgpAttrMED:
+			route.MED = attr.Metric
+		case bgpAttrLocalPref:
+			var localPref uint32
+			if json.Unmarshal(attr.Value, &localPref) == nil {
+				route.LocalPref = &localPref
+			}
+		case bgpAttrCommunities:
+			for _, community := range attr.Communities {
+				route.Communities = append(route.Communities, fmt.Sprintf("%d:%d", community>>16, community&0xffff))
+			}
+		}
+	}
+	return route
+}
+
+// parseGoBGPJSON converts the JSON output of a gobgp command: the peers of 'neighbor', the paths
+// of the RIB commands, and the decoded JSON of any other command
+func parseGoBGPJSON(output string) (map[string]any, error) {
+	trimmed := strings.TrimSpace(output)
+	if trimmed == "" {
+		return nil, fmt.Errorf("empty output")
+	}
+
+	// 'neighbor' lists the peers, 'neighbor <ip>' returns one peer
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(trimmed), &objects); err == nil && len(objects) > 0 && peerFields(objects[0]) {
+		var peers []gobgpJSONPeer
+		if err := json.Unmarshal([]byte(trimmed), &peers); err != nil {
+			return nil, fmt.Errorf("invalid peers: %v", err)
+		}
+		converted := make([]bgpJSONPeer, 0, len(peers))
+		for _, peer := range peers {
+			converted = append(converted, convertGoBGPPeer(peer))
+		}
+		return map[string]any{"peers": converted}, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(trimmed), &fields); err == nil && peerFields(fields) {
+		var peer gobgpJSONPeer
+		if err := json.Unmarshal([]byte(trimmed), &peer); err != nil {
+			return nil, fmt.Errorf("invalid peer: %v", err)
+		}
+		return map[string]any{"peers": []bgpJSONPeer{convertGoBGPPeer(peer)}}, nil
+	}
+
+	// The RIB commands map every prefix to its paths
+	var rib map[string][]gobgpJSONPath
+	if fields != nil && ribKeys(fields) && json.Unmarshal([]byte(trimmed), &rib) == nil {
+		prefixes := make([]string, 0, len(rib))
+		for prefix := range rib {
+			prefixes = append(prefixes, prefix)
+		}
+		sort.Strings(prefixes)
+		routes := make([]bgpJSONRoute, 0, len(rib))
+		for _, prefix := range prefixes {
+			for _, path := range rib[prefix] {
+				routes = append(routes, convertGoBGPPath(prefix, path))
+			}
+		}
+		return map[string]any{"routes": routes}, nil
+	}
+
+	var data any
+	if err := json.Unmarshal([]byte(trimmed), &data); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	return map[string]any{"data": data}, nil
+}
+
+// peerFields reports whether a JSON object is a peer of 'gobgp neighbor -j'
+func peerFields(fields map[string]json.RawMessage) bool {
+	_, hasConf := fields["conf"]
+	_, hasState := fields["state"]
+	return hasConf || hasState
+}
+
+// ribKeys reports whether every key of a JSON object is a prefix, as in the output of the RIB commands
+func ribKeys(fields map[string]json.RawMessage) bool {
+	for key := range fields {
+		if _, err := netip.ParsePrefix(key); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// goBGPStructuredContent returns the structured content of a gobgp command run with the json
+// format: its parsed output. The other formats return nil rather than running the command a
+// second time with -j. It also returns nil for dry runs and unparsable output.
+func goBGPStructuredContent(ctx context.Context, output commandOutput, format string) map[string]any {
+	if format != outputFormatJSON || dryRunFromContext(ctx) != nil {
+		return nil
+	}
+	structured, err := parseGoBGPJSON(output.Output)
+	if err != nil {
+		log.Printf("Failed to parse the JSON output of gobgp %s on pod %s: %v", output.Command, output.Pod, err)
+		return nil
+	}
+	structured["command"] = "gobgp " + output.Command
+	structured["pod"] = output.Pod
+	if output.Node != "" {
+		structured["node"] = output.Node
+	}
+	return structured
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGoBGPJSON(t *testing.T) {
+	med := uint32(10)
+	localPref := uint32(100)
+
+	tests := []struct {
+		name    string
+		output  string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name: "neighbor list of gobgp v3",
+			output: `[{"conf":{"neighbor_address":"192.168.0.2","peer_asn":64512},
+				"state":{"neighbor_address":"192.168.0.2","peer_asn":64512,"session_state":6,"admin_state":0},
+				"timers":{"state":{"uptime":{"seconds":1700000000}}},
+				"afi_safis":[{"state":{"family":{"afi":1,"safi":1},"received":3,"accepted":3,"advertised":1}}]}]`,
+			want: map[string]any{"peers": []bgpJSONPeer{{
+				Address:       "192.168.0.2",
+				AS:            64512,
+				State:         "established",
+				AdminState:    "up",
+				EstablishedAt: "2023-11-14T22:13:20Z",
+				Families:      []bgpJSONFamily{{Family: "ipv4-unicast", Received: 3, Accepted: 3, Advertised: 1}},
+			}}},
+		},
+		{
+			name:   "single neighbor of gobgp v2 with string states",
+			output: `{"conf":{"neighbor_address":"fd00::2","peer_as":65000,"description":"tor"},"state":{"session_state":"ACTIVE","admin_state":"DOWN"}}`,
+			want: map[string]any{"peers": []bgpJSONPeer{{
+				Address:     "fd00::2",
+				AS:          65000,
+				Description: "tor",
+				State:       "active",
+				AdminState:  "down",
+			}}},
+		},
+		{
+			name: "global rib",
+			output: `{"10.0.1.0/26":[{"nlri":{"prefix":"10.0.1.0/26"},"age":1700000000,"best":true,"neighbor-ip":"192.168.0.2",
+				"attrs":[{"type":1,"value":0},{"type":2,"as_paths":[{"type":2,"num":1,"asns":[64512]}]},
+				{"type":3,"nexthop":"192.168.0.2"},{"type":4,"metric":10},{"type":5,"value":100},{"type":8,"communities":[4259840100]}]}],
+				"10.0.0.0/26":[{"nlri":{"prefix":"10.0.0.0/26"},"best":true,"attrs":[{"type":14,"nexthop":"0.0.0.0"}]}]}`,
+			want: map[string]any{"routes": []bgpJSONRoute{
+				{Prefix: "10.0.0.0/26", Best: true, NextHop: "0.0.0.0"},
+				{
+					Prefix:      "10.0.1.0/26",
+					Best:        true,
+					NextHop:     "192.168.0.2",
+					ASPath:      []uint32{64512},
+					Origin:      "igp",
+					MED:         &med,
+					LocalPref:   &localPref,
+					Communities: []string{"65000:100"},
+					Neighbor:    "192.168.0.2",
+					Age:         1700000000,
+				},
+			}},
+		},
+		{
+			name:   "other command",
+			output: `{"global":{"as":64512,"router_id":"192.168.0.1"}}`,
+			want:   map[string]any{"data": map[string]any{"global": map[string]any{"as": float64(64512), "router_id": "192.168.0.1"}}},
+		},
+		{
+			name:    "empty output",
+			output:  "  \n",
+			wantErr: true,
+		},
+		{
+			name:    "text output",
+			output:  "Network not in table",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGoBGPJSON(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGoBGPJSON() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseGoBGPJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
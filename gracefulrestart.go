@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// bgpRestartTimeRegexp matches the restart time of a graceful restart capability, e.g. "restart time 120 sec"
+var bgpRestartTimeRegexp = regexp.MustCompile(`restart time (\d+) sec`)
+
+// BGPGracefulRestartInput represents the input for reporting the graceful restart status of BGP peers
+type BGPGracefulRestartInput struct {
+	// PodName specifies the name of the Kubernetes pod running the agent container with gobgp
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// Neighbor restricts the report to one BGP neighbor (default: every neighbor of the pod)
+	Neighbor string `json:"neighbor,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// bgpRestartFamily is an address family of a graceful restart capability
+type bgpRestartFamily struct {
+	Family string `json:"family"`
+	// Forwarding reports whether the forwarding state of the family is preserved across a restart
+	Forwarding bool `json:"forwarding"`
+	// RestartTime is the stale routes time of a long-lived graceful restart family
+	RestartTime int `json:"restart_time,omitempty"`
+}
+
+// bgpRestartSide is the graceful restart capability sent by one side of a session
+type bgpRestartSide struct {
+	RestartTime  int                `json:"restart_time"`
+	Restarting   bool               `json:"restarting"`
+	Notification bool               `json:"notification"`
+	Families     []bgpRestartFamily `json:"families"`
+}
+
+// bgpRestartCapability is a graceful restart capability of a session, as negotiated by both sides
+type bgpRestartCapability struct {
+	// Support is "advertised and received", "advertised" or "received"
+	Support string          `json:"support"`
+	Local   *bgpRestartSide `json:"local,omitempty"`
+	Remote  *bgpRestartSide `json:"remote,omitempty"`
+}
+
+// bgpGracefulRestart is the graceful restart status of a BGP neighbor
+type bgpGracefulRestart struct {
+	Peer            string                `json:"peer"`
+	GracefulRestart *bgpRestartCapability `json:"graceful_restart,omitempty"`
+	LongLived       *bgpRestartCapability `json:"long_lived_graceful_restart,omitempty"`
+	Issues          []string              `json:"issues,omitempty"`
+}
+
+// parseBGPRestartFamily reads a family line of a capability, e.g. "ipv4-unicast, forward flag set"
+// or "ipv4-unicast, restart time 100 sec"
+func parseBGPRestartFamily(line string) bgpRestartFamily {
+	items := strings.Split(line, ",")
+	family := bgpRestartFamily{Family: strings.TrimSpace(items[0])}
+	for _, item := range items[1:] {
+		item = strings.TrimSpace(item)
+		if item == "forward flag set" {
+			family.Forwarding = true
+		} else if match := bgpRestartTimeRegexp.FindStringSubmatch(item); match != nil {
+			family.RestartTime, _ = strconv.Atoi(match[1])
+		}
+	}
+	return family
+}
+
+// parseBGPGracefulRestart reads the graceful-restart and long-lived-graceful-restart capabilities
+// of 'gobgp neighbor <ip>' output
+func parseBGPGracefulRestart(peer, output string) bgpGracefulRestart {
+	status := bgpGracefulRestart{Peer: peer}
+	var capability *bgpRestartCapability
+	var side *bgpRestartSide
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		name, value, hasColon := strings.Cut(line, ":")
+		switch {
+		case hasColon && name == "graceful-restart":
+			capability, side = &bgpRestartCapability{Support: strings.TrimSpace(value)}, nil
+			status.GracefulRestart = capability
+		case hasColon && name == "long-lived-graceful-restart":
+			capability, side = &bgpRestartCapability{Support: strings.TrimSpace(value)}, nil
+			status.LongLived = capability
+		case capability == nil:
+		case hasColon && (name == "Local" || name == "Remote"):
+			side = &bgpRestartSide{}
+			if name == "Local" {
+				capability.Local = side
+			} else {
+				capability.Remote = side
+			}
+			for _, item := range strings.Split(value, ",") {
+				switch item = strings.TrimSpace(item); {
+				case item == "restart flag set":
+					side.Restarting = true
+				case item == "notification flag set":
+					side.Notification = true
+				default:
+					if match := bgpRestartTimeRegexp.FindStringSubmatch(item); match != nil {
+						side.RestartTime, _ = strconv.Atoi(match[1])
+					}
+				}
+			}
+		case line != "" && !hasColon && side != nil:
+			side.Families = append(side.Families, parseBGPRestartFamily(line))
+		default:
+			// Any other line ends the capability
+			capability, side = nil, nil
+		}
+	}
+	return status
+}
+
+// restartFamilies returns the families of a capability side by name
+func restartFamilies(side *bgpRestartSide) map[string]bgpRestartFamily {
+	families := make(map[string]bgpRestartFamily)
+	if side != nil {
+		for _, family := range side.Families {
+			families[family.Family] = family
+		}
+	}
+	return families
+}
+
+// checkBGPGracefulRestart describes the graceful restart misconfigurations of a neighbor: a
+// capability advertised by one side only, families missing on one side, and forwarding state
+// the peer does not preserve
+func checkBGPGracefulRestart(status *bgpGracefulRestart) {
+	gr := status.GracefulRestart
+	switch {
+	case gr == nil:
+		status.Issues = append(status.Issues, "graceful restart is not enabled on either side: routes are withdrawn as soon as the session drops")
+		return
+	case gr.Support == "advertised":
+		status.Issues = append(status.Issues, "graceful restart is advertised by this node only: the peer does not retain the routes of the node when it restarts")
+	case gr.Support == "received":
+		status.Issues = append(status.Issues, "graceful restart is advertised by the peer only: enable it on this node to retain the routes of the peer when it restarts")
+	}
+	if gr.Local == nil || gr.Remote == nil {
+		return
+	}
+
+	local, remote := restartFamilies(gr.Local), restartFamilies(gr.Remote)
+	for _, family := range gr.Local.Families {
+		if _, ok := remote[family.Family]; !ok {
+			status.Issues = append(status.Issues, fmt.Sprintf("family %s has graceful restart on this node only", family.Family))
+		}
+	}
+	for _, family := range gr.Remote.Families {
+		if _, ok := local[family.Family]; !ok {
+			status.Issues = append(status.Issues, fmt.Sprintf("family %s has graceful restart on the peer only", family.Family))
+		} else if !family.Forwarding {
+			status.Issues = append(status.Issues, fmt.Sprintf("the peer does not preserve the forwarding state of %s across a restart", family.Family))
+		}
+	}
+	if gr.Remote.RestartTime == 0 && len(gr.Remote.Families) > 0 {
+		status.Issues = append(status.Issues, "the restart time of the peer is 0: the routes of the peer are not retained while it restarts")
+	}
+	if gr.Local.RestartTime != gr.Remote.RestartTime {
+		status.Issues = append(status.Issues, fmt.Sprintf("restart times differ: %d sec on this node, %d sec on the peer", gr.Local.RestartTime, gr.Remote.RestartTime))
+	}
+}
+
+// formatBGPRestartCapability describes a graceful restart capability
+func formatBGPRestartCapability(name string, capability *bgpRestartCapability) string {
+	if capability == nil {
+		return fmt.Sprintf("  %s: not enabled\n", name)
+	}
+	text := fmt.Sprintf("  %s: %s\n", name, capability.Support)
+	for _, side := range []struct {
+		label string
+		side  *bgpRestartSide
+	}{{"Local", capability.Local}, {"Remote", capability.Remote}} {
+		if side.side == nil {
+			continue
+		}
+		line := fmt.Sprintf("    %s:", side.label)
+		if side.side.RestartTime > 0 {
+			line += fmt.Sprintf(" restart time %d sec", side.side.RestartTime)
+		}
+		if side.side.Restarting {
+			line += " [restarting]"
+		}
+		if side.side.Notification {
+			line += " [notification]"
+		}
+		families := make([]string, 0, len(side.side.Families))
+		for _, family := range side.side.Families {
+			description := family.Family
+			if family.RestartTime > 0 {
+				description += fmt.Sprintf(" (%d sec)", family.RestartTime)
+			}
+			if family.Forwarding {
+				description += " (forwarding)"
+			}
+			families = append(families, description)
+		}
+		if len(families) > 0 {
+			line += " families " + strings.Join(families, ", ")
+		}
+		text += line + "\n"
+	}
+	return text
+}
+
+// handleBGPGracefulRestart reports the graceful restart capabilities and timers negotiated with
+// the BGP neighbors of a pod, so GR misconfigurations between Calico VPP and the ToRs stand out
+func (s *VPPMCPServer) handleBGPGracefulRestart(ctx context.Context, input BGPGracefulRestartInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received BGP graceful restart request for pod: %s, neighbor: %s", input.PodName, input.Neighbor)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: Pod name is required. Please specify the Kubernetes pod name or node name.",
+				},
+			},
+		}, nil, fmt.Errorf("pod name is required")
+	}
+
+	// Report the given neighbor, or every neighbor of the pod
+	neighbors := []string{input.Neighbor}
+	if input.Neighbor != "" {
+		if _, err := netip.ParseAddr(input.Neighbor); err != nil {
+			err = fmt.Errorf("invalid neighbor %q: must be an IP address", input.Neighbor)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	} else {
+		result, err := s.ExecutePodGoBGPCommand(ctx, input.PodName, "neighbor")
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error listing the BGP neighbors of pod %s: %v", input.PodName, err),
+					},
+				},
+			}, nil, err
+		}
+		neighbors = nil
+		for _, peer := range parseBGPNeighbors(result["output"].(string)) {
+			neighbors = append(neighbors, peer.Address)
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("BGP Graceful Restart Status of pod %s:\n\n", input.PodName))
+	var statuses []bgpGracefulRestart
+	issues := 0
+	for _, neighbor := range neighbors {
+		result, err := s.ExecutePodGoBGPCommand(ctx, input.PodName, "neighbor "+neighbor)
+		if err != nil {
+			text.WriteString(fmt.Sprintf("Neighbor %s: gobgp neighbor %s failed: %v\n\n", neighbor, neighbor, err))
+			continue
+		}
+		if dryRunFromContext(ctx) != nil {
+			continue
+		}
+		status := parseBGPGracefulRestart(neighbor, result["output"].(string))
+		checkBGPGracefulRestart(&status)
+		statuses = append(statuses, status)
+		issues += len(status.Issues)
+
+		text.WriteString(fmt.Sprintf("Neighbor %s:\n", neighbor))
+		text.WriteString(formatBGPRestartCapability("Graceful restart", status.GracefulRestart))
+		text.WriteString(formatBGPRestartCapability("Long-lived graceful restart", status.LongLived))
+		for _, issue := range status.Issues {
+			text.WriteString(fmt.Sprintf("  ! %s\n", issue))
+		}
+		text.WriteString("\n")
+	}
+	if len(neighbors) == 0 {
+		text.WriteString("No BGP neighbor found\n\n")
+	}
+	text.WriteString(fmt.Sprintf("Summary: %d neighbor(s), %d issue(s)\n", len(statuses), issues))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"pod":       input.PodName,
+			"neighbors": statuses,
+		},
+	}, nil, nil
+}
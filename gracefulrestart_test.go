@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const bgpNeighborCapabilities = `BGP neighbor is 192.168.0.2, remote AS 65000
+  BGP version 4, remote router ID 192.168.0.2
+  BGP state = BGP_FSM_ESTABLISHED, up for 00:10:23
+  Neighbor capabilities:
+    multiprotocol:
+        ipv4-unicast:	advertised and received
+        ipv6-unicast:	advertised and received
+    route-refresh:	advertised and received
+    graceful-restart:	advertised and received
+        Local: restart time 120 sec
+	    ipv4-unicast, forward flag set
+	    ipv6-unicast, forward flag set
+        Remote: restart time 90 sec, restart flag set, notification flag set
+	    ipv4-unicast, forward flag set
+	    ipv6-unicast
+    4-octet-as:	advertised and received
+    long-lived-graceful-restart:	advertised
+        Local:
+	    ipv4-unicast, restart time 3600 sec
+  Message statistics:
+`
+
+func TestParseBGPGracefulRestart(t *testing.T) {
+	want := bgpGracefulRestart{
+		Peer: "192.168.0.2",
+		GracefulRestart: &bgpRestartCapability{
+			Support: "advertised and received",
+			Local: &bgpRestartSide{RestartTime: 120, Families: []bgpRestartFamily{
+				{Family: "ipv4-unicast", Forwarding: true},
+				{Family: "ipv6-unicast", Forwarding: true},
+			}},
+			Remote: &bgpRestartSide{RestartTime: 90, Restarting: true, Notification: true, Families: []bgpRestartFamily{
+				{Family: "ipv4-unicast", Forwarding: true},
+				{Family: "ipv6-unicast"},
+			}},
+		},
+		LongLived: &bgpRestartCapability{
+			Support: "advertised",
+			Local: &bgpRestartSide{Families: []bgpRestartFamily{
+				{Family: "ipv4-unicast", RestartTime: 3600},
+			}},
+		},
+	}
+
+	if got := parseBGPGracefulRestart("192.168.0.2", bgpNeighborCapabilities); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBGPGracefulRestart() = %+v, want %+v", got, want)
+	}
+
+	if got := parseBGPGracefulRestart("192.168.0.3", "  Neighbor capabilities:\n    route-refresh:\tadvertised and received\n"); got.GracefulRestart != nil || got.LongLived != nil {
+		t.Errorf("parseBGPGracefulRestart() without the capability = %+v, want none", got)
+	}
+}
+
+func TestCheckBGPGracefulRestart(t *testing.T) {
+	side := func(restartTime int, families ...bgpRestartFamily) *bgpRestartSide {
+		return &bgpRestartSide{RestartTime: restartTime, Families: families}
+	}
+	ipv4 := bgpRestartFamily{Family: "ipv4-unicast", Forwarding: true}
+	ipv6 := bgpRestartFamily{Family: "ipv6-unicast", Forwarding: true}
+
+	tests := []struct {
+		name       string
+		capability *bgpRestartCapability
+		want       []string
+	}{
+		{
+			name:       "negotiated on both sides",
+			capability: &bgpRestartCapability{Support: "advertised and received", Local: side(120, ipv4), Remote: side(120, ipv4)},
+		},
+		{
+			name: "not enabled",
+			want: []string{"graceful restart is not enabled on either side: routes are withdrawn as soon as the session drops"},
+		},
+		{
+			name:       "advertised by this node only",
+			capability: &bgpRestartCapability{Support: "advertised", Local: side(120, ipv4)},
+			want:       []string{"graceful restart is advertised by this node only: the peer does not retain the routes of the node when it restarts"},
+		},
+		{
+			name:       "families and timers differ",
+			capability: &bgpRestartCapability{Support: "advertised and received", Local: side(120, ipv4), Remote: side(0, bgpRestartFamily{Family: "ipv4-unicast"}, ipv6)},
+			want: []string{
+				"the peer does not preserve the forwarding state of ipv4-unicast across a restart",
+				"family ipv6-unicast has graceful restart on the peer only",
+				"the restart time of the peer is 0: the routes of the peer are not retained while it restarts",
+				"restart times differ: 120 sec on this node, 0 sec on the peer",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &bgpGracefulRestart{Peer: "192.168.0.2", GracefulRestart: tt.capability}
+			checkBGPGracefulRestart(status)
+			if !reflect.DeepEqual(status.Issues, tt.want) {
+				t.Errorf("checkBGPGracefulRestart() issues = %q, want %q", status.Issues, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// vlibGraphSlotRegexp matches the next slot following a next node in 'show vlib graph' output, e.g. "[3]"
+var vlibGraphSlotRegexp = regexp.MustCompile(`^\[(\d+)\]$`)
+
+// VPPGraphInput represents the input for the node graph tool
+type VPPGraphInput struct {
+	VPPCommandInput
+	// Dot converts the graph to Graphviz DOT
+	Dot bool `json:"dot,omitempty"`
+}
+
+// vlibGraphNext is an arc of the node graph
+type vlibGraphNext struct {
+	Slot int    `json:"slot"`
+	Node string `json:"node"`
+}
+
+// vlibGraphNode is a node of the packet processing graph with its arcs
+type vlibGraphNode struct {
+	Name     string          `json:"name"`
+	Next     []vlibGraphNext `json:"next,omitempty"`
+	Previous []string        `json:"previous,omitempty"`
+}
+
+// parseVlibGraph parses 'show vlib graph' output into an adjacency list. A node starts on an
+// unindented line with its name, followed by its next nodes (with their slot) and previous
+// nodes, continued on the indented lines below it.
+func parseVlibGraph(output string) []*vlibGraphNode {
+	var nodes []*vlibGraphNode
+	var current *vlibGraphNode
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || (fields[0] == "Name" && len(fields) <= 3) {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			current = &vlibGraphNode{Name: fields[0]}
+			nodes = append(nodes, current)
+			fields = fields[1:]
+		}
+		if current == nil {
+			continue
+		}
+		for i := 0; i < len(fields); i++ {
+			if i+1 < len(fields) {
+				if match := vlibGraphSlotRegexp.FindStringSubmatch(fields[i+1]); match != nil {
+					slot, _ := strconv.Atoi(match[1])
+					current.Next = append(current.Next, vlibGraphNext{Slot: slot, Node: fields[i]})
+					i++
+					continue
+				}
+			}
+			current.Previous = append(current.Previous, fields[i])
+		}
+	}
+	return nodes
+}
+
+// formatVlibGraphDot renders the next arcs of the node graph as a Graphviz digraph.
+// Nodes without any arc, such as processes, are left out.
+func formatVlibGraphDot(nodes []*vlibGraphNode) string {
+	var dot strings.Builder
+	dot.WriteString("digraph vlib {\n")
+	for _, node := range nodes {
+		for _, next := range node.Next {
+			dot.WriteString(fmt.Sprintf("  %q -> %q [label=\"%d\"];\n", node.Name, next.Node, next.Slot))
+		}
+	}
+	dot.WriteString("}")
+	return dot.String()
+}
+
+// handleShowGraph shows the packet processing graph, optionally converted to DOT
+func (s *VPPMCPServer) handleShowGraph(ctx context.Context, input VPPGraphInput) (*mcp.CallToolResult, any, error) {
+	if input.Dot && input.Format == outputFormatJSON {
+		err := fmt.Errorf("dot and the json format are exclusive: the json format already returns the adjacency list")
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	return s.handleVPPCommandWithTransform(ctx, input.VPPCommandInput, "show vlib graph", "VPP Node Graph", func(output string) string {
+		if !input.Dot {
+			return output
+		}
+		return formatVlibGraphDot(parseVlibGraph(output))
+	})
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// grpcServiceName is the fully-qualified name of the tool service
+	grpcServiceName = "vppmcp.ToolService"
+
+	defaultGRPCListen    = "127.0.0.1"
+	defaultWatchInterval = 5 * time.Second
+	minWatchInterval     = 2 * time.Second
+
+	// jsonRPCInvalidParams is the JSON-RPC error code of the MCP server for unknown tools and
+	// arguments failing the input schema
+	jsonRPCInvalidParams = -32602
+)
+
+// watchExcludedTools lists the read-only tools not watched: they run for a long time
+var watchExcludedTools = map[string]bool{
+	"bgp_monitor":  true,
+	"bgp_mrt_dump": true,
+}
+
+// watchTool reports whether WatchTool can invoke a tool repeatedly: only the read-only show
+// and BGP tools can
+func watchTool(name string) bool {
+	group := toolGroups[name]
+	return (group == toolGroupShow || group == toolGroupBGP) && !watchExcludedTools[name]
+}
+
+// ToolInfo describes a tool of the MCP registry
+type ToolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema,omitempty"`
+}
+
+// ListToolsRequest is the request of ToolService/ListTools
+type ListToolsRequest struct{}
+
+// ListToolsResponse is the response of ToolService/ListTools
+type ListToolsResponse struct {
+	Tools []ToolInfo `json:"tools"`
+}
+
+// InvokeToolRequest is the request of ToolService/InvokeTool
+type InvokeToolRequest struct {
+	// Tool is the name of the MCP tool to invoke
+	Tool string `json:"tool"`
+	// Arguments holds the tool input as a JSON object
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// InvokeToolResponse is the response of ToolService/InvokeTool and a message of ToolService/WatchTool
+type InvokeToolResponse struct {
+	Text    string `json:"text"`
+	IsError bool   `json:"is_error,omitempty"`
+	// StructuredContent is the structured content of the tool result, if any
+	StructuredContent json.RawMessage `json:"structured_content,omitempty"`
+	Seq               int             `json:"seq,omitempty"`
+	Time              time.Time       `json:"time"`
+}
+
+// WatchToolRequest is the request of ToolService/WatchTool
+type WatchToolRequest struct {
+	InvokeToolRequest
+	// IntervalSeconds is the delay between two invocations (default: 5, min: 2)
+	IntervalSeconds int `json:"interval_seconds,omitempty"`
+	// Count stops the stream after that many invocations (0 streams until the client cancels)
+	Count int `json:"count,omitempty"`
+}
+
+// ToolServiceServer is the gRPC service mirroring the MCP tool registry, for
+// automation that doesn't speak MCP
+type ToolServiceServer interface {
+	ListTools(ctx context.Context, req *ListToolsRequest) (*ListToolsResponse, error)
+	InvokeTool(ctx context.Context, req *InvokeToolRequest) (*InvokeToolResponse, error)
+	WatchTool(req *WatchToolRequest, stream grpc.ServerStream) error
+}
+
+// jsonCodec encodes gRPC messages as JSON, so the service needs no generated protobuf code
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return "json" }
+
+// toolServiceDesc is the hand-written service descriptor of ToolService
+var toolServiceDesc = grpc.ServiceDesc{
+	ServiceName: grpcServiceName,
+	HandlerType: (*ToolServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListTools",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				req := new(ListToolsRequest)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req any) (any, error) {
+					return srv.(ToolServiceServer).ListTools(ctx, req.(*ListToolsRequest))
+				}
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + grpcServiceName + "/ListTools"}
+				return interceptor(ctx, req, info, handler)
+			},
+		},
+		{
+			MethodName: "InvokeTool",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				req := new(InvokeToolRequest)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req any) (any, error) {
+					return srv.(ToolServiceServer).InvokeTool(ctx, req.(*InvokeToolRequest))
+				}
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + grpcServiceName + "/InvokeTool"}
+				return interceptor(ctx, req, info, handler)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "WatchTool",
+			Handler: func(srv any, stream grpc.ServerStream) error {
+				req := new(WatchToolRequest)
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(ToolServiceServer).WatchTool(req, stream)
+			},
+			ServerStreams: true,
+		},
+	},
+}
+
+// grpcToolService implements ToolServiceServer on top of an in-memory MCP client session,
+// so every tool registered on the MCP server is reachable with the same schema and validation
+type grpcToolService struct {
+	session *mcp.ClientSession
+}
+
+// newGRPCToolService connects an in-memory MCP client to the server
+func newGRPCToolService(ctx context.Context, vppServer *VPPMCPServer) (*grpcToolService, error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := vppServer.server.Connect(ctx, serverTransport, nil); err != nil {
+		return nil, fmt.Errorf("failed to connect MCP server: %v", err)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "vpp-mcp-grpc", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect MCP client: %v", err)
+	}
+	return &grpcToolService{session: session}, nil
+}
+
+// ListTools returns the tools of the MCP registry
+func (g *grpcToolService) ListTools(ctx context.Context, req *ListToolsRequest) (*ListToolsResponse, error) {
+	result, err := g.session.ListTools(ctx, &mcp.ListToolsParams{})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list tools: %v", err)
+	}
+
+	resp := &ListToolsResponse{}
+	for _, tool := range result.Tools {
+		schema, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode schema of tool %s: %v", tool.Name, err)
+		}
+		resp.Tools = append(resp.Tools, ToolInfo{Name: tool.Name, Description: tool.Description, InputSchema: schema})
+	}
+	return resp, nil
+}
+
+// InvokeTool calls a tool by name. Tool failures are reported in the response, not as gRPC errors.
+func (g *grpcToolService) InvokeTool(ctx context.Context, req *InvokeToolRequest) (*InvokeToolResponse, error) {
+	if req.Tool == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "tool is required")
+	}
+	log.Printf("gRPC InvokeTool: %s", req.Tool)
+
+	params := &mcp.CallToolParams{Name: req.Tool}
+	if len(req.Arguments) > 0 {
+		params.Arguments = req.Arguments
+	}
+
+	result, err := g.session.CallTool(ctx, params)
+	if err != nil {
+		return nil, toolCallStatus(ctx, req.Tool, err)
+	}
+
+	var text strings.Builder
+	for _, content := range result.Content {
+		if textContent, ok := content.(*mcp.TextContent); ok {
+			text.WriteString(textContent.Text)
+		}
+	}
+	resp := &InvokeToolResponse{Text: text.String(), IsError: result.IsError, Time: time.Now()}
+	if result.StructuredContent != nil {
+		if resp.StructuredContent, err = json.Marshal(result.StructuredContent); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode structured content of tool %s: %v", req.Tool, err)
+		}
+	}
+	return resp, nil
+}
+
+// toolCallStatus maps the failure of an MCP tool call to a gRPC status: invalid arguments
+// for unknown tools and arguments rejected by the input schema, the context error for
+// cancelled and expired calls, unavailable once the MCP session is closed, internal otherwise
+func toolCallStatus(ctx context.Context, tool string, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case jsonRPCErrorCode(err) == jsonRPCInvalidParams:
+		return status.Errorf(codes.InvalidArgument, "failed to call tool %s: %v", tool, err)
+	case errors.Is(err, mcp.ErrConnectionClosed):
+		return status.Errorf(codes.Unavailable, "failed to call tool %s: %v", tool, err)
+	default:
+		return status.Errorf(codes.Internal, "failed to call tool %s: %v", tool, err)
+	}
+}
+
+// jsonRPCErrorCode returns the JSON-RPC error code carried by err, or 0. The wire error type
+// of the MCP SDK is internal, so the code is read from its JSON encoding.
+func jsonRPCErrorCode(err error) int64 {
+	for ; err != nil; err = errors.Unwrap(err) {
+		var wire struct {
+			Code int64 `json:"code"`
+		}
+		data, jsonErr := json.Marshal(err)
+		if jsonErr == nil && json.Unmarshal(data, &wire) == nil && wire.Code != 0 {
+			return wire.Code
+		}
+	}
+	return 0
+}
+
+// WatchTool invokes a read-only tool repeatedly and streams every result until the client
+// cancels or Count invocations have been sent
+func (g *grpcToolService) WatchTool(req *WatchToolRequest, stream grpc.ServerStream) error {
+	if req.Tool != "" && !watchTool(req.Tool) {
+		return status.Errorf(codes.InvalidArgument, "%s can't be watched: only the read-only show and BGP tools can", req.Tool)
+	}
+	interval := defaultWatchInterval
+	if req.IntervalSeconds != 0 {
+		interval = time.Duration(req.IntervalSeconds) * time.Second
+		if interval < minWatchInterval {
+			return status.Errorf(codes.InvalidArgument, "invalid interval_seconds %d: must be at least %d", req.IntervalSeconds, int(minWatchInterval/time.Second))
+		}
+	}
+
+	ctx := stream.Context()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for seq := 1; ; seq++ {
+		resp, err := g.InvokeTool(ctx, &req.InvokeToolRequest)
+		if err != nil {
+			return err
+		}
+		resp.Seq = seq
+		if err := stream.SendMsg(resp); err != nil {
+			return err
+		}
+		if seq == req.Count {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// grpcServiceConfig configures the listener and the security of the gRPC tool service
+type grpcServiceConfig struct {
+	// Listen is the address the service binds to, and Port its port
+	Listen string
+	Port   string
+	// TLSCertFile and TLSKeyFile serve the service over TLS when set
+	TLSCertFile string
+	TLSKeyFile  string
+	// Token is the bearer token clients send in the authorization metadata, when set
+	Token string
+}
+
+// loopbackAddress reports whether a listen address only accepts connections from the host
+func loopbackAddress(listen string) bool {
+	if listen == "localhost" {
+		return true
+	}
+	addr, err := netip.ParseAddr(listen)
+	return err == nil && addr.IsLoopback()
+}
+
+// serverOptions returns the gRPC server options of the configuration. The service runs any
+// tool the MCP server exposes, so it refuses to bind beyond the loopback interface without
+// TLS or a token.
+func (c grpcServiceConfig) serverOptions() ([]grpc.ServerOption, error) {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return nil, fmt.Errorf("--grpc-tls-cert and --grpc-tls-key must be set together")
+	}
+	if !loopbackAddress(c.Listen) && c.TLSCertFile == "" && c.Token == "" {
+		return nil, fmt.Errorf("refusing to serve gRPC on non-loopback address %q without TLS (--grpc-tls-cert, --grpc-tls-key) or a token (--grpc-token-file)", c.Listen)
+	}
+
+	options := []grpc.ServerOption{grpc.ForceServerCodec(jsonCodec{})}
+	if c.TLSCertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the gRPC TLS certificate: %v", err)
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+	if c.Token != "" {
+		options = append(options,
+			grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				if err := c.authorize(ctx); err != nil {
+					return nil, err
+				}
+				return handler(ctx, req)
+			}),
+			grpc.StreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				if err := c.authorize(stream.Context()); err != nil {
+					return err
+				}
+				return handler(srv, stream)
+			}))
+	}
+	return options, nil
+}
+
+// authorize checks the "authorization: Bearer <token>" metadata of a call
+func (c grpcServiceConfig) authorize(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if subtle.ConstantTimeCompare([]byte(value), []byte("Bearer "+c.Token)) == 1 {
+			return nil
+		}
+	}
+	return status.Errorf(codes.Unauthenticated, "missing or invalid bearer token")
+}
+
+// runGRPCServer serves the tool service on the configured address until the context is cancelled
+func runGRPCServer(ctx context.Context, vppServer *VPPMCPServer, config grpcServiceConfig) {
+	options, err := config.serverOptions()
+	if err != nil {
+		log.Fatalf("Failed to configure gRPC service: %v", err)
+	}
+
+	service, err := newGRPCToolService(ctx, vppServer)
+	if err != nil {
+		log.Fatalf("Failed to initialize gRPC service: %v", err)
+	}
+
+	address := net.JoinHostPort(config.Listen, config.Port)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC address %s: %v", address, err)
+	}
+
+	grpcServer := grpc.NewServer(options...)
+	grpcServer.RegisterService(&toolServiceDesc, service)
+
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+		if err := service.session.Close(); err != nil {
+			log.Printf("Error closing gRPC MCP session: %v", err)
+		}
+	}()
+
+	log.Printf("gRPC tool service listening on %s (TLS: %t, token: %t)", address, config.TLSCertFile != "", config.Token != "")
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Printf("gRPC server error: %v", err)
+	}
+}
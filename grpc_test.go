@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCServiceConfigServerOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  grpcServiceConfig
+		wantErr bool
+	}{
+		{name: "loopback ipv4", config: grpcServiceConfig{Listen: "127.0.0.1"}},
+		{name: "loopback ipv6", config: grpcServiceConfig{Listen: "::1"}},
+		{name: "localhost", config: grpcServiceConfig{Listen: "localhost"}},
+		{name: "every interface with a token", config: grpcServiceConfig{Listen: "0.0.0.0", Token: "secret"}},
+		{name: "every interface without security", config: grpcServiceConfig{Listen: "0.0.0.0"}, wantErr: true},
+		{name: "empty address without security", config: grpcServiceConfig{Listen: ""}, wantErr: true},
+		{name: "node address without security", config: grpcServiceConfig{Listen: "10.0.0.1"}, wantErr: true},
+		{name: "certificate without a key", config: grpcServiceConfig{Listen: "127.0.0.1", TLSCertFile: "server.crt"}, wantErr: true},
+		{name: "missing certificate files", config: grpcServiceConfig{Listen: "0.0.0.0", TLSCertFile: "missing.crt", TLSKeyFile: "missing.key"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.config.serverOptions()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("serverOptions() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWatchTool(t *testing.T) {
+	tests := map[string]bool{
+		"vpp_show_int":       true,
+		"bgp_show_neighbors": true,
+		"bgp_monitor":        false,
+		"vpp_trace":          false,
+		"vpp_clear_errors":   false,
+		"vpp_exec":           false,
+		"unknown_tool":       false,
+	}
+	for tool, want := range tests {
+		if got := watchTool(tool); got != want {
+			t.Errorf("watchTool(%q) = %t, want %t", tool, got, want)
+		}
+	}
+}
+
+// statusInput is the input of the tool of the gRPC status tests
+type statusInput struct {
+	Count int `json:"count"`
+}
+
+func TestGRPCInvokeTool(t *testing.T) {
+	ctx := context.Background()
+	s := NewVPPMCPServer(&fakeExecutor{})
+	s.server = mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	mcp.AddTool(s.server, &mcp.Tool{Name: "vpp_show_int"}, func(ctx context.Context, req *mcp.CallToolRequest, input statusInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, map[string]int{"count": input.Count}, nil
+	})
+
+	service, err := newGRPCToolService(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := service.InvokeTool(ctx, &InvokeToolRequest{Tool: "vpp_show_int", Arguments: json.RawMessage(`{"count": 3}`)})
+	if err != nil {
+		t.Fatalf("InvokeTool() error = %v", err)
+	}
+	if resp.Text != "ok" || string(resp.StructuredContent) != `{"count":3}` {
+		t.Errorf("InvokeTool() = %q with structured content %s, want ok with {\"count\":3}", resp.Text, resp.StructuredContent)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	failures := []struct {
+		name string
+		ctx  context.Context
+		req  *InvokeToolRequest
+		want codes.Code
+	}{
+		{"no tool", ctx, &InvokeToolRequest{}, codes.InvalidArgument},
+		{"unknown tool", ctx, &InvokeToolRequest{Tool: "vpp_show_nothing"}, codes.InvalidArgument},
+		{"arguments failing the schema", ctx, &InvokeToolRequest{Tool: "vpp_show_int", Arguments: json.RawMessage(`{"count": "three"}`)}, codes.InvalidArgument},
+		{"cancelled call", cancelled, &InvokeToolRequest{Tool: "vpp_show_int"}, codes.Canceled},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.InvokeTool(tt.ctx, tt.req)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("InvokeTool() error = %v, want code %s", err, tt.want)
+			}
+		})
+	}
+
+	if err := service.session.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.InvokeTool(ctx, &InvokeToolRequest{Tool: "vpp_show_int"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("InvokeTool() on a closed session error = %v, want Unavailable", err)
+	}
+
+	for _, req := range []*WatchToolRequest{
+		{InvokeToolRequest: InvokeToolRequest{Tool: "vpp_trace"}},
+		{InvokeToolRequest: InvokeToolRequest{Tool: "vpp_show_int"}, IntervalSeconds: 1},
+		{InvokeToolRequest: InvokeToolRequest{Tool: "vpp_show_int"}, IntervalSeconds: -5},
+	} {
+		if err := service.WatchTool(req, nil); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("WatchTool(%s every %ds) error = %v, want InvalidArgument", req.Tool, req.IntervalSeconds, err)
+		}
+	}
+}
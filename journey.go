@@ -0,0 +1,475 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultJourneyCount    = 50
+	maxJourneyCount        = 1000
+	defaultJourneyDuration = 30 * time.Second
+	maxJourneyDuration     = 5 * time.Minute
+
+	// Roles of the pods along a packet journey
+	journeyRoleSource      = "source"
+	journeyRoleGateway     = "gateway"
+	journeyRoleDestination = "destination"
+)
+
+// defaultJourneyInterfaces lists the interface types traced when none are specified:
+// pod-facing tuntap interfaces and the uplink
+var defaultJourneyInterfaces = []string{"virtio", "phy"}
+
+var (
+	// tracePacketRegexp matches the header of a packet in 'show trace' output
+	tracePacketRegexp = regexp.MustCompile(`^Packet (\d+)`)
+	// traceNodeRegexp matches a graph node line in 'show trace' output, e.g. "00:00:57:345443: ip4-input"
+	traceNodeRegexp = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}:\d+: (\S+)`)
+	// traceTOSRegexp matches the ToS / traffic class of a decoded IPv4 or IPv6 header
+	traceTOSRegexp = regexp.MustCompile(`\btos 0x([0-9a-fA-F]{2})`)
+	// traceFragmentIDRegexp matches the identification of a decoded IPv4 header
+	traceFragmentIDRegexp = regexp.MustCompile(`\bfragment id 0x([0-9a-fA-F]{4})`)
+)
+
+// PacketJourneyInput represents the input for cross-node packet journey correlation
+type PacketJourneyInput struct {
+	// SourceNode specifies the Kubernetes node the flow originates from
+	SourceNode string `json:"source_node"`
+	// DestinationNode specifies the Kubernetes node the flow is destined to
+	DestinationNode string `json:"destination_node"`
+	// GatewayPods lists intermediate calico-vpp pods traversed by the flow, in order
+	GatewayPods []string `json:"gateway_pods,omitempty"`
+	// SrcIP and DstIP specify the addresses of the flow
+	SrcIP string `json:"src_ip"`
+	DstIP string `json:"dst_ip"`
+	// Protocol specifies the IP protocol: tcp, udp or icmp
+	Protocol string `json:"protocol,omitempty"`
+	// SrcPort and DstPort specify the L4 ports of the flow (tcp and udp only)
+	SrcPort int `json:"src_port,omitempty"`
+	DstPort int `json:"dst_port,omitempty"`
+	// Interfaces specifies the interface types traced on every pod
+	Interfaces []string `json:"interfaces,omitempty"`
+	// Count specifies the number of packets to capture per pod
+	Count int `json:"count,omitempty"`
+	// DurationSeconds specifies how long the traces run
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pods
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// journeyHop is one calico-vpp pod traced along the journey
+type journeyHop struct {
+	Role    string
+	Pod     VPPPod
+	Packets []tracedPacket
+	Err     error
+}
+
+// tracedPacket is a packet of a VPP trace with the graph nodes it went through
+type tracedPacket struct {
+	Number string
+	Nodes  []string
+	// TOS holds the ToS byte of every IP header decoded along the path, in order
+	TOS []uint8
+	// IPIDs holds the identification of every IPv4 header decoded along the path, in order
+	IPIDs []uint16
+}
+
+// dropped reports whether the packet ended in a drop node
+func (p tracedPacket) dropped() bool {
+	if len(p.Nodes) == 0 {
+		return false
+	}
+	return isDropNode(p.Nodes[len(p.Nodes)-1])
+}
+
+// parseTracePackets splits 'show trace' output into packets and their graph node path
+func parseTracePackets(output string) []tracedPacket {
+	var packets []tracedPacket
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if match := tracePacketRegexp.FindStringSubmatch(line); match != nil {
+			packets = append(packets, tracedPacket{Number: match[1]})
+			continue
+		}
+		if len(packets) == 0 {
+			continue
+		}
+		current := &packets[len(packets)-1]
+		if match := traceNodeRegexp.FindStringSubmatch(line); match != nil {
+			current.Nodes = append(current.Nodes, match[1])
+		}
+		for _, match := range traceTOSRegexp.FindAllStringSubmatch(line, -1) {
+			if tos, err := strconv.ParseUint(match[1], 16, 8); err == nil {
+				current.TOS = append(current.TOS, uint8(tos))
+			}
+		}
+		for _, match := range traceFragmentIDRegexp.FindAllStringSubmatch(line, -1) {
+			if id, err := strconv.ParseUint(match[1], 16, 16); err == nil {
+				current.IPIDs = append(current.IPIDs, uint16(id))
+			}
+		}
+	}
+	return packets
+}
+
+// buildFlowClassifier returns the arguments of 'classify filter trace' matching the flow
+func buildFlowClassifier(input PacketJourneyInput) (string, error) {
+	src, dst := net.ParseIP(input.SrcIP), net.ParseIP(input.DstIP)
+	if src == nil || dst == nil {
+		return "", fmt.Errorf("src_ip and dst_ip must be valid IP addresses")
+	}
+	if (src.To4() == nil) != (dst.To4() == nil) {
+		return "", fmt.Errorf("src_ip and dst_ip must be of the same address family")
+	}
+
+	family := "ip4"
+	if src.To4() == nil {
+		family = "ip6"
+	}
+
+	mask := []string{"l3", family, "src", "dst"}
+	match := []string{"l3", family, "src", src.String(), "dst", dst.String()}
+
+	var proto int
+	switch strings.ToLower(input.Protocol) {
+	case "":
+	case "tcp":
+		proto = 6
+	case "udp":
+		proto = 17
+	case "icmp":
+		proto = 1
+		if family == "ip6" {
+			proto = 58
+		}
+	default:
+		return "", fmt.Errorf("invalid protocol: %s. Use tcp, udp or icmp", input.Protocol)
+	}
+	if proto != 0 {
+		mask = append(mask, "proto")
+		match = append(match, "proto", fmt.Sprintf("%d", proto))
+	}
+
+	if input.SrcPort != 0 || input.DstPort != 0 {
+		if proto != 6 && proto != 17 {
+			return "", fmt.Errorf("src_port and dst_port require protocol tcp or udp")
+		}
+		mask = append(mask, "l4")
+		match = append(match, "l4")
+		if input.SrcPort != 0 {
+			mask = append(mask, "src_port")
+			match = append(match, "src_port", fmt.Sprintf("%d", input.SrcPort))
+		}
+		if input.DstPort != 0 {
+			mask = append(mask, "dst_port")
+			match = append(match, "dst_port", fmt.Sprintf("%d", input.DstPort))
+		}
+	}
+
+	return fmt.Sprintf("mask %s match %s", strings.Join(mask, " "), strings.Join(match, " ")), nil
+}
+
+// captureTrace runs a trace on one pod and returns the captured packets. The trace is
+// restricted to packets matching classifier, unless it is empty.
+func (s *VPPMCPServer) captureTrace(ctx context.Context, pod, classifier string, inputNodes []string, count int, duration time.Duration) ([]tracedPacket, error) {
+	unlock, err := s.lockCapture(ctx, pod)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return s.captureTraceLocked(ctx, pod, classifier, inputNodes, count, duration)
+}
+
+// captureTraceLocked is captureTrace for callers already holding the capture lock of the pod
+func (s *VPPMCPServer) captureTraceLocked(ctx context.Context, pod, classifier string, inputNodes []string, count int, duration time.Duration) ([]tracedPacket, error) {
+	if _, err := s.ExecutePodVPPCommand(ctx, pod, "clear trace"); err != nil {
+		return nil, fmt.Errorf("clear trace failed: %v", err)
+	}
+	// Clean up even when the call is cancelled, so the trace state doesn't leak into later traces
+	defer func() {
+		_, _ = s.ExecutePodVPPCommand(context.WithoutCancel(ctx), pod, "clear trace")
+	}()
+
+	filter := ""
+	if classifier != "" {
+		filterCmd := "classify filter trace " + classifier
+		if _, err := s.ExecutePodVPPCommand(ctx, pod, filterCmd); err != nil {
+			return nil, fmt.Errorf("installing the trace filter failed: %v", err)
+		}
+		defer func() {
+			_, _ = s.ExecutePodVPPCommand(context.WithoutCancel(ctx), pod, filterCmd+" del")
+		}()
+		filter = " filter"
+	}
+
+	for _, inputNode := range inputNodes {
+		if _, err := s.ExecutePodVPPCommand(ctx, pod, fmt.Sprintf("trace add %s %d%s", inputNode, count, filter)); err != nil {
+			return nil, fmt.Errorf("trace add %s failed: %v", inputNode, err)
+		}
+	}
+
+	if dryRunFromContext(ctx) == nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(duration):
+		}
+	}
+
+	result, err := s.ExecutePodVPPCommand(ctx, pod, fmt.Sprintf("show trace max %d", count))
+	if err != nil {
+		return nil, fmt.Errorf("show trace failed: %v", err)
+	}
+	return parseTracePackets(result["output"].(string)), nil
+}
+
+// formatJourneyReport stitches the per-pod traces into one report ordered along the journey
+func formatJourneyReport(input PacketJourneyInput, classifier string, hops []*journeyHop, inputNodes []string, duration time.Duration) string {
+	var text strings.Builder
+	text.WriteString("Packet Journey Report:\n\n")
+	text.WriteString(fmt.Sprintf("Flow: %s -> %s", input.SrcIP, input.DstIP))
+	if input.Protocol != "" {
+		text.WriteString(fmt.Sprintf(" (%s", strings.ToLower(input.Protocol)))
+		if input.SrcPort != 0 || input.DstPort != 0 {
+			text.WriteString(fmt.Sprintf(" %d -> %d", input.SrcPort, input.DstPort))
+		}
+		text.WriteString(")")
+	}
+	text.WriteString("\n\n")
+
+	reached := false
+	for i, hop := range hops {
+		text.WriteString(fmt.Sprintf("Hop %d - %s: node %s, pod %s\n", i+1, hop.Role, hop.Pod.Node, hop.Pod.Name))
+		if hop.Err != nil {
+			text.WriteString(fmt.Sprintf("  Trace failed: %v\n\n", hop.Err))
+			continue
+		}
+		if len(hop.Packets) == 0 {
+			text.WriteString("  No matching packets captured\n\n")
+			continue
+		}
+
+		drops := 0
+		for _, packet := range hop.Packets {
+			status := ""
+			if packet.dropped() {
+				drops++
+				status = " [DROPPED]"
+			}
+			text.WriteString(fmt.Sprintf("  Packet %s: %s%s\n", packet.Number, strings.Join(packet.Nodes, " -> "), status))
+		}
+		text.WriteString(fmt.Sprintf("  Captured: %d packets, %d dropped\n\n", len(hop.Packets), drops))
+		if strings.HasSuffix(hop.Role, journeyRoleDestination) && drops < len(hop.Packets) {
+			reached = true
+		}
+	}
+
+	text.WriteString("Summary:\n")
+	if reached {
+		text.WriteString("- The flow reached the destination node\n")
+	} else {
+		// Point at the last hop that saw the flow
+		lastSeen := -1
+		for i, hop := range hops {
+			if len(hop.Packets) > 0 {
+				lastSeen = i
+			}
+		}
+		if lastSeen < 0 {
+			text.WriteString("- No hop captured the flow. Check the 5-tuple and the traced interfaces\n")
+		} else {
+			text.WriteString(fmt.Sprintf("- The flow was last seen at hop %d (%s, node %s) and did not reach the destination\n",
+				lastSeen+1, hops[lastSeen].Role, hops[lastSeen].Pod.Node))
+		}
+	}
+
+	text.WriteString(fmt.Sprintf("\nCapture Parameters:\n- Classifier: %s\n- VPP Input Nodes: %s\n- Count: %d per pod\n- Capture Duration: %s\n",
+		classifier, strings.Join(inputNodes, ", "), input.Count, duration))
+	return text.String()
+}
+
+// handlePacketJourney traces a flow on every pod along its path at the same time and correlates the traces
+func (s *VPPMCPServer) handlePacketJourney(ctx context.Context, input PacketJourneyInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received packet journey request: %s -> %s (%s -> %s)", input.SrcIP, input.DstIP, input.SourceNode, input.DestinationNode)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.SourceNode == "" || input.DestinationNode == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: source_node and destination_node are required.",
+				},
+			},
+		}, nil, fmt.Errorf("source_node and destination_node are required")
+	}
+
+	classifier, err := buildFlowClassifier(input)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	if input.Count == 0 {
+		input.Count = defaultJourneyCount
+	}
+	duration := defaultJourneyDuration
+	if input.DurationSeconds > 0 {
+		duration = time.Duration(input.DurationSeconds) * time.Second
+	}
+	switch {
+	case input.Count < 0 || input.Count > maxJourneyCount:
+		err = fmt.Errorf("invalid count %d: must be between 1 and %d", input.Count, maxJourneyCount)
+	case input.DurationSeconds < 0 || input.DurationSeconds > int(maxJourneyDuration.Seconds()):
+		err = fmt.Errorf("invalid duration_seconds %d: must be between 1 and %d", input.DurationSeconds, int(maxJourneyDuration.Seconds()))
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	interfaces := input.Interfaces
+	if len(interfaces) == 0 {
+		interfaces = defaultJourneyInterfaces
+	}
+
+	k8sClient, err := newKubeClient()
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Map every interface type to its VPP input node, skipping duplicates
+	var inputNodes []string
+	seen := make(map[string]bool)
+	for _, iface := range interfaces {
+		inputNode, _, err := mapInterfaceTypeToVppInputNode(k8sClient, iface)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error mapping interface: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		if !seen[inputNode] {
+			seen[inputNode] = true
+			inputNodes = append(inputNodes, inputNode)
+		}
+	}
+
+	// Resolve the calico-vpp pod of every hop
+	pods, err := listVPPPods(ctx, k8sClient)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	podByNode := make(map[string]VPPPod)
+	podByName := make(map[string]VPPPod)
+	for _, pod := range pods {
+		podByNode[pod.Node] = pod
+		podByName[pod.Name] = pod
+	}
+
+	source, ok := podByNode[input.SourceNode]
+	if !ok {
+		err := fmt.Errorf("no calico-vpp pod found on source node %s", input.SourceNode)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	destination, ok := podByNode[input.DestinationNode]
+	if !ok {
+		err := fmt.Errorf("no calico-vpp pod found on destination node %s", input.DestinationNode)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Trace every pod once, so all traces cover the same window
+	hops := []*journeyHop{{Role: journeyRoleSource, Pod: source}}
+	traced := map[string]bool{source.Name: true, destination.Name: true}
+	for _, name := range input.GatewayPods {
+		if traced[name] {
+			continue
+		}
+		traced[name] = true
+		gateway, ok := podByName[name]
+		if !ok {
+			err := fmt.Errorf("gateway pod %s is not a calico-vpp pod", name)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		hops = append(hops, &journeyHop{Role: journeyRoleGateway, Pod: gateway})
+	}
+	if destination.Name != source.Name {
+		hops = append(hops, &journeyHop{Role: journeyRoleDestination, Pod: destination})
+	} else {
+		hops[0].Role = journeyRoleSource + "/" + journeyRoleDestination
+	}
+
+	// Trace all hops at the same time so they observe the same packets
+	var wg sync.WaitGroup
+	for _, hop := range hops {
+		wg.Add(1)
+		go func(hop *journeyHop) {
+			defer wg.Done()
+			hop.Packets, hop.Err = s.captureTrace(ctx, hop.Pod.Name, classifier, inputNodes, input.Count, duration)
+		}(hop)
+	}
+	wg.Wait()
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: formatJourneyReport(input, classifier, hops, inputNodes, duration),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestBuildFlowClassifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   PacketJourneyInput
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "addresses only",
+			input: PacketJourneyInput{SrcIP: "10.0.0.1", DstIP: "10.0.0.2"},
+			want:  "mask l3 ip4 src dst match l3 ip4 src 10.0.0.1 dst 10.0.0.2",
+		},
+		{
+			name:  "tcp with both ports",
+			input: PacketJourneyInput{SrcIP: "10.0.0.1", DstIP: "10.0.0.2", Protocol: "TCP", SrcPort: 1234, DstPort: 80},
+			want:  "mask l3 ip4 src dst proto l4 src_port dst_port match l3 ip4 src 10.0.0.1 dst 10.0.0.2 proto 6 l4 src_port 1234 dst_port 80",
+		},
+		{
+			name:  "udp with the destination port only",
+			input: PacketJourneyInput{SrcIP: "10.0.0.1", DstIP: "10.0.0.2", Protocol: "udp", DstPort: 53},
+			want:  "mask l3 ip4 src dst proto l4 dst_port match l3 ip4 src 10.0.0.1 dst 10.0.0.2 proto 17 l4 dst_port 53",
+		},
+		{
+			name:  "icmp over ipv6 is protocol 58",
+			input: PacketJourneyInput{SrcIP: "2001:db8::1", DstIP: "2001:db8::2", Protocol: "icmp"},
+			want:  "mask l3 ip6 src dst proto match l3 ip6 src 2001:db8::1 dst 2001:db8::2 proto 58",
+		},
+		{
+			name:    "invalid address",
+			input:   PacketJourneyInput{SrcIP: "10.0.0", DstIP: "10.0.0.2"},
+			wantErr: true,
+		},
+		{
+			name:    "mixed address families",
+			input:   PacketJourneyInput{SrcIP: "10.0.0.1", DstIP: "2001:db8::2"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown protocol",
+			input:   PacketJourneyInput{SrcIP: "10.0.0.1", DstIP: "10.0.0.2", Protocol: "sctp"},
+			wantErr: true,
+		},
+		{
+			name:    "ports without tcp or udp",
+			input:   PacketJourneyInput{SrcIP: "10.0.0.1", DstIP: "10.0.0.2", Protocol: "icmp", DstPort: 80},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildFlowClassifier(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildFlowClassifier() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("buildFlowClassifier() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTracePackets(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []tracedPacket
+	}{
+		{
+			name:   "empty trace",
+			output: "------------------- Start of thread 0 vpp_main -------------------\nNo packets in trace buffer\n",
+			want:   nil,
+		},
+		{
+			name: "packets with their node path, ToS and IPv4 identification",
+			output: `------------------- Start of thread 1 vpp_wk_0 -------------------
+Packet 1
+
+00:01:02:123456: virtio-input
+  virtio: hw_if_index 3 next-index 4 vring 0 len 98
+00:01:02:123470: ip4-input
+  ICMP: 10.0.0.1 -> 10.0.0.2
+    tos 0x00, ttl 64, length 84, checksum 0x1234 dscp CS0 ecn NON_ECN
+    fragment id 0x1f2e
+00:01:02:123480: ip4-lookup
+  fib 0 dpo-idx 5 flow hash: 0x00000000
+00:01:02:123490: ip4-rewrite
+    tos 0xb8, ttl 63, length 84, checksum 0x1235 dscp EF ecn NON_ECN
+    fragment id 0x1f2e
+
+Packet 2
+
+00:01:02:223456: virtio-input
+  virtio: hw_if_index 3 next-index 4 vring 0 len 98
+00:01:02:223470: ip4-drop
+  ip4-input: ip4 ttl <= 1
+`,
+			want: []tracedPacket{
+				{Number: "1", Nodes: []string{"virtio-input", "ip4-input", "ip4-lookup", "ip4-rewrite"}, TOS: []uint8{0x00, 0xb8}, IPIDs: []uint16{0x1f2e, 0x1f2e}},
+				{Number: "2", Nodes: []string{"virtio-input", "ip4-drop"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTracePackets(tt.output)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTracePackets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlePacketJourneyLimits(t *testing.T) {
+	s := NewVPPMCPServer(&fakeExecutor{})
+
+	for _, limits := range []struct{ count, duration int }{
+		{maxJourneyCount + 1, 0},
+		{-1, 0},
+		{0, int(maxJourneyDuration.Seconds()) + 1},
+		{0, -1},
+	} {
+		input := PacketJourneyInput{
+			SourceNode:      "worker-1",
+			DestinationNode: "worker-2",
+			SrcIP:           "10.0.0.1",
+			DstIP:           "10.0.0.2",
+			Count:           limits.count,
+			DurationSeconds: limits.duration,
+		}
+		if _, _, err := s.handlePacketJourney(context.Background(), input); err == nil {
+			t.Errorf("handlePacketJourney() with count %d and duration_seconds %d succeeded", limits.count, limits.duration)
+		}
+	}
+}
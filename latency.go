@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultLatencyProbeCount = 5
+	maxLatencyProbeCount     = 20
+	// latencyProbeTimeoutMargin is added to the count seconds 'vppctl ping' takes, one probe
+	// per second, to form the default timeout of a ping
+	latencyProbeTimeoutMargin = 5 * time.Second
+)
+
+var (
+	// pingReplyRegexp matches the RTT of a reply in 'vppctl ping' output, e.g. "time=.2034 ms"
+	pingReplyRegexp = regexp.MustCompile(`time=([0-9.]+) ms`)
+	// pingStatsRegexp matches the summary line of 'vppctl ping' output
+	pingStatsRegexp = regexp.MustCompile(`Statistics: (\d+) sent, (\d+) received`)
+)
+
+// LatencyProbeInput represents the input for latency probing between calico-vpp pods
+type LatencyProbeInput struct {
+	// SourceNode restricts the probes to those sent from this node
+	SourceNode string `json:"source_node,omitempty"`
+	// DestinationNode restricts the probes to those sent to this node
+	DestinationNode string `json:"destination_node,omitempty"`
+	// Count specifies the number of pings per pair (1 to 20)
+	Count int `json:"count,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pods
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// latencyResult is the outcome of pinging one node from another
+type latencyResult struct {
+	Sent     int
+	Received int
+	Min      float64
+	Avg      float64
+	Max      float64
+	Err      error
+}
+
+// loss returns the packet loss in percent
+func (r latencyResult) loss() float64 {
+	if r.Sent == 0 {
+		return 100
+	}
+	return float64(r.Sent-r.Received) * 100 / float64(r.Sent)
+}
+
+// parsePingOutput extracts the RTT statistics from 'vppctl ping' output
+func parsePingOutput(output string, count int) latencyResult {
+	result := latencyResult{Sent: count}
+	var total float64
+	for _, match := range pingReplyRegexp.FindAllStringSubmatch(output, -1) {
+		rtt, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			continue
+		}
+		if result.Received == 0 || rtt < result.Min {
+			result.Min = rtt
+		}
+		if rtt > result.Max {
+			result.Max = rtt
+		}
+		total += rtt
+		result.Received++
+	}
+	if result.Received > 0 {
+		result.Avg = total / float64(result.Received)
+	}
+
+	// Prefer the counters reported by VPP when present
+	if match := pingStatsRegexp.FindStringSubmatch(output); match != nil {
+		result.Sent, _ = strconv.Atoi(match[1])
+		result.Received, _ = strconv.Atoi(match[2])
+	}
+	return result
+}
+
+// handleLatencyProbe pings the uplink address of every selected node from the VPP of every
+// other selected node and reports an RTT/loss matrix
+func (s *VPPMCPServer) handleLatencyProbe(ctx context.Context, input LatencyProbeInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received latency probe request: %s -> %s", input.SourceNode, input.DestinationNode)
+
+	count := input.Count
+	if count <= 0 {
+		count = defaultLatencyProbeCount
+	}
+	if count > maxLatencyProbeCount {
+		count = maxLatencyProbeCount
+	}
+
+	// vppctl ping sends one probe per second, so unless the call sets its own timeout each
+	// ping gets at least count seconds plus a margin
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+	if input.TimeoutSeconds <= 0 {
+		if timeout := time.Duration(count)*time.Second + latencyProbeTimeoutMargin; timeout > s.commandTimeout(ctx, defaultVPPCommandTimeout) {
+			ctx = context.WithValue(ctx, execTimeoutKey{}, timeout)
+		}
+	}
+
+	k8sClient, err := newKubeClient()
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	pods, err := listVPPPods(ctx, k8sClient)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Node < pods[j].Node })
+
+	// calico-vpp pods run in the host network, so the pod IP is the node uplink address
+	var sources, destinations []VPPPod
+	for _, pod := range pods {
+		if pod.IP == "" {
+			continue
+		}
+		if input.SourceNode == "" || pod.Node == input.SourceNode {
+			sources = append(sources, pod)
+		}
+		if input.DestinationNode == "" || pod.Node == input.DestinationNode {
+			destinations = append(destinations, pod)
+		}
+	}
+	if len(sources) == 0 || len(destinations) == 0 {
+		err := fmt.Errorf("no calico-vpp pod with an address found for the selected nodes")
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Probe from every source in parallel, one destination at a time per source
+	results := make(map[string]map[string]latencyResult)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source VPPPod) {
+			defer wg.Done()
+			for _, destination := range destinations {
+				if destination.Name == source.Name {
+					continue
+				}
+				var result latencyResult
+				output, err := s.ExecutePodVPPCommand(ctx, source.Name, fmt.Sprintf("ping %s repeat %d", destination.IP, count))
+				if err != nil {
+					result = latencyResult{Sent: count, Err: err}
+				} else {
+					result = parsePingOutput(output["output"].(string), count)
+				}
+
+				mu.Lock()
+				if results[source.Node] == nil {
+					results[source.Node] = make(map[string]latencyResult)
+				}
+				results[source.Node][destination.Node] = result
+				mu.Unlock()
+			}
+		}(source)
+	}
+	wg.Wait()
+
+	var text strings.Builder
+	text.WriteString("VPP Uplink Latency Matrix (avg RTT / loss, rows ping columns):\n\n")
+	text.WriteString("| source \\ destination |")
+	for _, destination := range destinations {
+		text.WriteString(fmt.Sprintf(" %s |", destination.Node))
+	}
+	text.WriteString("\n|---|")
+	text.WriteString(strings.Repeat("---|", len(destinations)))
+	text.WriteString("\n")
+
+	var lossy, failed []string
+	for _, source := range sources {
+		text.WriteString(fmt.Sprintf("| %s |", source.Node))
+		for _, destination := range destinations {
+			result, ok := results[source.Node][destination.Node]
+			switch {
+			case !ok:
+				text.WriteString(" - |")
+			case result.Err != nil:
+				text.WriteString(" error |")
+				failed = append(failed, fmt.Sprintf("%s -> %s: %v", source.Node, destination.Node, result.Err))
+			default:
+				text.WriteString(fmt.Sprintf(" %.3f ms / %.0f%% |", result.Avg, result.loss()))
+				if result.loss() > 0 {
+					lossy = append(lossy, fmt.Sprintf("%s -> %s (%s): %d/%d received, rtt min/avg/max %.3f/%.3f/%.3f ms",
+						source.Node, destination.Node, destination.IP, result.Received, result.Sent, result.Min, result.Avg, result.Max))
+				}
+			}
+		}
+		text.WriteString("\n")
+	}
+
+	text.WriteString("\nSummary:\n")
+	if len(lossy) == 0 && len(failed) == 0 {
+		text.WriteString("- No loss between the probed nodes: the underlay is unlikely to explain overlay symptoms\n")
+	}
+	for _, line := range lossy {
+		text.WriteString(fmt.Sprintf("- Loss %s\n", line))
+	}
+	for _, line := range failed {
+		text.WriteString(fmt.Sprintf("- Probe failed %s\n", line))
+	}
+
+	text.WriteString(fmt.Sprintf("\nProbe Parameters:\n- Pings per pair: %d\n- Sources: %d\n- Destinations: %d\n", count, len(sources), len(destinations)))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// defaultContainerLogTail and maxContainerLogTail bound the container log lines fetched by a call
+	defaultContainerLogTail = 1000
+	maxContainerLogTail     = 20000
+)
+
+// vppLogLevels lists the VPP log levels from the most to the least severe, as printed by
+// 'show logging'. "error" and "warning" are accepted as aliases of "err" and "warn".
+var vppLogLevels = []string{"emerg", "alert", "crit", "err", "warn", "notice", "info", "debug"}
+
+var (
+	// logEntryRegexp matches the first line of a 'show logging' entry, e.g.
+	// "2024/05/02 10:11:12:345 warn       linux-cp/nl    message"
+	logEntryRegexp = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \S+ +(\S+) +(\S+)`)
+	// logClassRegexp matches a log class or class/subclass, e.g. "dpdk" or "linux-cp/nl"
+	logClassRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*(/[a-z0-9][a-z0-9_-]*)?$`)
+)
+
+// VPPLogsInput represents the input for the VPP logs tool
+type VPPLogsInput struct {
+	VPPCommandInput
+	// Class restricts the entries to a log class, e.g. "dpdk" or "linux-cp/nl"
+	Class string `json:"class,omitempty"`
+	// Level restricts the entries to this severity and above
+	Level string `json:"level,omitempty"`
+	// Tail keeps only the last entries
+	Tail int `json:"tail,omitempty"`
+}
+
+// logLevelRank returns the position of level in vppLogLevels, or -1 for an unknown level
+func logLevelRank(level string) int {
+	switch level {
+	case "error":
+		level = "err"
+	case "warning":
+		level = "warn"
+	}
+	for i, known := range vppLogLevels {
+		if level == known {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateLogClass checks that class is a log class name
+func validateLogClass(class string) error {
+	if !logClassRegexp.MatchString(class) {
+		return fmt.Errorf("invalid log class %q: expected e.g. dpdk or linux-cp/nl", class)
+	}
+	return nil
+}
+
+// filterLogEntries keeps the 'show logging' entries of class with a severity of at least
+// maxRank (-1 keeps every level), then the last tail of them (0 keeps all). A class without
+// a subclass also matches its subclasses. Lines that don't start an entry belong to the
+// previous one.
+func filterLogEntries(output, class string, maxRank, tail int) string {
+	var entries [][]string
+	keep := false
+	for _, line := range strings.Split(output, "\n") {
+		match := logEntryRegexp.FindStringSubmatch(line)
+		if match == nil {
+			if keep && len(entries) > 0 {
+				entries[len(entries)-1] = append(entries[len(entries)-1], line)
+			}
+			continue
+		}
+
+		rank := logLevelRank(match[1])
+		keep = (maxRank < 0 || (rank >= 0 && rank <= maxRank)) &&
+			(class == "" || match[2] == class || strings.HasPrefix(match[2], class+"/"))
+		if keep {
+			entries = append(entries, []string{line})
+		}
+	}
+
+	if tail > 0 && len(entries) > tail {
+		entries = entries[len(entries)-tail:]
+	}
+	if len(entries) == 0 {
+		return "(no log entry matched the class/level filter)"
+	}
+
+	var lines []string
+	for _, entry := range entries {
+		lines = append(lines, entry...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleGetLogs shows the VPP logs, filtered by class and minimum severity
+func (s *VPPMCPServer) handleGetLogs(ctx context.Context, input VPPLogsInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received logs request for pod: %s, class: %s, level: %s, tail: %d", input.PodName, input.Class, input.Level, input.Tail)
+
+	var err error
+	maxRank := -1
+	if input.Class != "" {
+		err = validateLogClass(input.Class)
+	}
+	if err == nil && input.Level != "" {
+		if maxRank = logLevelRank(input.Level); maxRank < 0 {
+			err = fmt.Errorf("invalid level %q. Use one of %s", input.Level, strings.Join(vppLogLevels, ", "))
+		}
+	}
+	if err == nil && input.Tail < 0 {
+		err = fmt.Errorf("invalid tail %d: must not be negative", input.Tail)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	return s.handleVPPCommandWithTransform(ctx, input.VPPCommandInput, "show logging", "VPP Logs", func(output string) string {
+		if input.Class == "" && maxRank < 0 && input.Tail == 0 {
+			return output
+		}
+		return filterLogEntries(output, input.Class, maxRank, input.Tail)
+	})
+}
+
+// ContainerLogsInput represents the input for the container logs tools
+type ContainerLogsInput struct {
+	// PodName specifies the name of the Kubernetes calico-vpp pod
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// Tail keeps the last lines of the logs
+	Tail int `json:"tail,omitempty"`
+	// Since keeps the lines written in the last duration, e.g. 10m or 1h
+	Since string `json:"since,omitempty"`
+	// Grep keeps the lines matching this regular expression
+	Grep string `json:"grep,omitempty"`
+	// Previous returns the logs of the previous instance of a restarted container
+	Previous bool `json:"previous,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of the log request
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the command the call would execute without running it
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// fetchContainerLogs returns the logs of a container of the pod. Dry runs record the command
+// and return no log.
+func (s *VPPMCPServer) fetchContainerLogs(ctx context.Context, podName, container string, options logOptions) (string, error) {
+	if recorder := dryRunFromContext(ctx); recorder != nil {
+		recorder.record(s.executor.LogsCommandLine(podName, container, options))
+		return "", nil
+	}
+
+	release, err := s.acquireExecSlot(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	logsCtx, cancel := context.WithTimeout(ctx, s.commandTimeout(ctx, defaultGoBGPCommandTimeout))
+	defer cancel()
+	output, err := s.executor.Logs(logsCtx, podName, container, options)
+	return strings.TrimRight(string(output), "\n"), err
+}
+
+// handleGetContainerLogs shows the stdout logs of a container of a calico-vpp pod. The agent
+// container logs most Calico VPP control plane errors (BGP, IPAM, CNI, policies), the vpp
+// container the startup errors and crashes that 'show logging' misses.
+func (s *VPPMCPServer) handleGetContainerLogs(ctx context.Context, input ContainerLogsInput, container, commandDescription string) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received %s request for pod: %s, tail: %d, since: %s, grep: %s", commandDescription, input.PodName, input.Tail, input.Since, input.Grep)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: Pod name is required. Please specify the Kubernetes pod name or node name.",
+				},
+			},
+		}, nil, fmt.Errorf("pod name is required")
+	}
+
+	options := logOptions{Tail: input.Tail, Previous: input.Previous}
+	if options.Tail == 0 {
+		options.Tail = defaultContainerLogTail
+	}
+	err := validatePodName(input.PodName)
+	if err == nil && (input.Tail < 0 || input.Tail > maxContainerLogTail) {
+		err = fmt.Errorf("invalid tail %d: must be between 1 and %d", input.Tail, maxContainerLogTail)
+	}
+	if err == nil && input.Since != "" {
+		if options.Since, err = time.ParseDuration(input.Since); err != nil || options.Since <= 0 {
+			err = fmt.Errorf("invalid since %q: expected a duration such as 10m or 1h", input.Since)
+		}
+	}
+	var grep *regexp.Regexp
+	if err == nil {
+		grep, err = compileOutputFilter(input.Grep)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	commandLine := s.executor.LogsCommandLine(input.PodName, container, options)
+	logs, err := s.fetchContainerLogs(ctx, input.PodName, container, options)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error fetching the %s container logs of pod %s: %v\nCommand attempted: %s", container, input.PodName, err, commandLine),
+				},
+			},
+		}, nil, err
+	}
+	if dryRunFromContext(ctx) != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("%s: the logs of the %s container of pod %s would be fetched\n", commandDescription, container, input.PodName),
+				},
+			},
+		}, nil, nil
+	}
+
+	if logs == "" {
+		logs = "(no log line)"
+	} else {
+		logs = filterOutput(logs, grep)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("%s:\n\n%s\n\nCommand executed: %s\nPod: %s (container: %s)%s", commandDescription, logs, commandLine, input.PodName, container, describeOutputFilter(grep)),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestFilterLogEntries(t *testing.T) {
+	output := `2024/05/02 10:11:12:345 notice     dpdk           EAL init done
+2024/05/02 10:11:13:001 warn       linux-cp/nl    netlink socket overrun
+  resyncing the routes
+2024/05/02 10:11:14:500 err        linux-cp       failed to create the tap
+2024/05/02 10:11:15:000 info       dpdk/cryptodev no crypto device
+2024/05/02 10:11:16:000 debug      linux-cp/nl    route added`
+
+	tests := []struct {
+		name    string
+		class   string
+		maxRank int
+		tail    int
+		want    string
+	}{
+		{
+			name:    "class and its subclasses",
+			class:   "linux-cp",
+			maxRank: -1,
+			want: `2024/05/02 10:11:13:001 warn       linux-cp/nl    netlink socket overrun
+  resyncing the routes
+2024/05/02 10:11:14:500 err        linux-cp       failed to create the tap
+2024/05/02 10:11:16:000 debug      linux-cp/nl    route added`,
+		},
+		{
+			name:    "subclass only",
+			class:   "dpdk/cryptodev",
+			maxRank: -1,
+			want:    "2024/05/02 10:11:15:000 info       dpdk/cryptodev no crypto device",
+		},
+		{
+			name:    "warnings and above",
+			maxRank: logLevelRank("warning"),
+			want: `2024/05/02 10:11:13:001 warn       linux-cp/nl    netlink socket overrun
+  resyncing the routes
+2024/05/02 10:11:14:500 err        linux-cp       failed to create the tap`,
+		},
+		{
+			name:    "last entries",
+			maxRank: -1,
+			tail:    2,
+			want: `2024/05/02 10:11:15:000 info       dpdk/cryptodev no crypto device
+2024/05/02 10:11:16:000 debug      linux-cp/nl    route added`,
+		},
+		{
+			name:    "no match",
+			class:   "ikev2",
+			maxRank: -1,
+			want:    "(no log entry matched the class/level filter)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterLogEntries(output, tt.class, tt.maxRank, tt.tail); got != tt.want {
+				t.Errorf("filterLogEntries() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -19,85 +22,197 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-// ExecutePodVPPCommand runs a VPP command directly on a specified Kubernetes pod
-func ExecutePodVPPCommand(ctx context.Context, podName, command string) (map[string]interface{}, error) {
-	// Use hardcoded defaults
-	namespace := "calico-vpp-dataplane"
-	containerName := "vpp"
+const (
+	// Default timeouts of a single vppctl and gobgp command
+	defaultVPPCommandTimeout   = 10 * time.Second
+	defaultGoBGPCommandTimeout = 30 * time.Second
 
-	// Build kubectl exec command
-	cmdArgs := []string{
-		"exec",
-		"-n", namespace,
-		podName,
-		"-c", containerName,
+	// Default of the largest timeout_seconds a tool call may request
+	defaultMaxExecTimeout = 5 * time.Minute
+
+	// timeoutParameterLine documents the timeout_seconds parameter of tools running commands in a pod
+	timeoutParameterLine        = "- timeout_seconds: Timeout of each command run in the pod, in seconds, at most server --max-exec-timeout (default: server --exec-timeout)"
+	timeoutParameterDescription = "\n\nOptional parameters:\n" + timeoutParameterLine + "\n" + dryRunParameterLine
+
+	// containerParameterLine documents the container parameter of tools running a single command
+	containerParameterLine = "- container: Container the command runs in, for Calico VPP variants naming it differently (default: vpp for VPP tools, agent for BGP tools)"
+
+	// filterParameterLine documents the filter parameter of VPP show tools
+	filterParameterLine = "- filter: Regular expression applied line-wise to the output; only matching lines are returned (e.g. 'drop|error')"
+)
+
+// execTimeoutKey is the context key of the per-call exec timeout
+type execTimeoutKey struct{}
+
+// withExecTimeout returns a context carrying the exec timeout requested by a tool call.
+// Seconds are clamped before the conversion so huge values can't overflow the duration.
+func withExecTimeout(ctx context.Context, seconds int) context.Context {
+	if seconds <= 0 {
+		return ctx
 	}
+	timeout := time.Duration(math.MaxInt64)
+	if int64(seconds) < int64(timeout/time.Second) {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	return context.WithValue(ctx, execTimeoutKey{}, timeout)
+}
 
-	// Add the vppctl command
-	cmdArgs = append(cmdArgs, "--", "vppctl")
+// newExecTimeoutMiddleware returns a middleware rejecting tool calls whose timeout_seconds
+// exceeds max, so a single call can't hold an exec slot indefinitely (0 disables the limit)
+func newExecTimeoutMiddleware(max time.Duration) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+			if method != "tools/call" || !ok || max <= 0 {
+				return next(ctx, method, req)
+			}
 
-	// Add the specific VPP command arguments
-	cmdArgs = append(cmdArgs, strings.Fields(command)...)
+			var target struct {
+				TimeoutSeconds int `json:"timeout_seconds"`
+			}
+			// Malformed arguments are reported by the tool handler itself
+			_ = json.Unmarshal(params.Arguments, &target)
+			// Compare in seconds, as huge values would overflow the duration
+			if target.TimeoutSeconds <= int(max/time.Second) {
+				return next(ctx, method, req)
+			}
 
-	// Execute the command with a timeout
-	log.Printf("Executing command: kubectl %s", strings.Join(cmdArgs, " "))
+			err := fmt.Errorf("invalid timeout_seconds %d: must be at most %d (server --max-exec-timeout)", target.TimeoutSeconds, int(max/time.Second))
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+	}
+}
 
-	// Set a timeout for the command
-	cmdCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+// execContainerKey is the context key of the per-call container override
+type execContainerKey struct{}
 
-	cmd := exec.CommandContext(cmdCtx, "kubectl", cmdArgs...)
+// withExecContainer returns a context carrying the container requested by a tool call
+func withExecContainer(ctx context.Context, container string) context.Context {
+	if container == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, execContainerKey{}, container)
+}
 
-	// Capture stdout and stderr separately
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// execContainer returns the container commands run in: the per-call override if set, otherwise fallback
+func execContainer(ctx context.Context, fallback string) string {
+	if container, ok := ctx.Value(execContainerKey{}).(string); ok {
+		return container
+	}
+	return fallback
+}
 
-	log.Printf("Starting command execution...")
-	execErr := cmd.Run()
-	log.Printf("Command completed with status: %v", execErr == nil)
+// commandTimeout returns the timeout of a single command: the per-call timeout if set,
+// capped at the server maximum, otherwise the server default, otherwise the given fallback
+func (s *VPPMCPServer) commandTimeout(ctx context.Context, fallback time.Duration) time.Duration {
+	if timeout, ok := ctx.Value(execTimeoutKey{}).(time.Duration); ok {
+		if s.maxExecTimeout > 0 && timeout > s.maxExecTimeout {
+			return s.maxExecTimeout
+		}
+		return timeout
+	}
+	if s.execTimeout > 0 {
+		return s.execTimeout
+	}
+	return fallback
+}
 
-	// Get the output
-	output := stdout.Bytes()
-	errOutput := stderr.String()
+// ExecutePodVPPCommand runs a VPP command directly on a specified Kubernetes pod
+func (s *VPPMCPServer) ExecutePodVPPCommand(ctx context.Context, podName, command string) (map[string]interface{}, error) {
+	namespace := vppNamespace
 
-	if errOutput != "" {
-		log.Printf("Command stderr: %s", errOutput)
+	// Reject malformed pod names and injected commands before anything is executed
+	if err := validateExecRequest(podName, command); err != nil {
+		return map[string]interface{}{
+			"success":   false,
+			"error":     err.Error(),
+			"pod":       podName,
+			"namespace": namespace,
+			"command":   command,
+		}, err
 	}
 
-	err := execErr
+	container := execContainer(ctx, vppContainerName)
+
+	// Record the command instead of running it on dry runs
+	if recorder := dryRunFromContext(ctx); recorder != nil {
+		recorder.record(s.executor.CommandLine(podName, container, s.executor.VPPArgs(command)))
+		return map[string]interface{}{
+			"success":   true,
+			"output":    "",
+			"command":   command,
+			"pod":       podName,
+			"namespace": namespace,
+			"container": container,
+		}, nil
+	}
 
+	release, err := s.acquireExecSlot(ctx)
 	if err != nil {
-		errorMsg := ""
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			errorMsg = string(exitErr.Stderr)
-		}
 		return map[string]interface{}{
 			"success":   false,
-			"error":     fmt.Sprintf("%v - %s", err, errorMsg),
+			"error":     err.Error(),
 			"pod":       podName,
 			"namespace": namespace,
 			"command":   command,
 		}, err
 	}
+	defer release()
+
+	// Set a timeout for the command
+	cmdCtx, cancel := context.WithTimeout(ctx, s.commandTimeout(ctx, defaultVPPCommandTimeout))
+	defer cancel()
+
+	output, err := s.executor.ExecVPP(cmdCtx, podName, command)
+	if err != nil {
+		return map[string]interface{}{
+			"success":   false,
+			"error":     err.Error(),
+			"pod":       podName,
+			"namespace": namespace,
+			"command":   command,
+		}, newExecError(ctx, cmdCtx, errorCodeVPPCtlError, "vppctl "+command, err)
+	}
 	return map[string]interface{}{
 		"success":   true,
-		"output":    string(output),
+		"output":    output,
 		"command":   command,
 		"pod":       podName,
 		"namespace": namespace,
-		"container": containerName,
+		"container": container,
 	}, nil
 }
 
 const kubeClientTimeout = 30 * time.Second
 
+const (
+	// defaultVPPNamespace is the namespace used by manifest-based Calico VPP installs
+	defaultVPPNamespace = "calico-vpp-dataplane"
+	// defaultVPPPodSelector matches the pods of the calico-vpp-node daemonset
+	defaultVPPPodSelector = "k8s-app=calico-vpp-node"
+)
+
+// candidateVPPNamespaces lists the namespaces probed at startup, in order of preference.
+// Operator-based installs put the daemonset in calico-system.
+var candidateVPPNamespaces = []string{defaultVPPNamespace, "calico-system"}
+
+// vppNamespace is the namespace running the calico-vpp pods, resolved once at startup
+var vppNamespace = defaultVPPNamespace
+
+// vppPodSelector is the label selector identifying calico-vpp pods
+var vppPodSelector = defaultVPPPodSelector
+
 // KubeClient wraps Kubernetes client for VPP operations
 type KubeClient struct {
 	clientset *kubernetes.Clientset
+	config    *rest.Config
 	timeout   time.Duration
 }
 
@@ -122,7 +237,78 @@ func newKubeClient() (*KubeClient, error) {
 		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
 	}
 
-	return &KubeClient{clientset: clientset, timeout: kubeClientTimeout}, nil
+	return &KubeClient{clientset: clientset, config: config, timeout: kubeClientTimeout}, nil
+}
+
+// detectVPPNamespace probes the candidate namespaces and returns the first one
+// containing pods that match the given label selector
+func detectVPPNamespace(k *KubeClient, selector string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), k.timeout)
+	defer cancel()
+
+	for _, namespace := range candidateVPPNamespaces {
+		pods, err := k.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector, Limit: 1})
+		if err != nil {
+			log.Printf("Failed to list pods in namespace %s: %v", namespace, err)
+			continue
+		}
+		if len(pods.Items) > 0 {
+			return namespace, nil
+		}
+	}
+
+	return "", fmt.Errorf("no pods matching %q found in namespaces %s", selector, strings.Join(candidateVPPNamespaces, ", "))
+}
+
+// VPPPod identifies a calico-vpp pod and the node it is scheduled on
+type VPPPod struct {
+	Name string
+	Node string
+	IP   string
+}
+
+// listVPPPods returns the calico-vpp pods matching the pod selector in the detected namespace
+func listVPPPods(ctx context.Context, k *KubeClient) ([]VPPPod, error) {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+
+	pods, err := k.clientset.CoreV1().Pods(vppNamespace).List(ctx, metav1.ListOptions{LabelSelector: vppPodSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calico-vpp pods: %v", err)
+	}
+
+	vppPods := make([]VPPPod, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		vppPods = append(vppPods, VPPPod{Name: pod.Name, Node: pod.Spec.NodeName, IP: pod.Status.PodIP})
+	}
+	return vppPods, nil
+}
+
+// initVPPNamespace resolves the namespace used for every tool call. An explicit
+// namespace always wins; otherwise the candidates are probed and the default is
+// kept if detection fails.
+func initVPPNamespace(namespace, selector string) {
+	vppPodSelector = selector
+	if namespace != "" {
+		vppNamespace = namespace
+		log.Printf("Using configured namespace: %s", vppNamespace)
+		return
+	}
+
+	k8sClient, err := newKubeClient()
+	if err != nil {
+		log.Printf("Namespace detection skipped, using default %s: %v", vppNamespace, err)
+		return
+	}
+
+	detected, err := detectVPPNamespace(k8sClient, selector)
+	if err != nil {
+		log.Printf("Namespace detection failed, using default %s: %v", vppNamespace, err)
+		return
+	}
+
+	vppNamespace = detected
+	log.Printf("Detected calico-vpp namespace: %s", vppNamespace)
 }
 
 // getVppDriverFromConfigMap retrieves the vppDriver from the calico-vpp-config ConfigMap
@@ -130,7 +316,7 @@ func getVppDriverFromConfigMap(k *KubeClient) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), k.timeout)
 	defer cancel()
 
-	configMap, err := k.clientset.CoreV1().ConfigMaps("calico-vpp-dataplane").Get(ctx, "calico-vpp-config", metav1.GetOptions{})
+	configMap, err := k.clientset.CoreV1().ConfigMaps(vppNamespace).Get(ctx, calicoVPPConfigMap, metav1.GetOptions{})
 	if err != nil {
 		return "", fmt.Errorf("failed to get calico-vpp-config ConfigMap: %v", err)
 	}
@@ -168,6 +354,9 @@ func getVppDriverFromConfigMap(k *KubeClient) (string, error) {
 func mapInterfaceTypeToVppInputNode(k *KubeClient, interfaceType string) (string, string, error) {
 	switch interfaceType {
 	case "phy":
+		if k == nil {
+			return "", "", fmt.Errorf("interface type phy requires Kubernetes access to read calico-vpp-config")
+		}
 		// Get the actual VPP driver from the ConfigMap
 		actualDriver, err := getVppDriverFromConfigMap(k)
 		if err != nil {
@@ -250,209 +439,421 @@ func parseVppInterfaces(output string) []string {
 	return upInterfaces
 }
 
+// parseVppInterfaceCounters parses the output of "vppctl show interface" and returns
+// the counters of every interface, keyed by interface name then counter name
+func parseVppInterfaceCounters(output string) map[string]map[string]uint64 {
+	counters := make(map[string]map[string]uint64)
+	current := ""
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+
+		// Interface lines start at column 0: "name idx state mtu [counter... value]"
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			if len(fields) < 4 || fields[0] == "Name" {
+				current = ""
+				continue
+			}
+			if _, err := strconv.Atoi(fields[1]); err != nil {
+				current = ""
+				continue
+			}
+			current = fields[0]
+			counters[current] = make(map[string]uint64)
+			fields = fields[4:]
+		}
+
+		// Counter lines (or the tail of an interface line): "counter name... value"
+		if current == "" || len(fields) < 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[len(fields)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		counters[current][strings.Join(fields[:len(fields)-1], " ")] = value
+	}
+
+	return counters
+}
+
 // VPPCommandInput represents the generic input for VPP command tools
 type VPPCommandInput struct {
 	// PodName specifies the name of the Kubernetes pod running VPP
-	PodName string `json:"pod_name"`
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// SnapshotID specifies a stored snapshot to read the output from instead of the live pod
+	SnapshotID string `json:"snapshot_id,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+	// Filter is a regular expression selecting the output lines to return
+	Filter string `json:"filter,omitempty"`
+	// Format selects the output format: raw, markdown (default) or json
+	Format string `json:"format,omitempty"`
+	// Container overrides the container the command runs in (default: vpp)
+	Container string `json:"container,omitempty"`
+}
+
+// VPPVersionInput represents the input for the version tool
+type VPPVersionInput struct {
+	VPPCommandInput
+	// Verbose adds the build details and the command line VPP was started with
+	Verbose bool `json:"verbose,omitempty"`
 }
 
 // VPPCaptureInput represents the input for VPP packet capture tools (trace, pcap, dispatch)
 type VPPCaptureInput struct {
 	// PodName specifies the name of the Kubernetes pod running VPP
-	PodName string `json:"pod_name"`
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
 	// Count specifies the number of packets to capture (default: run for 30 seconds)
 	Count int `json:"count,omitempty"`
 	// Interface specifies the interface type or name to capture from
 	Interface string `json:"interface,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// VPPTraceInput represents the input for the VPP trace tool
+type VPPTraceInput struct {
+	VPPCaptureInput
+	// FilterInclude keeps only the packets visiting this graph node
+	FilterInclude string `json:"filter_include,omitempty"`
+	// FilterExclude drops the packets visiting this graph node
+	FilterExclude string `json:"filter_exclude,omitempty"`
+	// Thread keeps only the packets traced on this VPP thread (0 is the main thread)
+	Thread *int `json:"thread,omitempty"`
+	// RxInterface keeps only the packets traced on the worker polling this interface
+	RxInterface string `json:"rx_interface,omitempty"`
+	// RxQueue specifies the queue of RxInterface (default: 0)
+	RxQueue int `json:"rx_queue,omitempty"`
+	// PodIP keeps only the packets traced on the worker polling the interface of the workload pod with this IP
+	PodIP string `json:"pod_ip,omitempty"`
 }
 
 // VPPFIBInput represents the input for VPP FIB tools requiring fib_index
 type VPPFIBInput struct {
 	// PodName specifies the name of the Kubernetes pod running VPP
-	PodName string `json:"pod_name"`
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
 	// FibIndex specifies the FIB table index
 	FibIndex string `json:"fib_index"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+	// Format selects the output format: raw, markdown (default) or json
+	Format string `json:"format,omitempty"`
 }
 
 // VPPFIBPrefixInput represents the input for VPP FIB tools requiring fib_index and prefix
 type VPPFIBPrefixInput struct {
 	// PodName specifies the name of the Kubernetes pod running VPP
-	PodName string `json:"pod_name"`
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
 	// FibIndex specifies the FIB table index
 	FibIndex string `json:"fib_index"`
 	// Prefix specifies the IP prefix to query
 	Prefix string `json:"prefix"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+	// Format selects the output format: raw, markdown (default) or json
+	Format string `json:"format,omitempty"`
 }
 
 // BGPCommandInput represents the input for BGP command tools
 type BGPCommandInput struct {
 	// PodName specifies the name of the Kubernetes pod running the agent container with gobgp
-	PodName string `json:"pod_name"`
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// SnapshotID specifies a stored snapshot to read the output from instead of the live pod
+	SnapshotID string `json:"snapshot_id,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+	// Format selects the output format: raw, markdown (default) or json
+	Format string `json:"format,omitempty"`
+	// Container overrides the container the command runs in (default: agent)
+	Container string `json:"container,omitempty"`
 }
 
 // BGPParameterCommandInput represents the input for BGP command tools that require a parameter (IP, prefix, or neighbor IP)
 type BGPParameterCommandInput struct {
 	// PodName specifies the name of the Kubernetes pod running the agent container with gobgp
-	PodName string `json:"pod_name"`
-	// Parameter specifies the parameter value (IP address, prefix, or neighbor IP)
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// Parameter specifies the parameter value (IP address, prefix, or neighbor IP). Neighbor
+	// commands also accept the Kubernetes node name of the neighbor.
 	Parameter string `json:"parameter"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+	// Format selects the output format: raw, markdown (default) or json
+	Format string `json:"format,omitempty"`
 }
 
 // EmptyInput represents tools that don't require any input parameters
 type EmptyInput struct{}
 
+// GetPodsInput represents the input for the vpp_get_pods tool
+type GetPodsInput struct {
+	// DryRun returns the command the call would execute without running it
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
 // VPPMCPServer implements the MCP server for VPP debugging
 type VPPMCPServer struct {
-	server *mcp.Server
+	server    *mcp.Server
+	executor  Executor
+	artifacts *ArtifactStore
+	// execTimeout is the default timeout of a single command (0 uses the per-command defaults)
+	execTimeout time.Duration
+	// maxExecTimeout caps the per-call timeout of a single command (0 for no cap)
+	maxExecTimeout time.Duration
+	// rawVPPPrefixes lists the command prefixes allowed by vpp_exec
+	rawVPPPrefixes [][]string
+	// execSlots bounds the commands running at the same time (nil for no bound)
+	execSlots chan struct{}
+	// captureLocks serializes the captures on each pod
+	captureLocks *podLocks
+	// captures tracks the background capture jobs
+	captures *captureJobs
 }
 
-// NewVPPMCPServer creates a new VPP MCP server
-func NewVPPMCPServer() *VPPMCPServer {
-	return &VPPMCPServer{}
+// NewVPPMCPServer creates a new VPP MCP server running commands through the given executor
+func NewVPPMCPServer(executor Executor) *VPPMCPServer {
+	return &VPPMCPServer{executor: executor, captureLocks: newPodLocks(), captures: newCaptureJobs()}
 }
 
 // ExecutePodGoBGPCommand runs a gobgp command directly on a specified Kubernetes pod
-func ExecutePodGoBGPCommand(ctx context.Context, podName, command string) (map[string]interface{}, error) {
+func (s *VPPMCPServer) ExecutePodGoBGPCommand(ctx context.Context, podName, command string) (map[string]interface{}, error) {
 	if podName == "" {
 		return nil, fmt.Errorf("pod name is required")
 	}
 
-	namespace := "calico-vpp-dataplane"
+	namespace := vppNamespace
+
+	// Reject malformed pod names and injected commands before anything is executed
+	if err := validateExecRequest(podName, command); err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"pod":     podName,
+			"command": command,
+		}, err
+	}
+
+	container := execContainer(ctx, agentContainerName)
+
+	// Record the command instead of running it on dry runs
+	if recorder := dryRunFromContext(ctx); recorder != nil {
+		recorder.record(s.executor.CommandLine(podName, container, gobgpArgs(command)))
+		return map[string]interface{}{
+			"success":   true,
+			"output":    "",
+			"command":   command,
+			"node":      "",
+			"pod":       podName,
+			"container": container,
+		}, nil
+	}
 
 	// Get the node name for the pod
 	nodeName := ""
-	k8sClient, err := newKubeClient()
-	if err == nil {
-		pod, err := k8sClient.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if !s.bypassesKubernetes() {
+		k8sClient, err := newKubeClient()
 		if err == nil {
-			nodeName = pod.Spec.NodeName
+			pod, err := k8sClient.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+			if err == nil {
+				nodeName = pod.Spec.NodeName
+			}
 		}
 	}
 
-	// Build kubectl command to execute in the agent container
-	cmdArgs := []string{
-		"exec",
-		"-n", namespace,
-		"-c", "agent", // Use the agent container
-		podName,
-		"--",
-		"gobgp",
+	release, err := s.acquireExecSlot(ctx)
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"node":    nodeName,
+			"pod":     podName,
+			"command": command,
+		}, err
 	}
-
-	// Add the specific gobgp command arguments
-	cmdArgs = append(cmdArgs, strings.Fields(command)...)
-
-	// Execute the command with a timeout
-	log.Printf("Executing command: kubectl %s", strings.Join(cmdArgs, " "))
+	defer release()
 
 	// Set a timeout for the command
-	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	cmdCtx, cancel := context.WithTimeout(ctx, s.commandTimeout(ctx, defaultGoBGPCommandTimeout))
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "kubectl", cmdArgs...)
-
-	// Capture stdout and stderr separately
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	log.Printf("Starting command execution...")
-	execErr := cmd.Run()
-	log.Printf("Command completed with status: %v", execErr == nil)
-
-	// Get the output
-	output := stdout.Bytes()
-	errOutput := stderr.String()
-
-	if errOutput != "" {
-		log.Printf("Command stderr: %s", errOutput)
-	}
-
+	output, execErr := s.executor.ExecGoBGP(cmdCtx, podName, command)
 	if execErr != nil {
-		errorMsg := ""
-		if exitErr, ok := execErr.(*exec.ExitError); ok {
-			errorMsg = string(exitErr.Stderr)
-		}
 		return map[string]interface{}{
 			"success": false,
-			"error":   fmt.Sprintf("%v - %s", execErr, errorMsg),
+			"error":   execErr.Error(),
 			"node":    nodeName,
 			"pod":     podName,
 			"command": command,
-		}, execErr
+		}, newExecError(ctx, cmdCtx, errorCodeGoBGPError, "gobgp "+command, execErr)
 	}
 	return map[string]interface{}{
-		"success": true,
-		"output":  string(output),
-		"command": command,
-		"node":    nodeName,
-		"pod":     podName,
+		"success":   true,
+		"output":    output,
+		"command":   command,
+		"node":      nodeName,
+		"pod":       podName,
+		"container": container,
 	}, nil
 }
 
 // HandleGoBGPCommand is a generic handler for gobgp commands
 func (s *VPPMCPServer) HandleGoBGPCommand(ctx context.Context, input BGPCommandInput, command, commandDescription string) (*mcp.CallToolResult, any, error) {
+	return s.handleGoBGPCommandWithFilter(ctx, input, command, commandDescription, nil, "")
+}
+
+// handleGoBGPCommandWithFilter is HandleGoBGPCommand keeping only the live output lines for which
+// keep returns true, for tools filtering the output of their command. filter describes the filter.
+func (s *VPPMCPServer) handleGoBGPCommandWithFilter(ctx context.Context, input BGPCommandInput, command, commandDescription string, keep func(string) bool, filter string) (*mcp.CallToolResult, any, error) {
 	// Log the request details
 	log.Printf("Received %s request for pod: %s", commandDescription, input.PodName)
 	log.Printf("Executing gobgp %s command on pod: %s", command, input.PodName)
 
-	if input.PodName == "" {
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	// Snapshots record their pod, so replaying one needs no pod name
+	if input.PodName == "" && input.SnapshotID == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: "Error: Pod name is required. Please specify the Kubernetes pod name.",
+					Text: "Error: Pod name is required. Please specify the Kubernetes pod name or node name.",
 				},
 			},
 		}, nil, fmt.Errorf("pod name is required")
 	}
 
-	// Initialize Kubernetes client for validation
-	k8sClient, err := newKubeClient()
-	if err != nil {
+	if err := validateOutputFormat(input.Format); err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					Text: fmt.Sprintf("Error: %v", err),
 				},
 			},
 		}, nil, err
 	}
 
-	namespace := "calico-vpp-dataplane"
-
-	// Validate pod exists
-	_, err = k8sClient.CoreV1().Pods(namespace).Get(ctx, input.PodName, metav1.GetOptions{})
-	if err != nil {
+	if err := validateContainerName(input.Container); err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("Error validating pod: %v", err),
+					Text: fmt.Sprintf("Error: %v", err),
 				},
 			},
 		}, nil, err
 	}
+	ctx = withExecContainer(ctx, input.Container)
+
+	// Answer from the stored snapshot instead of the live pod
+	if input.SnapshotID != "" {
+		return s.handleSnapshotCommand(input.SnapshotID, input.PodName, snapshotSourceGoBGP, command, commandDescription, input.Format, nil, nil)
+	}
+
+	// Validate the pod exists, unless commands run locally without Kubernetes
+	if !s.bypassesKubernetes() {
+		// Initialize Kubernetes client for validation
+		k8sClient, err := newKubeClient()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					},
+				},
+			}, nil, err
+		}
+
+		namespace := vppNamespace
+
+		// Validate pod exists
+		_, err = k8sClient.CoreV1().Pods(namespace).Get(ctx, input.PodName, metav1.GetOptions{})
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error validating pod: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
+	// gobgp encodes its output natively in JSON
+	if input.Format == outputFormatJSON {
+		command += " -j"
+	}
 
 	// Execute the gobgp command on the Kubernetes pod
-	result, err := ExecutePodGoBGPCommand(ctx, input.PodName, command)
+	result, err := s.ExecutePodGoBGPCommand(ctx, input.PodName, command)
 
 	if err != nil {
 		log.Printf("Error executing gobgp command: %v", err)
 	}
 
 	if success, ok := result["success"].(bool); ok && success {
-		output := result["output"].(string)
-		cmd := result["command"].(string)
-		node := result["node"].(string)
-		pod := result["pod"].(string)
+		output := commandOutput{
+			Binary:    "gobgp",
+			Command:   result["command"].(string),
+			Node:      result["node"].(string),
+			Pod:       result["pod"].(string),
+			Container: result["container"].(string),
+			Output:    result["output"].(string),
+		}
+		if keep != nil {
+			var kept []string
+			for _, line := range strings.Split(output.Output, "\n") {
+				if keep(line) {
+					kept = append(kept, line)
+				}
+			}
+			output.Output, output.Filter = strings.Join(kept, "\n"), filter
+		}
 
 		response := &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("%s:\n\n%s\n\nCommand executed: gobgp %s\nNode: %s\nPod: %s (container: agent)",
-						commandDescription, output, cmd, node, pod),
+					Text: output.render(input.Format, commandDescription),
 				},
 			},
 		}
+		if structured := goBGPStructuredContent(ctx, output, input.Format); structured != nil {
+			response.StructuredContent = structured
+		}
 
 		log.Println("Successfully executed gobgp command, returning result")
 		return response, nil, nil
@@ -479,11 +880,13 @@ func (s *VPPMCPServer) HandleGoBGPCommand(ctx context.Context, input BGPCommandI
 func (s *VPPMCPServer) HandleGoBGPParameterCommand(ctx context.Context, input BGPParameterCommandInput, commandTemplate, commandDescription string) (*mcp.CallToolResult, any, error) {
 	log.Printf("Received %s request for pod: %s, parameter: %s", commandDescription, input.PodName, input.Parameter)
 
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
 	if input.PodName == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: "Error: Pod name is required. Please specify the Kubernetes pod name.",
+					Text: "Error: Pod name is required. Please specify the Kubernetes pod name or node name.",
 				},
 			},
 		}, nil, fmt.Errorf("pod name is required")
@@ -499,57 +902,107 @@ func (s *VPPMCPServer) HandleGoBGPParameterCommand(ctx context.Context, input BG
 		}, nil, fmt.Errorf("parameter is required")
 	}
 
-	namespace := "calico-vpp-dataplane"
+	// Neighbor commands also accept the Kubernetes node name of the neighbor
+	if validateIPOrPrefix(input.Parameter) != nil && strings.HasPrefix(commandTemplate, "neighbor ") {
+		address, err := s.resolveNodeBGPAddress(ctx, input.Parameter)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		log.Printf("Resolved node %s to BGP address %s", input.Parameter, address)
+		input.Parameter = address
+	}
 
-	// Initialize Kubernetes client for validation
-	k8sClient, err := newKubeClient()
-	if err != nil {
+	if err := validateIPOrPrefix(input.Parameter); err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					Text: fmt.Sprintf("Error: %v", err),
 				},
 			},
 		}, nil, err
 	}
 
-	// Validate pod exists
-	_, err = k8sClient.CoreV1().Pods(namespace).Get(ctx, input.PodName, metav1.GetOptions{})
-	if err != nil {
+	if err := validateOutputFormat(input.Format); err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("Error validating pod: %v", err),
+					Text: fmt.Sprintf("Error: %v", err),
 				},
 			},
 		}, nil, err
 	}
 
+	// Validate the pod exists, unless commands run locally without Kubernetes
+	if !s.bypassesKubernetes() {
+		namespace := vppNamespace
+
+		// Initialize Kubernetes client for validation
+		k8sClient, err := newKubeClient()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					},
+				},
+			}, nil, err
+		}
+
+		// Validate pod exists
+		_, err = k8sClient.CoreV1().Pods(namespace).Get(ctx, input.PodName, metav1.GetOptions{})
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error validating pod: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
 	// Build the command with parameter
 	command := fmt.Sprintf(commandTemplate, input.Parameter)
 	log.Printf("Executing gobgp %s command on pod: %s", command, input.PodName)
 
+	// gobgp encodes its output natively in JSON
+	if input.Format == outputFormatJSON {
+		command += " -j"
+	}
+
 	// Execute the gobgp command on the Kubernetes pod
-	result, err := ExecutePodGoBGPCommand(ctx, input.PodName, command)
+	result, err := s.ExecutePodGoBGPCommand(ctx, input.PodName, command)
 
 	if err != nil {
 		log.Printf("Error executing gobgp command: %v", err)
 	}
 
 	if success, ok := result["success"].(bool); ok && success {
-		output := result["output"].(string)
-		cmd := result["command"].(string)
-		node := result["node"].(string)
-		pod := result["pod"].(string)
+		output := commandOutput{
+			Binary:    "gobgp",
+			Command:   result["command"].(string),
+			Node:      result["node"].(string),
+			Pod:       result["pod"].(string),
+			Container: result["container"].(string),
+			Output:    result["output"].(string),
+		}
 
 		response := &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("%s:\n\n%s\n\nCommand executed: gobgp %s\nNode: %s\nPod: %s (container: agent)",
-						commandDescription, output, cmd, node, pod),
+					Text: output.render(input.Format, commandDescription),
 				},
 			},
 		}
+		if structured := goBGPStructuredContent(ctx, output, input.Format); structured != nil {
+			response.StructuredContent = structured
+		}
 
 		log.Println("Successfully executed gobgp command, returning result")
 		return response, nil, nil
@@ -573,16 +1026,23 @@ func (s *VPPMCPServer) HandleGoBGPParameterCommand(ctx context.Context, input BG
 }
 
 // handleGetPods implements listing all calico-vpp pods with IPs and nodes
-func (s *VPPMCPServer) handleGetPods(ctx context.Context, input EmptyInput) (*mcp.CallToolResult, any, error) {
+func (s *VPPMCPServer) handleGetPods(ctx context.Context, input GetPodsInput) (*mcp.CallToolResult, any, error) {
 	log.Printf("Received vpp_get_pods request")
 
 	// Execute kubectl command to get pods with wide output
 	cmdArgs := []string{
 		"get", "pods",
-		"-n", "calico-vpp-dataplane",
+		"-n", vppNamespace,
+		"-l", vppPodSelector,
 		"-owide",
 	}
 
+	// Record the command instead of running it on dry runs
+	if recorder := dryRunFromContext(ctx); recorder != nil {
+		recorder.record("kubectl " + strings.Join(cmdArgs, " "))
+		return &mcp.CallToolResult{}, nil, nil
+	}
+
 	log.Printf("Executing command: kubectl %s", strings.Join(cmdArgs, " "))
 
 	// Set a timeout for the command
@@ -636,12 +1096,21 @@ func (s *VPPMCPServer) handleGetPods(ctx context.Context, input EmptyInput) (*mc
 
 // handleVPPCommand is a generic handler for VPP commands
 func (s *VPPMCPServer) handleVPPCommand(ctx context.Context, input VPPCommandInput, command, commandDescription string) (*mcp.CallToolResult, any, error) {
+	return s.handleVPPCommandWithTransform(ctx, input, command, commandDescription, func(output string) string { return output })
+}
+
+// handleVPPCommandWithTransform is handleVPPCommand with a transform applied to the live
+// output before the filter, for tools post-processing the output of their command
+func (s *VPPMCPServer) handleVPPCommandWithTransform(ctx context.Context, input VPPCommandInput, command, commandDescription string, transform func(string) string) (*mcp.CallToolResult, any, error) {
 	// Log the request details
 	inputJSON, _ := json.Marshal(input)
 	log.Printf("Received %s request with input: %s", commandDescription, string(inputJSON))
 	log.Printf("Executing vppctl %s command on pod: %s", command, input.PodName)
 
-	if input.PodName == "" {
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	// Snapshots record their pod, so replaying one needs no pod name
+	if input.PodName == "" && input.SnapshotID == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
@@ -651,9 +1120,46 @@ func (s *VPPMCPServer) handleVPPCommand(ctx context.Context, input VPPCommandInp
 		}, nil, fmt.Errorf("PodName is required")
 	}
 
-	// Execute the VPP command on the Kubernetes pod
+	if err := validateOutputFormat(input.Format); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	if err := validateContainerName(input.Container); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	ctx = withExecContainer(ctx, input.Container)
+
+	filter, err := compileOutputFilter(input.Filter)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Answer from the stored snapshot instead of the live pod
+	if input.SnapshotID != "" {
+		return s.handleSnapshotCommand(input.SnapshotID, input.PodName, snapshotSourceVPP, command, commandDescription, input.Format, transform, filter)
+	}
+
+	// Execute the VPP command on the Kubernetes pod
 	log.Printf("About to execute pod VPP command...")
-	result, err := ExecutePodVPPCommand(ctx, input.PodName, command)
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, command)
 
 	log.Printf("Command execution completed, processing results...")
 	if err != nil {
@@ -661,15 +1167,21 @@ func (s *VPPMCPServer) handleVPPCommand(ctx context.Context, input VPPCommandInp
 	}
 
 	if success, ok := result["success"].(bool); ok && success {
-		output := result["output"].(string)
-		cmd := result["command"].(string)
-		pod := result["pod"].(string)
+		output := commandOutput{
+			Binary:    "vppctl",
+			Command:   result["command"].(string),
+			Pod:       result["pod"].(string),
+			Container: result["container"].(string),
+			Output:    filterOutput(transform(result["output"].(string)), filter),
+		}
+		if filter != nil {
+			output.Filter = filter.String()
+		}
 
 		response := &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("%s:\n\n%s\n\nCommand executed: vppctl %s\nPod: %s (container: vpp)",
-						commandDescription, output, cmd, pod),
+					Text: output.render(input.Format, commandDescription),
 				},
 			},
 		}
@@ -699,6 +1211,8 @@ func (s *VPPMCPServer) handleVPPFIBCommand(ctx context.Context, input VPPFIBInpu
 	inputJSON, _ := json.Marshal(input)
 	log.Printf("Received %s request with input: %s", commandDescription, string(inputJSON))
 
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
 	if input.PodName == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -719,26 +1233,49 @@ func (s *VPPMCPServer) handleVPPFIBCommand(ctx context.Context, input VPPFIBInpu
 		}, nil, fmt.Errorf("fib_index is required")
 	}
 
+	if err := validateFibIndex(input.FibIndex); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	if err := validateOutputFormat(input.Format); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
 	// Build the command with fib_index
 	command := fmt.Sprintf(commandTemplate, input.FibIndex)
 	log.Printf("Executing vppctl %s command on pod: %s", command, input.PodName)
 
-	result, err := ExecutePodVPPCommand(ctx, input.PodName, command)
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, command)
 
 	if err != nil {
 		log.Printf("Error executing VPP command: %v", err)
 	}
 
 	if success, ok := result["success"].(bool); ok && success {
-		output := result["output"].(string)
-		cmd := result["command"].(string)
-		pod := result["pod"].(string)
+		output := commandOutput{
+			Binary:    "vppctl",
+			Command:   result["command"].(string),
+			Pod:       result["pod"].(string),
+			Container: result["container"].(string),
+			Output:    result["output"].(string),
+		}
 
 		response := &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("%s:\n\n%s\n\nCommand executed: vppctl %s\nPod: %s (container: vpp)",
-						commandDescription, output, cmd, pod),
+					Text: output.render(input.Format, commandDescription),
 				},
 			},
 		}
@@ -768,6 +1305,8 @@ func (s *VPPMCPServer) handleVPPFIBPrefixCommand(ctx context.Context, input VPPF
 	inputJSON, _ := json.Marshal(input)
 	log.Printf("Received %s request with input: %s", commandDescription, string(inputJSON))
 
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
 	if input.PodName == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -788,6 +1327,16 @@ func (s *VPPMCPServer) handleVPPFIBPrefixCommand(ctx context.Context, input VPPF
 		}, nil, fmt.Errorf("fib_index is required")
 	}
 
+	if err := validateFibIndex(input.FibIndex); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
 	if input.Prefix == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -798,26 +1347,49 @@ func (s *VPPMCPServer) handleVPPFIBPrefixCommand(ctx context.Context, input VPPF
 		}, nil, fmt.Errorf("prefix is required")
 	}
 
+	if err := validateIPOrPrefix(input.Prefix); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	if err := validateOutputFormat(input.Format); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
 	// Build the command with fib_index and prefix
 	command := fmt.Sprintf(commandTemplate, input.FibIndex, input.Prefix)
 	log.Printf("Executing vppctl %s command on pod: %s", command, input.PodName)
 
-	result, err := ExecutePodVPPCommand(ctx, input.PodName, command)
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, command)
 
 	if err != nil {
 		log.Printf("Error executing VPP command: %v", err)
 	}
 
 	if success, ok := result["success"].(bool); ok && success {
-		output := result["output"].(string)
-		cmd := result["command"].(string)
-		pod := result["pod"].(string)
+		output := commandOutput{
+			Binary:    "vppctl",
+			Command:   result["command"].(string),
+			Pod:       result["pod"].(string),
+			Container: result["container"].(string),
+			Output:    result["output"].(string),
+		}
 
 		response := &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("%s:\n\n%s\n\nCommand executed: vppctl %s\nPod: %s (container: vpp)",
-						commandDescription, output, cmd, pod),
+					Text: output.render(input.Format, commandDescription),
 				},
 			},
 		}
@@ -842,10 +1414,105 @@ func (s *VPPMCPServer) handleVPPFIBPrefixCommand(ctx context.Context, input VPPF
 	}
 }
 
+const (
+	// traceCaptureWindow bounds how long vpp_trace waits for the requested packets
+	traceCaptureWindow = 30 * time.Second
+	// tracePollInterval is how often vpp_trace checks whether the requested packets were traced
+	tracePollInterval = 2 * time.Second
+)
+
+// countTracePackets returns the number of packets in 'show trace' output
+func countTracePackets(output string) int {
+	packets := 0
+	for _, line := range strings.Split(output, "\n") {
+		if tracePacketRegexp.MatchString(strings.TrimSpace(line)) {
+			packets++
+		}
+	}
+	return packets
+}
+
+// waitForTracePackets polls the trace of a pod until it holds count packets or the window
+// elapses, and returns the last 'show trace' result with the time waited. When thread is
+// set, only the packets of that thread are counted and kept in the output.
+func (s *VPPMCPServer) waitForTracePackets(ctx context.Context, pod string, count int, window time.Duration, thread *int) (map[string]interface{}, time.Duration, error) {
+	showCmd := fmt.Sprintf("show trace max %d", count)
+	start := time.Now()
+	if dryRunFromContext(ctx) != nil {
+		result, err := s.ExecutePodVPPCommand(ctx, pod, showCmd)
+		return result, 0, err
+	}
+
+	deadline := start.Add(window)
+	for {
+		wait := tracePollInterval
+		if remaining := time.Until(deadline); remaining < wait {
+			wait = remaining
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, time.Since(start), ctx.Err()
+		}
+
+		result, err := s.ExecutePodVPPCommand(ctx, pod, showCmd)
+		if err != nil {
+			return result, time.Since(start), err
+		}
+		if thread != nil {
+			result["output"] = filterTraceThread(result["output"].(string), *thread)
+		}
+		if !time.Now().Before(deadline) || countTracePackets(result["output"].(string)) >= count {
+			return result, time.Since(start), nil
+		}
+	}
+}
+
+// traceFilterLine describes the trace filter and thread selection of a vpp_trace call in its capture parameters
+func traceFilterLine(input VPPTraceInput) string {
+	line := ""
+	switch {
+	case input.FilterInclude != "":
+		line = "\n- Filter: only packets visiting " + input.FilterInclude
+	case input.FilterExclude != "":
+		line = "\n- Filter: packets visiting " + input.FilterExclude + " excluded"
+	}
+	switch {
+	case input.Thread != nil:
+		line += fmt.Sprintf("\n- Thread: %d", *input.Thread)
+	case input.PodIP != "" && input.RxInterface != "":
+		line += fmt.Sprintf("\n- Thread: worker polling %s queue %d, the interface of pod IP %s", input.RxInterface, input.RxQueue, input.PodIP)
+	case input.RxInterface != "":
+		line += fmt.Sprintf("\n- Thread: worker polling %s queue %d", input.RxInterface, input.RxQueue)
+	}
+	return line
+}
+
 // handleTraceCapture implements VPP trace capture
-func (s *VPPMCPServer) handleTraceCapture(ctx context.Context, input VPPCaptureInput) (*mcp.CallToolResult, any, error) {
+func (s *VPPMCPServer) handleTraceCapture(ctx context.Context, input VPPTraceInput) (*mcp.CallToolResult, any, error) {
 	log.Printf("Received trace capture request for pod: %s", input.PodName)
 
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	// Scope the trace to the interface of a workload pod, resolving its calico-vpp pod if needed
+	if input.PodIP != "" {
+		var err error
+		if input.Thread != nil || input.RxInterface != "" {
+			err = fmt.Errorf("pod_ip, thread and rx_interface are mutually exclusive")
+		} else {
+			input.PodName, input.RxInterface, err = s.resolvePodIP(ctx, input.PodName, input.PodIP)
+		}
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error resolving the interface of pod IP %s: %v", input.PodIP, err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
 	if input.PodName == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -856,18 +1523,58 @@ func (s *VPPMCPServer) handleTraceCapture(ctx context.Context, input VPPCaptureI
 		}, nil, fmt.Errorf("PodName is required")
 	}
 
-	// Initialize Kubernetes client for validation
-	k8sClient, err := newKubeClient()
+	// Only one filter applies to a trace
+	filterCmd := ""
+	var err error
+	switch {
+	case input.FilterInclude != "" && input.FilterExclude != "":
+		err = fmt.Errorf("filter_include and filter_exclude are mutually exclusive")
+	case input.FilterInclude != "":
+		if err = validateNodeName(input.FilterInclude); err == nil {
+			filterCmd = "trace filter include " + input.FilterInclude
+		}
+	case input.FilterExclude != "":
+		if err = validateNodeName(input.FilterExclude); err == nil {
+			filterCmd = "trace filter exclude " + input.FilterExclude
+		}
+	}
+	if err == nil {
+		switch {
+		case input.Thread != nil && input.RxInterface != "":
+			err = fmt.Errorf("thread and rx_interface are mutually exclusive")
+		case input.Thread != nil && *input.Thread < 0:
+			err = fmt.Errorf("invalid thread %d: must not be negative", *input.Thread)
+		case input.RxQueue < 0:
+			err = fmt.Errorf("invalid rx_queue %d: must not be negative", input.RxQueue)
+		case input.RxInterface != "":
+			err = validateInterfaceName(input.RxInterface)
+		}
+	}
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					Text: fmt.Sprintf("Error: %v", err),
 				},
 			},
 		}, nil, err
 	}
 
+	// Initialize Kubernetes client for validation, unless commands run locally without Kubernetes
+	var k8sClient *KubeClient
+	if !s.bypassesKubernetes() {
+		k8sClient, err = newKubeClient()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
 	// Map interface type to VPP input node
 	vppInputNode, _, err := mapInterfaceTypeToVppInputNode(k8sClient, input.Interface)
 	if err != nil {
@@ -886,9 +1593,44 @@ func (s *VPPMCPServer) handleTraceCapture(ctx context.Context, input VPPCaptureI
 		count = 500
 	}
 
+	// Resolve the worker polling the rx queue. VPP traces every thread, so the packets of
+	// the other threads are dropped from the output.
+	thread := input.Thread
+	if input.RxInterface != "" {
+		placement, err := s.ExecutePodVPPCommand(ctx, input.PodName, "show interface rx-placement")
+		if err == nil && dryRunFromContext(ctx) == nil {
+			var resolved int
+			if resolved, err = rxQueueThread(placement["output"].(string), input.RxInterface, input.RxQueue); err == nil {
+				thread = &resolved
+			}
+		}
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error resolving the worker of %s queue %d: %v", input.RxInterface, input.RxQueue, err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
+	// Serialize the captures on the pod
+	unlock, err := s.lockCapture(ctx, input.PodName)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	defer unlock()
+
 	// Step 1: Clear trace to ensure clean state
 	log.Printf("Clearing trace on pod %s", input.PodName)
-	_, err = ExecutePodVPPCommand(ctx, input.PodName, "clear trace")
+	_, err = s.ExecutePodVPPCommand(ctx, input.PodName, "clear trace")
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -899,10 +1641,28 @@ func (s *VPPMCPServer) handleTraceCapture(ctx context.Context, input VPPCaptureI
 		}, nil, err
 	}
 
+	// Narrow the trace to the packets matching the filter, and remove the filter once done
+	if filterCmd != "" {
+		filterCmd = fmt.Sprintf("%s %d", filterCmd, count)
+		log.Printf("Setting trace filter: %s", filterCmd)
+		if _, err = s.ExecutePodVPPCommand(ctx, input.PodName, filterCmd); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error setting trace filter: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		defer func() {
+			_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, "trace filter none")
+		}()
+	}
+
 	// Step 2: Start trace capture
 	traceCmd := fmt.Sprintf("trace add %s %d", vppInputNode, count)
 	log.Printf("Starting trace: %s", traceCmd)
-	_, err = ExecutePodVPPCommand(ctx, input.PodName, traceCmd)
+	_, err = s.ExecutePodVPPCommand(ctx, input.PodName, traceCmd)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -913,14 +1673,9 @@ func (s *VPPMCPServer) handleTraceCapture(ctx context.Context, input VPPCaptureI
 		}, nil, err
 	}
 
-	// Step 3: Wait for capture (30 seconds or until count is reached)
-	log.Printf("Capturing packets for 30 seconds or until %d packets captured...", count)
-	time.Sleep(30 * time.Second)
-
-	// Step 4: Get trace results
-	traceCmd = fmt.Sprintf("show trace max %d", count)
-	log.Printf("Retrieving trace results...")
-	result, err := ExecutePodVPPCommand(ctx, input.PodName, traceCmd)
+	// Step 3: Poll the trace until count is reached, for at most 30 seconds
+	log.Printf("Capturing packets for %s or until %d packets captured...", traceCaptureWindow, count)
+	result, waited, err := s.waitForTracePackets(ctx, input.PodName, count, traceCaptureWindow, thread)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -931,19 +1686,34 @@ func (s *VPPMCPServer) handleTraceCapture(ctx context.Context, input VPPCaptureI
 		}, nil, err
 	}
 
-	// Step 5: Clear trace after retrieval
-	_, _ = ExecutePodVPPCommand(ctx, input.PodName, "clear trace")
+	// Step 4: Clear trace after retrieval
+	_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, "clear trace")
 
 	if success, ok := result["success"].(bool); ok && success {
 		output := result["output"].(string)
+		parameters := fmt.Sprintf("Capture Parameters:\n- VPP Input Node: %s\n- Count: %d\n- Packets Captured: %d\n- Capture Duration: %s\n- Pod: %s%s",
+			vppInputNode, count, countTracePackets(output), waited.Round(time.Second), input.PodName, traceFilterLine(input))
+
+		// Large traces are stored as an artifact and summarized inline
+		if len(output) > traceInlineMaxBytes {
+			if response, ok := s.traceArtifactResult(input.PodName, vppInputNode, output, parameters); ok {
+				return response, nil, nil
+			}
+		}
+
 		response := &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("VPP Trace Capture Results:\n\n%s\n\nCapture Parameters:\n- VPP Input Node: %s\n- Count: %d\n- Capture Duration: 30 seconds\n- Pod: %s\n\n**Important**: Trace is not saved to any file\n\n",
-						output, vppInputNode, count, input.PodName),
+					Text: fmt.Sprintf("VPP Trace Capture Results:\n\n%s\n\n%s\n\n**Important**: Trace is not saved to any file\n\n", output, parameters),
 				},
 			},
 		}
+		// Structured content lets clients analyze the packets without parsing the text
+		response.StructuredContent = map[string]any{
+			"pod":        input.PodName,
+			"input_node": vppInputNode,
+			"packets":    parseTraceRecords(output),
+		}
 		return response, nil, nil
 	}
 
@@ -958,9 +1728,30 @@ func (s *VPPMCPServer) handleTraceCapture(ctx context.Context, input VPPCaptureI
 }
 
 // handlePcapCapture implements VPP pcap capture
-func (s *VPPMCPServer) handlePcapCapture(ctx context.Context, input VPPCaptureInput) (*mcp.CallToolResult, any, error) {
+func (s *VPPMCPServer) handlePcapCapture(ctx context.Context, input VPPPcapInput) (*mcp.CallToolResult, any, error) {
 	log.Printf("Received pcap capture request for pod: %s", input.PodName)
 
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	// Capture on the interface of a workload pod, resolving its calico-vpp pod if needed
+	if input.PodIP != "" {
+		var err error
+		if input.Interface != "" {
+			err = fmt.Errorf("pod_ip and interface are mutually exclusive")
+		} else {
+			input.PodName, input.Interface, err = s.resolvePodIP(ctx, input.PodName, input.PodIP)
+		}
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error resolving the interface of pod IP %s: %v", input.PodIP, err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
 	if input.PodName == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -971,8 +1762,32 @@ func (s *VPPMCPServer) handlePcapCapture(ctx context.Context, input VPPCaptureIn
 		}, nil, fmt.Errorf("PodName is required")
 	}
 
+	// Translate the 5-tuple into a classify filter
+	classifyFilter := ""
+	directions, err := pcapDirections(input)
+	switch {
+	case err != nil:
+	case input.MaxBytesPerPacket != 0 && (input.MaxBytesPerPacket < minPcapBytesPerPacket || input.MaxBytesPerPacket > maxPcapBytesPerPacket):
+		err = fmt.Errorf("invalid max_bytes_per_packet %d: must be between %d and %d", input.MaxBytesPerPacket, minPcapBytesPerPacket, maxPcapBytesPerPacket)
+	case input.MaxFileMB < 0:
+		err = fmt.Errorf("invalid max_file_mb %d: must not be negative", input.MaxFileMB)
+	case input.KeepFiles < 0:
+		err = fmt.Errorf("invalid keep_files %d: must not be negative", input.KeepFiles)
+	case input.hasFilter():
+		classifyFilter, err = pcapClassifyFilter(input)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
 	// Get list of available interfaces
-	interfaceResult, err := ExecutePodVPPCommand(ctx, input.PodName, "show int")
+	interfaceResult, err := s.ExecutePodVPPCommand(ctx, input.PodName, "show int")
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1031,14 +1846,87 @@ func (s *VPPMCPServer) handlePcapCapture(ctx context.Context, input VPPCaptureIn
 		count = 500
 	}
 
+	// Lower the count so the capture file fits in max_file_mb
+	bytesPerPacket := defaultPcapBytesPerPacket
+	if input.MaxBytesPerPacket > 0 {
+		bytesPerPacket = input.MaxBytesPerPacket
+	}
+	countNote := ""
+	if input.MaxFileMB > 0 {
+		limit := int((int64(input.MaxFileMB)*1024*1024 - pcapFileHeaderBytes) / int64(bytesPerPacket+pcapRecordHeaderBytes))
+		if count > limit {
+			count = limit
+			countNote = fmt.Sprintf(" (capped by max_file_mb %d)", input.MaxFileMB)
+		}
+	}
+
+	// Timestamped files are rotated, trace.pcap is overwritten by every capture
+	pcapFile := "trace.pcap"
+	if input.KeepFiles > 0 {
+		pcapFile = rotatedPcapName(time.Now())
+	}
+
+	// Serialize the captures on the pod
+	unlock, err := s.lockCapture(ctx, input.PodName)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	defer unlock()
+
+	// Refuse to fill /tmp of the pod. Containers without df are not checked.
+	if available, err := s.tmpAvailableBytes(ctx, input.PodName); err != nil {
+		log.Printf("Could not check the space available in /tmp of pod %s: %v", input.PodName, err)
+	} else if needed := pcapMaxBytes(count, bytesPerPacket); dryRunFromContext(ctx) == nil && available < needed {
+		err := fmt.Errorf("not enough space in /tmp of pod %s: the capture may need %s but only %s is available. Lower count, max_bytes_per_packet or max_file_mb",
+			input.PodName, formatBytes(needed), formatBytes(available))
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
 	// Step 1: Stop any existing pcap capture
 	log.Printf("Stopping any existing pcap capture on pod %s", input.PodName)
-	_, _ = ExecutePodVPPCommand(ctx, input.PodName, "pcap trace off")
+	_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, "pcap trace off")
+
+	// Narrow the capture to the flow, replacing any previous filter, and remove the filter once done
+	if classifyFilter != "" {
+		_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, "classify filter pcap del")
+		filterCmd := "classify filter pcap " + classifyFilter
+		log.Printf("Setting pcap filter: %s", filterCmd)
+		if _, err = s.ExecutePodVPPCommand(ctx, input.PodName, filterCmd); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error setting pcap filter: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		defer func() {
+			_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, "classify filter pcap del")
+		}()
+	}
 
 	// Step 2: Start pcap capture
-	pcapCmd := fmt.Sprintf("pcap trace tx rx max %d intfc %s file trace.pcap", count, interfaceName)
+	pcapCmd := fmt.Sprintf("pcap trace %s max %d intfc %s file %s", directions, count, interfaceName, pcapFile)
+	if input.MaxBytesPerPacket > 0 {
+		pcapCmd += fmt.Sprintf(" max-bytes-per-pkt %d", input.MaxBytesPerPacket)
+	}
+	if classifyFilter != "" {
+		pcapCmd += " filter"
+	}
 	log.Printf("Starting pcap: %s", pcapCmd)
-	_, err = ExecutePodVPPCommand(ctx, input.PodName, pcapCmd)
+	_, err = s.ExecutePodVPPCommand(ctx, input.PodName, pcapCmd)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1051,11 +1939,13 @@ func (s *VPPMCPServer) handlePcapCapture(ctx context.Context, input VPPCaptureIn
 
 	// Step 3: Wait for capture (30 seconds or until count is reached)
 	log.Printf("Capturing packets for 30 seconds or until %d packets captured...", count)
-	time.Sleep(30 * time.Second)
+	if dryRunFromContext(ctx) == nil {
+		time.Sleep(30 * time.Second)
+	}
 
 	// Step 4: Stop pcap capture
 	log.Printf("Stopping pcap capture...")
-	result, err := ExecutePodVPPCommand(ctx, input.PodName, "pcap trace off")
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, "pcap trace off")
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1066,13 +1956,25 @@ func (s *VPPMCPServer) handlePcapCapture(ctx context.Context, input VPPCaptureIn
 		}, nil, err
 	}
 
+	// Step 5: Remove the oldest timestamped captures
+	rotation := ""
+	if input.KeepFiles > 0 {
+		removed, err := s.rotatePcapFiles(ctx, input.PodName, input.KeepFiles)
+		switch {
+		case err != nil:
+			rotation = fmt.Sprintf("\n- Rotation failed: %v", err)
+		case len(removed) > 0:
+			rotation = fmt.Sprintf("\n- Rotation: removed %s", strings.Join(removed, ", "))
+		}
+	}
+
 	if success, ok := result["success"].(bool); ok && success {
 		output := result["output"].(string)
 		response := &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("VPP PCAP Capture Results:\n\n%s\n\nCapture Parameters:\n- Interface: %s\n- Count: %d\n- Capture Duration: 30 seconds\n- Pod: %s\n\n**Important**: PCAP file saved at /tmp/trace.pcap\n\n",
-						output, interfaceName, count, input.PodName),
+					Text: fmt.Sprintf("VPP PCAP Capture Results:\n\n%s\n\nCapture Parameters:\n- Interface: %s\n- Direction: %s\n- Count: %d%s\n- Capture Duration: 30 seconds\n- Pod: %s%s%s\n\n**Important**: PCAP file saved at /tmp/%s\n\n",
+						output, interfaceName, directions, count, countNote, input.PodName, pcapFilterLine(input), rotation, pcapFile),
 				},
 			},
 		}
@@ -1093,6 +1995,8 @@ func (s *VPPMCPServer) handlePcapCapture(ctx context.Context, input VPPCaptureIn
 func (s *VPPMCPServer) handleDispatchCapture(ctx context.Context, input VPPCaptureInput) (*mcp.CallToolResult, any, error) {
 	log.Printf("Received dispatch capture request for pod: %s", input.PodName)
 
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
 	if input.PodName == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1103,16 +2007,20 @@ func (s *VPPMCPServer) handleDispatchCapture(ctx context.Context, input VPPCaptu
 		}, nil, fmt.Errorf("PodName is required")
 	}
 
-	// Initialize Kubernetes client for validation
-	k8sClient, err := newKubeClient()
-	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+	// Initialize Kubernetes client for validation, unless commands run locally without Kubernetes
+	var k8sClient *KubeClient
+	var err error
+	if !s.bypassesKubernetes() {
+		k8sClient, err = newKubeClient()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					},
 				},
-			},
-		}, nil, err
+			}, nil, err
+		}
 	}
 
 	// Map interface type to VPP input node
@@ -1133,14 +2041,27 @@ func (s *VPPMCPServer) handleDispatchCapture(ctx context.Context, input VPPCaptu
 		count = 500
 	}
 
+	// Serialize the captures on the pod
+	unlock, err := s.lockCapture(ctx, input.PodName)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	defer unlock()
+
 	// Step 1: Stop any existing dispatch trace
 	log.Printf("Stopping any existing dispatch trace on pod %s", input.PodName)
-	_, _ = ExecutePodVPPCommand(ctx, input.PodName, "pcap dispatch trace off")
+	_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, "pcap dispatch trace off")
 
 	// Step 2: Start dispatch trace capture
 	dispatchCmd := fmt.Sprintf("pcap dispatch trace on max %d buffer-trace %s %d", count, vppInputNode, count)
 	log.Printf("Starting dispatch trace: %s", dispatchCmd)
-	_, err = ExecutePodVPPCommand(ctx, input.PodName, dispatchCmd)
+	_, err = s.ExecutePodVPPCommand(ctx, input.PodName, dispatchCmd)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1153,11 +2074,13 @@ func (s *VPPMCPServer) handleDispatchCapture(ctx context.Context, input VPPCaptu
 
 	// Step 3: Wait for capture (30 seconds or until count is reached)
 	log.Printf("Capturing packets for 30 seconds or until %d packets captured...", count)
-	time.Sleep(30 * time.Second)
+	if dryRunFromContext(ctx) == nil {
+		time.Sleep(30 * time.Second)
+	}
 
 	// Step 4: Stop dispatch trace
 	log.Printf("Stopping dispatch trace...")
-	result, err := ExecutePodVPPCommand(ctx, input.PodName, "pcap dispatch trace off")
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, "pcap dispatch trace off")
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1195,32 +2118,129 @@ func main() {
 	// Parse command-line flags
 	transportMode := flag.String("transport", "stdio", "Transport mode: stdio or http")
 	port := flag.String("port", "8080", "HTTP port (only used when transport=http)")
+	namespace := flag.String("namespace", "", "Namespace of the calico-vpp pods (auto-detected when empty)")
+	podSelector := flag.String("pod-selector", defaultVPPPodSelector, "Label selector identifying calico-vpp pods")
+	artifactDir := flag.String("artifact-dir", filepath.Join(os.TempDir(), "vpp-mcp-artifacts"), "Directory of the server-side artifact store")
+	artifactMaxSizeMB := flag.Int64("artifact-max-size-mb", defaultArtifactMaxBytes/(1024*1024), "Maximum total size of stored artifacts in MiB (0 disables the limit)")
+	artifactTTL := flag.Duration("artifact-ttl", defaultArtifactTTL, "Retention period of stored artifacts (0 keeps them until the size limit is hit)")
+	backend := flag.String("backend", backendKubectl, "Exec backend used to run commands: kubectl, client-go or local")
+	cliSocket := flag.String("cli-socket", "", "Path of the VPP CLI socket passed to vppctl -s in the vpp container, or locally with the local backend (vppctl default when empty)")
+	execTimeout := flag.Duration("exec-timeout", 0, "Default timeout of each command run in a pod (0 uses 10s for vppctl and 30s for gobgp)")
+	maxExecTimeout := flag.Duration("max-exec-timeout", defaultMaxExecTimeout, "Largest timeout_seconds a tool call may request (0 disables the limit)")
+	quotaConfig := flag.String("quota-config", "", "JSON file of per-tool quotas and cooldowns (built-in capture limits when empty)")
+	structuredErrors := flag.Bool("structured-errors", true, "Attach a typed error category to failed tool calls in structured content")
+	maxOutputBytes := flag.Int("max-output-bytes", defaultMaxOutputBytes, "Largest tool output returned inline; larger outputs are truncated and stored as a resource (0 disables truncation)")
+	maxConcurrentExecs := flag.Int("max-concurrent-execs", defaultMaxConcurrentExecs, "Maximum number of commands running at the same time across all tool calls (0 disables the limit)")
+	dryRun := flag.Bool("dry-run", false, "Return the commands every tool call would execute without running them")
+	enableWrite := flag.Bool("enable-write", false, "Register the tools changing the VPP configuration or state (SPAN mirrors, interface MTU and state, logging levels, interface counters, CNAT and host stack sessions, packet generator, BGP neighbor admin state)")
+	enableRaw := flag.Bool("enable-raw", false, "Register the vpp_exec tool running arbitrary vppctl commands")
+	rawPrefixes := flag.String("raw-allowed-prefixes", defaultRawVPPPrefixes, "Comma-separated vppctl command prefixes allowed by vpp_exec, e.g. \"show,clear errors\"")
+	enableRawBGP := flag.Bool("enable-raw-bgp", false, "Register the bgp_exec tool running arbitrary read-only gobgp commands")
+	toolsAllow := flag.String("tools-allow", "", "Comma-separated tools or tool groups to expose (all when empty)")
+	toolsDeny := flag.String("tools-deny", "", "Comma-separated tools or tool groups to hide, e.g. capture,clear")
+	grpcPort := flag.String("grpc-port", "", "Port of the optional gRPC tool service (disabled when empty)")
+	grpcListen := flag.String("grpc-listen", defaultGRPCListen, "Address the gRPC tool service binds to; non-loopback addresses need TLS or a token")
+	grpcTLSCert := flag.String("grpc-tls-cert", "", "TLS certificate file of the gRPC tool service")
+	grpcTLSKey := flag.String("grpc-tls-key", "", "TLS private key file of the gRPC tool service")
+	grpcTokenFile := flag.String("grpc-token-file", "", "File holding the bearer token gRPC clients must send in the authorization metadata")
+	snapshotInterval := flag.Duration("snapshot-interval", 0, "Interval at which snapshots of every calico-vpp pod are recorded (0 disables the scheduler)")
 	flag.Parse()
 
 	log.Printf("Starting VPP MCP Server with transport=%s...", *transportMode)
 
-	// Create the VPP MCP server instance
-	vppServer := NewVPPMCPServer()
+	// Resolve the calico-vpp namespace once and reuse it for every tool call.
+	// The local backend runs next to VPP and never talks to Kubernetes.
+	if *backend != backendLocal {
+		initVPPNamespace(*namespace, *podSelector)
+	}
 
-	// Create MCP server with implementation info
-	impl := &mcp.Implementation{
-		Name:    "vpp-mcp-server",
-		Version: "1.0.0",
+	// Create the exec backend used to run commands in the calico-vpp pods
+	executor, err := newExecutor(*backend, *cliSocket)
+	if err != nil {
+		log.Fatalf("Failed to initialize exec backend: %v", err)
+	}
+	log.Printf("Using %s exec backend", *backend)
+
+	// Create the VPP MCP server instance
+	vppServer := NewVPPMCPServer(executor)
+	vppServer.execTimeout = *execTimeout
+	vppServer.maxExecTimeout = *maxExecTimeout
+	if *maxConcurrentExecs > 0 {
+		vppServer.execSlots = make(chan struct{}, *maxConcurrentExecs)
+	}
+
+	artifacts, err := NewArtifactStore(*artifactDir, *artifactMaxSizeMB*1024*1024, *artifactTTL)
+	if err != nil {
+		log.Fatalf("Failed to initialize artifact store: %v", err)
+	}
+	vppServer.artifacts = artifacts
+
+	// Create MCP server with implementation info
+	impl := &mcp.Implementation{
+		Name:    "vpp-mcp-server",
+		Version: "1.0.0",
 	}
 
 	vppServer.server = mcp.NewServer(impl, nil)
 
+	// Reject tool calls exceeding their quota before they reach the dataplane
+	quotas, err := LoadToolQuotas(*quotaConfig)
+	if err != nil {
+		log.Fatalf("Failed to load tool quotas: %v", err)
+	}
+	for _, line := range describeToolQuotas(quotas) {
+		log.Printf("Tool quota %s", line)
+	}
+	vppServer.server.AddReceivingMiddleware(NewQuotaEnforcer(quotas).Middleware)
+
+	// Reject per-call timeouts above the server maximum before the call is counted
+	vppServer.server.AddReceivingMiddleware(newExecTimeoutMiddleware(*maxExecTimeout))
+
+	// Categorize failed tool calls so clients can branch on the error type
+	if *structuredErrors {
+		vppServer.server.AddReceivingMiddleware(structuredErrorMiddleware)
+	}
+
+	// Truncate large outputs and serve them in full as resources
+	vppServer.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "artifact",
+		Description: "Artifacts of the server-side store, such as the full output of truncated tool calls",
+		URITemplate: artifactURIPrefix + "{id}",
+	}, vppServer.handleReadArtifact)
+	// Record the commands of dry-run calls instead of running them. Added last so it
+	// wraps the quota enforcer, which lets dry runs through.
+	vppServer.server.AddReceivingMiddleware(newDryRunMiddleware(*dryRun))
+
+	// Run read-only tools on several pods, each pod call going through the quotas and the dry run
+	vppServer.server.AddReceivingMiddleware(vppServer.fanOutMiddleware)
+
+	// Added after the fan-out so the merged result of a fanned-out call is limited as a whole
+	if *maxOutputBytes > 0 {
+		vppServer.server.AddReceivingMiddleware(newOutputLimitMiddleware(vppServer.artifacts, *maxOutputBytes))
+	}
+
+	// Resolve node_name to the calico-vpp pod of the node. Added after the other middlewares
+	// so the quota enforcer and the output store see the pod name.
+	vppServer.server.AddReceivingMiddleware(vppServer.nodeNameMiddleware)
+
 	// Define the vpp_show_version tool with a better description
 	tool := &mcp.Tool{
 		Name: "vpp_show_version",
 		Description: "Get VPP version information by running 'vppctl show version' in a Kubernetes VPP container\n\n" +
+			"For support cases, use verbose to capture the exact build provenance: build date, build host, compiler and the startup command line.\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			"- verbose: Run 'vppctl show version verbose cmdline' to add the build details and the command line VPP was started with (default: false)\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine,
 	}
 
 	// Add the tool to the server
-	mcp.AddTool(vppServer.server, tool, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
-		return vppServer.handleVPPCommand(ctx, input, "show version", "VPP Version Information")
+	mcp.AddTool(vppServer.server, tool, func(ctx context.Context, req *mcp.CallToolRequest, input VPPVersionInput) (*mcp.CallToolResult, any, error) {
+		if input.Verbose {
+			return vppServer.handleVPPCommand(ctx, input.VPPCommandInput, "show version verbose cmdline", "VPP Version and Build Information")
+		}
+		return vppServer.handleVPPCommand(ctx, input.VPPCommandInput, "show version", "VPP Version Information")
 	})
 
 	// Define vpp_show_int tool
@@ -1228,7 +2248,10 @@ func main() {
 		Name: "vpp_show_int",
 		Description: "Get VPP interface information by running 'vppctl show int' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowInt, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show int", "VPP Interface Information")
@@ -1239,7 +2262,10 @@ func main() {
 		Name: "vpp_show_int_addr",
 		Description: "Get VPP interface address information by running 'vppctl show int addr' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowIntAddr, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show int addr", "VPP Interface Address Information")
@@ -1250,21 +2276,74 @@ func main() {
 		Name: "vpp_show_errors",
 		Description: "Get VPP error counters by running 'vppctl show errors' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			"- sort: List the counters by count, highest first (default: false)\n" +
+			"- min_count: Hide the counters below this count, e.g. 100 to only show significant drops\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
-	mcp.AddTool(vppServer.server, toolShowErrors, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
-		return vppServer.handleVPPCommand(ctx, input, "show errors", "VPP Error Counters")
+	mcp.AddTool(vppServer.server, toolShowErrors, func(ctx context.Context, req *mcp.CallToolRequest, input VPPErrorsInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleShowErrors(ctx, input)
 	})
 
 	// Define vpp_show_session_verbose tool
 	toolShowSession := &mcp.Tool{
 		Name: "vpp_show_session_verbose",
 		Description: "Get VPP session information by running 'vppctl show session verbose 2' in a Kubernetes VPP container\n\n" +
+			"The output is very large on loaded nodes: narrow it down with the protocol, state, ip and port filters.\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- protocol: Only list sessions of this transport protocol - tcp|udp|tls|quic|sctp|http (vppctl 'proto' option)\n" +
+			"- state: Only list sessions in this state, e.g. ready, listening, closing (vppctl 'state' option)\n" +
+			"- ip: Only list sessions with this local or remote address, passed to vppctl as 'ep <ip>'\n" +
+			"- port: Only list sessions with this local or remote port, on the same endpoint as ip when both are given\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
-	mcp.AddTool(vppServer.server, toolShowSession, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
-		return vppServer.handleVPPCommand(ctx, input, "show session verbose 2", "VPP Session Information (Verbose)")
+	mcp.AddTool(vppServer.server, toolShowSession, func(ctx context.Context, req *mcp.CallToolRequest, input VPPSessionInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleShowSession(ctx, input)
+	})
+
+	// Define vpp_show_tcp_connections tool
+	toolShowTCPConnections := &mcp.Tool{
+		Name: "vpp_show_tcp_connections",
+		Description: "Debug individual TCP connections of the VPP host stack by running 'vppctl show session proto tcp verbose 2' filtered to a 4-tuple, " +
+			"'vppctl show tcp punt' and, when the 4-tuple is complete, 'vppctl show tcp scoreboard trace' in a Kubernetes VPP container\n\n" +
+			"The verbose session details include the TCP state, send/receive windows, congestion control and retransmission counters of every matching connection. " +
+			"The scoreboard trace is only available when VPP is built with TCP scoreboard tracing.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- local_ip: Only list connections with this local address\n" +
+			"- local_port: Only list connections with this local port\n" +
+			"- remote_ip: Only list connections with this remote address\n" +
+			"- remote_port: Only list connections with this remote port\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowTCPConnections, func(ctx context.Context, req *mcp.CallToolRequest, input TCPConnectionsInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleShowTCPConnections(ctx, input)
+	})
+
+	// Define vpp_show_udp tool
+	toolShowUDP := &mcp.Tool{
+		Name: "vpp_show_udp",
+		Description: "Check UDP-based services (DNS, QUIC, ...) terminated by the VPP host stack by running 'vppctl show udp punt' and " +
+			"'vppctl show session proto udp verbose' in a Kubernetes VPP container\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- port: Only list UDP sessions with this local or remote port, e.g. 53 for DNS\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowUDP, func(ctx context.Context, req *mcp.CallToolRequest, input UDPInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleShowUDP(ctx, input)
 	})
 
 	// Define vpp_show_npol_rules tool
@@ -1272,7 +2351,10 @@ func main() {
 		Name: "vpp_show_npol_rules",
 		Description: "List rules that are referenced by policies by running 'vppctl show npol rules' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowNpolRules, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show npol rules", "VPP NPOL Rules")
@@ -1283,7 +2365,10 @@ func main() {
 		Name: "vpp_show_npol_policies",
 		Description: "List all the policies that are referenced on interfaces by running 'vppctl show npol policies' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowNpolPolicies, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show npol policies", "VPP NPOL Policies")
@@ -1294,7 +2379,10 @@ func main() {
 		Name: "vpp_show_npol_ipset",
 		Description: "List ipsets that are referenced by rules (IPsets are just list of IPs) by running 'vppctl show npol ipset' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowNpolIpset, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show npol ipset", "VPP NPOL IPset")
@@ -1310,7 +2398,10 @@ func main() {
 			"- rx: contains rules that are applied on packets that ENTER VPP on a given interface. Rules are applied top to bottom.\n" +
 			"- profiles: are specific rules that are enforced when a matched rule action is PASS or when no policies are configured.\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowNpolInterfaces, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show npol interfaces", "VPP NPOL Interfaces")
@@ -1321,49 +2412,168 @@ func main() {
 		Name: "vpp_trace",
 		Description: "Capture VPP packet traces by running 'vppctl trace add' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP\n\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
 			"Optional parameters:\n" +
 			"- count: Number of packets to capture (default: 500)\n" +
-			"- interface: Interface type - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio)\n\n" +
+			"- interface: Interface type - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio)\n" +
+			"- filter_include: Only keep the packets visiting this graph node (e.g. ip4-drop)\n" +
+			"- filter_exclude: Drop the packets visiting this graph node; mutually exclusive with filter_include\n" +
+			"- thread: Only keep the packets traced on this VPP thread (0 is the main thread, workers start at 1)\n" +
+			"- rx_interface: Only keep the packets traced on the worker polling this interface (e.g. tap0); mutually exclusive with thread\n" +
+			"- rx_queue: Queue of rx_interface (default: 0)\n" +
+			"- pod_ip: IP of a workload pod: only keep the packets traced on the worker polling queue 0 of its interface, resolved from the FIB. pod_name may be omitted, the calico-vpp pod of the node hosting the workload pod is used\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
 			"The tool will:\n" +
 			"1. Clear existing traces\n" +
 			"2. Start packet capture\n" +
-			"3. Wait 30 seconds or until count is reached\n" +
-			"4. Display captured traces",
+			"3. Poll the trace every 2 seconds and stop as soon as count packets are captured, or after 30 seconds\n" +
+			"4. Display captured traces, also returned as structured content: one record per packet with its thread, input node, node path with timestamps, and drop reason if dropped\n\n" +
+			fmt.Sprintf("Traces larger than %d KiB are stored in the artifact store and linked as a resource; the result then carries a summary of the trace instead.", traceInlineMaxBytes/1024),
 	}
-	mcp.AddTool(vppServer.server, toolTrace, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCaptureInput) (*mcp.CallToolResult, any, error) {
+	mcp.AddTool(vppServer.server, toolTrace, func(ctx context.Context, req *mcp.CallToolRequest, input VPPTraceInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleTraceCapture(ctx, input)
 	})
 
+	// Define vpp_trace_analyze tool
+	toolTraceAnalyze := &mcp.Tool{
+		Name: "vpp_trace_analyze",
+		Description: "Capture VPP packet traces like vpp_trace and return an aggregated summary instead of the raw traces: " +
+			"packets per final node, drop reasons with their counts, and example packets per drop reason\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- count: Number of packets to capture (default: 500)\n" +
+			"- interface: Interface type - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio)\n" +
+			"- filter_include: Only keep the packets visiting this graph node (e.g. ip4-drop)\n" +
+			"- filter_exclude: Drop the packets visiting this graph node; mutually exclusive with filter_include\n" +
+			"- thread: Only keep the packets traced on this VPP thread (0 is the main thread, workers start at 1)\n" +
+			"- rx_interface: Only keep the packets traced on the worker polling this interface (e.g. tap0); mutually exclusive with thread\n" +
+			"- rx_queue: Queue of rx_interface (default: 0)\n" +
+			"- pod_ip: IP of a workload pod: only keep the packets traced on the worker polling queue 0 of its interface, resolved from the FIB. pod_name may be omitted, the calico-vpp pod of the node hosting the workload pod is used\n" +
+			fmt.Sprintf("- examples: Number of example packets reported per drop reason (default: %d)\n", defaultTraceAnalyzeExamples) +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- The drop reason is the error counter printed by the drop node, e.g. 'ip4-input: ip4 ttl <= 1'\n" +
+			"- Example packets show their node path and the lines printed by the node that sent them to the drop nodes",
+	}
+	mcp.AddTool(vppServer.server, toolTraceAnalyze, func(ctx context.Context, req *mcp.CallToolRequest, input VPPTraceAnalyzeInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleTraceAnalyze(ctx, input)
+	})
+
+	// Define vpp_trace_stream tool
+	toolTraceStream := &mcp.Tool{
+		Name: "vpp_trace_stream",
+		Description: "Run a long VPP packet trace in a Kubernetes VPP container, polling it every few seconds and streaming the packets of each poll " +
+			"as notifications, for near-real-time visibility instead of one dump at the end\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- interface: Interface type - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio)\n" +
+			fmt.Sprintf("- count: Number of packets traced between two polls (default: %d)\n", defaultTraceStreamCount) +
+			fmt.Sprintf("- duration_seconds: How long the trace runs (default: %d, max: %d)\n", int(defaultTraceStreamDuration.Seconds()), int(maxTraceStreamDuration.Seconds())) +
+			fmt.Sprintf("- poll_seconds: Interval between two polls (default: %d)\n", int(defaultTraceStreamPoll.Seconds())) +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"The packets of each poll are sent as progress notifications when the call carries a progress token, and as log notifications (logger vpp_trace_stream) " +
+			"once the client sets a log level. The trace is cleared and re-armed after every poll; the result summarizes every packet traced.",
+	}
+	mcp.AddTool(vppServer.server, toolTraceStream, func(ctx context.Context, req *mcp.CallToolRequest, input VPPTraceStreamInput) (*mcp.CallToolResult, any, error) {
+		notifier := traceStreamNotifier{session: req.Session}
+		if req.Params != nil {
+			notifier.progressToken = req.Params.GetProgressToken()
+		}
+		return vppServer.handleTraceStream(ctx, notifier, input)
+	})
+
 	// Define vpp_pcap tool
 	toolPcap := &mcp.Tool{
 		Name: "vpp_pcap",
 		Description: "Capture VPP packets to pcap file by running 'vppctl pcap trace' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP\n\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
 			"Optional parameters:\n" +
 			"- count: Number of packets to capture (default: 500)\n" +
-			"- interface: Interface name (e.g., host-eth0) or 'any' (default: first available interface)\n\n" +
+			"- interface: Interface name (e.g., host-eth0) or 'any' (default: first available interface)\n" +
+			"- pod_ip: IP of a workload pod: capture on its interface, resolved from the FIB; mutually exclusive with interface. pod_name may be omitted, the calico-vpp pod of the node hosting the workload pod is used\n" +
+			"- src_ip: Only capture packets from this IPv4 or IPv6 address\n" +
+			"- dst_ip: Only capture packets to this IPv4 or IPv6 address\n" +
+			"- protocol: Only capture packets of this IP protocol - tcp|udp|icmp|icmp6|sctp or a protocol number\n" +
+			"- src_port: Only capture packets from this port (requires protocol tcp, udp or sctp)\n" +
+			"- dst_port: Only capture packets to this port (requires protocol tcp, udp or sctp)\n" +
+			fmt.Sprintf("- max_bytes_per_packet: Truncate the captured packets to this many bytes (%d-%d, default: %d)\n", minPcapBytesPerPacket, maxPcapBytesPerPacket, defaultPcapBytesPerPacket) +
+			"- max_file_mb: Cap the size of the capture file by lowering count\n" +
+			"- keep_files: Write a timestamped capture file (/tmp/vpp-mcp-pcap-<timestamp>.pcap) instead of /tmp/trace.pcap, and keep only this many of them\n" +
+			"- direction: Packets to capture - rx|tx|both (default: both, or none when drop is set)\n" +
+			"- drop: Also capture the packets dropped by VPP; without direction, only the dropped packets are captured\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
 			"The tool will:\n" +
 			"1. Validate the interface exists\n" +
-			"2. Start pcap capture on tx/rx\n" +
+			"2. Check that /tmp has room for the capture, then start pcap capture on the selected directions, through a 'classify filter pcap' filter when any of the 5-tuple fields is set\n" +
 			"3. Wait 30 seconds or until count is reached\n" +
 			"4. Stop capture and save to /tmp/vpp-capture-<timestamp>.pcap\n" +
 			"5. Display capture status",
 	}
-	mcp.AddTool(vppServer.server, toolPcap, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCaptureInput) (*mcp.CallToolResult, any, error) {
+	mcp.AddTool(vppServer.server, toolPcap, func(ctx context.Context, req *mcp.CallToolRequest, input VPPPcapInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handlePcapCapture(ctx, input)
 	})
 
+	// Define vpp_trace_pcap tool
+	toolTracePcap := &mcp.Tool{
+		Name: "vpp_trace_pcap",
+		Description: "Capture a VPP packet trace and a pcap at the same time, over the same window, in a Kubernetes VPP container, " +
+			"and return both so graph traces can be correlated with the packets on the wire\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- count: Number of packets to capture, for each capture (default: 500)\n" +
+			"- interface: Interface type traced - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio)\n" +
+			"- pcap_interface: Interface name the pcap captures from (e.g., host-eth0) or 'any' (default: 'any')\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"The tool will:\n" +
+			"1. Start the trace with 'trace add' and the pcap with 'pcap trace'\n" +
+			"2. Wait 30 seconds\n" +
+			"3. Stop the pcap, saved at /tmp/trace.pcap, and retrieve the trace\n" +
+			"4. Display both, with the traced packets also returned as structured content",
+	}
+	mcp.AddTool(vppServer.server, toolTracePcap, func(ctx context.Context, req *mcp.CallToolRequest, input VPPTracePcapInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleTracePcap(ctx, input)
+	})
+
+	// Define vpp_cluster_capture tool
+	toolClusterCapture := &mcp.Tool{
+		Name: "vpp_cluster_capture",
+		Description: "Run the same packet trace or pcap capture on several calico-vpp pods (default: every pod) at the same time, for the same window, " +
+			"so both ends of a failing flow are captured in one operation\n\n" +
+			"Required parameters:\n" +
+			"- type: Capture to run - trace|pcap\n\n" +
+			"Optional parameters:\n" +
+			"- pod_names: The calico-vpp pods to capture on (default: every calico-vpp pod)" + nodeNamesParameterDescription + "\n" +
+			"- interface: Interface type for trace - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio); interface name or 'any' for pcap (default: 'any')\n" +
+			"- count: Number of packets to capture per pod (default: 500)\n" +
+			fmt.Sprintf("- duration_seconds: How long the captures run (default: %d, max: %d)\n", int(defaultCaptureJobDuration.Seconds()), int(maxClusterCaptureDuration.Seconds())) +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"The output of every pod is returned in its own section; a pod failing to capture does not abort the others.",
+	}
+	mcp.AddTool(vppServer.server, toolClusterCapture, func(ctx context.Context, req *mcp.CallToolRequest, input VPPClusterCaptureInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleClusterCapture(ctx, input)
+	})
+
 	// Define vpp_dispatch tool
 	toolDispatch := &mcp.Tool{
 		Name: "vpp_dispatch",
 		Description: "Capture VPP dispatch trace to pcap file by running 'vppctl pcap dispatch trace' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP\n\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
 			"Optional parameters:\n" +
 			"- count: Number of packets to capture (default: 500)\n" +
-			"- interface: Interface type - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio)\n\n" +
+			"- interface: Interface type - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
 			"The tool will:\n" +
 			"1. Start dispatch trace with buffer trace\n" +
 			"2. Wait 30 seconds or until count is reached\n" +
@@ -1374,28 +2584,162 @@ func main() {
 		return vppServer.handleDispatchCapture(ctx, input)
 	})
 
+	// Define vpp_dispatch_analyze tool
+	toolDispatchAnalyze := &mcp.Tool{
+		Name: "vpp_dispatch_analyze",
+		Description: "Capture a VPP dispatch trace like vpp_dispatch and summarize which graph node sequences the packets took and how often, " +
+			"highlighting unexpected paths such as packets punted, dropped or answered with ICMP errors\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- count: Number of packets to capture (default: 500)\n" +
+			"- interface: Interface type - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"The paths come from the buffer traces enabled by the dispatch trace, read with 'show trace'; the dispatch pcap is still saved at /tmp/dispatch.pcap.",
+	}
+	mcp.AddTool(vppServer.server, toolDispatchAnalyze, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCaptureInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleDispatchAnalyze(ctx, input)
+	})
+
+	// Define vpp_capture_status tool
+	toolVPPCaptureStatus := &mcp.Tool{
+		Name: "vpp_capture_status",
+		Description: "Check whether a pcap trace, a pcap dispatch trace or a buffer trace is active on a VPP pod, e.g. left running by a cancelled tool call\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Reports 'pcap trace status', 'pcap dispatch trace status', the packets held in the trace buffer of each thread, " +
+			"and the captures of this server running on the pod.",
+	}
+	mcp.AddTool(vppServer.server, toolVPPCaptureStatus, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCaptureStatusInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCaptureStatus(ctx, input)
+	})
+
+	// Define vpp_capture_cleanup tool
+	toolCaptureCleanup := &mcp.Tool{
+		Name: "vpp_capture_cleanup",
+		Description: "List the capture files (.pcap files and the API trace) left in /tmp of a VPP container by previous captures, and delete the stale ones\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- older_than_minutes: Only consider the files not modified within this many minutes stale (default: 0, every capture file)\n" +
+			"- delete: Delete the stale files and report the reclaimed space, instead of only listing them (default: false)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Deleting fails while a capture runs on the pod.",
+	}
+	mcp.AddTool(vppServer.server, toolCaptureCleanup, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCaptureCleanupInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleCaptureCleanup(ctx, input)
+	})
+
+	// Define capture_start tool
+	toolCaptureStart := &mcp.Tool{
+		Name: "capture_start",
+		Description: "Start a VPP packet trace, pcap or dispatch trace in the background and return its job ID without waiting for it\n\n" +
+			"Unlike vpp_trace, vpp_pcap and vpp_dispatch, the call returns as soon as the capture is started, so long captures don't hold the request open " +
+			"and several captures on different pods can be tracked at once. Only one capture runs per pod at a time.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n" +
+			"- type: Capture to run - trace|pcap|dispatch\n\n" +
+			"Optional parameters:\n" +
+			"- interface: Interface type for trace and dispatch - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio); interface name or 'any' for pcap (default: 'any')\n" +
+			"- count: Number of packets to capture (default: 500)\n" +
+			fmt.Sprintf("- duration_seconds: How long the capture runs unless stopped earlier (default: %d, max: %d)\n", int(defaultCaptureJobDuration.Seconds()), int(maxCaptureJobDuration.Seconds())) +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Follow the job with capture_status, end it early with capture_stop and read the captured packets with capture_result.",
+	}
+	mcp.AddTool(vppServer.server, toolCaptureStart, func(ctx context.Context, req *mcp.CallToolRequest, input CaptureStartInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleCaptureStart(ctx, input)
+	})
+
+	// Define capture_status tool
+	toolCaptureStatus := &mcp.Tool{
+		Name: "capture_status",
+		Description: "Report the state of the background capture jobs started with capture_start\n\n" +
+			"Optional parameters:\n" +
+			"- job_id: The capture job to report (default: every job)\n\n" +
+			fmt.Sprintf("Finished jobs are kept for %s.", captureJobRetention),
+	}
+	mcp.AddTool(vppServer.server, toolCaptureStatus, func(ctx context.Context, req *mcp.CallToolRequest, input CaptureStatusInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleCaptureStatus(ctx, input)
+	})
+
+	// Define capture_stop tool
+	toolCaptureStop := &mcp.Tool{
+		Name: "capture_stop",
+		Description: "Stop a running background capture job before its duration elapses and return the captured packets\n\n" +
+			"Required parameters:\n" +
+			"- job_id: The capture job returned by capture_start",
+	}
+	mcp.AddTool(vppServer.server, toolCaptureStop, func(ctx context.Context, req *mcp.CallToolRequest, input CaptureJobInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleCaptureStop(ctx, input)
+	})
+
+	// Define capture_result tool
+	toolCaptureResult := &mcp.Tool{
+		Name: "capture_result",
+		Description: "Return the captured packets of a finished background capture job, or its remaining time while it is still running\n\n" +
+			"Required parameters:\n" +
+			"- job_id: The capture job returned by capture_start",
+	}
+	mcp.AddTool(vppServer.server, toolCaptureResult, func(ctx context.Context, req *mcp.CallToolRequest, input CaptureJobInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleCaptureResult(ctx, input)
+	})
+
 	// Define vpp_get_pods tool
 	toolGetPods := &mcp.Tool{
 		Name: "vpp_get_pods",
 		Description: "List all calico-vpp pods along with their IP addresses and the node on which they are running\n\n" +
-			"This tool runs 'kubectl get pods -n calico-vpp-dataplane -owide' to display:\n" +
+			"This tool runs 'kubectl get pods -n <namespace> -l <selector> -owide' in the detected calico-vpp namespace to display:\n" +
 			"- Pod names\n" +
 			"- Pod status\n" +
 			"- Pod IP addresses\n" +
 			"- Node names\n" +
 			"- Age and other metadata\n\n" +
-			"No parameters required.",
+			"Optional parameters:\n" +
+			dryRunParameterLine,
 	}
-	mcp.AddTool(vppServer.server, toolGetPods, func(ctx context.Context, req *mcp.CallToolRequest, input EmptyInput) (*mcp.CallToolResult, any, error) {
+	mcp.AddTool(vppServer.server, toolGetPods, func(ctx context.Context, req *mcp.CallToolRequest, input GetPodsInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleGetPods(ctx, input)
 	})
 
+	// Define vpp_get_felix_configuration tool
+	toolGetFelixConfiguration := &mcp.Tool{
+		Name: "vpp_get_felix_configuration",
+		Description: "Read the Calico FelixConfiguration resources from the Kubernetes API, highlighting the dataplane-relevant settings " +
+			"(WireGuard, IPIP and VXLAN enablement, BPF, IPv6 support) and reporting the defaults of the unset ones, " +
+			"along with the calico-vpp feature gates of the calico-vpp-config ConfigMap where IPsec is enabled. " +
+			"Use it to check that the VPP state matches the configured encryption and encapsulation.\n\n" +
+			"Optional parameters:\n" +
+			"- name: Only report this FelixConfiguration, e.g. default or node.<node name>",
+	}
+	mcp.AddTool(vppServer.server, toolGetFelixConfiguration, func(ctx context.Context, req *mcp.CallToolRequest, input CalicoConfigurationInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleGetFelixConfiguration(ctx, input)
+	})
+
+	// Define vpp_get_bgp_configuration tool
+	toolGetBGPConfiguration := &mcp.Tool{
+		Name: "vpp_get_bgp_configuration",
+		Description: "Read the Calico BGPConfiguration resources from the Kubernetes API, highlighting the AS number, " +
+			"the node-to-node mesh, the BGP listen port and the service IP advertisements, and reporting the defaults of the unset ones. " +
+			"Use it to check the configuration the BGP sessions and routes of the calico-vpp pods should follow.\n\n" +
+			"Optional parameters:\n" +
+			"- name: Only report this BGPConfiguration, e.g. default or node.<node name>",
+	}
+	mcp.AddTool(vppServer.server, toolGetBGPConfiguration, func(ctx context.Context, req *mcp.CallToolRequest, input CalicoConfigurationInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleGetBGPConfiguration(ctx, input)
+	})
+
 	// Define vpp_clear_errors tool
 	toolClearErrors := &mcp.Tool{
 		Name: "vpp_clear_errors",
 		Description: "Reset the error counters by running 'vppctl clear errors' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + timeoutParameterDescription,
 	}
 	mcp.AddTool(vppServer.server, toolClearErrors, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "clear errors", "VPP Clear Error Counters")
@@ -1406,7 +2750,10 @@ func main() {
 		Name: "vpp_tcp_stats",
 		Description: "Display global statistics reported by TCP by running 'vppctl show tcp stats' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolTcpStats, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show tcp stats", "VPP TCP Statistics")
@@ -1417,7 +2764,10 @@ func main() {
 		Name: "vpp_session_stats",
 		Description: "Display global statistics reported by the session layer by running 'vppctl show session stats' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolSessionStats, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show session stats", "VPP Session Statistics")
@@ -1428,10 +2778,97 @@ func main() {
 		Name: "vpp_get_logs",
 		Description: "Display VPP logs by running 'vppctl show logging' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			"- class: Only show entries of this log class, e.g. dpdk or linux-cp/nl (a class also matches its subclasses)\n" +
+			"- level: Only show entries of this severity and above - emerg|alert|crit|err|warn|notice|info|debug\n" +
+			"- tail: Only show the last N matching entries\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolGetLogs, func(ctx context.Context, req *mcp.CallToolRequest, input VPPLogsInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleGetLogs(ctx, input)
+	})
+
+	// Define vpp_get_agent_logs tool
+	toolGetAgentLogs := &mcp.Tool{
+		Name: "vpp_get_agent_logs",
+		Description: "Display the logs of the agent container of a calico-vpp pod by running 'kubectl logs -c agent'. " +
+			"Most Calico VPP control plane errors (BGP, IPAM, CNI, policies) only appear in the agent logs, not in vppctl output.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- tail: Only fetch the last N lines (default: 1000, max: 20000)\n" +
+			"- since: Only fetch the lines written in the last duration, e.g. 10m or 1h\n" +
+			"- grep: Regular expression applied line-wise to the fetched lines; only matching lines are returned (e.g. 'error|warn')\n" +
+			"- previous: Fetch the logs of the previous instance of the agent container, after a crash or a restart\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolGetAgentLogs, func(ctx context.Context, req *mcp.CallToolRequest, input ContainerLogsInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleGetContainerLogs(ctx, input, agentContainerName, "Agent Logs")
+	})
+
+	// Define vpp_get_container_logs tool
+	toolGetContainerLogs := &mcp.Tool{
+		Name: "vpp_get_container_logs",
+		Description: "Display the stdout logs of the vpp container of a calico-vpp pod by running 'kubectl logs -c vpp'. " +
+			"They capture the VPP startup errors (startup.conf, driver and interface initialization) and the crashes that 'vppctl show logging' misses.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- tail: Only fetch the last N lines (default: 1000, max: 20000)\n" +
+			"- since: Only fetch the lines written in the last duration, e.g. 10m or 1h\n" +
+			"- grep: Regular expression applied line-wise to the fetched lines; only matching lines are returned (e.g. 'error|warn')\n" +
+			"- previous: Fetch the logs of the previous instance of the vpp container, after a crash or a restart\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolGetContainerLogs, func(ctx context.Context, req *mcp.CallToolRequest, input ContainerLogsInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleGetContainerLogs(ctx, input, vppContainerName, "VPP Container Logs")
+	})
+
+	// Define vpp_get_crash_logs tool
+	toolGetCrashLogs := &mcp.Tool{
+		Name: "vpp_get_crash_logs",
+		Description: "Investigate the crashes of a calico-vpp pod: reports the restart count and last termination (reason, exit code, signal, time) " +
+			"of the vpp and agent containers, and fetches the logs of the previous instance of each restarted container by running 'kubectl logs --previous'.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- tail: Only fetch the last N lines of each previous instance (default: 300, max: 20000)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine,
 	}
-	mcp.AddTool(vppServer.server, toolGetLogs, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
-		return vppServer.handleVPPCommand(ctx, input, "show logging", "VPP Logs")
+	mcp.AddTool(vppServer.server, toolGetCrashLogs, func(ctx context.Context, req *mcp.CallToolRequest, input CrashLogsInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleGetCrashLogs(ctx, input)
+	})
+
+	// Define vpp_describe_pod tool
+	toolDescribePod := &mcp.Tool{
+		Name: "vpp_describe_pod",
+		Description: "Describe a calico-vpp pod from the Kubernetes API: phase, conditions, container states, readiness and restart counts, " +
+			"the last termination of restarted containers, resource requests and limits, and the recent events of the pod.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- events: Only report the N most recent events (default: 20)",
+	}
+	mcp.AddTool(vppServer.server, toolDescribePod, func(ctx context.Context, req *mcp.CallToolRequest, input DescribePodInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleDescribePod(ctx, input)
+	})
+
+	// Define vpp_node_conditions tool
+	toolNodeConditions := &mcp.Tool{
+		Name: "vpp_node_conditions",
+		Description: "Summarize the Kubernetes conditions (Ready, MemoryPressure, DiskPressure, PIDPressure, NetworkUnavailable), " +
+			"taints and cordoning of the nodes hosting calico-vpp pods, flagging the unhealthy ones. " +
+			"Node-level problems often masquerade as dataplane bugs, so check them before digging into VPP.\n\n" +
+			"Optional parameters:\n" +
+			"- node_name: Only summarize this node",
+	}
+	mcp.AddTool(vppServer.server, toolNodeConditions, func(ctx context.Context, req *mcp.CallToolRequest, input NodeConditionsInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleNodeConditions(ctx, input)
 	})
 
 	// Define vpp_show_cnat_translation tool
@@ -1439,7 +2876,10 @@ func main() {
 		Name: "vpp_show_cnat_translation",
 		Description: "Shows the active CNAT translations by running 'vppctl show cnat translation' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowCnatTranslation, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show cnat translation", "VPP CNAT Translation")
@@ -1454,7 +2894,10 @@ func main() {
 			"Then it displays the `5-tuple after dNAT & sNAT`, followed by the `direction` and finally the `age` in seconds. " +
 			"`direction` being input for the PRE-ROUTING sessions and output is the POST-ROUTING sessions\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowCnatSession, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show cnat session", "VPP CNAT Session")
@@ -1465,7 +2908,7 @@ func main() {
 		Name: "vpp_clear_run",
 		Description: "Clears live running error stats in VPP by running 'vppctl clear run' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + timeoutParameterDescription,
 	}
 	mcp.AddTool(vppServer.server, toolClearRun, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "clear run", "VPP Clear Runtime Statistics")
@@ -1481,11 +2924,258 @@ func main() {
 			"Output interpretation:\n" +
 			"A loaded VPP will typically have (1) a high Vectors/Call maxing out at 256 (2) a low loops/sec struggling around 10000. " +
 			"The Clocks column tells you the consumption in cycles per node on average. Beyond 1e3 is expensive.\n\n" +
+			"On nodes with many workers, narrow the output down with thread and max_nodes (applied by the server: 'show run' has no per-thread option).\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			"- thread: Only show this thread - 0 is the main thread, 1 the first worker (vpp_wk_0)\n" +
+			"- max_nodes: Only show the N nodes of every thread that processed the most vectors\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
-	mcp.AddTool(vppServer.server, toolShowRun, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
-		return vppServer.handleVPPCommand(ctx, input, "show run", "VPP Runtime Statistics")
+	mcp.AddTool(vppServer.server, toolShowRun, func(ctx context.Context, req *mcp.CallToolRequest, input VPPRuntimeInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleShowRun(ctx, input)
+	})
+
+	// Define vpp_memory_trace_start tool
+	toolMemoryTraceStart := &mcp.Tool{
+		Name: "vpp_memory_trace_start",
+		Description: "Start tracing the allocations of the VPP main heap by running 'vppctl memory-trace main-heap on' in a Kubernetes VPP container\n\n" +
+			"Debugging workflow:\n" +
+			"To track down slow heap growth, start the memory trace, let the node run in the issue state for a while, then run `vpp_memory_trace_report` " +
+			"to see the allocations still held and their call sites. Run `vpp_memory_trace_stop` afterwards: tracing slows down every allocation.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + timeoutParameterDescription,
+	}
+	mcp.AddTool(vppServer.server, toolMemoryTraceStart, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "memory-trace main-heap on", "VPP Main Heap Memory Trace Start")
+	})
+
+	// Define vpp_memory_trace_stop tool
+	toolMemoryTraceStop := &mcp.Tool{
+		Name: "vpp_memory_trace_stop",
+		Description: "Stop tracing the allocations of the VPP main heap by running 'vppctl memory-trace main-heap off' in a Kubernetes VPP container\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + timeoutParameterDescription,
+	}
+	mcp.AddTool(vppServer.server, toolMemoryTraceStop, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "memory-trace main-heap off", "VPP Main Heap Memory Trace Stop")
+	})
+
+	// Define vpp_memory_trace_report tool
+	toolMemoryTraceReport := &mcp.Tool{
+		Name: "vpp_memory_trace_report",
+		Description: "Show the VPP main heap usage and the traced allocations by running 'vppctl show memory main-heap verbose' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"The heap summary shows the total, used and free sizes. When a memory trace is running (see `vpp_memory_trace_start`), the allocations still held " +
+			"are listed with their size, count and call stack: call sites whose count keeps growing between reports are likely leaking.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolMemoryTraceReport, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show memory main-heap verbose", "VPP Main Heap Memory Report")
+	})
+
+	// Define vpp_perfmon_start tool
+	toolPerfmonStart := &mcp.Tool{
+		Name: "vpp_perfmon_start",
+		Description: "Start collecting CPU performance counters with the perfmon plugin by running 'vppctl perfmon start bundle <bundle>' in a Kubernetes VPP container\n\n" +
+			"Debugging workflow:\n" +
+			"List the bundles supported by the CPU with `vpp_perfmon_show` (bundles: true), start one (e.g. inst-and-clock for instructions and clocks " +
+			"per packet of every node, cache-hierarchy for cache misses), let traffic run for a few seconds, read the results with `vpp_perfmon_show`, " +
+			"then run `vpp_perfmon_stop`. Only one bundle runs at a time.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n" +
+			"- bundle: The perfmon bundle to collect, e.g. inst-and-clock\n\n" +
+			"Optional parameters:\n" +
+			"- type: Bundle type for bundles supporting several - node|thread|system\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolPerfmonStart, func(ctx context.Context, req *mcp.CallToolRequest, input VPPPerfmonStartInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handlePerfmonStart(ctx, input)
+	})
+
+	// Define vpp_perfmon_stop tool
+	toolPerfmonStop := &mcp.Tool{
+		Name: "vpp_perfmon_stop",
+		Description: "Stop collecting CPU performance counters by running 'vppctl perfmon stop' in a Kubernetes VPP container\n\n" +
+			"The statistics collected so far remain available to `vpp_perfmon_show`.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + timeoutParameterDescription,
+	}
+	mcp.AddTool(vppServer.server, toolPerfmonStop, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "perfmon stop", "VPP Perfmon Stop")
+	})
+
+	// Define vpp_perfmon_show tool
+	toolPerfmonShow := &mcp.Tool{
+		Name: "vpp_perfmon_show",
+		Description: "Show the results of the perfmon bundle started with `vpp_perfmon_start` by running 'vppctl show perfmon statistics' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Node bundles report one line per graph node and thread, e.g. instructions per packet and IPC for inst-and-clock: " +
+			"nodes with a low IPC or a high miss rate are the candidates for optimization.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			"- raw: Show the raw counter values instead of the computed metrics (default: false)\n" +
+			"- bundles: List the bundles supported by the CPU instead of the statistics, with 'show perfmon bundle verbose' (default: false)\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolPerfmonShow, func(ctx context.Context, req *mcp.CallToolRequest, input VPPPerfmonShowInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handlePerfmonShow(ctx, input)
+	})
+
+	// Define vpp_show_graph tool
+	toolShowGraph := &mcp.Tool{
+		Name: "vpp_show_graph",
+		Description: "Show the VPP packet processing node graph by running 'vppctl show vlib graph' in a Kubernetes VPP container\n\n" +
+			"Every node is listed with its next nodes (and the next slot of each arc) and its previous nodes. " +
+			"Use the json format for an adjacency list, or dot to visualize the graph with Graphviz.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			"- dot: Convert the graph to Graphviz DOT (default: false)\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowGraph, func(ctx context.Context, req *mcp.CallToolRequest, input VPPGraphInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleShowGraph(ctx, input)
+	})
+
+	// Define vpp_show_api_clients tool
+	toolShowAPIClients := &mcp.Tool{
+		Name: "vpp_show_api_clients",
+		Description: "List the clients connected to the VPP binary API by running 'vppctl show api clients' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"The calico-vpp agent programs the dataplane through the binary API and must be listed. When it is missing, or was replaced by a new client index after a restart, policies, routes and interfaces are no longer being programmed: check the agent container and vpp_show_api_ring_stats.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowAPIClients, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show api clients", "VPP Binary API Clients")
+	})
+
+	// Define vpp_show_api_ring_stats tool
+	toolShowAPIRingStats := &mcp.Tool{
+		Name: "vpp_show_api_ring_stats",
+		Description: "Show the usage of the VPP binary API shared memory rings by running 'vppctl show api ring-stats' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"A client whose pending message count stays high or keeps growing has stopped reading its replies: the agent is stuck and VPP may be blocked sending to it. Compare two runs a few seconds apart to tell a busy channel from a stuck one.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowAPIRingStats, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show api ring-stats", "VPP Binary API Ring Statistics")
+	})
+
+	// Define vpp_api_trace tool
+	toolAPITrace := &mcp.Tool{
+		Name: "vpp_api_trace",
+		Description: "Audit the binary API calls made to VPP (e.g. by the calico-vpp agent) by running 'vppctl api trace on', waiting, " +
+			"'vppctl api trace save' and 'vppctl api trace dump' in a Kubernetes VPP container\n\n" +
+			"Debugging workflow:\n" +
+			"When policies, routes or interfaces are not programmed as expected, trace the API while reproducing the change (e.g. applying a network policy) " +
+			"to see which messages the agent sent, in order, and with which arguments. Check `vpp_show_api_clients` first if the agent may be disconnected.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- duration_seconds: How long the API messages are traced (default: 30, max: 300)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolAPITrace, func(ctx context.Context, req *mcp.CallToolRequest, input VPPAPITraceInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleAPITrace(ctx, input)
+	})
+
+	// Define vpp_show_lldp tool
+	toolShowLLDP := &mcp.Tool{
+		Name: "vpp_show_lldp",
+		Description: "Show the LLDP neighbors of the VPP uplinks by running 'vppctl show lldp detail' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"For every interface with LLDP enabled, the chassis ID, port ID and system name advertised by the peer identify the switch and port it is cabled to. No neighbor means LLDP is disabled on the interface or the switch, or the link is down.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowLLDP, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show lldp detail", "VPP LLDP Neighbors")
+	})
+
+	// Define vpp_show_bond tool
+	toolShowBond := &mcp.Tool{
+		Name: "vpp_show_bond",
+		Description: "Show the bonded interfaces and their member links by running 'vppctl show bond details' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Every bond lists its mode (e.g. lacp, active-backup), load balancing and its members. A member configured but not active is not forwarding: check its link state and, in lacp mode, the LACP partner with vpp_show_lacp.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowBond, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show bond details", "VPP Bond Details")
+	})
+
+	// Define vpp_show_lacp tool
+	toolShowLACP := &mcp.Tool{
+		Name: "vpp_show_lacp",
+		Description: "Show the LACP state of the bond member links by running 'vppctl show lacp details' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"A healthy member shows the same partner system and key as the other members, with the collecting and distributing flags set on both actor and partner. A zero partner system ID means no LACPDU is received from the switch on that link.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowLACP, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show lacp details", "VPP LACP Details")
+	})
+
+	// Define vpp_show_lcp tool
+	toolShowLCP := &mcp.Tool{
+		Name: "vpp_show_lcp",
+		Description: "List the VPP to Linux interface pairs of the linux-cp plugin by running 'vppctl show lcp' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Every pair maps a VPP interface (phy) to the tap (host-if) mirroring it in a Linux network namespace. An empty output means the linux-cp plugin is loaded without pairs, and an unknown command error that the plugin is not loaded.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowLCP, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show lcp", "VPP Linux-CP Interface Pairs")
+	})
+
+	// Define vpp_show_urpf tool
+	toolShowURPF := &mcp.Tool{
+		Name: "vpp_show_urpf",
+		Description: "Show the unicast reverse path forwarding (uRPF) configuration of the interfaces by running 'vppctl show urpf' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Interfaces with strict uRPF drop packets whose source is not reachable back through the same interface, which breaks asymmetric routing; loose mode only requires the source to be routable. Drops show up as ip4-rx-urpf-strict / ip4-rx-urpf-loose errors in vpp_show_errors.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowURPF, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show urpf", "VPP uRPF Configuration")
 	})
 
 	// Define vpp_show_ip_table tool
@@ -1493,7 +3183,10 @@ func main() {
 		Name: "vpp_show_ip_table",
 		Description: "Prints all available IPv4 VRFs by running 'vppctl show ip table' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowIpTable, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show ip table", "VPP IPv4 VRF Tables")
@@ -1504,19 +3197,55 @@ func main() {
 		Name: "vpp_show_ip6_table",
 		Description: "Prints all available IPv6 VRFs by running 'vppctl show ip6 table' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + snapshotParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowIp6Table, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPCommand(ctx, input, "show ip6 table", "VPP IPv6 VRF Tables")
 	})
 
+	// Define vpp_show_ip_neighbors tool
+	toolShowIpNeighbors := &mcp.Tool{
+		Name: "vpp_show_ip_neighbors",
+		Description: "Show the IPv4 neighbor (ARP) table by running 'vppctl show ip4 neighbors' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Each entry shows the IP address, flags (S: static, D: dynamic, N: no-fib-entry), the MAC address and the interface. A peer or pod missing from the table, or an entry with an unexpected MAC address, points to an ARP resolution problem toward it.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowIpNeighbors, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show ip4 neighbors", "VPP IPv4 Neighbors")
+	})
+
+	// Define vpp_show_ip6_neighbors tool
+	toolShowIp6Neighbors := &mcp.Tool{
+		Name: "vpp_show_ip6_neighbors",
+		Description: "Show the IPv6 neighbor (ND) table by running 'vppctl show ip6 neighbors' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Each entry shows the IPv6 address, flags (S: static, D: dynamic, N: no-fib-entry), the MAC address and the interface. A peer or pod missing from the table points to a neighbor discovery problem toward it.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowIp6Neighbors, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show ip6 neighbors", "VPP IPv6 Neighbors")
+	})
+
 	// Define vpp_show_ip_fib tool
 	toolShowIpFib := &mcp.Tool{
 		Name: "vpp_show_ip_fib",
 		Description: "Prints all routes in a given pod IPv4 VRF by running 'vppctl show ip fib index <idx>' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP\n" +
-			"- fib_index: The FIB table index",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n" +
+			"- fib_index: The FIB table index" + timeoutParameterDescription + "\n" +
+			formatParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowIpFib, func(ctx context.Context, req *mcp.CallToolRequest, input VPPFIBInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPFIBCommand(ctx, input, "show ip fib index %s", "VPP IPv4 FIB Routes")
@@ -1527,8 +3256,9 @@ func main() {
 		Name: "vpp_show_ip6_fib",
 		Description: "Prints all routes in a given pod IPv6 VRF by running 'vppctl show ip6 fib index <idx>' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP\n" +
-			"- fib_index: The FIB table index",
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n" +
+			"- fib_index: The FIB table index" + timeoutParameterDescription + "\n" +
+			formatParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowIp6Fib, func(ctx context.Context, req *mcp.CallToolRequest, input VPPFIBInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPFIBCommand(ctx, input, "show ip6 fib index %s", "VPP IPv6 FIB Routes")
@@ -1539,9 +3269,10 @@ func main() {
 		Name: "vpp_show_ip_fib_prefix",
 		Description: "Prints information about a specific prefix in a given pod IPv4 VRF by running 'vppctl show ip fib index <idx> <prefix>' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n" +
 			"- fib_index: The FIB table index\n" +
-			"- prefix: The IP prefix to query (e.g., 10.0.0.0/24)",
+			"- prefix: The IP prefix to query (e.g., 10.0.0.0/24)" + timeoutParameterDescription + "\n" +
+			formatParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowIpFibPrefix, func(ctx context.Context, req *mcp.CallToolRequest, input VPPFIBPrefixInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPFIBPrefixCommand(ctx, input, "show ip fib index %s %s", "VPP IPv4 FIB Prefix Information")
@@ -1552,24 +3283,138 @@ func main() {
 		Name: "vpp_show_ip6_fib_prefix",
 		Description: "Prints information about a specific prefix in a given pod IPv6 VRF by running 'vppctl show ip6 fib index <idx> <prefix>' in a Kubernetes VPP container\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running VPP\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n" +
 			"- fib_index: The FIB table index\n" +
-			"- prefix: The IPv6 prefix to query (e.g., 2001:db8::/32)",
+			"- prefix: The IPv6 prefix to query (e.g., 2001:db8::/32)" + timeoutParameterDescription + "\n" +
+			formatParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolShowIp6FibPrefix, func(ctx context.Context, req *mcp.CallToolRequest, input VPPFIBPrefixInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.handleVPPFIBPrefixCommand(ctx, input, "show ip6 fib index %s %s", "VPP IPv6 FIB Prefix Information")
 	})
 
+	// Define vpp_show_wireguard tool
+	toolShowWireguard := &mcp.Tool{
+		Name: "vpp_show_wireguard",
+		Description: "Show the Wireguard peers by running 'vppctl show wireguard peer' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Each peer shows its endpoint address and port, its public key, the allowed IPs routed through it and its handshake state. A peer without a recent handshake, or with an endpoint or allowed IPs not matching the remote node, will not carry encrypted traffic.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowWireguard, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show wireguard peer", "VPP Wireguard Peers")
+	})
+
+	// Define vpp_show_gre tool
+	toolShowGre := &mcp.Tool{
+		Name: "vpp_show_gre",
+		Description: "Show the GRE tunnels by running 'vppctl show gre tunnel' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Each tunnel shows its index, source and destination addresses, FIB index, payload type (L3, TEB or ERSPAN) and mode (point-to-point or multipoint). Check the source and destination against the node addresses and the FIB index against the expected VRF.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowGre, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show gre tunnel", "VPP GRE Tunnels")
+	})
+
+	// Define vpp_show_sr_policies tool
+	toolShowSrPolicies := &mcp.Tool{
+		Name: "vpp_show_sr_policies",
+		Description: "Show the SRv6 policies by running 'vppctl show sr policies' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Each policy shows its binding SID, its type (default or spray), its FIB table and the weighted segment lists traffic steered into it is encapsulated with.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowSrPolicies, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show sr policies", "VPP SRv6 Policies")
+	})
+
+	// Define vpp_show_sr_localsids tool
+	toolShowSrLocalsids := &mcp.Tool{
+		Name: "vpp_show_sr_localsids",
+		Description: "Show the SRv6 local SIDs by running 'vppctl show sr localsids' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Each local SID shows its address, its behavior (e.g. End, End.DT4, End.DT6, End.DX4) with its parameters, and the number of packets and bytes it has processed. A SID whose counters stay at zero is not receiving the traffic it is expected to terminate.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowSrLocalsids, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show sr localsids", "VPP SRv6 Local SIDs")
+	})
+
+	// Define vpp_show_sr_steering_policies tool
+	toolShowSrSteeringPolicies := &mcp.Tool{
+		Name: "vpp_show_sr_steering_policies",
+		Description: "Show the SRv6 steering policies by running 'vppctl show sr steering-policies' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Each entry maps a traffic selector (an L3 prefix in a FIB table, or an L2 interface) to the binding SID of the SR policy it is steered into.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowSrSteeringPolicies, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show sr steering-policies", "VPP SRv6 Steering Policies")
+	})
+
+	// Define vpp_show_virtio tool
+	toolShowVirtio := &mcp.Tool{
+		Name: "vpp_show_virtio",
+		Description: "Show the virtio PCI interfaces by running 'vppctl show virtio pci' in a Kubernetes VPP container\n\n" +
+			"Output interpretation:\n" +
+			"Each interface shows its PCI address, the negotiated features (look for GSO and checksum offload: VIRTIO_NET_F_CSUM, VIRTIO_NET_F_GUEST_TSO4/6, VIRTIO_NET_F_HOST_TSO4/6), the number of queue pairs and the size of every RX and TX ring. Missing offloads or small rings on the uplink limit the throughput of the node.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowVirtio, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show virtio pci", "VPP Virtio PCI Interfaces")
+	})
+
+	// Define vpp_ip_route_lookup tool
+	toolRouteLookup := &mcp.Tool{
+		Name: "vpp_ip_route_lookup",
+		Description: "Look up the route VPP uses for a destination in every FIB table by running 'vppctl show ip fib <address>' " +
+			"(or 'show ip6 fib <address>') in a Kubernetes VPP container\n\n" +
+			"Unlike vpp_show_ip_fib_prefix, no fib_index is required: the longest prefix match, and the adjacencies it forwards to, " +
+			"are reported for every table.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + "\n" +
+			"- address: The IPv4 or IPv6 destination address (e.g. 10.0.0.1)" + timeoutParameterDescription,
+	}
+	mcp.AddTool(vppServer.server, toolRouteLookup, func(ctx context.Context, req *mcp.CallToolRequest, input RouteLookupInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleRouteLookup(ctx, input)
+	})
+
 	// Define bgp_show_neighbors tool
 	toolBgpShowNeighbors := &mcp.Tool{
 		Name: "bgp_show_neighbors",
 		Description: "Show BGP peers by running 'gobgp neighbor' in the agent container of a calico-vpp pod\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp\n\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
 			"Output interpretation:\n" +
 			"- Established peerings will show up as Establ\n" +
 			"- Unsuccessful connections will show up as Opened with 0 in #Received Accepted\n" +
-			"- CalicoVPP learns about new peers using the kubernetes API. If peers are missing from this list, there might be an issue accessing this API",
+			"- CalicoVPP learns about new peers using the kubernetes API. If peers are missing from this list, there might be an issue accessing this API" + snapshotParameterDescription + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolBgpShowNeighbors, func(ctx context.Context, req *mcp.CallToolRequest, input BGPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.HandleGoBGPCommand(ctx, input, "neighbor", "BGP Neighbor Information")
@@ -1580,9 +3425,11 @@ func main() {
 		Name: "bgp_show_global_info",
 		Description: "Show BGP global information by running 'gobgp global' in the agent container of a calico-vpp pod\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp\n\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
 			"Output interpretation:\n" +
-			"- Shows the information goBGP advertises to peers",
+			"- Shows the information goBGP advertises to peers" + snapshotParameterDescription + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolBgpShowGlobalInfo, func(ctx context.Context, req *mcp.CallToolRequest, input BGPCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.HandleGoBGPCommand(ctx, input, "global", "BGP Global Information")
@@ -1593,14 +3440,22 @@ func main() {
 		Name: "bgp_show_global_rib4",
 		Description: "Show BGP IPv4 RIB information by running 'gobgp global rib -a 4' in the agent container of a calico-vpp pod\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp\n\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
 			"Output interpretation:\n" +
 			"- Prints out the IPv4 prefixes advertised by peers\n" +
 			"- Next Hop being the peer's IP\n" +
-			"- Shows all route information",
+			"- Shows all route information\n\n" +
+			"Optional parameters:\n" +
+			"- community: Only show the routes carrying this standard community (e.g. 65000:100)\n" +
+			"- asn: Only show the routes whose AS path contains this AS number\n" +
+			"- neighbor: Only show the routes received from this BGP neighbor IP, before import policies (runs 'gobgp neighbor <neighborIP> adj-in -a 4')\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
 	}
-	mcp.AddTool(vppServer.server, toolBgpShowGlobalRib4, func(ctx context.Context, req *mcp.CallToolRequest, input BGPCommandInput) (*mcp.CallToolResult, any, error) {
-		return vppServer.HandleGoBGPCommand(ctx, input, "global rib -a 4", "BGP IPv4 RIB Information")
+	mcp.AddTool(vppServer.server, toolBgpShowGlobalRib4, func(ctx context.Context, req *mcp.CallToolRequest, input BGPRIBInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleGoBGPRIB(ctx, input, "4")
 	})
 
 	// Define bgp_show_global_rib6 tool
@@ -1608,14 +3463,43 @@ func main() {
 		Name: "bgp_show_global_rib6",
 		Description: "Show BGP IPv6 RIB information by running 'gobgp global rib -a 6' in the agent container of a calico-vpp pod\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp\n\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
 			"Output interpretation:\n" +
 			"- Prints out the IPv6 prefixes advertised by peers\n" +
 			"- Next Hop being the peer's IP\n" +
-			"- Shows all route information",
+			"- Shows all route information\n\n" +
+			"Optional parameters:\n" +
+			"- community: Only show the routes carrying this standard community (e.g. 65000:100)\n" +
+			"- asn: Only show the routes whose AS path contains this AS number\n" +
+			"- neighbor: Only show the routes received from this BGP neighbor IP, before import policies (runs 'gobgp neighbor <neighborIP> adj-in -a 6')\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolBgpShowGlobalRib6, func(ctx context.Context, req *mcp.CallToolRequest, input BGPRIBInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleGoBGPRIB(ctx, input, "6")
+	})
+
+	// Define bgp_show_rib_summary tool
+	toolBgpShowRibSummary := &mcp.Tool{
+		Name: "bgp_show_rib_summary",
+		Description: "Show the size of the BGP RIB by running 'gobgp global rib summary -a 4|6' in the agent container of a calico-vpp pod, without dumping the entire table\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- family: RIB to summarize - 4|6 (default: 4)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Destination is the number of prefixes in the table, Path the number of paths to them\n" +
+			"- More paths than destinations means several peers advertise the same prefixes\n" +
+			"- Use it to size a RIB before running bgp_show_global_rib4 or bgp_show_global_rib6 on a large cluster",
 	}
-	mcp.AddTool(vppServer.server, toolBgpShowGlobalRib6, func(ctx context.Context, req *mcp.CallToolRequest, input BGPCommandInput) (*mcp.CallToolResult, any, error) {
-		return vppServer.HandleGoBGPCommand(ctx, input, "global rib -a 6", "BGP IPv6 RIB Information")
+	mcp.AddTool(vppServer.server, toolBgpShowRibSummary, func(ctx context.Context, req *mcp.CallToolRequest, input BGPRIBSummaryInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleGoBGPRIBSummary(ctx, input)
 	})
 
 	// Define bgp_show_ip tool
@@ -1623,11 +3507,12 @@ func main() {
 		Name: "bgp_show_ip",
 		Description: "Show BGP RIB entry for a specific IP by running 'gobgp global rib <ip>' in the agent container of a calico-vpp pod\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n" +
 			"- ip: The IP address to query\n\n" +
 			"Output interpretation:\n" +
 			"- Prints the RIB entry for that specific IP\n" +
-			"- Shows specific route information",
+			"- Shows specific route information" + timeoutParameterDescription + "\n" +
+			formatParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolBgpShowIp, func(ctx context.Context, req *mcp.CallToolRequest, input BGPParameterCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.HandleGoBGPParameterCommand(ctx, input, "global rib %s", "BGP RIB Entry for IP")
@@ -1638,11 +3523,12 @@ func main() {
 		Name: "bgp_show_prefix",
 		Description: "Show BGP RIB entry for a specific prefix by running 'gobgp global rib <prefix>' in the agent container of a calico-vpp pod\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n" +
 			"- prefix: The prefix to query (e.g., 10.0.0.0/24)\n\n" +
 			"Output interpretation:\n" +
 			"- Prints the RIB entry for that specific prefix\n" +
-			"- Shows specific route information",
+			"- Shows specific route information" + timeoutParameterDescription + "\n" +
+			formatParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolBgpShowPrefix, func(ctx context.Context, req *mcp.CallToolRequest, input BGPParameterCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.HandleGoBGPParameterCommand(ctx, input, "global rib %s", "BGP RIB Entry for Prefix")
@@ -1653,19 +3539,717 @@ func main() {
 		Name: "bgp_show_neighbor",
 		Description: "Show detailed information for a specific BGP neighbor by running 'gobgp neighbor <neighborIP>' in the agent container of a calico-vpp pod\n\n" +
 			"Required parameters:\n" +
-			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp\n" +
-			"- neighbor_ip: The IP address of the BGP neighbor\n\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n" +
+			"- parameter: The IP address of the BGP neighbor, or the Kubernetes node name of a node peering with this node\n\n" +
 			"Output interpretation:\n" +
-			"- Prints detailed status information for the specified BGP peer",
+			"- Prints detailed status information for the specified BGP peer" + timeoutParameterDescription + "\n" +
+			formatParameterLine,
 	}
 	mcp.AddTool(vppServer.server, toolBgpShowNeighbor, func(ctx context.Context, req *mcp.CallToolRequest, input BGPParameterCommandInput) (*mcp.CallToolResult, any, error) {
 		return vppServer.HandleGoBGPParameterCommand(ctx, input, "neighbor %s", "BGP Neighbor Details")
 	})
 
+	// Define bgp_show_adj_rib_in tool
+	toolBgpShowAdjRibIn := &mcp.Tool{
+		Name: "bgp_show_adj_rib_in",
+		Description: "Show the routes received from a specific BGP neighbor, before import policies are applied, by running 'gobgp neighbor <neighborIP> adj-in' in the agent container of a calico-vpp pod\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n" +
+			"- parameter: The IP address of the BGP neighbor, or the Kubernetes node name of a node peering with this node\n\n" +
+			"Output interpretation:\n" +
+			"- Prints the Adj-RIB-In of the peer: every route it advertised, including the routes rejected by policy and missing from 'gobgp global rib'\n" +
+			"- Only IPv4 unicast routes are listed" + timeoutParameterDescription + "\n" +
+			formatParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolBgpShowAdjRibIn, func(ctx context.Context, req *mcp.CallToolRequest, input BGPParameterCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.HandleGoBGPParameterCommand(ctx, input, "neighbor %s adj-in", "BGP Adj-RIB-In")
+	})
+
+	// Define bgp_show_adj_rib_out tool
+	toolBgpShowAdjRibOut := &mcp.Tool{
+		Name: "bgp_show_adj_rib_out",
+		Description: "Show the routes advertised to a specific BGP neighbor, after export policies are applied, by running 'gobgp neighbor <neighborIP> adj-out' in the agent container of a calico-vpp pod\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n" +
+			"- parameter: The IP address of the BGP neighbor, or the Kubernetes node name of a node peering with this node\n\n" +
+			"Output interpretation:\n" +
+			"- Prints the Adj-RIB-Out of the peer: exactly the prefixes this node advertises to it, with their next hop and attributes\n" +
+			"- A pod CIDR missing here is not advertised, e.g. because an export policy filters it, and the peer cannot learn it\n" +
+			"- Only IPv4 unicast routes are listed" + timeoutParameterDescription + "\n" +
+			formatParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolBgpShowAdjRibOut, func(ctx context.Context, req *mcp.CallToolRequest, input BGPParameterCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.HandleGoBGPParameterCommand(ctx, input, "neighbor %s adj-out", "BGP Adj-RIB-Out")
+	})
+
+	// Define bgp_monitor tool
+	toolBgpMonitor := &mcp.Tool{
+		Name: "bgp_monitor",
+		Description: "Watch the BGP global RIB by running 'gobgp monitor global rib' in the agent container of a calico-vpp pod for a bounded duration, " +
+			"and return the route additions and withdrawals observed in that window\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			fmt.Sprintf("- duration_seconds: How long the RIB is monitored (default: %d, max: %d)\n", int(defaultBGPMonitorDuration.Seconds()), int(maxBGPMonitorDuration.Seconds())) +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Lists every event with its time, prefix, next hop and AS path, and counts the additions and withdrawals of each prefix\n" +
+			"- Prefixes both withdrawn and advertised, or changed several times, during the window are reported as flapping\n" +
+			"- No event means the global RIB was stable during the window",
+	}
+	mcp.AddTool(vppServer.server, toolBgpMonitor, func(ctx context.Context, req *mcp.CallToolRequest, input BGPMonitorInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleBGPMonitor(ctx, input)
+	})
+
+	// Define bgp_mrt_dump tool
+	toolBgpMRTDump := &mcp.Tool{
+		Name: "bgp_mrt_dump",
+		Description: "Export the BGP RIB in MRT format by running 'gobgp mrt dump rib' in the agent container of a calico-vpp pod, " +
+			"and store the dump as an artifact served as an MCP resource for offline analysis with standard BGP tooling (bgpdump, bgpscanner, ...)\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- neighbor: Dump the Adj-RIB-In of this BGP neighbor IP instead of the global RIB\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"The result links the resource of the dump; the dump file is removed from the container once fetched.",
+	}
+	mcp.AddTool(vppServer.server, toolBgpMRTDump, func(ctx context.Context, req *mcp.CallToolRequest, input BGPMRTDumpInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleBGPMRTDump(ctx, input)
+	})
+
+	// Define bgp_peer_flaps tool
+	toolBgpPeerFlaps := &mcp.Tool{
+		Name: "bgp_peer_flaps",
+		Description: "Detect flapping BGP peers by sampling 'gobgp neighbor' on several calico-vpp pods (default: every pod) twice, interval_seconds apart, " +
+			"or by comparing it with the latest stored snapshot of each pod, and flag the sessions that restarted in between\n\n" +
+			"Optional parameters:\n" +
+			"- pod_names: The calico-vpp pods to check (default: every calico-vpp pod)" + nodeNamesParameterDescription + "\n" +
+			fmt.Sprintf("- interval_seconds: Time between the two samples (default: %d, max: %d)\n", int(defaultBGPFlapInterval.Seconds()), int(maxBGPFlapInterval.Seconds())) +
+			"- from_snapshots: Compare with the latest stored snapshot of each pod (see vpp_snapshot) instead of sampling twice (default: false)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- restarted: the session is established in both samples but its uptime is shorter than the time between them, so it was reset meanwhile\n" +
+			"- went down / came up: the session left or reached the Establ state\n" +
+			"- added / removed: the peer appeared in or disappeared from the neighbor list",
+	}
+	mcp.AddTool(vppServer.server, toolBgpPeerFlaps, func(ctx context.Context, req *mcp.CallToolRequest, input BGPPeerFlapsInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleBGPPeerFlaps(ctx, input)
+	})
+
+	// Define bgp_route_counts tool
+	toolBgpRouteCounts := &mcp.Tool{
+		Name: "bgp_route_counts",
+		Description: "Report the routes received, accepted and advertised by every BGP peer of several calico-vpp pods (default: every pod), " +
+			"by running 'gobgp neighbor' and 'gobgp neighbor <neighborIP>' in their agent containers, and highlight the outliers\n\n" +
+			"Optional parameters:\n" +
+			"- pod_names: The calico-vpp pods to report (default: every calico-vpp pod)" + nodeNamesParameterDescription + "\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- One line per pod and peer; outliers are marked with what stands out\n" +
+			"- A node receiving no route, or far fewer routes than the cluster median, misses routes its peers should send\n" +
+			"- Received but not accepted routes are rejected by an import policy; a peer receiving no advertised route cannot learn the pod CIDRs of the node",
+	}
+	mcp.AddTool(vppServer.server, toolBgpRouteCounts, func(ctx context.Context, req *mcp.CallToolRequest, input BGPRouteCountsInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleBGPRouteCounts(ctx, input)
+	})
+
+	// Define bgp_graceful_restart tool
+	toolBgpGracefulRestart := &mcp.Tool{
+		Name: "bgp_graceful_restart",
+		Description: "Report the graceful restart capabilities and timers negotiated with the BGP neighbors of a calico-vpp pod, parsed from 'gobgp neighbor <neighborIP>', " +
+			"so graceful restart misconfigurations between Calico VPP and the ToRs can be spotted\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + fanOutParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- neighbor: The IP address of the BGP neighbor to report (default: every neighbor of the pod)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- For each neighbor, the graceful-restart and long-lived-graceful-restart capabilities sent by this node (Local) and the peer (Remote): restart time, flags and families\n" +
+			"- Issues flag a capability advertised by one side only, families with graceful restart on one side only, forwarding state not preserved by the peer and differing restart times",
+	}
+	mcp.AddTool(vppServer.server, toolBgpGracefulRestart, func(ctx context.Context, req *mcp.CallToolRequest, input BGPGracefulRestartInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleBGPGracefulRestart(ctx, input)
+	})
+
+	// Define bgp_rib_divergence tool
+	toolBgpRIBDivergence := &mcp.Tool{
+		Name: "bgp_rib_divergence",
+		Description: "Compare the BGP RIB of several calico-vpp pods (default: every pod) by running 'gobgp global rib -a 4' and 'gobgp global rib -a 6' in their agent containers, " +
+			"and report the prefixes whose presence or next hop differs between the nodes\n\n" +
+			"Optional parameters:\n" +
+			"- pod_names: The calico-vpp pods to compare (default: every calico-vpp pod)" + nodeNamesParameterDescription + "\n" +
+			"- family: RIB to compare - 4|6|both (default: both)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Prefixes missing on some pods are listed with those pods\n" +
+			"- Prefixes whose best path goes through different next hops are listed with the pods using each next hop; the routes a node originates itself are not compared\n" +
+			"- Either usually indicates a partial peering or a policy problem",
+	}
+	mcp.AddTool(vppServer.server, toolBgpRIBDivergence, func(ctx context.Context, req *mcp.CallToolRequest, input BGPRIBDivergenceInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleBGPRIBDivergence(ctx, input)
+	})
+
+	// Define bgp_peer_reconcile tool
+	toolBgpPeerReconcile := &mcp.Tool{
+		Name: "bgp_peer_reconcile",
+		Description: "Read the Calico BGPPeer and BGPConfiguration resources through the Kubernetes API and compare the peers they configure on each node " +
+			"with the output of 'gobgp neighbor' in the agent container of the calico-vpp pod of the node\n\n" +
+			"Optional parameters:\n" +
+			"- pod_names: The calico-vpp pods to check (default: every calico-vpp pod)" + nodeNamesParameterDescription + "\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Expected peers come from the node-to-node mesh (unless disabled in the default BGPConfiguration), global BGPPeers and the BGPPeers selecting the node\n" +
+			"- Missing peers are configured but have no gobgp session on the node, unexpected peers have a session no resource accounts for\n" +
+			"- AS mismatches are peers whose AS differs from the asNumber of their BGPPeer\n" +
+			"- Selectors other than all(), has(), !has(), == and != joined by && are not evaluated and listed as such",
+	}
+	mcp.AddTool(vppServer.server, toolBgpPeerReconcile, func(ctx context.Context, req *mcp.CallToolRequest, input BGPPeerReconcileInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleBGPPeerReconcile(ctx, input)
+	})
+
+	// Define bgp_pod_route tool
+	toolBgpPodRoute := &mcp.Tool{
+		Name: "bgp_pod_route",
+		Description: "Find the route to a workload pod IP across the cluster: the node running the pod is looked up in the Kubernetes API, " +
+			"then the covering prefix is looked up with 'gobgp global rib -a 4|6 <ip>' and 'vppctl show ip fib|ip6 fib <ip>' on every calico-vpp pod\n\n" +
+			"Required parameters:\n" +
+			"- pod_ip: IP address of the workload pod\n\n" +
+			"Optional parameters:\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Each node lists the longest BGP prefix covering the pod IP with its next hop, and the matching entry of the default FIB table with its adjacencies\n" +
+			"- Nodes with no covering prefix, only a default route, or a FIB entry dropping the packets are flagged: the pod is unreachable from them\n" +
+			"- The owner node is expected to have a host route to the pod tap and to originate a covering prefix",
+	}
+	mcp.AddTool(vppServer.server, toolBgpPodRoute, func(ctx context.Context, req *mcp.CallToolRequest, input BGPPodRouteInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleBGPPodRoute(ctx, input)
+	})
+
+	// Define bgp_block_affinities tool
+	toolBgpBlockAffinities := &mcp.Tool{
+		Name: "bgp_block_affinities",
+		Description: "List the Calico IPAM block affinities per node from the Kubernetes API and cross-check the routes of every block " +
+			"with 'gobgp global rib -a 4|6' and 'vppctl show ip fib|ip6 fib index 0' on the calico-vpp pods (default: every pod), catching stale-affinity routing bugs\n\n" +
+			"Optional parameters:\n" +
+			"- pod_names: The calico-vpp pods whose routes are checked (default: every calico-vpp pod)" + nodeNamesParameterDescription + "\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Each node lists its affine blocks with their state and the number of addresses in use\n" +
+			"- A confirmed block is flagged when its node does not originate it, when another node originates it, when the other nodes route it to another node, or when it is missing from their VPP FIB\n" +
+			"- Affinities not confirmed, of a deleted node, or disagreeing with their IPAMBlock are flagged, and the blocks without a confirmed affinity still advertised are listed",
+	}
+	mcp.AddTool(vppServer.server, toolBgpBlockAffinities, func(ctx context.Context, req *mcp.CallToolRequest, input BlockAffinitiesInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleBlockAffinities(ctx, input)
+	})
+
+	// Define vpp_packet_journey tool
+	toolPacketJourney := &mcp.Tool{
+		Name: "vpp_packet_journey",
+		Description: "Trace one flow on every calico-vpp pod along its path at the same time and stitch the per-node traces into a single ordered packet journey\n\n" +
+			"A trace filter matching the 5-tuple is installed on the source node, the destination node and the optional gateway pods, " +
+			"so only packets of the flow are captured.\n\n" +
+			"Required parameters:\n" +
+			"- source_node: The Kubernetes node the flow originates from\n" +
+			"- destination_node: The Kubernetes node the flow is destined to\n" +
+			"- src_ip: Source IP address of the flow\n" +
+			"- dst_ip: Destination IP address of the flow\n\n" +
+			"Optional parameters:\n" +
+			"- protocol: tcp|udp|icmp\n" +
+			"- src_port: Source port (tcp and udp only)\n" +
+			"- dst_port: Destination port (tcp and udp only)\n" +
+			"- gateway_pods: Intermediate calico-vpp pods traversed by the flow, in order\n" +
+			"- interfaces: Interface types traced on every pod (default: virtio, phy)\n" +
+			"- count: Number of packets to capture per pod, at most 1000 (default: 50)\n" +
+			"- duration_seconds: How long the traces run, at most 300 (default: 30)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Each hop lists the graph node path of every captured packet, in journey order\n" +
+			"- Packets ending in a drop node are marked [DROPPED]\n" +
+			"- The summary tells whether the flow reached the destination or the last hop where it was seen",
+	}
+	mcp.AddTool(vppServer.server, toolPacketJourney, func(ctx context.Context, req *mcp.CallToolRequest, input PacketJourneyInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handlePacketJourney(ctx, input)
+	})
+
+	// Define vpp_qos_preservation tool
+	toolQoSPreservation := &mcp.Tool{
+		Name: "vpp_qos_preservation",
+		Description: "Verify that DSCP and ECN bits are preserved (or remarked as configured) across the VPP path using a short capture on ingress and egress\n\n" +
+			"The ToS byte of the IP header traced on ingress is compared with the one of the packet transmitted on egress_interface, captured with 'pcap trace tx'. " +
+			"Egress packets are matched to the traced ones by IPv4 identification, or, when a flow is given, by the ToS shared by all its traced packets. " +
+			"Without egress_interface, the last IP header decoded in the trace (after ip4-rewrite/ip6-rewrite) stands for the egress one, which misses changes made by output features.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- interface: Interface type traced on ingress - phy|af_xdp|af_packet|avf|vmxnet3|virtio|rdma|dpdk|memif|vcl (default: virtio)\n" +
+			"- egress_interface: VPP interface the packets leave through, e.g. tap0 or host-eth0 (default: read the egress bits from the trace)\n" +
+			"- src_ip, dst_ip: Restrict the capture to one flow (both required together)\n" +
+			"- protocol, src_port, dst_port: Further restrict the flow\n" +
+			"- expected_dscp: DSCP value packets are expected to be remarked to (default: unchanged)\n" +
+			"- count: Number of packets to capture, at most 1000 (default: 100)\n" +
+			"- duration_seconds: How long the capture runs, at most 300 (default: 10)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Packets whose egress DSCP differs from the expected value, or whose ECN bits were cleared, are listed with their graph node path, or their headers when captured on egress_interface\n" +
+			"- Egress packets that can't be matched to a traced packet are counted as not verifiable\n" +
+			"- ECN changing to CE is allowed, since VPP may mark congestion",
+	}
+	mcp.AddTool(vppServer.server, toolQoSPreservation, func(ctx context.Context, req *mcp.CallToolRequest, input QoSPreservationInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleQoSPreservation(ctx, input)
+	})
+
+	// Define vpp_latency_probe tool
+	toolLatencyProbe := &mcp.Tool{
+		Name: "vpp_latency_probe",
+		Description: "Run 'vppctl ping' between the uplink addresses of calico-vpp nodes and report an RTT/loss matrix, " +
+			"establishing whether underlay problems explain overlay symptoms\n\n" +
+			"Optional parameters:\n" +
+			"- source_node: Only probe from this node (default: every node)\n" +
+			"- destination_node: Only probe this node (default: every node)\n" +
+			"- count: Number of pings per pair (default: 5, max: 20)\n" +
+			"- timeout_seconds: Timeout of each ping, in seconds (default: count plus 5 seconds, or server --exec-timeout when longer)\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Each cell shows the average RTT and the packet loss from the row node to the column node\n" +
+			"- Loss or high RTT on the underlay points to a network problem outside of VPP",
+	}
+	mcp.AddTool(vppServer.server, toolLatencyProbe, func(ctx context.Context, req *mcp.CallToolRequest, input LatencyProbeInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleLatencyProbe(ctx, input)
+	})
+
+	// Define vpp_interface_rates tool
+	toolInterfaceRates := &mcp.Tool{
+		Name: "vpp_interface_rates",
+		Description: "Sample the interface counters twice by running 'vppctl show int' in a Kubernetes VPP container and report the packet, bit, drop and error rates of every interface\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- interface: Only report this interface (default: every interface)\n" +
+			"- interval_seconds: Delay between the two samples (default: 5, max: 60)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Interfaces are sorted by total packet rate, busiest first\n" +
+			"- Drops, RX misses (RX queue overflows) or errors during the interval are listed in the summary",
+	}
+	mcp.AddTool(vppServer.server, toolInterfaceRates, func(ctx context.Context, req *mcp.CallToolRequest, input VPPInterfaceRatesInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleInterfaceRates(ctx, input)
+	})
+
+	// Define vpp_analyze_run tool
+	toolAnalyzeRun := &mcp.Tool{
+		Name: "vpp_analyze_run",
+		Description: "Assess the load of every VPP thread by parsing 'vppctl show run' in a Kubernetes VPP container, " +
+			"and report its vectors/call, loops/sec, a load classification (idle/moderate/saturated) and its most expensive nodes\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+			"Optional parameters:\n" +
+			"- top: Number of most expensive nodes reported per thread (default: 5)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- A thread is saturated when its vectors/call approaches 256 or its loops/sec drops to around 10000\n" +
+			"- The most expensive nodes spend the most cycles in total (clocks/vector times vectors); beyond 1e3 clocks/vector a node is expensive\n" +
+			"- Run `vpp_clear_run` first and wait a few seconds in the issue state so the node statistics only cover the issue",
+	}
+	mcp.AddTool(vppServer.server, toolAnalyzeRun, func(ctx context.Context, req *mcp.CallToolRequest, input VPPAnalyzeRunInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleAnalyzeRun(ctx, input)
+	})
+
+	// Define pcap_decode tool
+	toolPcapDecode := &mcp.Tool{
+		Name: "pcap_decode",
+		Description: "Fetch a capture file from a Kubernetes VPP container and list its packets (timestamp, 5-tuple, length, TCP flags), " +
+			"so captures taken with vpp_pcap can be analyzed without external tooling\n\n" +
+			"Required parameters (one of):\n" +
+			"- pod_name: The name of the Kubernetes pod the capture is fetched from" + nodeNameParameterDescription + "\n" +
+			"- artifact_id: A capture already kept in the artifact store, instead of fetching one\n\n" +
+			"Optional parameters:\n" +
+			"- file: Capture file in the vpp container, a .pcap file in /tmp (default: /tmp/trace.pcap, written by vpp_pcap)\n" +
+			fmt.Sprintf("- max_packets: Number of packets listed (default: %d, max: %d)\n", defaultPcapDecodePackets, maxPcapDecodePackets) +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"The fetched capture is stored in the artifact store, so it can be decoded again or downloaded as an artifact:// resource. " +
+			"Dispatch traces (/tmp/dispatch.pcap) carry VPP buffer metadata and are not decoded.",
+	}
+	mcp.AddTool(vppServer.server, toolPcapDecode, func(ctx context.Context, req *mcp.CallToolRequest, input PcapDecodeInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handlePcapDecode(ctx, input)
+	})
+
+	// Define pcap_analyze tool
+	toolPcapAnalyze := &mcp.Tool{
+		Name: "pcap_analyze",
+		Description: "Fetch a capture file from a Kubernetes VPP container and summarize it: protocol breakdown, top flows by packets and by bytes, " +
+			"and TCP retransmissions per flow\n\n" +
+			"Required parameters (one of):\n" +
+			"- pod_name: The name of the Kubernetes pod the capture is fetched from" + nodeNameParameterDescription + "\n" +
+			"- artifact_id: A capture already kept in the artifact store, e.g. fetched by pcap_decode\n\n" +
+			"Optional parameters:\n" +
+			"- file: Capture file in the vpp container, a .pcap file in /tmp (default: /tmp/trace.pcap, written by vpp_pcap)\n" +
+			fmt.Sprintf("- top: Number of flows reported in each ranking (default: %d)\n", defaultPcapTopFlows) +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- Flows are unidirectional; both directions of a connection are reported separately\n" +
+			"- A TCP segment carrying data with the sequence number of an earlier segment of the same flow counts as a retransmission",
+	}
+	mcp.AddTool(vppServer.server, toolPcapAnalyze, func(ctx context.Context, req *mcp.CallToolRequest, input PcapAnalyzeInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handlePcapAnalyze(ctx, input)
+	})
+
+	// Define verify_service tool
+	toolVerifyService := &mcp.Tool{
+		Name: "verify_service",
+		Description: "Verify that a Kubernetes Service is programmed in VPP: reads its ClusterIPs, external and load balancer IPs, ports and ready endpoints " +
+			"from the Kubernetes API, then checks on every node that 'vppctl show cnat translation' translates each VIP and port to exactly these endpoints\n\n" +
+			"Required parameters:\n" +
+			"- service: The name of the Kubernetes service\n\n" +
+			"Optional parameters:\n" +
+			"- namespace: The namespace of the service (default: default)\n" +
+			"- pod_names: The calico-vpp pods to check (default: every calico-vpp pod)" + nodeNamesParameterDescription + "\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- missing translation: the node has no CNAT translation for the VIP and port, traffic to the service is not load balanced there\n" +
+			"- missing backend: a ready endpoint is not a backend of the translation\n" +
+			"- stale backend: the translation still sends traffic to an endpoint that is gone or not ready",
+	}
+	mcp.AddTool(vppServer.server, toolVerifyService, func(ctx context.Context, req *mcp.CallToolRequest, input VerifyServiceInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVerifyService(ctx, input)
+	})
+
+	// Define verify_network_policy tool
+	toolVerifyNetworkPolicy := &mcp.Tool{
+		Name: "verify_network_policy",
+		Description: "Verify that a Kubernetes or Calico NetworkPolicy is programmed for a pod in VPP: checks that the policy selects the pod, finds the pod interface " +
+			"in 'vppctl show npol interfaces' on its node, and checks that the sections enforcing the policy directions (tx for Ingress, rx for Egress) have policies " +
+			"whose rules, listed by 'vppctl show npol policies', reference the ports and CIDRs of the policy\n\n" +
+			"Required parameters:\n" +
+			"- policy: The name of the network policy\n" +
+			"- pod: The name of the workload pod the policy applies to\n\n" +
+			"Optional parameters:\n" +
+			"- namespace: The namespace of the policy and the pod (default: default)\n" +
+			"- kind: kubernetes for a networking.k8s.io NetworkPolicy, calico for a projectcalico.org NetworkPolicy (default: kubernetes)\n" +
+			timeoutParameterLine + "\n" +
+			dryRunParameterLine + "\n\n" +
+			"Output interpretation:\n" +
+			"- An empty tx or rx section for a direction of the policy means the policy is not programmed on the pod interface\n" +
+			"- Ports and CIDRs are matched textually in the rules: a missing one points at a rule not programmed, named ports are not checked",
+	}
+	mcp.AddTool(vppServer.server, toolVerifyNetworkPolicy, func(ctx context.Context, req *mcp.CallToolRequest, input VerifyNetworkPolicyInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVerifyNetworkPolicy(ctx, input)
+	})
+
+	// Define artifact_list tool
+	toolListArtifacts := &mcp.Tool{
+		Name: "artifact_list",
+		Description: "List the artifacts (snapshots, fetched pcaps, large traces, MRT dumps, reports) kept in the server-side artifact store\n\n" +
+			"Artifacts are garbage-collected once they exceed the retention period or the store exceeds its size budget.\n\n" +
+			"No parameters required.",
+	}
+	mcp.AddTool(vppServer.server, toolListArtifacts, func(ctx context.Context, req *mcp.CallToolRequest, input EmptyInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleListArtifacts(ctx, input)
+	})
+
+	// Define vpp_snapshot tool
+	toolSnapshot := &mcp.Tool{
+		Name: "vpp_snapshot",
+		Description: "Record a snapshot of a calico-vpp pod (interfaces, errors, runtime, session and BGP state) into the artifact store\n\n" +
+			"The returned snapshot_id can be passed to the show tools to re-query the recorded state later, " +
+			"e.g. for post-incident analysis after the cluster has recovered.\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + timeoutParameterDescription,
+	}
+	mcp.AddTool(vppServer.server, toolSnapshot, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleTakeSnapshot(ctx, input)
+	})
+
+	if *snapshotInterval > 0 {
+		// Define vpp_interface_anomalies tool
+		toolInterfaceAnomalies := &mcp.Tool{
+			Name: "vpp_interface_anomalies",
+			Description: "Analyze the interface counter history recorded by the snapshot scheduler for a node and flag statistically anomalous jumps\n\n" +
+				"Required parameters (one of):\n" +
+				"- node_name: The Kubernetes node whose counter history is analyzed\n" +
+				"- pod_name: The name of the calico-vpp pod whose counter history is analyzed\n\n" +
+				"Optional parameters:\n" +
+				"- counters: Interface counters to analyze (default: drops, rx-miss, rx-error, tx-error)\n" +
+				"- threshold: Sensitivity in scaled median absolute deviations above the median rate (default: 3)\n" +
+				"- window_minutes: Analyze the snapshots recorded in the last minutes (default: 360)\n\n" +
+				"Output interpretation:\n" +
+				"- Each anomaly lists the time window between two snapshots, the counter increase and its rate compared to the baseline rate\n" +
+				"- Counter resets (e.g. after clear interfaces or a VPP restart) are ignored\n" +
+				"- Increases below 10 and small fluctuations of a steady rate are not reported",
+		}
+		mcp.AddTool(vppServer.server, toolInterfaceAnomalies, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCounterAnomalyInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleInterfaceAnomalies(ctx, input)
+		})
+	}
+
+	// Define vpp_show_interface_span tool
+	toolShowInterfaceSpan := &mcp.Tool{
+		Name: "vpp_show_interface_span",
+		Description: "Show the SPAN mirrors configured on the interfaces by running 'vppctl show interface span' in a Kubernetes VPP container\n\n" +
+			"Required parameters:\n" +
+			"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + fanOutParameterDescription + timeoutParameterDescription + "\n" +
+			filterParameterLine + "\n" +
+			formatParameterLine + "\n" +
+			containerParameterLine,
+	}
+	mcp.AddTool(vppServer.server, toolShowInterfaceSpan, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+		return vppServer.handleVPPCommand(ctx, input, "show interface span", "VPP Interface SPAN Mirrors")
+	})
+
+	// Tools changing the VPP configuration are only exposed when explicitly enabled
+	if *enableWrite {
+		// Define vpp_set_interface_span tool
+		toolSetInterfaceSpan := &mcp.Tool{
+			Name: "vpp_set_interface_span",
+			Description: "Mirror the traffic of an interface (e.g. a pod tap) to another interface for external capture by running " +
+				"'vppctl set interface span <interface> destination <destination> <direction>' in a Kubernetes VPP container\n\n" +
+				"This changes the dataplane configuration: remove the mirror with disable once the capture is done.\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n" +
+				"- interface: The interface whose traffic is mirrored (e.g. tap3)\n\n" +
+				"Optional parameters:\n" +
+				"- destination: The interface receiving the mirrored traffic (required unless disable is set)\n" +
+				"- direction: Mirrored direction - rx|tx|both (default: both)\n" +
+				"- disable: Remove the mirror of the interface (default: false)\n" +
+				timeoutParameterLine + "\n" +
+				dryRunParameterLine,
+		}
+		mcp.AddTool(vppServer.server, toolSetInterfaceSpan, func(ctx context.Context, req *mcp.CallToolRequest, input VPPSpanInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleSetInterfaceSpan(ctx, input)
+		})
+
+		// Define vpp_set_interface_mtu tool
+		toolSetInterfaceMTU := &mcp.Tool{
+			Name: "vpp_set_interface_mtu",
+			Description: "Change the MTU of an interface by running 'vppctl set interface mtu <layer> <mtu> <interface>' in a Kubernetes VPP container\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n" +
+				"- interface: The interface to resize\n" +
+				"- mtu: The new MTU in bytes (64-9216)\n\n" +
+				"Optional parameters:\n" +
+				"- layer: MTU to change - packet|ip4|ip6|mpls (default: packet)\n" +
+				timeoutParameterLine + "\n" +
+				dryRunParameterLine,
+		}
+		mcp.AddTool(vppServer.server, toolSetInterfaceMTU, func(ctx context.Context, req *mcp.CallToolRequest, input VPPInterfaceMTUInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleSetInterfaceMTU(ctx, input)
+		})
+
+		// Define vpp_set_interface_state tool
+		toolSetInterfaceState := &mcp.Tool{
+			Name: "vpp_set_interface_state",
+			Description: "Change the admin state of an interface by running 'vppctl set interface state <interface> up|down' in a Kubernetes VPP container\n\n" +
+				"Use bounce to set the interface down then up again, the usual remediation for a stuck interface.\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n" +
+				"- interface: The interface to change\n" +
+				"- state: New admin state - up|down|bounce" + timeoutParameterDescription,
+		}
+		mcp.AddTool(vppServer.server, toolSetInterfaceState, func(ctx context.Context, req *mcp.CallToolRequest, input VPPInterfaceStateInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleSetInterfaceState(ctx, input)
+		})
+
+		// Define vpp_set_logging_level tool
+		toolSetLoggingLevel := &mcp.Tool{
+			Name: "vpp_set_logging_level",
+			Description: "Change the level logged for a VPP log class by running 'vppctl set logging class <class> level <level>' in a Kubernetes VPP container\n\n" +
+				"Use it to enable verbose logging of one subsystem during an incident, read the entries with vpp_get_logs, " +
+				"then set the level back: debug logging of a busy subsystem quickly fills the log buffer.\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n" +
+				"- class: The log class, e.g. dpdk or linux-cp/nl\n" +
+				"- level: New level - emerg|alert|crit|err|warn|notice|info|debug|disabled" + timeoutParameterDescription,
+		}
+		mcp.AddTool(vppServer.server, toolSetLoggingLevel, func(ctx context.Context, req *mcp.CallToolRequest, input VPPLoggingLevelInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleSetLoggingLevel(ctx, input)
+		})
+
+		// Define vpp_clear_interfaces tool
+		toolClearInterfaces := &mcp.Tool{
+			Name: "vpp_clear_interfaces",
+			Description: "Reset the counters of every interface by running 'vppctl clear interfaces' in a Kubernetes VPP container\n\n" +
+				"Zero the counters before a controlled reproduction, then read them with `vpp_show_int`: they only reflect the reproduction. " +
+				"Monitoring reading the same counters sees them drop to zero.\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + timeoutParameterDescription,
+		}
+		mcp.AddTool(vppServer.server, toolClearInterfaces, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleVPPCommand(ctx, input, "clear interfaces", "VPP Clear Interface Counters")
+		})
+
+		// Define vpp_clear_cnat_sessions tool
+		toolClearCnatSessions := &mcp.Tool{
+			Name: "vpp_clear_cnat_sessions",
+			Description: "Purge the CNAT session table by running 'vppctl test cnat session purge' in a Kubernetes VPP container\n\n" +
+				"Remediation for stale NAT sessions pinning service traffic to a dead backend: new packets of established flows are translated again " +
+				"against the current translations (see `vpp_show_cnat_translation`). Every flow through a service is re-translated, " +
+				"so existing connections may be reset if the backend set changed.\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + timeoutParameterDescription,
+		}
+		mcp.AddTool(vppServer.server, toolClearCnatSessions, func(ctx context.Context, req *mcp.CallToolRequest, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleVPPCommand(ctx, input, "test cnat session purge", "VPP CNAT Session Purge")
+		})
+
+		// Define vpp_clear_sessions tool
+		toolClearSessions := &mcp.Tool{
+			Name: "vpp_clear_sessions",
+			Description: "Close host stack sessions by running 'vppctl clear session' in a Kubernetes VPP container\n\n" +
+				"Identify a session with the [thread:session] prefix of its line in `vpp_show_session_verbose`, or flush the whole session table with all.\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n\n" +
+				"Optional parameters:\n" +
+				"- thread: Thread of the session to close (required unless all is set)\n" +
+				"- session: Index of the session to close (required unless all is set)\n" +
+				"- all: Close every session (default: false)\n" +
+				timeoutParameterLine + "\n" +
+				dryRunParameterLine,
+		}
+		mcp.AddTool(vppServer.server, toolClearSessions, func(ctx context.Context, req *mcp.CallToolRequest, input VPPClearSessionsInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleClearSessions(ctx, input)
+		})
+
+		// Define vpp_pg_inject tool
+		toolPgInject := &mcp.Tool{
+			Name: "vpp_pg_inject",
+			Description: "Inject synthetic packets with the VPP packet generator in a Kubernetes VPP container, to validate a forwarding path without touching the workloads\n\n" +
+				"The packets are built from a template (src_ip, dst_ip, protocol, ports) or replayed from a pcap file of the pod. " +
+				"The tool creates a 'packet-generator new' stream, enables it, waits for it to complete, reports 'show packet-generator' and the error counters increased meanwhile, then deletes the stream. Each call uses its own stream and waits for the captures and injections running on the pod.\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n" +
+				"- src_ip, dst_ip: Addresses of the template packet (unless pcap_file is set)\n\n" +
+				"Optional parameters:\n" +
+				"- protocol: Protocol of the template packet - udp|tcp|icmp (default: udp; icmp sends IPv4 echo requests)\n" +
+				"- src_port, dst_port: Ports of a udp or tcp template packet (required for udp and tcp)\n" +
+				fmt.Sprintf("- payload_bytes: Payload size of the template packet (default: %d, max: %d)\n", defaultPgPayload, maxPgPayload) +
+				"- pcap_file: Replay the Ethernet frames of this pcap file of the pod (e.g. /tmp/trace.pcap) instead of a template packet\n" +
+				"- interface: Interface the packets appear to be received on, e.g. the tap of a pod (default: the packet-generator interface)\n" +
+				"- node: Graph node the packets are injected into (default: ip4-input or ip6-input for templates, ethernet-input for pcap files)\n" +
+				fmt.Sprintf("- count: Number of packets to inject (default: %d, max: %d)\n", defaultPgCount, maxPgCount) +
+				fmt.Sprintf("- rate: Packets per second (default: %d); the injection may last at most %s\n", defaultPgRate, maxPgInjectDuration) +
+				timeoutParameterLine + "\n" +
+				dryRunParameterLine,
+		}
+		mcp.AddTool(vppServer.server, toolPgInject, func(ctx context.Context, req *mcp.CallToolRequest, input VPPPgInjectInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handlePgInject(ctx, input)
+		})
+
+		// Define bgp_disable_neighbor tool
+		toolBgpDisableNeighbor := &mcp.Tool{
+			Name: "bgp_disable_neighbor",
+			Description: "Administratively disable the peering with a BGP neighbor by running 'gobgp neighbor <neighborIP> disable' in the agent container of a calico-vpp pod\n\n" +
+				"Drains the peering of a node during maintenance: the session is closed and stays down, so the routes learned from and advertised to the neighbor are withdrawn, " +
+				"until the neighbor is enabled again with bgp_enable_neighbor.\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + "\n" +
+				"- neighbor: The IP address of the BGP neighbor" + timeoutParameterDescription,
+		}
+		mcp.AddTool(vppServer.server, toolBgpDisableNeighbor, func(ctx context.Context, req *mcp.CallToolRequest, input BGPNeighborStateInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleSetBGPNeighborState(ctx, input, false)
+		})
+
+		// Define bgp_enable_neighbor tool
+		toolBgpEnableNeighbor := &mcp.Tool{
+			Name: "bgp_enable_neighbor",
+			Description: "Enable again the peering with a BGP neighbor disabled by bgp_disable_neighbor, by running 'gobgp neighbor <neighborIP> enable' in the agent container of a calico-vpp pod\n\n" +
+				"The session is re-established and the routes are exchanged again; check it with bgp_show_neighbor.\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + "\n" +
+				"- neighbor: The IP address of the BGP neighbor" + timeoutParameterDescription,
+		}
+		mcp.AddTool(vppServer.server, toolBgpEnableNeighbor, func(ctx context.Context, req *mcp.CallToolRequest, input BGPNeighborStateInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleSetBGPNeighborState(ctx, input, true)
+		})
+	}
+
+	if *enableRaw {
+		vppServer.rawVPPPrefixes = parseCommandPrefixes(*rawPrefixes)
+		if len(vppServer.rawVPPPrefixes) == 0 {
+			log.Fatalf("--raw-allowed-prefixes must list at least one command prefix")
+		}
+
+		// Define vpp_exec tool
+		toolRawVPP := &mcp.Tool{
+			Name: "vpp_exec",
+			Description: "Run a vppctl command not covered by a dedicated tool in a Kubernetes VPP container\n\n" +
+				"Only commands starting with one of the allowed prefixes are accepted: " + formatCommandPrefixes(vppServer.rawVPPPrefixes) + ". " +
+				"Abbreviated commands (e.g. 'sh int') must be spelled out.\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running VPP" + nodeNameParameterDescription + "\n" +
+				"- command: The vppctl command to run, without the vppctl prefix (e.g. 'show hardware-interfaces')" + timeoutParameterDescription,
+		}
+		mcp.AddTool(vppServer.server, toolRawVPP, func(ctx context.Context, req *mcp.CallToolRequest, input VPPRawCommandInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleRawVPPCommand(ctx, input)
+		})
+	}
+
+	if *enableRawBGP {
+		// Define bgp_exec tool
+		toolRawBGP := &mcp.Tool{
+			Name: "bgp_exec",
+			Description: "Run a read-only gobgp command not covered by a dedicated bgp_* tool in the agent container of a calico-vpp pod\n\n" +
+				"Only the read-only forms of the gobgp subcommands are allowed, optionally followed by -a <family>:\n" +
+				"- neighbor [<address> [adj-in|adj-out|local [<prefix> [longer-prefixes|shorter-prefixes] [detail]]|policy [import|export]]]\n" +
+				"- global [rib [summary|<prefix> [longer-prefixes|shorter-prefixes] [detail]]|policy [import|export]]\n" +
+				"- vrf [<name> rib [<prefix> ...]|<name> neighbor]\n" +
+				"- policy [prefix|neighbor|as-path|community|ext-community|large-community|statement]\n" +
+				"- rpki server|table\n\n" +
+				"Required parameters:\n" +
+				"- pod_name: The name of the Kubernetes pod running the agent container with gobgp" + nodeNameParameterDescription + "\n" +
+				"- command: The gobgp command to run, without the gobgp prefix (e.g. 'neighbor 192.168.0.2 adj-in')" + timeoutParameterDescription,
+		}
+		mcp.AddTool(vppServer.server, toolRawBGP, func(ctx context.Context, req *mcp.CallToolRequest, input BGPRawCommandInput) (*mcp.CallToolResult, any, error) {
+			return vppServer.handleRawGoBGPCommand(ctx, input)
+		})
+	}
+
+	// Hide the tools excluded by the configured policy
+	if err := applyToolPolicy(vppServer.server, *toolsAllow, *toolsDeny); err != nil {
+		log.Fatalf("Failed to apply tool policy: %v", err)
+	}
+
 	// Create context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Garbage-collect the artifact store in the background
+	go vppServer.artifacts.Run(ctx, artifactCollectInterval)
+
+	// Record periodic snapshots when the scheduler is enabled
+	if *snapshotInterval > 0 {
+		go NewSnapshotScheduler(vppServer, *snapshotInterval).Run(ctx)
+	}
+
+	// Expose the tool registry over gRPC for automation that doesn't speak MCP
+	if *grpcPort != "" {
+		config := grpcServiceConfig{Listen: *grpcListen, Port: *grpcPort, TLSCertFile: *grpcTLSCert, TLSKeyFile: *grpcTLSKey}
+		if *grpcTokenFile != "" {
+			token, err := os.ReadFile(*grpcTokenFile)
+			if err != nil {
+				log.Fatalf("Failed to read gRPC token file: %v", err)
+			}
+			config.Token = strings.TrimSpace(string(token))
+			if config.Token == "" {
+				log.Fatalf("gRPC token file %s is empty", *grpcTokenFile)
+			}
+		}
+		go runGRPCServer(ctx, vppServer, config)
+	}
+
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
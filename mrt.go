@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// mrtDumpFile names the MRT dump written to /tmp of the agent container, removed once fetched
+	mrtDumpFile = "vpp-mcp-rib.mrt"
+	// mrtMIMEType is the MIME type of the MCP resources serving MRT dumps
+	mrtMIMEType = "application/octet-stream"
+)
+
+// BGPMRTDumpInput represents the input for exporting the BGP RIB as an MRT dump
+type BGPMRTDumpInput struct {
+	// PodName specifies the name of the Kubernetes pod running the agent container with gobgp
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Neighbor restricts the dump to the Adj-RIB-In of a BGP neighbor instead of the global RIB
+	Neighbor string `json:"neighbor,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// execAgentArgs runs an argument vector in the agent container and returns its stdout. Dry runs
+// record the command and return no output.
+func (s *VPPMCPServer) execAgentArgs(ctx context.Context, podName string, args []string) (string, error) {
+	if err := validatePodName(podName); err != nil {
+		return "", err
+	}
+	container := execContainer(ctx, agentContainerName)
+
+	if recorder := dryRunFromContext(ctx); recorder != nil {
+		recorder.record(s.executor.CommandLine(podName, container, args))
+		return "", nil
+	}
+
+	release, err := s.acquireExecSlot(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	cmdCtx, cancel := context.WithTimeout(ctx, s.commandTimeout(ctx, defaultGoBGPCommandTimeout))
+	defer cancel()
+	output, err := s.executor.Exec(cmdCtx, podName, container, args)
+	return string(output), err
+}
+
+// handleBGPMRTDump dumps the BGP RIB of a pod in MRT format with 'gobgp mrt dump rib', fetches
+// the dump from the agent container and stores it as an artifact served as an MCP resource, so
+// it can be analyzed offline with standard BGP tooling (bgpdump, bgpscanner, ...)
+func (s *VPPMCPServer) handleBGPMRTDump(ctx context.Context, input BGPMRTDumpInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received BGP MRT dump request for pod: %s, neighbor: %s", input.PodName, input.Neighbor)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: Pod name is required. Please specify the Kubernetes pod name or node name.",
+				},
+			},
+		}, nil, fmt.Errorf("pod name is required")
+	}
+
+	rib := "global"
+	args := []string{"gobgp", "mrt", "dump", "rib", "global"}
+	if input.Neighbor != "" {
+		if _, err := netip.ParseAddr(input.Neighbor); err != nil {
+			err = fmt.Errorf("invalid neighbor %q: must be an IP address", input.Neighbor)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		rib = "neighbor " + input.Neighbor
+		args = []string{"gobgp", "mrt", "dump", "rib", "neighbor", input.Neighbor}
+	}
+	file := "/tmp/" + mrtDumpFile
+	args = append(args, "-o", "/tmp", "-f", mrtDumpFile)
+
+	// Step 1: Dump the RIB to a file of the agent container, and remove it once fetched
+	if output, err := s.execAgentArgs(ctx, input.PodName, args); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error dumping the %s RIB on pod %s: %v\n%s", rib, input.PodName, err, output),
+				},
+			},
+		}, nil, err
+	}
+	defer func() {
+		_, _ = s.execAgentArgs(context.WithoutCancel(ctx), input.PodName, []string{"rm", "-f", file})
+	}()
+
+	// Step 2: Fetch the dump
+	data, err := s.execAgentArgs(ctx, input.PodName, []string{"cat", file})
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error fetching MRT dump %s from pod %s: %v", file, input.PodName, err),
+				},
+			},
+		}, nil, err
+	}
+	if dryRunFromContext(ctx) != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("BGP MRT Dump: the %s RIB of pod %s would be dumped and stored as an artifact\n", rib, input.PodName),
+				},
+			},
+		}, nil, nil
+	}
+	if len(data) == 0 {
+		err := fmt.Errorf("MRT dump %s of pod %s is empty", file, input.PodName)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 3: Store the dump as an artifact
+	artifact, err := s.artifacts.Save(artifactKindMRT, input.PodName, mrtDumpFile, []byte(data))
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error storing MRT dump of pod %s: %v", input.PodName, err),
+				},
+			},
+		}, nil, err
+	}
+
+	uri := artifactURI(artifact.ID)
+	size := artifact.Size
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("BGP MRT Dump of the %s RIB of pod %s:\n\n- Size: %s\n- Artifact: %s\n- Resource: %s\n\n"+
+					"The dump is in MRT TABLE_DUMP_V2 format (RFC 6396). Read the resource and analyze it offline, e.g. with 'bgpdump -m %s'.\n",
+					rib, input.PodName, formatBytes(size), artifact.ID, uri, mrtDumpFile),
+			},
+			&mcp.ResourceLink{
+				URI:         uri,
+				Name:        artifact.Name,
+				Description: fmt.Sprintf("MRT dump of the %s RIB of pod %s", rib, input.PodName),
+				MIMEType:    mrtMIMEType,
+				Size:        &size,
+			},
+		},
+		StructuredContent: map[string]any{
+			"pod":         input.PodName,
+			"rib":         rib,
+			"artifact_id": artifact.ID,
+			"uri":         uri,
+			"size":        size,
+		},
+	}, nil, nil
+}
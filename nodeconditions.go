@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// kubeNodeConditionList is the subset of a NodeList read by the node condition summary
+type kubeNodeConditionList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Unschedulable bool `json:"unschedulable"`
+			Taints        []struct {
+				Key    string `json:"key"`
+				Value  string `json:"value"`
+				Effect string `json:"effect"`
+			} `json:"taints"`
+		} `json:"spec"`
+		Status struct {
+			Conditions []struct {
+				Type               string `json:"type"`
+				Status             string `json:"status"`
+				Reason             string `json:"reason"`
+				Message            string `json:"message"`
+				LastTransitionTime string `json:"lastTransitionTime"`
+			} `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// NodeConditionsInput represents the input for summarizing the conditions of the calico-vpp nodes
+type NodeConditionsInput struct {
+	// NodeName restricts the summary to one node
+	NodeName string `json:"node_name,omitempty"`
+}
+
+// nodeCondition is one condition of a node, e.g. Ready or MemoryPressure
+type nodeCondition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"last_transition_time,omitempty"`
+	Healthy            bool   `json:"healthy"`
+}
+
+// nodeConditionSummary is the condition summary of a node hosting a calico-vpp pod
+type nodeConditionSummary struct {
+	Node          string          `json:"node"`
+	Pod           string          `json:"pod"`
+	Unschedulable bool            `json:"unschedulable,omitempty"`
+	Taints        []string        `json:"taints,omitempty"`
+	Conditions    []nodeCondition `json:"conditions"`
+	Issues        []string        `json:"issues,omitempty"`
+}
+
+// nodeConditionHealthy reports whether a node condition status is the healthy one: Ready must be
+// True while the pressure and NetworkUnavailable conditions must be False
+func nodeConditionHealthy(conditionType, status string) bool {
+	if conditionType == "Ready" {
+		return status == "True"
+	}
+	return status == "False"
+}
+
+// handleNodeConditions summarizes the conditions of the nodes hosting calico-vpp pods, flagging the
+// nodes not Ready, under memory, disk or PID pressure, with their network unavailable, or cordoned
+func (s *VPPMCPServer) handleNodeConditions(ctx context.Context, input NodeConditionsInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received node conditions request for node: %q", input.NodeName)
+
+	var err error
+	if s.bypassesKubernetes() {
+		err = fmt.Errorf("node conditions are only available through the Kubernetes API, not with the %s backend", backendLocal)
+	}
+	var k8sClient *KubeClient
+	if err == nil {
+		if k8sClient, err = newKubeClient(); err != nil {
+			err = fmt.Errorf("failed to create Kubernetes client: %v", err)
+		}
+	}
+	var pods []VPPPod
+	if err == nil {
+		pods, err = listVPPPods(ctx, k8sClient)
+	}
+	var nodes kubeNodeConditionList
+	if err == nil {
+		err = k8sClient.getRawJSON(ctx, kubernetesNodesPath, &nodes)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 1: Keep the nodes hosting a calico-vpp pod
+	podByNode := make(map[string]string, len(pods))
+	for _, pod := range pods {
+		if pod.Node != "" && (input.NodeName == "" || pod.Node == input.NodeName) {
+			podByNode[pod.Node] = pod.Name
+		}
+	}
+	if len(podByNode) == 0 {
+		err = fmt.Errorf("no calico-vpp pod found in namespace %s", vppNamespace)
+		if input.NodeName != "" {
+			err = fmt.Errorf("no calico-vpp pod runs on node %s", input.NodeName)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 2: Summarize their conditions
+	var summaries []nodeConditionSummary
+	for _, item := range nodes.Items {
+		pod, ok := podByNode[item.Metadata.Name]
+		if !ok {
+			continue
+		}
+		delete(podByNode, item.Metadata.Name)
+		summary := nodeConditionSummary{
+			Node:          item.Metadata.Name,
+			Pod:           pod,
+			Unschedulable: item.Spec.Unschedulable,
+			Conditions:    []nodeCondition{},
+		}
+		for _, taint := range item.Spec.Taints {
+			if taint.Value != "" {
+				summary.Taints = append(summary.Taints, fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect))
+			} else {
+				summary.Taints = append(summary.Taints, fmt.Sprintf("%s:%s", taint.Key, taint.Effect))
+			}
+		}
+		for _, condition := range item.Status.Conditions {
+			healthy := nodeConditionHealthy(condition.Type, condition.Status)
+			summary.Conditions = append(summary.Conditions, nodeCondition{
+				Type:               condition.Type,
+				Status:             condition.Status,
+				Reason:             condition.Reason,
+				Message:            strings.TrimSpace(condition.Message),
+				LastTransitionTime: condition.LastTransitionTime,
+				Healthy:            healthy,
+			})
+			if !healthy {
+				issue := fmt.Sprintf("%s is %s", condition.Type, condition.Status)
+				if condition.Reason != "" {
+					issue += fmt.Sprintf(" (%s)", condition.Reason)
+				}
+				summary.Issues = append(summary.Issues, issue)
+			}
+		}
+		if summary.Unschedulable {
+			summary.Issues = append(summary.Issues, "the node is cordoned")
+		}
+		summaries = append(summaries, summary)
+	}
+	// Nodes deleted while their calico-vpp pod is still listed
+	for node, pod := range podByNode {
+		summaries = append(summaries, nodeConditionSummary{
+			Node:       node,
+			Pod:        pod,
+			Conditions: []nodeCondition{},
+			Issues:     []string{"the node is not found in the Kubernetes API"},
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Node < summaries[j].Node
+	})
+
+	var text strings.Builder
+	text.WriteString("Node Conditions of the calico-vpp nodes:\n\n")
+	unhealthy := 0
+	for _, summary := range summaries {
+		status := "healthy"
+		if len(summary.Issues) > 0 {
+			status = "UNHEALTHY"
+			unhealthy++
+		}
+		text.WriteString(fmt.Sprintf("Node %s (pod %s): %s\n", summary.Node, summary.Pod, status))
+		for _, condition := range summary.Conditions {
+			line := fmt.Sprintf("  %s=%s", condition.Type, condition.Status)
+			if !condition.Healthy {
+				if condition.Reason != "" {
+					line += fmt.Sprintf(" (%s)", condition.Reason)
+				}
+				if condition.Message != "" {
+					line += ": " + condition.Message
+				}
+				if condition.LastTransitionTime != "" {
+					line += " since " + condition.LastTransitionTime
+				}
+			}
+			text.WriteString(line + "\n")
+		}
+		if len(summary.Taints) > 0 {
+			text.WriteString(fmt.Sprintf("  Taints: %s\n", strings.Join(summary.Taints, ", ")))
+		}
+		for _, issue := range summary.Issues {
+			text.WriteString(fmt.Sprintf("  ! %s\n", issue))
+		}
+		text.WriteString("\n")
+	}
+	text.WriteString(fmt.Sprintf("Summary: %d node(s), %d unhealthy\n", len(summaries), unhealthy))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"nodes":     summaries,
+			"unhealthy": unhealthy,
+		},
+	}, nil, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// nodeNameParameterDescription documents the node_name alternative to the pod_name parameter
+	nodeNameParameterDescription = ", or node_name: the Kubernetes node whose calico-vpp pod is used instead"
+
+	// nodeNamesParameterDescription documents the node_names alternative to the pod_names parameter
+	nodeNamesParameterDescription = ", or node_names: the Kubernetes nodes whose calico-vpp pods are used instead"
+)
+
+// nativeNodeNameTools lists the tools reading node_name themselves rather than as a pod alias
+var nativeNodeNameTools = map[string]bool{
+	"vpp_interface_anomalies": true,
+	"vpp_node_conditions":     true,
+}
+
+// resolveNodePods returns the calico-vpp pod scheduled on each of the given nodes. Without
+// Kubernetes the commands run on the local node, so the node names stand in for the pod names.
+func (s *VPPMCPServer) resolveNodePods(ctx context.Context, nodes []string) ([]string, error) {
+	if s.bypassesKubernetes() {
+		return nodes, nil
+	}
+
+	k8sClient, err := newKubeClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+	pods, err := listVPPPods(ctx, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	podByNode := make(map[string]string, len(pods))
+	for _, pod := range pods {
+		podByNode[pod.Node] = pod.Name
+	}
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		name, ok := podByNode[node]
+		if !ok || node == "" {
+			return nil, fmt.Errorf("no calico-vpp pod runs on node %q in namespace %s", node, vppNamespace)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// resolveNodeArguments replaces the node_name and node_names arguments of a tool call by the
+// pod_name and pod_names of the calico-vpp pods scheduled on those nodes
+func (s *VPPMCPServer) resolveNodeArguments(ctx context.Context, arguments json.RawMessage) (json.RawMessage, error) {
+	var args map[string]json.RawMessage
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		// Malformed arguments are reported by the tool handler itself
+		return arguments, nil
+	}
+	var target struct {
+		PodName   string   `json:"pod_name"`
+		NodeName  string   `json:"node_name"`
+		PodNames  []string `json:"pod_names"`
+		NodeNames []string `json:"node_names"`
+	}
+	if err := json.Unmarshal(arguments, &target); err != nil {
+		return arguments, nil
+	}
+	if target.NodeName == "" && len(target.NodeNames) == 0 {
+		return arguments, nil
+	}
+
+	if target.NodeName != "" {
+		if target.PodName != "" {
+			return nil, fmt.Errorf("pod_name and node_name are mutually exclusive: specify only one of them")
+		}
+		pods, err := s.resolveNodePods(ctx, []string{target.NodeName})
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Resolved node %s to calico-vpp pod %s", target.NodeName, pods[0])
+		args["pod_name"], _ = json.Marshal(pods[0])
+		delete(args, "node_name")
+	}
+	if len(target.NodeNames) > 0 {
+		pods, err := s.resolveNodePods(ctx, target.NodeNames)
+		if err != nil {
+			return nil, err
+		}
+		args["pod_names"], _ = json.Marshal(append(target.PodNames, pods...))
+		delete(args, "node_names")
+	}
+	return json.Marshal(args)
+}
+
+// nodeNameMiddleware lets tool calls select calico-vpp pods by node: the node_name and
+// node_names arguments are resolved to pod names before the call reaches the quota
+// enforcer and the tool handler, which only see pod names
+func (s *VPPMCPServer) nodeNameMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+		if method != "tools/call" || !ok || nativeNodeNameTools[params.Name] || len(params.Arguments) == 0 {
+			return next(ctx, method, req)
+		}
+
+		arguments, err := s.resolveNodeArguments(ctx, params.Arguments)
+		if err != nil {
+			log.Printf("Rejected %s call: %v", params.Name, err)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		params.Arguments = arguments
+		return next(ctx, method, req)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// defaultMaxOutputBytes is the largest tool output returned inline
+	defaultMaxOutputBytes = 64 * 1024
+
+	// artifactURIPrefix prefixes the URI of the MCP resources serving stored artifacts
+	artifactURIPrefix = "vpp-mcp://artifacts/"
+)
+
+// artifactURI returns the URI of the MCP resource serving an artifact
+func artifactURI(id string) string {
+	return artifactURIPrefix + id
+}
+
+// truncateOutput cuts text to at most maxBytes, on a line boundary when possible
+func truncateOutput(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	cut := strings.LastIndex(text[:maxBytes], "\n")
+	if cut <= 0 {
+		cut = maxBytes
+	}
+	return strings.ToValidUTF8(text[:cut], "")
+}
+
+// compileOutputFilter compiles the filter regular expression of a tool call (nil when empty)
+func compileOutputFilter(filter string) (*regexp.Regexp, error) {
+	if filter == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter regular expression '%s': %v", filter, err)
+	}
+	return re, nil
+}
+
+// filterOutput keeps the lines of output matching filter. A nil filter keeps everything.
+func filterOutput(output string, filter *regexp.Regexp) string {
+	if filter == nil {
+		return output
+	}
+	var matched []string
+	for _, line := range strings.Split(output, "\n") {
+		if filter.MatchString(line) {
+			matched = append(matched, line)
+		}
+	}
+	if len(matched) == 0 {
+		return fmt.Sprintf("(no lines matched filter '%s')", filter)
+	}
+	return strings.Join(matched, "\n")
+}
+
+// describeOutputFilter renders the filter line appended to filtered results
+func describeOutputFilter(filter *regexp.Regexp) string {
+	if filter == nil {
+		return ""
+	}
+	return fmt.Sprintf("\nFilter: %s", filter)
+}
+
+// newOutputLimitMiddleware returns a middleware truncating tool outputs larger than maxBytes.
+// The full output is stored as an artifact and linked as an MCP resource the client can read on demand.
+func newOutputLimitMiddleware(store *ArtifactStore, maxBytes int) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+			if method != "tools/call" || !ok || err != nil {
+				return result, err
+			}
+			toolResult, ok := result.(*mcp.CallToolResult)
+			if !ok || len(toolResult.Content) == 0 {
+				return result, err
+			}
+			text, ok := toolResult.Content[0].(*mcp.TextContent)
+			if !ok || len(text.Text) <= maxBytes {
+				return result, err
+			}
+
+			var target struct {
+				PodName string `json:"pod_name"`
+			}
+			_ = json.Unmarshal(params.Arguments, &target)
+
+			artifact, saveErr := store.Save(artifactKindOutput, target.PodName, params.Name+".txt", []byte(text.Text))
+			if saveErr != nil {
+				// Returning the full output is better than losing it
+				log.Printf("Failed to store full output of %s: %v", params.Name, saveErr)
+				return result, err
+			}
+
+			uri := artifactURI(artifact.ID)
+			size := artifact.Size
+			truncated := truncateOutput(text.Text, maxBytes)
+			toolResult.Content[0] = &mcp.TextContent{
+				Text: fmt.Sprintf("%s\n\n[Output truncated: showing %s of %s. The full output is available as resource %s]",
+					truncated, formatBytes(int64(len(truncated))), formatBytes(size), uri),
+			}
+			toolResult.Content = append(toolResult.Content, &mcp.ResourceLink{
+				URI:         uri,
+				Name:        artifact.Name,
+				Description: fmt.Sprintf("Full output of %s", params.Name),
+				MIMEType:    "text/plain",
+				Size:        &size,
+			})
+			return toolResult, nil
+		}
+	}
+}
+
+// handleReadArtifact serves a stored artifact as an MCP resource
+func (s *VPPMCPServer) handleReadArtifact(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	id := strings.TrimPrefix(uri, artifactURIPrefix)
+	artifact, ok := s.artifacts.Get(id)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	data, err := s.artifacts.Read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := &mcp.ResourceContents{URI: uri}
+	switch artifact.Kind {
+	case artifactKindPcap:
+		contents.MIMEType = "application/vnd.tcpdump.pcap"
+		contents.Blob = data
+	case artifactKindMRT:
+		contents.MIMEType = mrtMIMEType
+		contents.Blob = data
+	default:
+		contents.MIMEType = "text/plain"
+		contents.Text = string(data)
+	}
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+}
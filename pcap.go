@@ -0,0 +1,774 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net/netip"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultPcapDecodeFile    = "/tmp/trace.pcap"
+	defaultPcapDecodePackets = 100
+	maxPcapDecodePackets     = 1000
+
+	// Link types of the captures written by VPP and common capture tools
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLinuxSLL = 113
+	linkTypeIPv4     = 228
+	linkTypeIPv6     = 229
+	// linkTypeVPPDispatch is the link type of 'pcap dispatch trace' captures, carrying VPP
+	// buffer metadata that only the Wireshark VPP dissector understands
+	linkTypeVPPDispatch = 280
+)
+
+// pcapFileRegexp matches the capture files pcap_decode may read from the vpp container
+var pcapFileRegexp = regexp.MustCompile(`^/tmp/[A-Za-z0-9][A-Za-z0-9._-]*\.pcap$`)
+
+// validatePcapFile checks that file is a capture file in the /tmp directory of the vpp container
+func validatePcapFile(file string) error {
+	if path.Clean(file) != file || !pcapFileRegexp.MatchString(file) {
+		return fmt.Errorf("invalid file %q: expected a .pcap file in /tmp, e.g. /tmp/trace.pcap", file)
+	}
+	return nil
+}
+
+// PcapDecodeInput represents the input for decoding a capture file
+type PcapDecodeInput struct {
+	// PodName specifies the name of the Kubernetes pod the capture is fetched from
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// File specifies the capture file in the vpp container
+	File string `json:"file,omitempty"`
+	// ArtifactID decodes a capture already kept in the artifact store instead of fetching one
+	ArtifactID string `json:"artifact_id,omitempty"`
+	// MaxPackets bounds the number of packets listed
+	MaxPackets int `json:"max_packets,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of the command fetching the file
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the command the call would execute without running it
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// pcapPacket is a packet record of a capture file
+type pcapPacket struct {
+	Timestamp time.Time
+	// Length is the length of the packet on the wire, data may be truncated to the snap length
+	Length int
+	data   []byte
+}
+
+// pcapCapture is a parsed capture file
+type pcapCapture struct {
+	LinkType uint32
+	Packets  []pcapPacket
+}
+
+// parsePcap parses a libpcap capture file with pcapgo, in either byte order and with
+// microsecond or nanosecond timestamps. A truncated last record is ignored.
+func parsePcap(data []byte) (*pcapCapture, error) {
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data[0:4]) == 0x0a0d0d0a {
+		return nil, fmt.Errorf("pcapng files are not supported, capture with 'vppctl pcap trace' instead")
+	}
+	reader, err := pcapgo.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("not a pcap file: %v", err)
+	}
+
+	// layers.LinkType is 8 bits wide and can't hold linkTypeVPPDispatch, so the link type
+	// is read from the file header, whose byte order pcapgo already checked
+	order := binary.ByteOrder(binary.LittleEndian)
+	if data[0] == 0xa1 {
+		order = binary.BigEndian
+	}
+	capture := &pcapCapture{LinkType: order.Uint32(data[20:24]) & 0x0fffffff}
+	for {
+		packet, info, err := reader.ReadPacketData()
+		if err != nil {
+			break
+		}
+		capture.Packets = append(capture.Packets, pcapPacket{
+			Timestamp: info.Timestamp,
+			Length:    info.Length,
+			data:      packet,
+		})
+	}
+	return capture, nil
+}
+
+// decodedPacket summarizes the headers of a captured packet
+type decodedPacket struct {
+	// Network is the network protocol: IPv4, IPv6, ARP or the EtherType
+	Network string
+	Src     netip.Addr
+	Dst     netip.Addr
+	// TOS is the ToS byte of an IPv4 header or the traffic class of an IPv6 header
+	TOS uint8
+	// ID is the identification of an IPv4 header
+	ID       uint16
+	Protocol string
+	SrcPort  uint16
+	DstPort  uint16
+	// TCPFlags lists the flags set in the TCP header, e.g. SYN,ACK
+	TCPFlags string
+	Seq      uint32
+	// Payload is the length of the transport payload
+	Payload int
+	// Note explains why the packet could not be fully decoded
+	Note string
+}
+
+// hasPorts reports whether the packet was decoded down to transport ports
+func (p decodedPacket) hasPorts() bool {
+	return p.Protocol == "TCP" || p.Protocol == "UDP" || p.Protocol == "SCTP"
+}
+
+// ipProtocolNames names the IP protocols decoded by pcap_decode
+var ipProtocolNames = map[byte]string{
+	1:   "ICMP",
+	6:   "TCP",
+	17:  "UDP",
+	47:  "GRE",
+	50:  "ESP",
+	58:  "ICMPv6",
+	132: "SCTP",
+}
+
+// decodePacket decodes the link, network and transport headers of a packet with gopacket
+func decodePacket(linkType uint32, data []byte) decodedPacket {
+	var first gopacket.Decoder
+	switch linkType {
+	case linkTypeEthernet:
+		first = layers.LayerTypeEthernet
+	case linkTypeLinuxSLL:
+		first = layers.LayerTypeLinuxSLL
+	case linkTypeRaw, linkTypeIPv4, linkTypeIPv6:
+		if len(data) == 0 {
+			return decodedPacket{Note: "empty packet"}
+		}
+		first = layers.LayerTypeIPv4
+		if data[0]>>4 == 6 {
+			first = layers.LayerTypeIPv6
+		}
+	case linkTypeVPPDispatch:
+		return decodedPacket{Note: "VPP dispatch trace record, open it with the Wireshark VPP dissector"}
+	default:
+		return decodedPacket{Note: fmt.Sprintf("unsupported link type %d", linkType)}
+	}
+	packet := gopacket.NewPacket(data, first, gopacket.DecodeOptions{Lazy: true, NoCopy: true})
+
+	var decoded decodedPacket
+	var transportLength int
+	switch network := decodedLayer(packet.NetworkLayer()).(type) {
+	case *layers.IPv4:
+		decoded = decodedPacket{Network: "IPv4", TOS: network.TOS, ID: network.Id, Protocol: ipProtocolName(byte(network.Protocol))}
+		decoded.Src, _ = netip.AddrFromSlice(network.SrcIP.To4())
+		decoded.Dst, _ = netip.AddrFromSlice(network.DstIP.To4())
+		if network.FragOffset != 0 {
+			decoded.Note = "non-first fragment"
+			return decoded
+		}
+		transportLength = int(network.Length) - int(network.IHL)*4
+	case *layers.IPv6:
+		decoded = decodedPacket{Network: "IPv6", TOS: network.TrafficClass}
+		decoded.Src, _ = netip.AddrFromSlice(network.SrcIP)
+		decoded.Dst, _ = netip.AddrFromSlice(network.DstIP)
+		next := network.NextHeader
+		transportLength = int(network.Length)
+		// Hop-by-hop, routing and destination options headers
+		for _, layer := range packet.Layers() {
+			switch extension := layer.(type) {
+			case *layers.IPv6HopByHop:
+				next, transportLength = extension.NextHeader, transportLength-len(extension.Contents)
+			case *layers.IPv6Routing:
+				next, transportLength = extension.NextHeader, transportLength-len(extension.Contents)
+			case *layers.IPv6Destination:
+				next, transportLength = extension.NextHeader, transportLength-len(extension.Contents)
+			}
+		}
+		decoded.Protocol = ipProtocolName(byte(next))
+		if next == layers.IPProtocolIPv6Fragment {
+			decoded.Note = "fragment"
+			return decoded
+		}
+	default:
+		if packet.Layer(layers.LayerTypeARP) != nil {
+			return decodedPacket{Network: "ARP"}
+		}
+		etherType, ok := innerEtherType(packet)
+		if failure := packet.ErrorLayer(); failure != nil && (!ok || etherType == layers.EthernetTypeIPv4 || etherType == layers.EthernetTypeIPv6) {
+			return decodedPacket{Note: fmt.Sprintf("undecodable headers: %v", failure.Error())}
+		}
+		return decodedPacket{Network: fmt.Sprintf("EtherType 0x%04x", uint16(etherType))}
+	}
+
+	switch transport := decodedLayer(packet.TransportLayer()).(type) {
+	case *layers.TCP:
+		decoded.SrcPort, decoded.DstPort = uint16(transport.SrcPort), uint16(transport.DstPort)
+		decoded.Seq = transport.Seq
+		decoded.TCPFlags = tcpFlags(transport)
+		decoded.Payload = transportLength - int(transport.DataOffset)*4
+	case *layers.UDP:
+		decoded.SrcPort, decoded.DstPort = uint16(transport.SrcPort), uint16(transport.DstPort)
+		decoded.Payload = transportLength - 8
+	case *layers.SCTP:
+		decoded.SrcPort, decoded.DstPort = uint16(transport.SrcPort), uint16(transport.DstPort)
+		decoded.Payload = transportLength - 12
+	default:
+		if decoded.hasPorts() {
+			decoded.Note = "truncated " + decoded.Protocol + " header"
+		}
+	}
+	if icmp, ok := packet.Layer(layers.LayerTypeICMPv4).(*layers.ICMPv4); ok {
+		decoded.Note = fmt.Sprintf("type %d code %d", icmp.TypeCode.Type(), icmp.TypeCode.Code())
+	} else if icmp, ok := packet.Layer(layers.LayerTypeICMPv6).(*layers.ICMPv6); ok {
+		decoded.Note = fmt.Sprintf("type %d code %d", icmp.TypeCode.Type(), icmp.TypeCode.Code())
+	}
+	if decoded.Payload < 0 {
+		decoded.Payload = 0
+	}
+	return decoded
+}
+
+// decodedLayer returns layer, or nil when gopacket failed to decode it: such layers are
+// still attached to the packet, without contents
+func decodedLayer(layer gopacket.Layer) gopacket.Layer {
+	if layer == nil || len(layer.LayerContents()) == 0 {
+		return nil
+	}
+	return layer
+}
+
+// innerEtherType returns the EtherType of the innermost link layer of a packet, after its
+// 802.1Q and 802.1ad tags, and whether a link layer was decoded
+func innerEtherType(packet gopacket.Packet) (layers.EthernetType, bool) {
+	var etherType layers.EthernetType
+	decoded := false
+	for _, layer := range packet.Layers() {
+		if decodedLayer(layer) == nil {
+			continue
+		}
+		switch link := layer.(type) {
+		case *layers.Ethernet:
+			etherType, decoded = link.EthernetType, true
+		case *layers.LinuxSLL:
+			etherType, decoded = link.EthernetType, true
+		case *layers.Dot1Q:
+			etherType, decoded = link.Type, true
+		}
+	}
+	return etherType, decoded
+}
+
+// tcpFlags lists the flags set in a TCP header, e.g. SYN,ACK
+func tcpFlags(tcp *layers.TCP) string {
+	var flags []string
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{tcp.FIN, "FIN"}, {tcp.SYN, "SYN"}, {tcp.RST, "RST"}, {tcp.PSH, "PSH"},
+		{tcp.ACK, "ACK"}, {tcp.URG, "URG"}, {tcp.ECE, "ECE"}, {tcp.CWR, "CWR"},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	return strings.Join(flags, ",")
+}
+
+// ipProtocolName returns the name of an IP protocol, or its number
+func ipProtocolName(protocol byte) string {
+	if name, ok := ipProtocolNames[protocol]; ok {
+		return name
+	}
+	return fmt.Sprintf("proto %d", protocol)
+}
+
+// endpoint renders an address with its port when the packet has ports
+func (p decodedPacket) endpoint(addr netip.Addr, port uint16) string {
+	if p.hasPorts() {
+		return netip.AddrPortFrom(addr, port).String()
+	}
+	return addr.String()
+}
+
+// summary renders the packet on one line, e.g. "10.0.0.1:1234 > 10.0.0.2:80 TCP [SYN] seq=1 payload=0"
+func (p decodedPacket) summary() string {
+	var text strings.Builder
+	if p.Src.IsValid() {
+		text.WriteString(fmt.Sprintf("%s > %s %s", p.endpoint(p.Src, p.SrcPort), p.endpoint(p.Dst, p.DstPort), p.Protocol))
+	} else {
+		text.WriteString(p.Network)
+	}
+	if p.Protocol == "TCP" && p.Note == "" {
+		text.WriteString(fmt.Sprintf(" [%s] seq=%d", p.TCPFlags, p.Seq))
+	}
+	if p.hasPorts() && p.Note == "" {
+		text.WriteString(fmt.Sprintf(" payload=%d", p.Payload))
+	}
+	if p.Note != "" {
+		text.WriteString(" (" + p.Note + ")")
+	}
+	return text.String()
+}
+
+// fetchPodFile returns the content of a file of the vpp container. Dry runs record the
+// command and return no content.
+func (s *VPPMCPServer) fetchPodFile(ctx context.Context, podName, file string) ([]byte, error) {
+	if err := validatePodName(podName); err != nil {
+		return nil, err
+	}
+	container := execContainer(ctx, vppContainerName)
+
+	if recorder := dryRunFromContext(ctx); recorder != nil {
+		recorder.record(s.executor.CommandLine(podName, container, []string{"cat", file}))
+		return nil, nil
+	}
+
+	release, err := s.acquireExecSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	cmdCtx, cancel := context.WithTimeout(ctx, s.commandTimeout(ctx, defaultVPPCommandTimeout))
+	defer cancel()
+	return s.executor.CopyFile(cmdCtx, podName, container, file)
+}
+
+// execPodArgs runs an argument vector in the vpp container and returns its stdout. Dry runs
+// record the command and return no output.
+func (s *VPPMCPServer) execPodArgs(ctx context.Context, podName string, args []string) (string, error) {
+	if err := validatePodName(podName); err != nil {
+		return "", err
+	}
+	container := execContainer(ctx, vppContainerName)
+
+	if recorder := dryRunFromContext(ctx); recorder != nil {
+		recorder.record(s.executor.CommandLine(podName, container, args))
+		return "", nil
+	}
+
+	release, err := s.acquireExecSlot(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	cmdCtx, cancel := context.WithTimeout(ctx, s.commandTimeout(ctx, defaultVPPCommandTimeout))
+	defer cancel()
+	output, err := s.executor.Exec(cmdCtx, podName, container, args)
+	return string(output), err
+}
+
+// loadPcap returns a capture from the artifact store, or fetches it from the pod and stores it.
+// The returned source describes where the capture comes from.
+func (s *VPPMCPServer) loadPcap(ctx context.Context, podName, file, artifactID string) ([]byte, string, error) {
+	if artifactID != "" {
+		artifact, ok := s.artifacts.Get(artifactID)
+		if !ok || artifact.Kind != artifactKindPcap {
+			return nil, "", fmt.Errorf("pcap artifact %s not found, list the artifacts with artifact_list", artifactID)
+		}
+		data, err := s.artifacts.Read(artifactID)
+		return data, "artifact " + artifactID, err
+	}
+
+	if podName == "" {
+		return nil, "", fmt.Errorf("pod_name or artifact_id is required")
+	}
+	if file == "" {
+		file = defaultPcapDecodeFile
+	}
+	if err := validatePcapFile(file); err != nil {
+		return nil, "", err
+	}
+	data, err := s.fetchPodFile(ctx, podName, file)
+	if err != nil || dryRunFromContext(ctx) != nil {
+		return nil, "", err
+	}
+	source := fmt.Sprintf("%s on pod %s", file, podName)
+	if artifact, err := s.artifacts.Save(artifactKindPcap, podName, path.Base(file), data); err != nil {
+		log.Printf("Failed to store capture %s of pod %s: %v", file, podName, err)
+	} else {
+		source += fmt.Sprintf(", stored as artifact %s (%s)", artifact.ID, artifactURIPrefix+artifact.ID)
+	}
+	return data, source, nil
+}
+
+// handlePcapDecode fetches a capture file and lists its packets
+func (s *VPPMCPServer) handlePcapDecode(ctx context.Context, input PcapDecodeInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received pcap decode request for pod: %s, file: %s, artifact: %s", input.PodName, input.File, input.ArtifactID)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	maxPackets := input.MaxPackets
+	if maxPackets == 0 {
+		maxPackets = defaultPcapDecodePackets
+	}
+	if maxPackets < 0 || maxPackets > maxPcapDecodePackets {
+		err := fmt.Errorf("invalid max_packets %d: must be between 1 and %d", input.MaxPackets, maxPcapDecodePackets)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	data, source, err := s.loadPcap(ctx, input.PodName, input.File, input.ArtifactID)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	if dryRunFromContext(ctx) != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: ""}}}, nil, nil
+	}
+
+	capture, err := parsePcap(data)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error decoding %s: %v", source, err),
+				},
+			},
+		}, nil, err
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Decoded Capture: %s\n\n- Link Type: %d\n- Packets: %d\n", source, capture.LinkType, len(capture.Packets)))
+	if len(capture.Packets) > 0 {
+		first, last := capture.Packets[0].Timestamp, capture.Packets[len(capture.Packets)-1].Timestamp
+		text.WriteString(fmt.Sprintf("- First Packet: %s\n- Duration: %s\n", first.Format(time.RFC3339Nano), last.Sub(first)))
+	}
+	text.WriteString("\n")
+	for i, packet := range capture.Packets {
+		if i == maxPackets {
+			text.WriteString(fmt.Sprintf("... %d more packets, raise max_packets to list them\n", len(capture.Packets)-maxPackets))
+			break
+		}
+		text.WriteString(fmt.Sprintf("%d %s %s len=%d\n", i+1, packet.Timestamp.Format("15:04:05.000000"),
+			decodePacket(capture.LinkType, packet.data).summary(), packet.Length))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
+
+const defaultPcapTopFlows = 10
+
+// PcapAnalyzeInput represents the input for summarizing a capture file
+type PcapAnalyzeInput struct {
+	// PodName specifies the name of the Kubernetes pod the capture is fetched from
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// File specifies the capture file in the vpp container
+	File string `json:"file,omitempty"`
+	// ArtifactID analyzes a capture already kept in the artifact store instead of fetching one
+	ArtifactID string `json:"artifact_id,omitempty"`
+	// Top specifies the number of flows reported
+	Top int `json:"top,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of the command fetching the file
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the command the call would execute without running it
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// pcapFlowKey identifies a unidirectional flow
+type pcapFlowKey struct {
+	Protocol string
+	Src      netip.AddrPort
+	Dst      netip.AddrPort
+}
+
+// pcapFlow accumulates the packets of a flow
+type pcapFlow struct {
+	Key             pcapFlowKey
+	Packets         int
+	Bytes           int
+	Retransmissions int
+	// seen records the sequence numbers of the TCP segments carrying data
+	seen map[uint32]bool
+}
+
+// pcapStats summarizes a capture
+type pcapStats struct {
+	Packets  int
+	Bytes    int
+	Duration time.Duration
+	// Protocols counts the packets and bytes per protocol, e.g. IPv4/TCP or ARP
+	Protocols map[string]*[2]int
+	Flows     []*pcapFlow
+}
+
+// analyzePcap aggregates the packets of a capture into protocols and flows. A TCP segment
+// carrying data with the sequence number of an earlier segment of its flow is a retransmission.
+func analyzePcap(capture *pcapCapture) pcapStats {
+	stats := pcapStats{Protocols: make(map[string]*[2]int)}
+	flows := make(map[pcapFlowKey]*pcapFlow)
+	for _, packet := range capture.Packets {
+		stats.Packets++
+		stats.Bytes += packet.Length
+
+		decoded := decodePacket(capture.LinkType, packet.data)
+		protocol := decoded.Network
+		if protocol == "" {
+			protocol = "undecoded"
+		}
+		if decoded.Protocol != "" {
+			protocol += "/" + decoded.Protocol
+		}
+		if stats.Protocols[protocol] == nil {
+			stats.Protocols[protocol] = &[2]int{}
+		}
+		stats.Protocols[protocol][0]++
+		stats.Protocols[protocol][1] += packet.Length
+
+		if !decoded.Src.IsValid() {
+			continue
+		}
+		key := pcapFlowKey{
+			Protocol: decoded.Protocol,
+			Src:      netip.AddrPortFrom(decoded.Src, decoded.SrcPort),
+			Dst:      netip.AddrPortFrom(decoded.Dst, decoded.DstPort),
+		}
+		flow, ok := flows[key]
+		if !ok {
+			flow = &pcapFlow{Key: key, seen: make(map[uint32]bool)}
+			flows[key] = flow
+			stats.Flows = append(stats.Flows, flow)
+		}
+		flow.Packets++
+		flow.Bytes += packet.Length
+		if decoded.Protocol == "TCP" && decoded.Note == "" && decoded.Payload > 0 {
+			if flow.seen[decoded.Seq] {
+				flow.Retransmissions++
+			}
+			flow.seen[decoded.Seq] = true
+		}
+	}
+	if len(capture.Packets) > 1 {
+		stats.Duration = capture.Packets[len(capture.Packets)-1].Timestamp.Sub(capture.Packets[0].Timestamp)
+	}
+	return stats
+}
+
+// String renders the flow, e.g. "TCP 10.0.0.1:1234 > 10.0.0.2:80"
+func (k pcapFlowKey) String() string {
+	packet := decodedPacket{Protocol: k.Protocol}
+	return fmt.Sprintf("%s %s > %s", k.Protocol, packet.endpoint(k.Src.Addr(), k.Src.Port()), packet.endpoint(k.Dst.Addr(), k.Dst.Port()))
+}
+
+// formatPcapStats renders the protocol breakdown, the top flows by packets and by bytes,
+// and the flows with retransmissions
+func formatPcapStats(stats pcapStats, top int) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("- Packets: %d\n- Bytes: %d\n- Duration: %s\n- Flows: %d\n", stats.Packets, stats.Bytes, stats.Duration, len(stats.Flows)))
+
+	text.WriteString("\nProtocols:\n")
+	protocols := make([]string, 0, len(stats.Protocols))
+	for protocol := range stats.Protocols {
+		protocols = append(protocols, protocol)
+	}
+	sort.Slice(protocols, func(i, j int) bool {
+		a, b := stats.Protocols[protocols[i]], stats.Protocols[protocols[j]]
+		if a[0] != b[0] {
+			return a[0] > b[0]
+		}
+		return protocols[i] < protocols[j]
+	})
+	for _, protocol := range protocols {
+		counts := stats.Protocols[protocol]
+		text.WriteString(fmt.Sprintf("- %s: %d packets (%.1f%%), %d bytes\n", protocol, counts[0], 100*float64(counts[0])/float64(stats.Packets), counts[1]))
+	}
+
+	flows := append([]*pcapFlow(nil), stats.Flows...)
+	for _, ranking := range []struct {
+		title string
+		value func(*pcapFlow) int
+	}{
+		{"Top Flows by Packets", func(f *pcapFlow) int { return f.Packets }},
+		{"Top Flows by Bytes", func(f *pcapFlow) int { return f.Bytes }},
+	} {
+		sort.SliceStable(flows, func(i, j int) bool { return ranking.value(flows[i]) > ranking.value(flows[j]) })
+		text.WriteString("\n" + ranking.title + ":\n")
+		for i, flow := range flows {
+			if i == top {
+				break
+			}
+			text.WriteString(fmt.Sprintf("%d. %s: %d packets, %d bytes\n", i+1, flow.Key, flow.Packets, flow.Bytes))
+		}
+	}
+
+	text.WriteString("\nTCP Retransmissions:\n")
+	sort.SliceStable(flows, func(i, j int) bool { return flows[i].Retransmissions > flows[j].Retransmissions })
+	retransmitting := 0
+	for _, flow := range flows {
+		if flow.Retransmissions == 0 {
+			break
+		}
+		retransmitting++
+		text.WriteString(fmt.Sprintf("- %s: %d of %d packets retransmitted\n", flow.Key, flow.Retransmissions, flow.Packets))
+	}
+	if retransmitting == 0 {
+		text.WriteString("- None\n")
+	}
+	return text.String()
+}
+
+// handlePcapAnalyze fetches a capture file and reports its protocols, top talkers and retransmissions
+func (s *VPPMCPServer) handlePcapAnalyze(ctx context.Context, input PcapAnalyzeInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received pcap analyze request for pod: %s, file: %s, artifact: %s", input.PodName, input.File, input.ArtifactID)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	top := input.Top
+	if top <= 0 {
+		top = defaultPcapTopFlows
+	}
+
+	data, source, err := s.loadPcap(ctx, input.PodName, input.File, input.ArtifactID)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	if dryRunFromContext(ctx) != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: ""}}}, nil, nil
+	}
+
+	capture, err := parsePcap(data)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error decoding %s: %v", source, err),
+				},
+			},
+		}, nil, err
+	}
+	if len(capture.Packets) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Capture Analysis: %s\n\nThe capture holds no packet.", source),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Capture Analysis: %s\n\n%s", source, formatPcapStats(analyzePcap(capture), top)),
+			},
+		},
+	}, nil, nil
+}
+
+const (
+	// defaultPcapBytesPerPacket is the snap length of 'pcap trace' without max-bytes-per-pkt
+	defaultPcapBytesPerPacket = 512
+	minPcapBytesPerPacket     = 32
+	maxPcapBytesPerPacket     = 9000
+	// pcapFileHeaderBytes and pcapRecordHeaderBytes are the overheads of the pcap file format
+	pcapFileHeaderBytes   = 24
+	pcapRecordHeaderBytes = 16
+
+	// rotatedPcapPrefix names the timestamped captures kept by vpp_pcap with keep_files
+	rotatedPcapPrefix = "vpp-mcp-pcap-"
+)
+
+// rotatedPcapRegexp matches the timestamped captures of vpp_pcap, sorting chronologically by name
+var rotatedPcapRegexp = regexp.MustCompile(`^vpp-mcp-pcap-\d{8}-\d{6}\.pcap$`)
+
+// rotatedPcapName returns the file name of a timestamped capture started at t
+func rotatedPcapName(t time.Time) string {
+	return rotatedPcapPrefix + t.UTC().Format("20060102-150405") + ".pcap"
+}
+
+// pcapMaxBytes returns the largest size of a capture of count packets truncated to bytesPerPacket
+func pcapMaxBytes(count, bytesPerPacket int) int64 {
+	return pcapFileHeaderBytes + int64(count)*int64(bytesPerPacket+pcapRecordHeaderBytes)
+}
+
+// parseDfAvailable returns the available bytes reported by 'df -Pk <dir>'
+func parseDfAvailable(output string) (int64, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+	available, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+	return available * 1024, nil
+}
+
+// tmpAvailableBytes returns the space available in the /tmp directory of the vpp container
+func (s *VPPMCPServer) tmpAvailableBytes(ctx context.Context, podName string) (int64, error) {
+	output, err := s.execPodArgs(ctx, podName, []string{"df", "-Pk", "/tmp"})
+	if err != nil {
+		return 0, err
+	}
+	return parseDfAvailable(output)
+}
+
+// rotatePcapFiles removes the oldest timestamped captures of the vpp container, keeping the
+// keep newest ones, and returns the removed files
+func (s *VPPMCPServer) rotatePcapFiles(ctx context.Context, podName string, keep int) ([]string, error) {
+	output, err := s.execPodArgs(ctx, podName, []string{"ls", "-1", "/tmp"})
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range strings.Split(output, "\n") {
+		if name = strings.TrimSpace(name); rotatedPcapRegexp.MatchString(name) {
+			files = append(files, "/tmp/"+name)
+		}
+	}
+	if len(files) <= keep {
+		return nil, nil
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	removed := files[keep:]
+	if _, err := s.execPodArgs(ctx, podName, append([]string{"rm", "-f"}, removed...)); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// serializePacket builds the bytes of a packet from its layers, fixing the lengths
+func serializePacket(t *testing.T, packetLayers ...gopacket.SerializableLayer) []byte {
+	t.Helper()
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, packetLayers...); err != nil {
+		t.Fatalf("serializing the packet: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func ethernet(etherType layers.EthernetType) *layers.Ethernet {
+	return &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0x02, 0, 0, 0, 0, 1},
+		DstMAC:       net.HardwareAddr{0x02, 0, 0, 0, 0, 2},
+		EthernetType: etherType,
+	}
+}
+
+func ipv4(protocol layers.IPProtocol) *layers.IPv4 {
+	return &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TOS:      0xb8,
+		Id:       0x1f2e,
+		TTL:      64,
+		Protocol: protocol,
+		SrcIP:    net.IPv4(10, 0, 0, 1),
+		DstIP:    net.IPv4(10, 0, 0, 2),
+	}
+}
+
+func TestParsePcap(t *testing.T) {
+	packet := serializePacket(t, ethernet(layers.EthernetTypeIPv4), ipv4(layers.IPProtocolUDP),
+		&layers.UDP{SrcPort: 1234, DstPort: 53}, gopacket.Payload([]byte("query")))
+	start := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	var file bytes.Buffer
+	writer := pcapgo.NewWriter(&file)
+	if err := writer.WriteFileHeader(65535, layers.LinkTypeEthernet); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		info := gopacket.CaptureInfo{Timestamp: start.Add(time.Duration(i) * time.Second), CaptureLength: len(packet), Length: len(packet)}
+		if err := writer.WritePacket(info, packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := file.Bytes()
+
+	capture, err := parsePcap(data)
+	if err != nil {
+		t.Fatalf("parsePcap() error = %v", err)
+	}
+	if capture.LinkType != linkTypeEthernet || len(capture.Packets) != 2 {
+		t.Fatalf("parsePcap() = link type %d with %d packets, want %d with 2", capture.LinkType, len(capture.Packets), linkTypeEthernet)
+	}
+	if got := capture.Packets[1]; !got.Timestamp.Equal(start.Add(time.Second)) || got.Length != len(packet) || !bytes.Equal(got.data, packet) {
+		t.Errorf("parsePcap() second packet = %v len=%d, want %v len=%d", got.Timestamp, got.Length, start.Add(time.Second), len(packet))
+	}
+
+	// A truncated last record is ignored
+	if capture, err := parsePcap(data[:len(data)-3]); err != nil || len(capture.Packets) != 1 {
+		t.Errorf("parsePcap() of a truncated file = %v, %v, want 1 packet", capture, err)
+	}
+
+	// The 32-bit link type of VPP dispatch traces doesn't fit layers.LinkType
+	dispatch := append([]byte(nil), data[:24]...)
+	binary.LittleEndian.PutUint32(dispatch[20:24], linkTypeVPPDispatch)
+	if capture, err := parsePcap(dispatch); err != nil || capture.LinkType != linkTypeVPPDispatch {
+		t.Errorf("parsePcap() of a dispatch trace = %v, %v, want link type %d", capture, err, linkTypeVPPDispatch)
+	}
+
+	for name, invalid := range map[string][]byte{
+		"pcapng": {0x0a, 0x0d, 0x0d, 0x0a, 0x1c, 0, 0, 0},
+		"short":  {0xd4, 0xc3, 0xb2},
+		"text":   []byte("this is not a capture file at all"),
+	} {
+		if _, err := parsePcap(invalid); err == nil {
+			t.Errorf("parsePcap() of %s succeeded, want an error", name)
+		}
+	}
+}
+
+func TestDecodePacket(t *testing.T) {
+	src4, dst4 := netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")
+	src6, dst6 := netip.MustParseAddr("2001:db8::1"), netip.MustParseAddr("2001:db8::2")
+
+	fragment := ipv4(layers.IPProtocolUDP)
+	fragment.FragOffset = 185
+
+	ip6 := &layers.IPv6{
+		Version:      6,
+		TrafficClass: 0x2e,
+		NextHeader:   layers.IPProtocolUDP,
+		HopLimit:     64,
+		SrcIP:        net.ParseIP("2001:db8::1"),
+		DstIP:        net.ParseIP("2001:db8::2"),
+	}
+	udp6 := &layers.UDP{SrcPort: 5353, DstPort: 53}
+	if err := udp6.SetNetworkLayerForChecksum(ip6); err != nil {
+		t.Fatal(err)
+	}
+
+	// IPv6 with a hop-by-hop header carrying a PadN option, then UDP with a 4-byte payload
+	hopByHop := append([]byte{0x60, 0, 0, 0, 0, 20, 0, 64}, append(ip6.SrcIP.To16(), ip6.DstIP.To16()...)...)
+	hopByHop = append(hopByHop, 17, 0, 1, 4, 0, 0, 0, 0)
+	hopByHop = append(hopByHop, 0x04, 0xd2, 0, 0x35, 0, 12, 0, 0, 1, 2, 3, 4)
+
+	tcpSYN := serializePacket(t, ethernet(layers.EthernetTypeDot1Q),
+		&layers.Dot1Q{VLANIdentifier: 100, Type: layers.EthernetTypeIPv4}, ipv4(layers.IPProtocolTCP),
+		&layers.TCP{SrcPort: 40000, DstPort: 80, Seq: 1000, SYN: true, ECE: true, DataOffset: 5, Window: 1024},
+		gopacket.Payload(make([]byte, 10)))
+
+	tests := []struct {
+		name     string
+		linkType uint32
+		data     []byte
+		want     decodedPacket
+	}{
+		{
+			name:     "tcp syn in a vlan",
+			linkType: linkTypeEthernet,
+			data:     tcpSYN,
+			want: decodedPacket{Network: "IPv4", Src: src4, Dst: dst4, TOS: 0xb8, ID: 0x1f2e, Protocol: "TCP",
+				SrcPort: 40000, DstPort: 80, TCPFlags: "SYN,ECE", Seq: 1000, Payload: 10},
+		},
+		{
+			name:     "tcp truncated by the snap length",
+			linkType: linkTypeEthernet,
+			data:     tcpSYN[:18+20+10],
+			want:     decodedPacket{Network: "IPv4", Src: src4, Dst: dst4, TOS: 0xb8, ID: 0x1f2e, Protocol: "TCP", Note: "truncated TCP header"},
+		},
+		{
+			name:     "udp over ipv6",
+			linkType: linkTypeEthernet,
+			data:     serializePacket(t, ethernet(layers.EthernetTypeIPv6), ip6, udp6, gopacket.Payload(make([]byte, 20))),
+			want:     decodedPacket{Network: "IPv6", Src: src6, Dst: dst6, TOS: 0x2e, Protocol: "UDP", SrcPort: 5353, DstPort: 53, Payload: 20},
+		},
+		{
+			name:     "udp after an ipv6 hop-by-hop header",
+			linkType: linkTypeIPv6,
+			data:     hopByHop,
+			want:     decodedPacket{Network: "IPv6", Src: src6, Dst: dst6, Protocol: "UDP", SrcPort: 1234, DstPort: 53, Payload: 4},
+		},
+		{
+			name:     "icmp echo request on a raw ip link",
+			linkType: linkTypeRaw,
+			data: serializePacket(t, ipv4(layers.IPProtocolICMPv4),
+				&layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoRequest, 0)}),
+			want: decodedPacket{Network: "IPv4", Src: src4, Dst: dst4, TOS: 0xb8, ID: 0x1f2e, Protocol: "ICMP", Note: "type 8 code 0"},
+		},
+		{
+			name:     "non-first fragment",
+			linkType: linkTypeIPv4,
+			data:     serializePacket(t, fragment, gopacket.Payload(make([]byte, 16))),
+			want:     decodedPacket{Network: "IPv4", Src: src4, Dst: dst4, TOS: 0xb8, ID: 0x1f2e, Protocol: "UDP", Note: "non-first fragment"},
+		},
+		{
+			name:     "arp",
+			linkType: linkTypeEthernet,
+			data: serializePacket(t, ethernet(layers.EthernetTypeARP), &layers.ARP{
+				AddrType: layers.LinkTypeEthernet, Protocol: layers.EthernetTypeIPv4, HwAddressSize: 6, ProtAddressSize: 4,
+				Operation: layers.ARPRequest, SourceHwAddress: make([]byte, 6), SourceProtAddress: make([]byte, 4),
+				DstHwAddress: make([]byte, 6), DstProtAddress: make([]byte, 4),
+			}),
+			want: decodedPacket{Network: "ARP"},
+		},
+		{
+			name:     "other ethertype",
+			linkType: linkTypeEthernet,
+			data:     serializePacket(t, ethernet(layers.EthernetType(0x88b5)), gopacket.Payload(make([]byte, 46))),
+			want:     decodedPacket{Network: "EtherType 0x88b5"},
+		},
+		{
+			name:     "empty raw packet",
+			linkType: linkTypeRaw,
+			want:     decodedPacket{Note: "empty packet"},
+		},
+		{
+			name:     "vpp dispatch trace",
+			linkType: linkTypeVPPDispatch,
+			data:     []byte{0},
+			want:     decodedPacket{Note: "VPP dispatch trace record, open it with the Wireshark VPP dissector"},
+		},
+		{
+			name:     "unsupported link type",
+			linkType: 147,
+			data:     []byte{0},
+			want:     decodedPacket{Note: "unsupported link type 147"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodePacket(tt.linkType, tt.data); got != tt.want {
+				t.Errorf("decodePacket() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Truncated link headers are reported, not decoded
+	if got := decodePacket(linkTypeEthernet, tcpSYN[:10]); got.Network != "" || got.Note == "" {
+		t.Errorf("decodePacket() of a truncated Ethernet header = %+v, want a note", got)
+	}
+}
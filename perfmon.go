@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// perfmonBundleRegexp matches a perfmon bundle name such as "inst-and-clock" or "cache-hierarchy"
+var perfmonBundleRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// VPPPerfmonStartInput represents the input for starting a perfmon bundle
+type VPPPerfmonStartInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Bundle specifies the perfmon bundle to collect, e.g. "inst-and-clock"
+	Bundle string `json:"bundle"`
+	// Type specifies the bundle type for bundles supporting several: node, thread or system
+	Type string `json:"type,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// VPPPerfmonShowInput represents the input for showing perfmon results
+type VPPPerfmonShowInput struct {
+	VPPCommandInput
+	// Raw shows the raw counter values instead of the computed metrics
+	Raw bool `json:"raw,omitempty"`
+	// Bundles lists the bundles supported by the CPU instead of the statistics
+	Bundles bool `json:"bundles,omitempty"`
+}
+
+// handlePerfmonStart starts collecting the counters of a perfmon bundle
+func (s *VPPMCPServer) handlePerfmonStart(ctx context.Context, input VPPPerfmonStartInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received perfmon start request for pod: %s, bundle: %s, type: %s", input.PodName, input.Bundle, input.Type)
+
+	var err error
+	if !perfmonBundleRegexp.MatchString(input.Bundle) {
+		err = fmt.Errorf("invalid bundle %q: expected a bundle name such as inst-and-clock (see vpp_perfmon_show with bundles)", input.Bundle)
+	} else if input.Type != "" && input.Type != "node" && input.Type != "thread" && input.Type != "system" {
+		err = fmt.Errorf("invalid type '%s'. Use 'node', 'thread' or 'system'", input.Type)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	command := "perfmon start bundle " + input.Bundle
+	if input.Type != "" {
+		command += " type " + input.Type
+	}
+	return s.handleVPPCommand(ctx, VPPCommandInput{PodName: input.PodName, TimeoutSeconds: input.TimeoutSeconds}, command, "VPP Perfmon Start")
+}
+
+// handlePerfmonShow shows the statistics of the running perfmon bundle, or the available bundles
+func (s *VPPMCPServer) handlePerfmonShow(ctx context.Context, input VPPPerfmonShowInput) (*mcp.CallToolResult, any, error) {
+	command, description := "show perfmon statistics", "VPP Perfmon Statistics"
+	switch {
+	case input.Bundles:
+		command, description = "show perfmon bundle verbose", "VPP Perfmon Bundles"
+	case input.Raw:
+		command += " raw"
+	}
+	return s.handleVPPCommand(ctx, input.VPPCommandInput, command, description)
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// pgStreamPrefix prefixes the packet-generator streams of vpp_pg_inject. Each call names its
+	// stream after its start time, so concurrent injections never replace each other's stream.
+	pgStreamPrefix      = "vpp-mcp-pg-"
+	defaultPgCount      = 10
+	maxPgCount          = 10000
+	defaultPgRate       = 100
+	defaultPgPayload    = 64
+	maxPgPayload        = 1400
+	maxPgInjectDuration = 60 * time.Second
+)
+
+// VPPPgInjectInput represents the input for injecting synthetic packets with the packet generator
+type VPPPgInjectInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// SrcIP and DstIP are the addresses of the template packet
+	SrcIP string `json:"src_ip,omitempty"`
+	DstIP string `json:"dst_ip,omitempty"`
+	// Protocol is the protocol of the template packet: udp, tcp or icmp (default: udp)
+	Protocol string `json:"protocol,omitempty"`
+	// SrcPort and DstPort are the ports of a UDP or TCP template packet
+	SrcPort int `json:"src_port,omitempty"`
+	DstPort int `json:"dst_port,omitempty"`
+	// PayloadBytes is the size of the payload of the template packet
+	PayloadBytes int `json:"payload_bytes,omitempty"`
+	// PcapFile replays the packets of a pcap file of the pod instead of a template packet
+	PcapFile string `json:"pcap_file,omitempty"`
+	// Interface is the interface the packets appear to be received on
+	Interface string `json:"interface,omitempty"`
+	// Node is the graph node the packets are injected into
+	Node string `json:"node,omitempty"`
+	// Count is the number of packets to inject
+	Count int `json:"count,omitempty"`
+	// Rate is the number of packets injected per second
+	Rate int `json:"rate,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// pgStreamName returns the name of the packet-generator stream of a call started at now
+func pgStreamName(now time.Time) string {
+	return pgStreamPrefix + strconv.FormatInt(now.UnixNano(), 10)
+}
+
+// pgStreamCommand builds the 'packet-generator new' command of an injection request, e.g.
+// "packet-generator new { name vpp-mcp-pg-1700000000000000000 limit 10 rate 100 node ip4-input data { UDP: 10.0.0.1 -> 10.0.0.2 UDP: 1234 -> 80 incrementing 64 } }".
+// Template packets start with the IP header, so they are injected into ip4-input or ip6-input;
+// pcap files hold Ethernet frames and are injected into ethernet-input.
+func pgStreamCommand(input VPPPgInjectInput, name string, count, rate int) (string, error) {
+	if input.Interface != "" {
+		if err := validateInterfaceName(input.Interface); err != nil {
+			return "", err
+		}
+	}
+	if input.Node != "" {
+		if err := validateNodeName(input.Node); err != nil {
+			return "", err
+		}
+	}
+
+	stream := []string{"name", name, "limit", strconv.Itoa(count), "rate", strconv.Itoa(rate)}
+	if input.Interface != "" {
+		stream = append(stream, "interface", input.Interface)
+	}
+
+	if input.PcapFile != "" {
+		if input.SrcIP != "" || input.DstIP != "" || input.Protocol != "" || input.SrcPort != 0 || input.DstPort != 0 || input.PayloadBytes != 0 {
+			return "", fmt.Errorf("pcap_file and the template packet fields are exclusive")
+		}
+		if err := validatePcapFile(input.PcapFile); err != nil {
+			return "", err
+		}
+		node := input.Node
+		if node == "" {
+			node = "ethernet-input"
+		}
+		stream = append(stream, "node", node, "pcap", input.PcapFile)
+		return "packet-generator new { " + strings.Join(stream, " ") + " }", nil
+	}
+
+	if input.SrcIP == "" || input.DstIP == "" {
+		return "", fmt.Errorf("src_ip and dst_ip are required unless pcap_file is set")
+	}
+	src, err := netip.ParseAddr(input.SrcIP)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: expected e.g. 10.0.0.1 or 2001:db8::1", input.SrcIP)
+	}
+	dst, err := netip.ParseAddr(input.DstIP)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: expected e.g. 10.0.0.1 or 2001:db8::1", input.DstIP)
+	}
+	if src.Is4() != dst.Is4() {
+		return "", fmt.Errorf("src_ip and dst_ip must be of the same address family")
+	}
+
+	payload := input.PayloadBytes
+	if payload == 0 {
+		payload = defaultPgPayload
+	}
+	if payload < 0 || payload > maxPgPayload {
+		return "", fmt.Errorf("invalid payload_bytes %d: must be between 1 and %d", payload, maxPgPayload)
+	}
+
+	protocol := strings.ToLower(input.Protocol)
+	if protocol == "" {
+		protocol = "udp"
+	}
+	var data []string
+	switch protocol {
+	case "udp", "tcp":
+		for _, port := range []struct {
+			name  string
+			value int
+		}{{"src_port", input.SrcPort}, {"dst_port", input.DstPort}} {
+			if port.value < 1 || port.value > 65535 {
+				return "", fmt.Errorf("invalid %s %d: must be between 1 and 65535", port.name, port.value)
+			}
+		}
+		name := strings.ToUpper(protocol)
+		data = []string{name + ":", src.String(), "->", dst.String(), name + ":", strconv.Itoa(input.SrcPort), "->", strconv.Itoa(input.DstPort)}
+	case "icmp":
+		if input.SrcPort != 0 || input.DstPort != 0 {
+			return "", fmt.Errorf("src_port and dst_port require protocol udp or tcp")
+		}
+		if !src.Is4() {
+			return "", fmt.Errorf("protocol icmp requires IPv4 addresses")
+		}
+		data = []string{"ICMP:", src.String(), "->", dst.String(), "ICMP", "echo_request"}
+	default:
+		return "", fmt.Errorf("invalid protocol '%s'. Use 'udp', 'tcp' or 'icmp'", input.Protocol)
+	}
+	data = append(data, "incrementing", strconv.Itoa(payload))
+
+	node := input.Node
+	if node == "" {
+		node = "ip4-input"
+		if !src.Is4() {
+			node = "ip6-input"
+		}
+	}
+	stream = append(stream, "node", node, "data", "{", strings.Join(data, " "), "}")
+	return "packet-generator new { " + strings.Join(stream, " ") + " }", nil
+}
+
+// parseErrorCounts maps the counters of 'show errors' output, keyed by node and reason, to their count
+func parseErrorCounts(output string) map[string]uint64 {
+	counts := make(map[string]uint64)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		count, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		counts[strings.Join(fields[1:], " ")] += count
+	}
+	return counts
+}
+
+// formatErrorDelta lists the error counters that increased between two 'show errors' outputs,
+// highest increase first
+func formatErrorDelta(before, after string) string {
+	previous := parseErrorCounts(before)
+	type delta struct {
+		counter  string
+		increase uint64
+	}
+	var deltas []delta
+	for counter, count := range parseErrorCounts(after) {
+		if count > previous[counter] {
+			deltas = append(deltas, delta{counter, count - previous[counter]})
+		}
+	}
+	if len(deltas) == 0 {
+		return "No error counter increased"
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].increase != deltas[j].increase {
+			return deltas[i].increase > deltas[j].increase
+		}
+		return deltas[i].counter < deltas[j].counter
+	})
+	var lines []string
+	for _, d := range deltas {
+		lines = append(lines, fmt.Sprintf("%12d  %s", d.increase, d.counter))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handlePgInject injects synthetic packets with the VPP packet generator, then reports the
+// stream and the error counters increased meanwhile, so a forwarding path can be validated
+// without touching the workloads
+func (s *VPPMCPServer) handlePgInject(ctx context.Context, input VPPPgInjectInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received packet generator request for pod: %s, src: %s, dst: %s, pcap: %s",
+		input.PodName, input.SrcIP, input.DstIP, input.PcapFile)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	count := input.Count
+	if count == 0 {
+		count = defaultPgCount
+	}
+	rate := input.Rate
+	if rate == 0 {
+		rate = defaultPgRate
+	}
+	name := pgStreamName(time.Now())
+	var command string
+	var err error
+	switch {
+	case count < 0 || count > maxPgCount:
+		err = fmt.Errorf("invalid count %d: must be between 1 and %d", count, maxPgCount)
+	case rate < 0:
+		err = fmt.Errorf("invalid rate %d: must not be negative", rate)
+	case time.Duration(count)*time.Second/time.Duration(rate) > maxPgInjectDuration:
+		err = fmt.Errorf("count %d at rate %d would inject for more than %s: raise rate or lower count", count, rate, maxPgInjectDuration)
+	default:
+		command, err = pgStreamCommand(input, name, count, rate)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Injections are serialized per pod, with each other and with the captures whose traces they would pollute
+	unlock, err := s.lockCapture(ctx, input.PodName)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	defer unlock()
+
+	// Step 1: Snapshot the error counters
+	errorsBefore, errBefore := s.ExecutePodVPPCommand(ctx, input.PodName, "show errors")
+
+	// Step 2: Create the stream. The braces of the stream definition are passed as arguments,
+	// without a shell, so it bypasses the command validation of ExecutePodVPPCommand.
+	if output, err := s.execPodArgs(ctx, input.PodName, s.executor.VPPArgs(command)); err != nil || strings.Contains(output, "unknown input") {
+		if err == nil {
+			err = fmt.Errorf("%s", strings.TrimSpace(output))
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error creating the packet-generator stream: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	defer func() {
+		_, _ = s.ExecutePodVPPCommand(context.WithoutCancel(ctx), input.PodName, "packet-generator delete "+name)
+	}()
+
+	// Step 3: Inject the packets and wait for the stream to complete
+	if _, err := s.ExecutePodVPPCommand(ctx, input.PodName, "packet-generator enable-stream "+name); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error enabling the packet-generator stream: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	if dryRunFromContext(ctx) == nil {
+		select {
+		case <-ctx.Done():
+			_, _ = s.ExecutePodVPPCommand(context.WithoutCancel(ctx), input.PodName, "packet-generator disable-stream "+name)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", ctx.Err()),
+					},
+				},
+			}, nil, ctx.Err()
+		case <-time.After(time.Duration(count)*time.Second/time.Duration(rate) + time.Second):
+		}
+	}
+
+	// Step 4: Report the stream and the error counters
+	var text strings.Builder
+	text.WriteString("VPP Packet Generator Injection:\n\n")
+	if result, err := s.ExecutePodVPPCommand(ctx, input.PodName, "show packet-generator"); err != nil {
+		text.WriteString(fmt.Sprintf("=== Stream ===\nError executing 'show packet-generator': %v\n\n", err))
+	} else {
+		text.WriteString(fmt.Sprintf("=== Stream ===\n%s\n\n", strings.TrimSpace(result["output"].(string))))
+	}
+	if result, err := s.ExecutePodVPPCommand(ctx, input.PodName, "show errors"); err != nil || errBefore != nil {
+		text.WriteString(fmt.Sprintf("=== Error Counters Increased ===\nError executing 'show errors': %v\n\n", errors.Join(errBefore, err)))
+	} else {
+		text.WriteString(fmt.Sprintf("=== Error Counters Increased ===\n%s\n\n", formatErrorDelta(errorsBefore["output"].(string), result["output"].(string))))
+	}
+
+	source := fmt.Sprintf("Template: %s %s -> %s", strings.ToLower(input.Protocol), input.SrcIP, input.DstIP)
+	if input.Protocol == "" {
+		source = fmt.Sprintf("Template: udp %s -> %s", input.SrcIP, input.DstIP)
+	}
+	if input.PcapFile != "" {
+		source = "Pcap: " + input.PcapFile
+	}
+	text.WriteString(fmt.Sprintf("Injection Parameters:\n- %s\n- Count: %d\n- Rate: %d pps\n- Pod: %s\n- Stream: %s\n\n",
+		source, count, rate, input.PodName, command))
+	text.WriteString("**Note**: The error counters are those of the whole VPP instance, so they also count the workload traffic of the same window.\n")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func TestPgStreamCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   VPPPgInjectInput
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "udp template",
+			input: VPPPgInjectInput{SrcIP: "10.0.0.1", DstIP: "10.0.0.2", SrcPort: 1234, DstPort: 80},
+			want:  "packet-generator new { name vpp-mcp-pg-1 limit 10 rate 100 node ip4-input data { UDP: 10.0.0.1 -> 10.0.0.2 UDP: 1234 -> 80 incrementing 64 } }",
+		},
+		{
+			name:  "tcp over ipv6 on an interface",
+			input: VPPPgInjectInput{SrcIP: "2001:db8::1", DstIP: "2001:db8::2", Protocol: "tcp", SrcPort: 1234, DstPort: 443, PayloadBytes: 200, Interface: "tap0"},
+			want:  "packet-generator new { name vpp-mcp-pg-1 limit 10 rate 100 interface tap0 node ip6-input data { TCP: 2001:db8::1 -> 2001:db8::2 TCP: 1234 -> 443 incrementing 200 } }",
+		},
+		{
+			name:  "icmp echo request",
+			input: VPPPgInjectInput{SrcIP: "10.0.0.1", DstIP: "10.0.0.2", Protocol: "ICMP"},
+			want:  "packet-generator new { name vpp-mcp-pg-1 limit 10 rate 100 node ip4-input data { ICMP: 10.0.0.1 -> 10.0.0.2 ICMP echo_request incrementing 64 } }",
+		},
+		{
+			name:  "pcap replay",
+			input: VPPPgInjectInput{PcapFile: "/tmp/capture.pcap"},
+			want:  "packet-generator new { name vpp-mcp-pg-1 limit 10 rate 100 node ethernet-input pcap /tmp/capture.pcap }",
+		},
+		{
+			name:    "pcap and template fields",
+			input:   VPPPgInjectInput{PcapFile: "/tmp/capture.pcap", SrcIP: "10.0.0.1"},
+			wantErr: true,
+		},
+		{
+			name:    "missing addresses",
+			input:   VPPPgInjectInput{SrcIP: "10.0.0.1"},
+			wantErr: true,
+		},
+		{
+			name:    "mixed address families",
+			input:   VPPPgInjectInput{SrcIP: "10.0.0.1", DstIP: "2001:db8::2", SrcPort: 1, DstPort: 2},
+			wantErr: true,
+		},
+		{
+			name:    "udp without ports",
+			input:   VPPPgInjectInput{SrcIP: "10.0.0.1", DstIP: "10.0.0.2"},
+			wantErr: true,
+		},
+		{
+			name:    "icmp over ipv6",
+			input:   VPPPgInjectInput{SrcIP: "2001:db8::1", DstIP: "2001:db8::2", Protocol: "icmp"},
+			wantErr: true,
+		},
+		{
+			name:    "payload too large",
+			input:   VPPPgInjectInput{SrcIP: "10.0.0.1", DstIP: "10.0.0.2", SrcPort: 1, DstPort: 2, PayloadBytes: maxPgPayload + 1},
+			wantErr: true,
+		},
+		{
+			name:    "invalid interface",
+			input:   VPPPgInjectInput{SrcIP: "10.0.0.1", DstIP: "10.0.0.2", SrcPort: 1, DstPort: 2, Interface: "tap0 }"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pgStreamCommand(tt.input, "vpp-mcp-pg-1", defaultPgCount, defaultPgRate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pgStreamCommand() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("pgStreamCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
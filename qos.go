@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultQoSCheckCount    = 100
+	maxQoSCheckCount        = 1000
+	defaultQoSCheckDuration = 10 * time.Second
+	maxQoSCheckDuration     = 5 * time.Minute
+	// qosEgressPcapFile is the file, under /tmp of the vpp container, of the egress capture
+	qosEgressPcapFile = "vpp-mcp-qos-egress.pcap"
+)
+
+// ecnNames maps ECN codepoints to their RFC 3168 names
+var ecnNames = [4]string{"Not-ECT", "ECT(1)", "ECT(0)", "CE"}
+
+// QoSPreservationInput represents the input for the DSCP/ECN preservation check
+type QoSPreservationInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Interface specifies the interface type traced on ingress
+	Interface string `json:"interface,omitempty"`
+	// EgressInterface is the VPP interface whose transmitted packets are captured to read the
+	// egress DSCP/ECN bits from the wire
+	EgressInterface string `json:"egress_interface,omitempty"`
+	// SrcIP and DstIP optionally restrict the capture to one flow
+	SrcIP string `json:"src_ip,omitempty"`
+	DstIP string `json:"dst_ip,omitempty"`
+	// Protocol, SrcPort and DstPort further restrict the flow
+	Protocol string `json:"protocol,omitempty"`
+	SrcPort  int    `json:"src_port,omitempty"`
+	DstPort  int    `json:"dst_port,omitempty"`
+	// ExpectedDSCP is the DSCP value packets are expected to be remarked to on egress
+	ExpectedDSCP *int `json:"expected_dscp,omitempty"`
+	// Count specifies the number of packets to capture
+	Count int `json:"count,omitempty"`
+	// DurationSeconds specifies how long the capture runs
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// formatTOS renders the DSCP and ECN fields of a ToS byte
+func formatTOS(tos uint8) string {
+	return fmt.Sprintf("DSCP %d, ECN %s", tos>>2, ecnNames[tos&0x3])
+}
+
+// qosCheck compares the ToS byte of a packet on ingress and egress. It returns the expected
+// DSCP and whether the egress bits are the expected ones.
+func qosCheck(ingress, egress uint8, expected *int) (uint8, bool) {
+	expectedDSCP := ingress >> 2
+	if expected != nil {
+		expectedDSCP = uint8(*expected)
+	}
+	// ECN must never be cleared by the dataplane; congestion may only set CE
+	ecnOK := egress&0x3 == ingress&0x3 || (ingress&0x3 != 0 && egress&0x3 == 0x3)
+	return expectedDSCP, egress>>2 == expectedDSCP && ecnOK
+}
+
+// ingressTOS returns the ToS byte of the packets on ingress by IPv4 identification, and the
+// ToS byte shared by all of them, if any
+func ingressTOS(packets []tracedPacket) (map[uint16]uint8, *uint8) {
+	byID := make(map[uint16]uint8)
+	var shared *uint8
+	mixed := false
+	for _, packet := range packets {
+		if len(packet.TOS) == 0 {
+			continue
+		}
+		tos := packet.TOS[0]
+		if len(packet.IPIDs) > 0 {
+			byID[packet.IPIDs[0]] = tos
+		}
+		switch {
+		case shared == nil && !mixed:
+			shared = &tos
+		case shared != nil && *shared != tos:
+			shared, mixed = nil, true
+		}
+	}
+	return byID, shared
+}
+
+// captureQoSEgress traces packets on ingress while capturing the transmitted packets of the
+// egress interface, so the egress DSCP/ECN bits are read after the rewrite, as they go on the wire
+func (s *VPPMCPServer) captureQoSEgress(ctx context.Context, pod, classifier, inputNode, egressInterface string, count int, duration time.Duration) ([]tracedPacket, []decodedPacket, error) {
+	unlock, err := s.lockCapture(ctx, pod)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	_, _ = s.ExecutePodVPPCommand(ctx, pod, "pcap trace off")
+	pcapCmd := fmt.Sprintf("pcap trace tx max %d intfc %s file %s", count, egressInterface, qosEgressPcapFile)
+	if classifier != "" {
+		_, _ = s.ExecutePodVPPCommand(ctx, pod, "classify filter pcap del")
+		if _, err := s.ExecutePodVPPCommand(ctx, pod, "classify filter pcap "+classifier); err != nil {
+			return nil, nil, fmt.Errorf("installing the pcap filter failed: %v", err)
+		}
+		defer func() {
+			_, _ = s.ExecutePodVPPCommand(context.WithoutCancel(ctx), pod, "classify filter pcap del")
+		}()
+		pcapCmd += " filter"
+	}
+	if _, err := s.ExecutePodVPPCommand(ctx, pod, pcapCmd); err != nil {
+		return nil, nil, fmt.Errorf("starting the egress pcap failed: %v", err)
+	}
+
+	packets, err := s.captureTraceLocked(ctx, pod, classifier, []string{inputNode}, count, duration)
+	if _, stopErr := s.ExecutePodVPPCommand(context.WithoutCancel(ctx), pod, "pcap trace off"); err == nil && stopErr != nil {
+		err = fmt.Errorf("stopping the egress pcap failed: %v", stopErr)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := s.fetchPodFile(ctx, pod, "/tmp/"+qosEgressPcapFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading the egress pcap failed: %v", err)
+	}
+	if data == nil {
+		// Dry run
+		return packets, nil, nil
+	}
+	capture, err := parsePcap(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing the egress pcap failed: %v", err)
+	}
+	egress := make([]decodedPacket, 0, len(capture.Packets))
+	for _, packet := range capture.Packets {
+		egress = append(egress, decodePacket(capture.LinkType, packet.data))
+	}
+	return packets, egress, nil
+}
+
+// handleQoSPreservation captures packets through VPP and compares the DSCP/ECN bits
+// of the ingress and egress IP headers
+func (s *VPPMCPServer) handleQoSPreservation(ctx context.Context, input QoSPreservationInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received DSCP/ECN preservation check for pod: %s", input.PodName)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	if input.ExpectedDSCP != nil && (*input.ExpectedDSCP < 0 || *input.ExpectedDSCP > 63) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: expected_dscp must be between 0 and 63.",
+				},
+			},
+		}, nil, fmt.Errorf("invalid expected_dscp")
+	}
+
+	// Restrict the capture to the flow when one is given
+	classifier := ""
+	if input.SrcIP != "" || input.DstIP != "" {
+		var err error
+		classifier, err = buildFlowClassifier(PacketJourneyInput{
+			SrcIP:    input.SrcIP,
+			DstIP:    input.DstIP,
+			Protocol: input.Protocol,
+			SrcPort:  input.SrcPort,
+			DstPort:  input.DstPort,
+		})
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
+	var err error
+	count := input.Count
+	if count == 0 {
+		count = defaultQoSCheckCount
+	}
+	duration := defaultQoSCheckDuration
+	if input.DurationSeconds > 0 {
+		duration = time.Duration(input.DurationSeconds) * time.Second
+	}
+	switch {
+	case count < 0 || count > maxQoSCheckCount:
+		err = fmt.Errorf("invalid count %d: must be between 1 and %d", count, maxQoSCheckCount)
+	case input.DurationSeconds < 0 || input.DurationSeconds > int(maxQoSCheckDuration.Seconds()):
+		err = fmt.Errorf("invalid duration_seconds %d: must be between 1 and %d", input.DurationSeconds, int(maxQoSCheckDuration.Seconds()))
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	var k8sClient *KubeClient
+	if !s.bypassesKubernetes() {
+		k8sClient, err = newKubeClient()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
+	vppInputNode, _, err := mapInterfaceTypeToVppInputNode(k8sClient, input.Interface)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error mapping interface: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	if input.EgressInterface != "" {
+		if err := validateInterfaceName(input.EgressInterface); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	}
+
+	var packets []tracedPacket
+	var egress []decodedPacket
+	if input.EgressInterface != "" {
+		packets, egress, err = s.captureQoSEgress(ctx, input.PodName, classifier, vppInputNode, input.EgressInterface, count, duration)
+	} else {
+		packets, err = s.captureTrace(ctx, input.PodName, classifier, []string{vppInputNode}, count, duration)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error capturing packets: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	var text strings.Builder
+	text.WriteString("VPP DSCP/ECN Preservation Check:\n\n")
+
+	preserved, changed, unverifiable := 0, 0, 0
+	if input.EgressInterface == "" {
+		// Without an egress capture, the last IP header decoded in the trace stands for the
+		// egress one: it is printed by ip4-rewrite/ip6-rewrite, after any remarking, but misses
+		// changes made by the output features and the device driver
+		for _, packet := range packets {
+			if len(packet.TOS) < 2 || packet.dropped() {
+				unverifiable++
+				continue
+			}
+			ingress, egress := packet.TOS[0], packet.TOS[len(packet.TOS)-1]
+			expectedDSCP, ok := qosCheck(ingress, egress, input.ExpectedDSCP)
+			if ok {
+				preserved++
+				continue
+			}
+
+			changed++
+			text.WriteString(fmt.Sprintf("- Packet %s: ingress %s -> egress %s (expected DSCP %d)\n  Path: %s\n",
+				packet.Number, formatTOS(ingress), formatTOS(egress), expectedDSCP, strings.Join(packet.Nodes, " -> ")))
+		}
+	} else {
+		// Egress packets are matched to the traced packets by IPv4 identification. When a flow
+		// is given, the capture only holds that flow, so packets without a match (IPv6,
+		// encapsulated) are compared with the ingress ToS if all traced packets share it.
+		byID, shared := ingressTOS(packets)
+		for i, packet := range egress {
+			if packet.Network != "IPv4" && packet.Network != "IPv6" {
+				continue
+			}
+			ingress, found := uint8(0), false
+			if packet.Network == "IPv4" {
+				ingress, found = byID[packet.ID]
+			}
+			if !found && classifier != "" && shared != nil {
+				ingress, found = *shared, true
+			}
+			if !found {
+				unverifiable++
+				continue
+			}
+			expectedDSCP, ok := qosCheck(ingress, packet.TOS, input.ExpectedDSCP)
+			if ok {
+				preserved++
+				continue
+			}
+
+			changed++
+			text.WriteString(fmt.Sprintf("- Egress packet %d (%s): ingress %s -> egress %s (expected DSCP %d)\n",
+				i+1, packet.summary(), formatTOS(ingress), formatTOS(packet.TOS), expectedDSCP))
+		}
+	}
+
+	switch {
+	case len(packets) == 0:
+		text.WriteString("No packets captured. Make sure traffic is flowing through the traced interface.\n")
+	case input.EgressInterface != "" && len(egress) == 0:
+		text.WriteString(fmt.Sprintf("No packets captured on %s. Make sure the flow leaves VPP through it; "+
+			"a flow encapsulated or NATed on egress doesn't match the src_ip/dst_ip filter there.\n", input.EgressInterface))
+	case changed == 0 && preserved > 0:
+		if input.ExpectedDSCP != nil {
+			text.WriteString(fmt.Sprintf("All verifiable packets were remarked to DSCP %d with ECN preserved.\n", *input.ExpectedDSCP))
+		} else {
+			text.WriteString("DSCP and ECN bits were preserved on all verifiable packets.\n")
+		}
+	case changed > 0:
+		text.WriteString("\nThe packets above left VPP with unexpected DSCP/ECN bits.\n")
+	}
+
+	if input.EgressInterface == "" {
+		text.WriteString(fmt.Sprintf("\nResults:\n- Captured: %d\n- Preserved: %d\n- Changed: %d\n- Not verifiable (dropped or single IP header): %d\n",
+			len(packets), preserved, changed, unverifiable))
+	} else {
+		text.WriteString(fmt.Sprintf("\nResults:\n- Traced on ingress: %d\n- Captured on egress: %d\n- Preserved: %d\n- Changed: %d\n- Not verifiable (no matching ingress packet): %d\n",
+			len(packets), len(egress), preserved, changed, unverifiable))
+	}
+	text.WriteString(fmt.Sprintf("\nCapture Parameters:\n- VPP Input Node: %s\n- Count: %d\n- Capture Duration: %s\n- Pod: %s\n",
+		vppInputNode, count, duration, input.PodName))
+	if input.EgressInterface != "" {
+		text.WriteString(fmt.Sprintf("- Egress Interface: %s (pcap tx)\n", input.EgressInterface))
+	} else {
+		text.WriteString("- Egress: last IP header of the trace; set egress_interface to read it from the wire\n")
+	}
+	if classifier != "" {
+		text.WriteString(fmt.Sprintf("- Classifier: %s\n", classifier))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestQoSCheck(t *testing.T) {
+	ef := 46
+
+	tests := []struct {
+		name     string
+		ingress  uint8
+		egress   uint8
+		expected *int
+		wantDSCP uint8
+		wantOK   bool
+	}{
+		{name: "preserved", ingress: 0xb8, egress: 0xb8, wantDSCP: 46, wantOK: true},
+		{name: "dscp cleared", ingress: 0xb8, egress: 0x00, wantDSCP: 46},
+		{name: "remarked as expected", ingress: 0x00, egress: 0xb8, expected: &ef, wantDSCP: 46, wantOK: true},
+		{name: "not remarked", ingress: 0x00, egress: 0x00, expected: &ef, wantDSCP: 46},
+		{name: "congestion marked", ingress: 0x02, egress: 0x03, wantOK: true},
+		{name: "ecn cleared", ingress: 0x02, egress: 0x00},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dscp, ok := qosCheck(tt.ingress, tt.egress, tt.expected)
+			if dscp != tt.wantDSCP || ok != tt.wantOK {
+				t.Errorf("qosCheck(0x%02x, 0x%02x) = %d, %t, want %d, %t", tt.ingress, tt.egress, dscp, ok, tt.wantDSCP, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIngressTOS(t *testing.T) {
+	byID, shared := ingressTOS([]tracedPacket{
+		{Number: "1", TOS: []uint8{0xb8, 0xb8}, IPIDs: []uint16{1, 1}},
+		{Number: "2", TOS: []uint8{0xb8}},
+		{Number: "3"},
+	})
+	if len(byID) != 1 || byID[1] != 0xb8 {
+		t.Errorf("ingressTOS() by ID = %v, want map[1:184]", byID)
+	}
+	if shared == nil || *shared != 0xb8 {
+		t.Errorf("ingressTOS() shared = %v, want 0xb8", shared)
+	}
+
+	_, shared = ingressTOS([]tracedPacket{
+		{Number: "1", TOS: []uint8{0xb8}},
+		{Number: "2", TOS: []uint8{0x00}},
+		{Number: "3", TOS: []uint8{0xb8}},
+	})
+	if shared != nil {
+		t.Errorf("ingressTOS() shared = %d, want none for mixed ToS", *shared)
+	}
+}
+
+func TestHandleQoSPreservationLimits(t *testing.T) {
+	s := NewVPPMCPServer(&fakeExecutor{})
+
+	for _, input := range []QoSPreservationInput{
+		{PodName: "calico-vpp-node-abcde", Count: maxQoSCheckCount + 1},
+		{PodName: "calico-vpp-node-abcde", Count: -1},
+		{PodName: "calico-vpp-node-abcde", DurationSeconds: int(maxQoSCheckDuration.Seconds()) + 1},
+		{PodName: "calico-vpp-node-abcde", DurationSeconds: -1},
+	} {
+		if _, _, err := s.handleQoSPreservation(context.Background(), input); err == nil {
+			t.Errorf("handleQoSPreservation() with count %d and duration_seconds %d succeeded", input.Count, input.DurationSeconds)
+		}
+	}
+	if got := s.executor.(*fakeExecutor).ran(); len(got) != 0 {
+		t.Errorf("ran %q, want no command for rejected calls", got)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultToolQuotas protects the dataplane when no quota configuration overrides them.
+// Concurrent captures on the same pod would clobber each other's trace, pcap and API trace state.
+var defaultToolQuotas = map[string]ToolQuota{
+	"vpp_trace":            {MaxConcurrentPerPod: 1},
+	"vpp_trace_analyze":    {MaxConcurrentPerPod: 1},
+	"vpp_trace_stream":     {MaxConcurrentPerPod: 1},
+	"vpp_pcap":             {MaxConcurrentPerPod: 1},
+	"vpp_trace_pcap":       {MaxConcurrentPerPod: 1},
+	"vpp_dispatch":         {MaxConcurrentPerPod: 1},
+	"vpp_dispatch_analyze": {MaxConcurrentPerPod: 1},
+	"vpp_api_trace":        {MaxConcurrentPerPod: 1},
+	"vpp_pg_inject":        {MaxConcurrentPerPod: 1},
+}
+
+// quotaDuration is a time.Duration read from a duration string such as "1h" or "30s"
+type quotaDuration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *quotaDuration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"1h\": %v", err)
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = quotaDuration(duration)
+	return nil
+}
+
+// ToolQuota limits how a tool may be called against a single pod
+type ToolQuota struct {
+	// MaxConcurrentPerPod limits the calls running at the same time on one pod
+	MaxConcurrentPerPod int `json:"max_concurrent_per_pod,omitempty"`
+	// MaxCalls limits the calls accepted per pod within Window
+	MaxCalls int `json:"max_calls,omitempty"`
+	// Window is the sliding window of MaxCalls
+	Window quotaDuration `json:"window,omitempty"`
+	// Cooldown is the minimum delay between two calls on the same pod
+	Cooldown quotaDuration `json:"cooldown,omitempty"`
+}
+
+// LoadToolQuotas returns the default quotas, overridden per tool by the JSON file at path.
+// The file maps tool names to quotas, e.g. {"vpp_clear_run": {"max_calls": 5, "window": "1h"}}.
+func LoadToolQuotas(path string) (map[string]ToolQuota, error) {
+	quotas := make(map[string]ToolQuota, len(defaultToolQuotas))
+	for tool, quota := range defaultToolQuotas {
+		quotas[tool] = quota
+	}
+	if path == "" {
+		return quotas, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota config %s: %v", path, err)
+	}
+	var configured map[string]ToolQuota
+	if err := json.Unmarshal(data, &configured); err != nil {
+		return nil, fmt.Errorf("failed to parse quota config %s: %v", path, err)
+	}
+	for tool, quota := range configured {
+		if quota.MaxCalls > 0 && quota.Window <= 0 {
+			return nil, fmt.Errorf("invalid quota for %s: max_calls requires a window", tool)
+		}
+		quotas[tool] = quota
+	}
+	return quotas, nil
+}
+
+// quotaKey identifies the calls of a tool on a pod
+type quotaKey struct {
+	tool string
+	pod  string
+}
+
+// QuotaEnforcer rejects tool calls exceeding their quota before they reach the handler
+type QuotaEnforcer struct {
+	mu      sync.Mutex
+	quotas  map[string]ToolQuota
+	running map[quotaKey]int
+	calls   map[quotaKey][]time.Time
+}
+
+// NewQuotaEnforcer creates an enforcer for the given per-tool quotas
+func NewQuotaEnforcer(quotas map[string]ToolQuota) *QuotaEnforcer {
+	return &QuotaEnforcer{
+		quotas:  quotas,
+		running: make(map[quotaKey]int),
+		calls:   make(map[quotaKey][]time.Time),
+	}
+}
+
+// acquire records a call of tool on pod, or explains why the quota rejects it.
+// The returned function must be called once the call has completed.
+func (q *QuotaEnforcer) acquire(tool, pod string) (func(), error) {
+	quota, ok := q.quotas[tool]
+	if !ok {
+		return func() {}, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := quotaKey{tool: tool, pod: pod}
+	now := time.Now()
+
+	// Forget calls older than both the window and the cooldown
+	keep := time.Duration(quota.Window)
+	if time.Duration(quota.Cooldown) > keep {
+		keep = time.Duration(quota.Cooldown)
+	}
+	calls := q.calls[key]
+	for len(calls) > 0 && now.Sub(calls[0]) >= keep {
+		calls = calls[1:]
+	}
+	q.calls[key] = calls
+
+	if quota.MaxConcurrentPerPod > 0 && q.running[key] >= quota.MaxConcurrentPerPod {
+		return nil, fmt.Errorf("%s is already running %d time(s) on pod %s (limit: %d concurrent call(s) per pod). Wait for the running call to complete",
+			tool, q.running[key], pod, quota.MaxConcurrentPerPod)
+	}
+
+	if quota.Cooldown > 0 && len(calls) > 0 {
+		since := now.Sub(calls[len(calls)-1])
+		if since < time.Duration(quota.Cooldown) {
+			return nil, fmt.Errorf("%s was called on pod %s %s ago and has a cooldown of %s. Retry in %s",
+				tool, pod, since.Round(time.Second), time.Duration(quota.Cooldown), (time.Duration(quota.Cooldown) - since).Round(time.Second))
+		}
+	}
+
+	if quota.MaxCalls > 0 {
+		var inWindow []time.Time
+		for _, at := range calls {
+			if now.Sub(at) < time.Duration(quota.Window) {
+				inWindow = append(inWindow, at)
+			}
+		}
+		if len(inWindow) >= quota.MaxCalls {
+			retryIn := time.Duration(quota.Window) - now.Sub(inWindow[0])
+			return nil, fmt.Errorf("%s quota exceeded on pod %s: at most %d call(s) per %s. Retry in %s",
+				tool, pod, quota.MaxCalls, time.Duration(quota.Window), retryIn.Round(time.Second))
+		}
+	}
+
+	q.calls[key] = append(calls, now)
+	q.running[key]++
+	return func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.running[key]--
+		if q.running[key] == 0 {
+			delete(q.running, key)
+		}
+	}, nil
+}
+
+// Middleware enforces the quotas on tools/call requests
+func (q *QuotaEnforcer) Middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+		// Dry runs execute nothing and don't count against quotas
+		if method != "tools/call" || !ok || dryRunFromContext(ctx) != nil {
+			return next(ctx, method, req)
+		}
+
+		var target struct {
+			PodName string `json:"pod_name"`
+		}
+		// Malformed arguments are reported by the tool handler itself
+		_ = json.Unmarshal(params.Arguments, &target)
+
+		release, err := q.acquire(params.Name, target.PodName)
+		if err != nil {
+			log.Printf("Rejected %s call: %v", params.Name, err)
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		defer release()
+
+		return next(ctx, method, req)
+	}
+}
+
+// describeToolQuotas renders the configured quotas for the startup log
+func describeToolQuotas(quotas map[string]ToolQuota) []string {
+	var lines []string
+	for tool, quota := range quotas {
+		lines = append(lines, fmt.Sprintf("%s: max_concurrent_per_pod=%d max_calls=%d window=%s cooldown=%s",
+			tool, quota.MaxConcurrentPerPod, quota.MaxCalls, time.Duration(quota.Window), time.Duration(quota.Cooldown)))
+	}
+	sort.Strings(lines)
+	return lines
+}
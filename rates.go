@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultRateInterval = 5 * time.Second
+	maxRateInterval     = 60 * time.Second
+)
+
+// VPPInterfaceRatesInput represents the input for the interface rate tool
+type VPPInterfaceRatesInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Interface restricts the rates to one interface
+	Interface string `json:"interface,omitempty"`
+	// IntervalSeconds specifies the delay between the two counter samples
+	IntervalSeconds int `json:"interval_seconds,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// interfaceRates holds the per-second rates of an interface between two samples
+type interfaceRates struct {
+	Interface string
+	RxPps     float64
+	RxBps     float64
+	TxPps     float64
+	TxBps     float64
+	Drops     float64
+	RxMiss    float64
+	Errors    float64
+	Reset     bool
+}
+
+// computeInterfaceRates derives the rates of every interface present in both samples.
+// An interface whose counters went down was cleared in between and is flagged as reset.
+func computeInterfaceRates(before, after map[string]map[string]uint64, seconds float64) []interfaceRates {
+	var rates []interfaceRates
+	for iface, cur := range after {
+		prev, ok := before[iface]
+		if !ok {
+			continue
+		}
+		r := interfaceRates{Interface: iface}
+		rate := func(counter string) float64 {
+			if cur[counter] < prev[counter] {
+				r.Reset = true
+				return 0
+			}
+			return float64(cur[counter]-prev[counter]) / seconds
+		}
+		r.RxPps, r.RxBps = rate("rx packets"), rate("rx bytes")*8
+		r.TxPps, r.TxBps = rate("tx packets"), rate("tx bytes")*8
+		r.Drops, r.RxMiss = rate("drops"), rate("rx-miss")
+		r.Errors = rate("rx-error") + rate("tx-error")
+		rates = append(rates, r)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if a, b := rates[i].RxPps+rates[i].TxPps, rates[j].RxPps+rates[j].TxPps; a != b {
+			return a > b
+		}
+		return rates[i].Interface < rates[j].Interface
+	})
+	return rates
+}
+
+// formatBitRate renders a rate in bits per second with a metric prefix
+func formatBitRate(bps float64) string {
+	switch {
+	case bps >= 1e9:
+		return fmt.Sprintf("%.2f Gbps", bps/1e9)
+	case bps >= 1e6:
+		return fmt.Sprintf("%.2f Mbps", bps/1e6)
+	case bps >= 1e3:
+		return fmt.Sprintf("%.2f Kbps", bps/1e3)
+	default:
+		return fmt.Sprintf("%.0f bps", bps)
+	}
+}
+
+// handleInterfaceRates samples the interface counters twice and reports packet, bit, drop and error rates
+func (s *VPPMCPServer) handleInterfaceRates(ctx context.Context, input VPPInterfaceRatesInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received interface rates request for pod: %s, interface: %s", input.PodName, input.Interface)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	interval := defaultRateInterval
+	if input.IntervalSeconds > 0 {
+		interval = time.Duration(input.IntervalSeconds) * time.Second
+	}
+	var err error
+	if input.IntervalSeconds < 0 || interval > maxRateInterval {
+		err = fmt.Errorf("invalid interval_seconds %d: must be between 1 and %d", input.IntervalSeconds, int(maxRateInterval.Seconds()))
+	} else if input.Interface != "" {
+		err = validateInterfaceName(input.Interface)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	command := "show int"
+	if input.Interface != "" {
+		command = "show int " + input.Interface
+	}
+
+	var samples [2]map[string]map[string]uint64
+	var times [2]time.Time
+	for i := range samples {
+		if i > 0 && dryRunFromContext(ctx) == nil {
+			time.Sleep(interval)
+		}
+		result, err := s.ExecutePodVPPCommand(ctx, input.PodName, command)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error executing VPP command on pod %s: %v\nCommand attempted: vppctl %s", input.PodName, err, command),
+					},
+				},
+			}, nil, nil
+		}
+		samples[i], times[i] = parseVppInterfaceCounters(result["output"].(string)), time.Now()
+	}
+
+	seconds := times[1].Sub(times[0]).Seconds()
+	rates := computeInterfaceRates(samples[0], samples[1], seconds)
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("VPP Interface Rates (over %.1f seconds):\n\n", seconds))
+	if len(rates) == 0 {
+		text.WriteString("No interface found in the output.\n")
+	} else {
+		text.WriteString("| Interface | RX pps | RX rate | TX pps | TX rate | Drops/s | RX miss/s | Errors/s |\n")
+		text.WriteString("|---|---|---|---|---|---|---|---|\n")
+	}
+	var lossy, reset []string
+	for _, r := range rates {
+		text.WriteString(fmt.Sprintf("| %s | %.1f | %s | %.1f | %s | %.1f | %.1f | %.1f |\n",
+			r.Interface, r.RxPps, formatBitRate(r.RxBps), r.TxPps, formatBitRate(r.TxBps), r.Drops, r.RxMiss, r.Errors))
+		if r.Drops > 0 || r.RxMiss > 0 || r.Errors > 0 {
+			lossy = append(lossy, r.Interface)
+		}
+		if r.Reset {
+			reset = append(reset, r.Interface)
+		}
+	}
+
+	text.WriteString("\nSummary:\n")
+	if len(lossy) == 0 {
+		text.WriteString("- No drops, RX misses or errors during the interval\n")
+	} else {
+		text.WriteString(fmt.Sprintf("- Dropping or erroring: %s (check vpp_show_errors for the drop reasons; RX misses mean the RX queues overflowed)\n", strings.Join(lossy, ", ")))
+	}
+	if len(reset) > 0 {
+		text.WriteString(fmt.Sprintf("- Counters cleared during the interval, rates underestimated: %s\n", strings.Join(reset, ", ")))
+	}
+
+	text.WriteString(fmt.Sprintf("\nCommand executed: vppctl %s (twice, %s apart)\nPod: %s (container: vpp)", command, interval, input.PodName))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultRawVPPPrefixes lists the vppctl command prefixes allowed by vpp_exec by default
+const defaultRawVPPPrefixes = "show"
+
+// rawGoBGPShape is a read-only form of a gobgp subcommand bgp_exec may run
+type rawGoBGPShape struct {
+	// usage documents the form in error messages
+	usage string
+	// match reports whether the arguments following the subcommand have the form
+	match func(args []string) bool
+}
+
+var (
+	// rawGoBGPShapes maps the gobgp subcommands bgp_exec may run to their read-only forms.
+	// Commands are matched word by word against these forms, so anything else, such as
+	// 'global as 65000 router-id 10.0.0.1' or 'neighbor 10.0.0.2 softreset', is rejected.
+	rawGoBGPShapes = map[string]rawGoBGPShape{
+		"neighbor": {"neighbor [<address> [adj-in|adj-out|local [<prefix> ...]|policy [import|export]]]", matchRawGoBGPNeighbor},
+		"global":   {"global [rib [summary|<prefix> ...]|policy [import|export]]", matchRawGoBGPGlobal},
+		"vrf":      {"vrf [<name> rib [<prefix> ...]|<name> neighbor]", matchRawGoBGPVRF},
+		"policy":   {"policy [prefix|neighbor|as-path|community|ext-community|large-community|statement]", matchRawGoBGPPolicy},
+		"rpki":     {"rpki server|table", matchRawGoBGPRPKI},
+	}
+	// rawGoBGPPolicyTypes lists the defined set types and statements 'gobgp policy' can show
+	rawGoBGPPolicyTypes = map[string]bool{
+		"prefix":          true,
+		"neighbor":        true,
+		"as-path":         true,
+		"community":       true,
+		"ext-community":   true,
+		"large-community": true,
+		"statement":       true,
+	}
+	// rawGoBGPVRFSubcommands lists the 'gobgp vrf' subcommands a VRF name must not be mistaken for
+	rawGoBGPVRFSubcommands = map[string]bool{
+		"add":    true,
+		"del":    true,
+		"delete": true,
+	}
+	// rawGoBGPVRFNameRegexp matches the VRF names of 'gobgp vrf <name>'
+	rawGoBGPVRFNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	// rawGoBGPFamilyRegexp matches the address families of the -a option, e.g. ipv4 or l2vpn-evpn
+	rawGoBGPFamilyRegexp = regexp.MustCompile(`^[a-z0-9-]+$`)
+)
+
+// VPPRawCommandInput represents the input for the raw vppctl command tool
+type VPPRawCommandInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Command specifies the vppctl command to run, without the vppctl prefix
+	Command string `json:"command"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// BGPRawCommandInput represents the input for the raw gobgp command tool
+type BGPRawCommandInput struct {
+	// PodName specifies the name of the Kubernetes pod running the agent container with gobgp
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Command specifies the gobgp subcommand to run, without the gobgp prefix
+	Command string `json:"command"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// parseCommandPrefixes splits a comma-separated list of command prefixes into their words
+func parseCommandPrefixes(list string) [][]string {
+	var prefixes [][]string
+	for _, prefix := range strings.Split(list, ",") {
+		if words := strings.Fields(prefix); len(words) > 0 {
+			prefixes = append(prefixes, words)
+		}
+	}
+	return prefixes
+}
+
+// commandHasPrefix reports whether the words of command start with one of the prefixes.
+// Prefixes match whole words, so "show" allows "show int" but not "showx".
+func commandHasPrefix(command string, prefixes [][]string) bool {
+	words := strings.Fields(command)
+	for _, prefix := range prefixes {
+		if len(words) < len(prefix) {
+			continue
+		}
+		matched := true
+		for i, word := range prefix {
+			if words[i] != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// formatCommandPrefixes renders prefixes for error messages and tool descriptions
+func formatCommandPrefixes(prefixes [][]string) string {
+	rendered := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		rendered = append(rendered, "'"+strings.Join(prefix, " ")+"'")
+	}
+	return strings.Join(rendered, ", ")
+}
+
+// handleRawVPPCommand runs an arbitrary vppctl command restricted to the allowed prefixes
+func (s *VPPMCPServer) handleRawVPPCommand(ctx context.Context, input VPPRawCommandInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received vpp_exec request for pod: %s, command: %s", input.PodName, input.Command)
+
+	command := strings.Join(strings.Fields(input.Command), " ")
+	if command == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: command is required. Please specify the vppctl command to run, e.g. 'show hardware-interfaces'.",
+				},
+			},
+		}, nil, fmt.Errorf("command is required")
+	}
+
+	if !commandHasPrefix(command, s.rawVPPPrefixes) {
+		err := fmt.Errorf("command '%s' is not allowed: vppctl commands must start with %s", command, formatCommandPrefixes(s.rawVPPPrefixes))
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	return s.handleVPPCommand(ctx, VPPCommandInput{PodName: input.PodName, TimeoutSeconds: input.TimeoutSeconds}, command, "VPP Command Output")
+}
+
+// validateRawGoBGPCommand rejects gobgp commands that are not one of the read-only forms
+// of rawGoBGPShapes. The address family option -a is accepted with every form.
+func validateRawGoBGPCommand(command string) error {
+	words := strings.Fields(command)
+	if len(words) == 0 {
+		return fmt.Errorf("command is required")
+	}
+	shape, ok := rawGoBGPShapes[words[0]]
+	if !ok {
+		verbs := make([]string, 0, len(rawGoBGPShapes))
+		for verb := range rawGoBGPShapes {
+			verbs = append(verbs, verb)
+		}
+		sort.Strings(verbs)
+		return fmt.Errorf("gobgp subcommand '%s' is not allowed: commands must start with one of %s", words[0], strings.Join(verbs, ", "))
+	}
+
+	args := make([]string, 0, len(words)-1)
+	for i := 1; i < len(words); i++ {
+		switch word := words[i]; {
+		case word == "-a" || word == "--address-family":
+			if i+1 == len(words) || !rawGoBGPFamilyRegexp.MatchString(words[i+1]) {
+				return fmt.Errorf("command '%s' is not allowed: %s must be followed by an address family such as ipv4", command, word)
+			}
+			i++
+		case strings.HasPrefix(word, "-"):
+			return fmt.Errorf("command '%s' is not allowed: option '%s' is not supported, only -a <family> is", command, word)
+		default:
+			args = append(args, word)
+		}
+	}
+
+	if !shape.match(args) {
+		return fmt.Errorf("command '%s' is not allowed: bgp_exec only runs the read-only forms %s", command, shape.usage)
+	}
+	return nil
+}
+
+// matchRawGoBGPNeighbor matches [<address> [adj-in|adj-out|local [<prefix> ...]|policy [import|export]]]
+func matchRawGoBGPNeighbor(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	if _, err := netip.ParseAddr(args[0]); err != nil {
+		return false
+	}
+	if len(args) == 1 {
+		return true
+	}
+	switch args[1] {
+	case "adj-in", "adj-out", "local":
+		return matchRawGoBGPRIBQuery(args[2:])
+	case "policy":
+		return matchRawGoBGPPolicyDirection(args[2:])
+	}
+	return false
+}
+
+// matchRawGoBGPGlobal matches [rib [summary|<prefix> ...]|policy [import|export]]
+func matchRawGoBGPGlobal(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	switch args[0] {
+	case "rib":
+		if len(args) == 2 && args[1] == "summary" {
+			return true
+		}
+		return matchRawGoBGPRIBQuery(args[1:])
+	case "policy":
+		return matchRawGoBGPPolicyDirection(args[1:])
+	}
+	return false
+}
+
+// matchRawGoBGPVRF matches [<name> rib [<prefix> ...]|<name> neighbor]
+func matchRawGoBGPVRF(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	if len(args) < 2 || rawGoBGPVRFSubcommands[args[0]] || !rawGoBGPVRFNameRegexp.MatchString(args[0]) {
+		return false
+	}
+	switch args[1] {
+	case "rib":
+		return matchRawGoBGPRIBQuery(args[2:])
+	case "neighbor":
+		return len(args) == 2
+	}
+	return false
+}
+
+// matchRawGoBGPPolicy matches [prefix|neighbor|as-path|community|ext-community|large-community|statement]
+func matchRawGoBGPPolicy(args []string) bool {
+	return len(args) == 0 || (len(args) == 1 && rawGoBGPPolicyTypes[args[0]])
+}
+
+// matchRawGoBGPRPKI matches server|table
+func matchRawGoBGPRPKI(args []string) bool {
+	return len(args) == 1 && (args[0] == "server" || args[0] == "table")
+}
+
+// matchRawGoBGPRIBQuery matches the optional lookup of a RIB: [<prefix> [longer-prefixes|shorter-prefixes] [detail]]
+func matchRawGoBGPRIBQuery(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	if _, err := netip.ParsePrefix(args[0]); err != nil {
+		if _, err := netip.ParseAddr(args[0]); err != nil {
+			return false
+		}
+	}
+	args = args[1:]
+	if len(args) > 0 && (args[0] == "longer-prefixes" || args[0] == "shorter-prefixes") {
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == "detail" {
+		args = args[1:]
+	}
+	return len(args) == 0
+}
+
+// matchRawGoBGPPolicyDirection matches the optional direction of a policy assignment: [import|export]
+func matchRawGoBGPPolicyDirection(args []string) bool {
+	return len(args) == 0 || (len(args) == 1 && (args[0] == "import" || args[0] == "export"))
+}
+
+// handleRawGoBGPCommand runs an arbitrary read-only gobgp subcommand in the agent container
+func (s *VPPMCPServer) handleRawGoBGPCommand(ctx context.Context, input BGPRawCommandInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received bgp_exec request for pod: %s, command: %s", input.PodName, input.Command)
+
+	command := strings.Join(strings.Fields(input.Command), " ")
+	if err := validateRawGoBGPCommand(command); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	return s.HandleGoBGPCommand(ctx, BGPCommandInput{PodName: input.PodName, TimeoutSeconds: input.TimeoutSeconds}, command, "GoBGP Command Output")
+}
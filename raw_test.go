@@ -0,0 +1,82 @@
+package main
+
+import "testing"
+
+func TestCommandHasPrefix(t *testing.T) {
+	prefixes := parseCommandPrefixes("show, clear errors")
+
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"show int", true},
+		{"show", true},
+		{"  show   ip fib ", true},
+		{"showx int", false},
+		{"clear errors", true},
+		{"clear errors verbose", true},
+		{"clear interfaces", false},
+		{"clear", false},
+		{"set int state tap0 down", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := commandHasPrefix(tt.command, prefixes); got != tt.want {
+				t.Errorf("commandHasPrefix(%q) = %t, want %t", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRawGoBGPCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		wantErr bool
+	}{
+		{"neighbor", false},
+		{"neighbor 192.168.0.2", false},
+		{"neighbor 192.168.0.2 adj-in", false},
+		{"neighbor 192.168.0.2 adj-out 10.0.0.0/24 longer-prefixes -a ipv4", false},
+		{"neighbor fd00::2 local -a ipv6", false},
+		{"neighbor 192.168.0.2 policy import", false},
+		{"global", false},
+		{"global rib", false},
+		{"global rib -a 6", false},
+		{"global rib summary", false},
+		{"global rib 10.0.0.0/24 detail", false},
+		{"global policy export", false},
+		{"vrf", false},
+		{"vrf blue rib -a ipv4", false},
+		{"vrf blue neighbor", false},
+		{"policy", false},
+		{"policy prefix", false},
+		{"rpki table -a ipv4", false},
+		{"", true},
+		{"monitor global rib", true},
+		{"bmp", true},
+		{"global as 65000 router-id 10.0.0.1", true},
+		{"global rib add 10.0.0.0/24", true},
+		{"global rib del all", true},
+		{"global policy import add policy1", true},
+		{"neighbor add 192.168.0.2 as 65001", true},
+		{"neighbor 192.168.0.2 softreset", true},
+		{"neighbor 192.168.0.2 shutdown", true},
+		{"neighbor 192.168.0.2 adj-in 10.0.0.0/24 extra", true},
+		{"vrf del blue", true},
+		{"vrf add rib", true},
+		{"policy prefix add ps1 10.0.0.0/24", true},
+		{"rpki server 10.0.0.1 reset", true},
+		{"global rib -u 10.0.0.1", true},
+		{"global rib -a", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if err := validateRawGoBGPCommand(tt.command); (err != nil) != tt.wantErr {
+				t.Errorf("validateRawGoBGPCommand(%q) error = %v, wantErr %t", tt.command, err, tt.wantErr)
+			}
+		})
+	}
+}
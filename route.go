@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// fibTableRegexp matches the table header of 'show ip fib' output, e.g. "ipv4-VRF:0, fib_index:0, flow hash:..."
+	fibTableRegexp = regexp.MustCompile(`^(ipv[46]-VRF:[^,]*), fib_index:(\d+)`)
+	// fibEntryRegexp matches the first line of a FIB entry, e.g. "10.0.0.0/24 fib:0 index:12 locks:2"
+	fibEntryRegexp = regexp.MustCompile(`^(\S+/\d+) fib:\d+`)
+	// fibPathRegexp matches the adjacency lines of the forwarding chain, e.g. "[0] [@5]: ipv4 via 10.0.0.2 tap0: mtu:9000 ..."
+	fibPathRegexp = regexp.MustCompile(`\[@\d+\]: (.*)$`)
+	// fibViaInterfaceRegexp matches the interface of an adjacency, e.g. "ipv4 via 10.0.0.2 tap0: mtu:9000 ..."
+	fibViaInterfaceRegexp = regexp.MustCompile(`\bvia \S+ ([^\s:]+)`)
+)
+
+// RouteLookupInput represents the input for looking up the route of a destination
+type RouteLookupInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// Address specifies the IPv4 or IPv6 destination address to look up
+	Address string `json:"address"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// fibLookup is the longest prefix match of an address in one FIB table
+type fibLookup struct {
+	Table      string
+	FibIndex   string
+	Prefix     string
+	Forwarding []string
+}
+
+// parseFibLookup extracts the matching entry of every table from 'show ip fib <addr>' output
+func parseFibLookup(output string) []*fibLookup {
+	var lookups []*fibLookup
+	var current *fibLookup
+	forwarding := false
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if match := fibTableRegexp.FindStringSubmatch(line); match != nil {
+			current = &fibLookup{Table: match[1], FibIndex: match[2]}
+			lookups = append(lookups, current)
+			forwarding = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if match := fibEntryRegexp.FindStringSubmatch(line); match != nil && current.Prefix == "" {
+			current.Prefix = match[1]
+			continue
+		}
+		if strings.HasPrefix(trimmed, "forwarding:") {
+			forwarding = true
+			continue
+		}
+		// The load-balance line only groups the adjacencies listed below it
+		if match := fibPathRegexp.FindStringSubmatch(trimmed); forwarding && match != nil && !strings.HasPrefix(match[1], "dpo-load-balance") {
+			current.Forwarding = append(current.Forwarding, match[1])
+		}
+	}
+	return lookups
+}
+
+// fibLookupCommand returns the FIB lookup of an address. IPv4-mapped IPv6 addresses are
+// looked up in the IPv4 FIB, where VPP routes them.
+func fibLookupCommand(address netip.Addr) string {
+	address = address.Unmap()
+	if address.Is6() {
+		return fmt.Sprintf("show ip6 fib %s", address)
+	}
+	return fmt.Sprintf("show ip fib %s", address)
+}
+
+// handleRouteLookup reports the table, prefix and adjacency VPP uses for a destination in every FIB table
+func (s *VPPMCPServer) handleRouteLookup(ctx context.Context, input RouteLookupInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received route lookup request for pod: %s, address: %s", input.PodName, input.Address)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	address, err := netip.ParseAddr(input.Address)
+	if err != nil {
+		err = fmt.Errorf("invalid address %q: expected an IPv4 or IPv6 address such as 10.0.0.1 or 2001:db8::1", input.Address)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	command := fibLookupCommand(address)
+
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, command)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error executing VPP command on pod %s: %v\nCommand attempted: vppctl %s", input.PodName, err, command),
+				},
+			},
+		}, nil, nil
+	}
+	output := result["output"].(string)
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("VPP Route Lookup for %s:\n\n", address))
+	lookups := parseFibLookup(output)
+	matched := 0
+	for _, lookup := range lookups {
+		if lookup.Prefix == "" {
+			text.WriteString(fmt.Sprintf("- %s (fib_index %s): no matching route\n", lookup.Table, lookup.FibIndex))
+			continue
+		}
+		matched++
+		text.WriteString(fmt.Sprintf("- %s (fib_index %s): %s\n", lookup.Table, lookup.FibIndex, lookup.Prefix))
+		for _, adjacency := range lookup.Forwarding {
+			text.WriteString(fmt.Sprintf("    -> %s\n", adjacency))
+		}
+	}
+	switch {
+	case len(lookups) == 0:
+		text.WriteString("No FIB table found in the output.\n")
+	case matched == 0:
+		text.WriteString("\nThe destination does not match any route: packets to it are dropped.\n")
+	}
+
+	text.WriteString(fmt.Sprintf("\nFull Output:\n\n%s\n\nCommand executed: vppctl %s\nPod: %s (container: vpp)", output, command, input.PodName))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
+
+// hostRouteInterface returns the interface of the host route of address in 'show ip fib <addr>'
+// output. Calico-vpp routes the IP of a local workload pod to the tap of the pod.
+func hostRouteInterface(output string, address netip.Addr) (string, error) {
+	hostPrefix := netip.PrefixFrom(address, address.BitLen()).String()
+	for _, lookup := range parseFibLookup(output) {
+		if lookup.Prefix != hostPrefix {
+			continue
+		}
+		for _, adjacency := range lookup.Forwarding {
+			if match := fibViaInterfaceRegexp.FindStringSubmatch(adjacency); match != nil {
+				return match[1], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no host route through an interface for %s: the pod does not run on the node of this VPP pod", address)
+}
+
+// lookupPodIPNode returns the node the workload pod with the given IP is scheduled on. Host
+// network pods share the IP of their node and are skipped.
+func lookupPodIPNode(ctx context.Context, k *KubeClient, address netip.Addr) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+
+	workloads, err := k.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "status.podIP=" + address.String()})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the pod with IP %s: %v", address, err)
+	}
+	for _, workload := range workloads.Items {
+		if !workload.Spec.HostNetwork {
+			return workload.Spec.NodeName, nil
+		}
+	}
+	return "", fmt.Errorf("no pod with IP %s found outside the host network", address)
+}
+
+// resolvePodIP returns the calico-vpp pod hosting the workload pod with the given IP and the
+// VPP interface of the workload pod. Without podName, the calico-vpp pod is looked up on the
+// node the workload pod is scheduled on. Dry runs don't resolve the interface.
+func (s *VPPMCPServer) resolvePodIP(ctx context.Context, podName, podIP string) (string, string, error) {
+	address, err := netip.ParseAddr(podIP)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid pod_ip %q: expected an IPv4 or IPv6 address such as 10.0.0.1 or 2001:db8::1", podIP)
+	}
+	address = address.Unmap()
+
+	if podName == "" {
+		if s.bypassesKubernetes() {
+			return "", "", fmt.Errorf("pod_name is required when commands run without Kubernetes")
+		}
+		k8sClient, err := newKubeClient()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to create Kubernetes client: %v", err)
+		}
+		node, err := lookupPodIPNode(ctx, k8sClient, address)
+		if err != nil {
+			return "", "", err
+		}
+		pods, err := listVPPPods(ctx, k8sClient)
+		if err != nil {
+			return "", "", err
+		}
+		for _, pod := range pods {
+			if pod.Node == node {
+				podName = pod.Name
+			}
+		}
+		if podName == "" {
+			return "", "", fmt.Errorf("no calico-vpp pod found on node %s, hosting the pod with IP %s", node, address)
+		}
+	}
+
+	command := fmt.Sprintf("show ip fib %s", address)
+	if address.Is6() {
+		command = fmt.Sprintf("show ip6 fib %s", address)
+	}
+	result, err := s.ExecutePodVPPCommand(ctx, podName, command)
+	if err != nil {
+		return "", "", err
+	}
+	if dryRunFromContext(ctx) != nil {
+		return podName, "", nil
+	}
+	iface, err := hostRouteInterface(result["output"].(string), address)
+	if err != nil {
+		return "", "", err
+	}
+	log.Printf("Resolved pod IP %s to interface %s of pod %s", address, iface, podName)
+	return podName, iface, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestFibLookupCommand(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"10.0.0.1", "show ip fib 10.0.0.1"},
+		{"2001:db8::1", "show ip6 fib 2001:db8::1"},
+		{"::ffff:10.0.0.1", "show ip fib 10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := fibLookupCommand(netip.MustParseAddr(tt.address)); got != tt.want {
+				t.Errorf("fibLookupCommand(%s) = %q, want %q", tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFibLookup(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []*fibLookup
+	}{
+		{
+			name:   "no table",
+			output: "",
+			want:   nil,
+		},
+		{
+			name: "match in the default table and none in a VRF",
+			output: `ipv4-VRF:0, fib_index:0, flow hash:[src dst sport dport proto flowlabel ] epoch:0 flags:none locks:[adjacency:1, default-route:1, ]
+10.0.1.0/26 fib:0 index:24 locks:2
+  API refs:1 src-flags:added,contributing,active,
+    path-list:[31] locks:2 flags:shared, uPRF-list:29 len:1 itfs:[1, ]
+      path:[35] pl-index:31 ip4 weight=1 pref=0 attached-nexthop:  oper-flags:resolved,
+        192.168.0.2 host-eth0
+
+ forwarding:   unicast-ip4-chain
+  [@0]: dpo-load-balance: [proto:ip4 index:26 buckets:1 uRPF:29 to:[0:0]]
+    [0] [@5]: ipv4 via 192.168.0.2 host-eth0: mtu:1500 next:3 flags:[] 0242c0a800020242c0a800010800
+ipv4-VRF:1, fib_index:1, flow hash:[src dst sport dport proto flowlabel ] epoch:0 flags:none locks:[API:1, ]
+`,
+			want: []*fibLookup{
+				{
+					Table:      "ipv4-VRF:0",
+					FibIndex:   "0",
+					Prefix:     "10.0.1.0/26",
+					Forwarding: []string{"ipv4 via 192.168.0.2 host-eth0: mtu:1500 next:3 flags:[] 0242c0a800020242c0a800010800"},
+				},
+				{Table: "ipv4-VRF:1", FibIndex: "1"},
+			},
+		},
+		{
+			name: "ipv6 drop route",
+			output: `ipv6-VRF:0, fib_index:0, flow hash:[src dst sport dport proto flowlabel ] epoch:0 flags:none locks:[default-route:1, ]
+::/0 fib:0 index:6 locks:2
+  default-route refs:1 entry-flags:drop, src-flags:added,contributing,active,
+    path-list:[8] locks:2 flags:drop, uPRF-list:5 len:0 itfs:[]
+      path:[8] pl-index:8 ip6 weight=1 pref=0 special:  cfg-flags:drop,
+        [@0]: dpo-drop ip6
+
+ forwarding:   unicast-ip6-chain
+  [@0]: dpo-load-balance: [proto:ip6 index:6 buckets:1 uRPF:5 to:[0:0]]
+    [0] [@0]: dpo-drop ip6
+`,
+			want: []*fibLookup{
+				{Table: "ipv6-VRF:0", FibIndex: "0", Prefix: "::/0", Forwarding: []string{"dpo-drop ip6"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseFibLookup(tt.output)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFibLookup() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
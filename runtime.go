@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultRuntimeTopNodes = 5
+
+	// Thresholds of the load classification, following the interpretation of 'show run':
+	// a loaded VPP has a Vectors/Call approaching 256 and a low loops/sec around 10000
+	runtimeIdleVectorRate      = 2.0
+	runtimeSaturatedVectorRate = 128.0
+	runtimeSaturatedLoopsRate  = 15000.0
+	runtimeExpensiveClocks     = 1000.0
+)
+
+// runtimeRatesRegexp matches the rates line of a thread in 'show run' output, e.g.
+// "Time 12.3, 10 sec internal node vector rate 1.23e1 loops/sec 123456.7"
+var runtimeRatesRegexp = regexp.MustCompile(`internal node vector rate (\S+) loops/sec (\S+)`)
+
+// VPPAnalyzeRunInput represents the input for the runtime load assessment
+type VPPAnalyzeRunInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Top specifies the number of most expensive nodes reported per thread
+	Top int `json:"top,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// runtimeNode is a graph node line of 'show run' output
+type runtimeNode struct {
+	Name           string
+	Calls          float64
+	Vectors        float64
+	Clocks         float64
+	VectorsPerCall float64
+}
+
+// runtimeThread holds the statistics of one thread of 'show run' output
+type runtimeThread struct {
+	Header     string
+	VectorRate float64
+	LoopsRate  float64
+	Nodes      []runtimeNode
+}
+
+// load classifies the load of the thread as idle, moderate or saturated
+func (t runtimeThread) load() string {
+	switch {
+	case t.VectorRate < runtimeIdleVectorRate:
+		return "idle"
+	case t.VectorRate >= runtimeSaturatedVectorRate || (t.LoopsRate > 0 && t.LoopsRate < runtimeSaturatedLoopsRate):
+		return "saturated"
+	default:
+		return "moderate"
+	}
+}
+
+// parseRuntime splits 'show run' output into threads with their rates and node statistics
+func parseRuntime(output string) []*runtimeThread {
+	var threads []*runtimeThread
+	var current *runtimeThread
+	for _, line := range strings.Split(output, "\n") {
+		if runtimeThreadRegexp.MatchString(line) {
+			current = &runtimeThread{Header: strings.TrimSpace(line)}
+			threads = append(threads, current)
+			continue
+		}
+		match := runtimeRatesRegexp.FindStringSubmatch(line)
+		_, isNode := runtimeNodeVectors(line)
+		if match == nil && !isNode {
+			continue
+		}
+		// Single-threaded VPP prints no thread header
+		if current == nil {
+			current = &runtimeThread{Header: "Thread 0 vpp_main"}
+			threads = append(threads, current)
+		}
+		if match != nil {
+			current.VectorRate, _ = strconv.ParseFloat(match[1], 64)
+			current.LoopsRate, _ = strconv.ParseFloat(match[2], 64)
+		} else {
+			fields := strings.Fields(line)
+			values := make([]float64, 5)
+			for i, field := range fields[len(fields)-5:] {
+				values[i], _ = strconv.ParseFloat(field, 64)
+			}
+			current.Nodes = append(current.Nodes, runtimeNode{
+				Name:           fields[0],
+				Calls:          values[0],
+				Vectors:        values[1],
+				Clocks:         values[3],
+				VectorsPerCall: values[4],
+			})
+		}
+	}
+	return threads
+}
+
+// handleAnalyzeRun parses 'show run' and reports the load of every thread and its most expensive nodes
+func (s *VPPMCPServer) handleAnalyzeRun(ctx context.Context, input VPPAnalyzeRunInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received runtime analysis request for pod: %s", input.PodName)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	top := input.Top
+	if top <= 0 {
+		top = defaultRuntimeTopNodes
+	}
+
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, "show run")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error executing VPP command on pod %s: %v\nCommand attempted: vppctl show run", input.PodName, err),
+				},
+			},
+		}, nil, nil
+	}
+
+	threads := parseRuntime(result["output"].(string))
+
+	var text strings.Builder
+	text.WriteString("VPP Runtime Load Assessment:\n\n")
+	if len(threads) == 0 {
+		text.WriteString("No runtime statistics found in the output.\n")
+	}
+
+	var saturated []string
+	for _, thread := range threads {
+		load := thread.load()
+		if load == "saturated" {
+			saturated = append(saturated, thread.Header)
+		}
+		text.WriteString(fmt.Sprintf("%s: %s (vectors/call %.2f, loops/sec %.0f)\n", thread.Header, load, thread.VectorRate, thread.LoopsRate))
+
+		// The most expensive nodes spend the most cycles in total: clocks per vector times vectors
+		var nodes []runtimeNode
+		for _, node := range thread.Nodes {
+			if node.Vectors > 0 {
+				nodes = append(nodes, node)
+			}
+		}
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].Clocks*nodes[i].Vectors > nodes[j].Clocks*nodes[j].Vectors })
+		if len(nodes) > top {
+			nodes = nodes[:top]
+		}
+		for _, node := range nodes {
+			note := ""
+			if node.Clocks > runtimeExpensiveClocks {
+				note = " - expensive"
+			}
+			text.WriteString(fmt.Sprintf("  - %s: %.3g clocks/vector, %.0f vectors, %.2f vectors/call%s\n",
+				node.Name, node.Clocks, node.Vectors, node.VectorsPerCall, note))
+		}
+		text.WriteString("\n")
+	}
+
+	text.WriteString("Summary:\n")
+	if len(saturated) == 0 {
+		text.WriteString("- No thread is saturated\n")
+	} else {
+		text.WriteString(fmt.Sprintf("- Saturated: %s. Packets are likely dropped as rx-miss on the interfaces polled by these threads; "+
+			"consider more workers or RSS queues, or look at the expensive nodes above\n", strings.Join(saturated, ", ")))
+	}
+	text.WriteString(fmt.Sprintf("- Classification: idle below %.0f vectors/call, saturated from %.0f vectors/call or below %.0f loops/sec; "+
+		"nodes above %.0f clocks/vector are expensive\n", runtimeIdleVectorRate, runtimeSaturatedVectorRate, runtimeSaturatedLoopsRate, runtimeExpensiveClocks))
+	text.WriteString("- Thread rates cover the last 10 seconds; node statistics accumulate since the last `vpp_clear_run`\n")
+
+	text.WriteString(fmt.Sprintf("\nCommand executed: vppctl show run\nPod: %s (container: vpp)", input.PodName))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionProtocols lists the transport protocols accepted by 'show session proto'
+var sessionProtocols = map[string]bool{
+	"tcp":  true,
+	"udp":  true,
+	"tls":  true,
+	"quic": true,
+	"sctp": true,
+	"http": true,
+}
+
+// sessionStateRegexp matches a session state name such as "ready" or "listening"
+var sessionStateRegexp = regexp.MustCompile(`^[a-z][a-z-]*$`)
+
+// sessionEndpointsRegexp matches the local and remote endpoints of a session header line,
+// e.g. 10.0.0.1:80->10.0.0.2:40000 or 2001:db8::1:80->2001:db8::2:40000
+var sessionEndpointsRegexp = regexp.MustCompile(`(\S+):(\d+)->(\S+):(\d+)`)
+
+// VPPSessionInput represents the input for the session listing tool
+type VPPSessionInput struct {
+	VPPCommandInput
+	// Protocol restricts the sessions to a transport protocol (tcp, udp, tls, quic, ...)
+	Protocol string `json:"protocol,omitempty"`
+	// State restricts the sessions to a session state (e.g. ready, listening, closing)
+	State string `json:"state,omitempty"`
+	// IP restricts the sessions to those with this local or remote address
+	IP string `json:"ip,omitempty"`
+	// Port restricts the sessions to those with this local or remote port, on the same
+	// endpoint as IP when both are given
+	Port int `json:"port,omitempty"`
+}
+
+// sessionCommand builds the 'show session' command of a session request. The protocol, state
+// and address filters map to vppctl options; filterSessions applies the endpoint filters again
+// on the output, since vppctl can't filter on a port alone or on an IPv6 endpoint with a port.
+func sessionCommand(input VPPSessionInput) (string, error) {
+	command := "show session"
+	if input.Protocol != "" {
+		protocol := strings.ToLower(input.Protocol)
+		if !sessionProtocols[protocol] {
+			return "", fmt.Errorf("invalid protocol '%s'. Use tcp, udp, tls, quic, sctp or http", input.Protocol)
+		}
+		command += " proto " + protocol
+	}
+	if input.State != "" {
+		if !sessionStateRegexp.MatchString(input.State) {
+			return "", fmt.Errorf("invalid state '%s'", input.State)
+		}
+		command += " state " + input.State
+	}
+	if input.Port < 0 || input.Port > 65535 {
+		return "", fmt.Errorf("invalid port %d: must be between 1 and 65535", input.Port)
+	}
+	if input.IP != "" {
+		address, err := netip.ParseAddr(input.IP)
+		if err != nil || address.Zone() != "" {
+			return "", fmt.Errorf("invalid ip %q: expected an IPv4 or IPv6 address", input.IP)
+		}
+		address = address.Unmap()
+		command += " ep " + address.String()
+		if address.Is4() && input.Port != 0 {
+			command += ":" + strconv.Itoa(input.Port)
+		}
+	}
+	return command + " verbose 2", nil
+}
+
+// filterSessionBlocks keeps the sessions of 'show session verbose' output whose header line
+// satisfies match. A session starts with a "[thread:index]" line followed by its indented details.
+func filterSessionBlocks(output string, match func(header string) bool) []string {
+	var kept, block []string
+	matches := func(block []string) bool {
+		return len(block) > 0 && strings.HasPrefix(block[0], "[") && match(block[0])
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "[") {
+			if matches(block) {
+				kept = append(kept, block...)
+			}
+			block = nil
+		}
+		block = append(block, line)
+	}
+	if matches(block) {
+		kept = append(kept, block...)
+	}
+	return kept
+}
+
+// filterSessions keeps the sessions of 'show session verbose' output with a local or remote
+// endpoint of address ip and port. Addresses are compared parsed, so 10.0.0.1 doesn't match
+// 10.0.0.10 and IPv6 addresses match whatever their notation.
+func filterSessions(output, ip string, port int) string {
+	var want netip.Addr
+	if ip != "" {
+		if address, err := netip.ParseAddr(ip); err == nil {
+			want = address.Unmap()
+		}
+	}
+
+	kept := filterSessionBlocks(output, func(header string) bool {
+		match := sessionEndpointsRegexp.FindStringSubmatch(header)
+		if match == nil {
+			return false
+		}
+		for _, endpoint := range [][2]string{{match[1], match[2]}, {match[3], match[4]}} {
+			if want.IsValid() {
+				address, err := netip.ParseAddr(endpoint[0])
+				if err != nil || address.Unmap() != want {
+					continue
+				}
+			}
+			if port != 0 && endpoint[1] != strconv.Itoa(port) {
+				continue
+			}
+			return true
+		}
+		return false
+	})
+
+	if len(kept) == 0 {
+		return "(no session matched the ip/port filter)"
+	}
+	return strings.Join(kept, "\n")
+}
+
+// handleShowSession lists the sessions, filtered by protocol, state, address and port
+func (s *VPPMCPServer) handleShowSession(ctx context.Context, input VPPSessionInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received session request for pod: %s, protocol: %s, state: %s, ip: %s, port: %d",
+		input.PodName, input.Protocol, input.State, input.IP, input.Port)
+
+	command, err := sessionCommand(input)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	return s.handleVPPCommandWithTransform(ctx, input.VPPCommandInput, command, "VPP Session Information (Verbose)", func(output string) string {
+		if input.IP == "" && input.Port == 0 {
+			return output
+		}
+		return filterSessions(output, input.IP, input.Port)
+	})
+}
+
+// TCPConnectionsInput represents the input for the TCP connection tool
+type TCPConnectionsInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// LocalIP and LocalPort restrict the connections to this local endpoint
+	LocalIP   string `json:"local_ip,omitempty"`
+	LocalPort int    `json:"local_port,omitempty"`
+	// RemoteIP and RemotePort restrict the connections to this remote endpoint
+	RemoteIP   string `json:"remote_ip,omitempty"`
+	RemotePort int    `json:"remote_port,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// connectionEndpoint validates an ip/port pair and renders it as in 'show session' output,
+// e.g. "10.0.0.1:8080". Either part may be empty, in which case it matches any value.
+func connectionEndpoint(name, ip string, port int) (string, string, error) {
+	var addr, portSuffix string
+	if ip != "" {
+		parsed, err := netip.ParseAddr(ip)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid %s_ip %q: expected an IPv4 or IPv6 address", name, ip)
+		}
+		addr = parsed.String()
+	}
+	if port < 0 || port > 65535 {
+		return "", "", fmt.Errorf("invalid %s_port %d: must be between 1 and 65535", name, port)
+	}
+	if port > 0 {
+		portSuffix = ":" + strconv.Itoa(port)
+	}
+	return addr, portSuffix, nil
+}
+
+// endpointRegexp matches an endpoint of a connection header. Empty parts match any value.
+func endpointRegexp(addr, portSuffix string) string {
+	if addr == "" {
+		addr = `\S+`
+	} else {
+		addr = regexp.QuoteMeta(addr)
+	}
+	if portSuffix == "" {
+		portSuffix = `:\d+`
+	}
+	return addr + portSuffix
+}
+
+// handleShowTCPConnections lists the TCP sessions matching a 4-tuple, with the TCP punt
+// configuration and, when the 4-tuple is complete, the scoreboard trace of the connection
+func (s *VPPMCPServer) handleShowTCPConnections(ctx context.Context, input TCPConnectionsInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received TCP connections request for pod: %s, %s:%d -> %s:%d",
+		input.PodName, input.LocalIP, input.LocalPort, input.RemoteIP, input.RemotePort)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	localAddr, localPort, err := connectionEndpoint("local", input.LocalIP, input.LocalPort)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	remoteAddr, remotePort, err := connectionEndpoint("remote", input.RemoteIP, input.RemotePort)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	sessionCommand := "show session proto tcp verbose 2"
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, sessionCommand)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error executing VPP command on pod %s: %v\nCommand attempted: vppctl %s", input.PodName, err, sessionCommand),
+				},
+			},
+		}, nil, nil
+	}
+
+	// Headers look like "[0:1][T] 10.0.0.1:8080->10.0.0.2:34567 ESTABLISHED"
+	tupleRegexp := regexp.MustCompile(endpointRegexp(localAddr, localPort) + "->" + endpointRegexp(remoteAddr, remotePort) + `(\s|$)`)
+	sessions := filterSessionBlocks(result["output"].(string), tupleRegexp.MatchString)
+	commands := []string{"vppctl " + sessionCommand}
+
+	var text strings.Builder
+	text.WriteString("VPP TCP Connections:\n\n")
+	if len(sessions) == 0 {
+		text.WriteString("(no TCP connection matched the 4-tuple)\n")
+	} else {
+		text.WriteString(strings.Join(sessions, "\n") + "\n")
+	}
+
+	type section struct {
+		title   string
+		command string
+	}
+	sections := []section{{"TCP Punt Configuration", "show tcp punt"}}
+	// The scoreboard is per connection and needs the complete 4-tuple
+	if localAddr != "" && localPort != "" && remoteAddr != "" && remotePort != "" {
+		sections = append(sections, section{"TCP Scoreboard Trace",
+			fmt.Sprintf("show tcp scoreboard trace %s %d %s %d", localAddr, input.LocalPort, remoteAddr, input.RemotePort)})
+	}
+	for _, section := range sections {
+		text.WriteString(fmt.Sprintf("\n%s:\n\n", section.title))
+		commands = append(commands, "vppctl "+section.command)
+		result, err := s.ExecutePodVPPCommand(ctx, input.PodName, section.command)
+		if err != nil {
+			text.WriteString(fmt.Sprintf("Error: %v\n", err))
+			continue
+		}
+		text.WriteString(result["output"].(string) + "\n")
+	}
+
+	text.WriteString(fmt.Sprintf("\nCommands executed: %s\nPod: %s (container: vpp)", strings.Join(commands, ", "), input.PodName))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
+
+// UDPInput represents the input for the UDP host stack tool
+type UDPInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Nodes runs the call on the calico-vpp pods of these nodes in parallel
+	Nodes []string `json:"nodes,omitempty"`
+	// Port restricts the sessions to those with this local or remote port
+	Port int `json:"port,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// handleShowUDP reports the UDP punt configuration and the UDP sessions of the host stack
+func (s *VPPMCPServer) handleShowUDP(ctx context.Context, input UDPInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received UDP request for pod: %s, port: %d", input.PodName, input.Port)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	if input.Port < 0 || input.Port > 65535 {
+		err := fmt.Errorf("invalid port %d: must be between 1 and 65535", input.Port)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	var text strings.Builder
+	text.WriteString("VPP UDP Host Stack:\n")
+
+	sections := []struct {
+		title   string
+		command string
+	}{
+		{"UDP Punt Configuration", "show udp punt"},
+		{"UDP Sessions", "show session proto udp verbose"},
+	}
+	var commands []string
+	for _, section := range sections {
+		commands = append(commands, "vppctl "+section.command)
+		result, err := s.ExecutePodVPPCommand(ctx, input.PodName, section.command)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error executing VPP command on pod %s: %v\nCommand attempted: vppctl %s", input.PodName, err, section.command),
+					},
+				},
+			}, nil, nil
+		}
+
+		output := result["output"].(string)
+		if section.command != "show udp punt" && input.Port > 0 {
+			output = filterSessions(output, "", input.Port)
+		}
+		text.WriteString(fmt.Sprintf("\n%s:\n\n%s\n", section.title, output))
+	}
+
+	text.WriteString(fmt.Sprintf("\nCommands executed: %s\nPod: %s (container: vpp)", strings.Join(commands, ", "), input.PodName))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import "testing"
+
+func TestFilterSessions(t *testing.T) {
+	output := `[0:0][T] 0.0.0.0:80->0.0.0.0:0                 LISTEN         0         0
+ index: 0 cfg: No csum offload flags: none
+[0:1][T] 10.0.0.1:80->10.0.0.2:40000            ESTABLISHED    0         0
+ index: 1 cfg: No csum offload flags: none
+[1:0][U] 10.0.0.5:5353->10.0.0.1:53             OPENED         0         0
+[1:1][T] 10.0.0.10:443->110.0.0.1:50000         ESTABLISHED    0         0
+[1:2][T] 2001:db8::1:443->2001:db8::2:50001     ESTABLISHED    0         0`
+
+	tests := []struct {
+		name string
+		ip   string
+		port int
+		want string
+	}{
+		{
+			name: "local or remote address",
+			ip:   "10.0.0.1",
+			want: `[0:1][T] 10.0.0.1:80->10.0.0.2:40000            ESTABLISHED    0         0
+ index: 1 cfg: No csum offload flags: none
+[1:0][U] 10.0.0.5:5353->10.0.0.1:53             OPENED         0         0`,
+		},
+		{
+			name: "port",
+			port: 80,
+			want: `[0:0][T] 0.0.0.0:80->0.0.0.0:0                 LISTEN         0         0
+ index: 0 cfg: No csum offload flags: none
+[0:1][T] 10.0.0.1:80->10.0.0.2:40000            ESTABLISHED    0         0
+ index: 1 cfg: No csum offload flags: none`,
+		},
+		{
+			name: "address and port",
+			ip:   "10.0.0.1",
+			port: 53,
+			want: "[1:0][U] 10.0.0.5:5353->10.0.0.1:53             OPENED         0         0",
+		},
+		{
+			name: "ipv6 address in another notation",
+			ip:   "2001:0db8:0:0::1",
+			want: "[1:2][T] 2001:db8::1:443->2001:db8::2:50001     ESTABLISHED    0         0",
+		},
+		{
+			name: "ipv4-mapped address",
+			ip:   "::ffff:110.0.0.1",
+			want: "[1:1][T] 10.0.0.10:443->110.0.0.1:50000         ESTABLISHED    0         0",
+		},
+		{
+			name: "port on another endpoint than the address",
+			ip:   "10.0.0.2",
+			port: 80,
+			want: "(no session matched the ip/port filter)",
+		},
+		{
+			name: "no match",
+			ip:   "10.0.0.9",
+			want: "(no session matched the ip/port filter)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterSessions(output, tt.ip, tt.port); got != tt.want {
+				t.Errorf("filterSessions() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   VPPSessionInput
+		want    string
+		wantErr bool
+	}{
+		{name: "no filter", want: "show session verbose 2"},
+		{name: "protocol and state", input: VPPSessionInput{Protocol: "TCP", State: "ready"}, want: "show session proto tcp state ready verbose 2"},
+		{name: "ipv4 endpoint", input: VPPSessionInput{IP: "10.0.0.1", Port: 80}, want: "show session ep 10.0.0.1:80 verbose 2"},
+		{name: "ipv4-mapped address", input: VPPSessionInput{IP: "::ffff:10.0.0.1"}, want: "show session ep 10.0.0.1 verbose 2"},
+		{name: "ipv6 endpoint", input: VPPSessionInput{IP: "2001:db8::1", Port: 443}, want: "show session ep 2001:db8::1 verbose 2"},
+		{name: "port alone", input: VPPSessionInput{Port: 53}, want: "show session verbose 2"},
+		{name: "invalid protocol", input: VPPSessionInput{Protocol: "gre"}, wantErr: true},
+		{name: "invalid ip", input: VPPSessionInput{IP: "10.0.0"}, wantErr: true},
+		{name: "invalid port", input: VPPSessionInput{Port: 70000}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sessionCommand(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sessionCommand() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("sessionCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Command sources recorded in a snapshot
+	snapshotSourceVPP   = "vpp"
+	snapshotSourceGoBGP = "gobgp"
+
+	// snapshotParameterDescription documents the snapshot_id parameter of tools that can be answered from a snapshot
+	snapshotParameterDescription = "\n\nOptional parameters:\n" +
+		"- snapshot_id: Return the output recorded in a stored snapshot (see artifact_list) instead of querying the live pod; pod_name may then be omitted\n" +
+		timeoutParameterLine + "\n" +
+		dryRunParameterLine
+)
+
+// snapshotVPPCommands lists the vppctl commands recorded in every snapshot.
+// The strings match the commands run by the corresponding tools.
+var snapshotVPPCommands = []string{
+	"show version",
+	"show int",
+	"show int addr",
+	"show errors",
+	"show run",
+	"show tcp stats",
+	"show session stats",
+	"show ip table",
+	"show ip6 table",
+	"show cnat translation",
+	"show npol interfaces",
+}
+
+// snapshotGoBGPCommands lists the gobgp commands recorded in every snapshot
+var snapshotGoBGPCommands = []string{
+	"neighbor",
+	"global",
+}
+
+// Snapshot holds the output of a fixed set of commands taken from one calico-vpp pod
+type Snapshot struct {
+	ID      string            `json:"-"`
+	Pod     string            `json:"pod"`
+	Node    string            `json:"node"`
+	TakenAt time.Time         `json:"taken_at"`
+	VPP     map[string]string `json:"vpp"`
+	GoBGP   map[string]string `json:"gobgp"`
+}
+
+// SnapshotScheduler periodically records snapshots of every calico-vpp pod into the artifact store
+type SnapshotScheduler struct {
+	server   *VPPMCPServer
+	interval time.Duration
+}
+
+// NewSnapshotScheduler creates a scheduler taking snapshots at the given interval
+func NewSnapshotScheduler(server *VPPMCPServer, interval time.Duration) *SnapshotScheduler {
+	return &SnapshotScheduler{server: server, interval: interval}
+}
+
+// Run takes snapshots of all calico-vpp pods until the context is cancelled
+func (s *SnapshotScheduler) Run(ctx context.Context) {
+	log.Printf("Snapshot scheduler started with interval %s", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.snapshotAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// snapshotAll records one snapshot per calico-vpp pod, or of the local host when commands run locally
+func (s *SnapshotScheduler) snapshotAll(ctx context.Context) {
+	if s.server.bypassesKubernetes() {
+		pod := localVPPPod("")
+		if _, err := saveSnapshot(s.server.artifacts, s.server.takeSnapshot(ctx, pod)); err != nil {
+			log.Printf("Failed to store snapshot of pod %s: %v", pod.Name, err)
+		}
+		return
+	}
+
+	k8sClient, err := newKubeClient()
+	if err != nil {
+		log.Printf("Snapshot skipped: %v", err)
+		return
+	}
+
+	pods, err := listVPPPods(ctx, k8sClient)
+	if err != nil {
+		log.Printf("Snapshot skipped: %v", err)
+		return
+	}
+
+	for _, pod := range pods {
+		snapshot := s.server.takeSnapshot(ctx, pod)
+		if _, err := saveSnapshot(s.server.artifacts, snapshot); err != nil {
+			log.Printf("Failed to store snapshot of pod %s: %v", pod.Name, err)
+		}
+	}
+}
+
+// localVPPPod returns the pod standing for the local host when commands run locally: the host
+// name is recorded as the node, and as the pod unless a pod name is given
+func localVPPPod(podName string) VPPPod {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	if podName == "" {
+		podName = hostname
+	}
+	return VPPPod{Name: podName, Node: hostname}
+}
+
+// takeSnapshot runs the snapshot commands on a pod. Failed commands are left out of the snapshot.
+func (s *VPPMCPServer) takeSnapshot(ctx context.Context, pod VPPPod) *Snapshot {
+	snapshot := &Snapshot{
+		Pod:     pod.Name,
+		Node:    pod.Node,
+		TakenAt: time.Now(),
+		VPP:     make(map[string]string),
+		GoBGP:   make(map[string]string),
+	}
+
+	for _, command := range snapshotVPPCommands {
+		result, err := s.ExecutePodVPPCommand(ctx, pod.Name, command)
+		if err != nil {
+			log.Printf("Snapshot of pod %s: vppctl %s failed: %v", pod.Name, command, err)
+			continue
+		}
+		snapshot.VPP[command] = result["output"].(string)
+	}
+
+	for _, command := range snapshotGoBGPCommands {
+		result, err := s.ExecutePodGoBGPCommand(ctx, pod.Name, command)
+		if err != nil {
+			log.Printf("Snapshot of pod %s: gobgp %s failed: %v", pod.Name, command, err)
+			continue
+		}
+		snapshot.GoBGP[command] = result["output"].(string)
+	}
+
+	return snapshot
+}
+
+// saveSnapshot stores a snapshot as a JSON artifact
+func saveSnapshot(artifacts *ArtifactStore, snapshot *Snapshot) (*Artifact, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+
+	artifact, err := artifacts.Save(artifactKindSnapshot, snapshot.Pod, snapshot.Node+".json", data)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.ID = artifact.ID
+	return artifact, nil
+}
+
+// loadSnapshot reads a stored snapshot by artifact ID
+func loadSnapshot(artifacts *ArtifactStore, id string) (*Snapshot, error) {
+	artifact, ok := artifacts.Get(id)
+	if !ok || artifact.Kind != artifactKindSnapshot {
+		return nil, fmt.Errorf("snapshot %s not found", id)
+	}
+
+	data, err := artifacts.Read(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %v", id, err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %v", id, err)
+	}
+	snapshot.ID = id
+	return &snapshot, nil
+}
+
+// loadSnapshotHistory returns the snapshots of a node (or pod) stored since a point in time,
+// oldest first, keeping the newest limit snapshots when limit is positive. Snapshots are
+// selected on their artifact metadata first, so the ones out of the window aren't read.
+func loadSnapshotHistory(artifacts *ArtifactStore, nodeName, podName string, since time.Time, limit int) []*Snapshot {
+	var history []*Snapshot
+	// List returns the newest artifacts first
+	for _, artifact := range artifacts.List() {
+		if limit > 0 && len(history) == limit {
+			break
+		}
+		if artifact.Kind != artifactKindSnapshot || artifact.CreatedAt.Before(since) {
+			continue
+		}
+		// Artifacts indexed from a previous run have no pod, their snapshot is checked below
+		if (nodeName != "" && artifact.Name != nodeName+".json") || (podName != "" && artifact.Pod != "" && artifact.Pod != podName) {
+			continue
+		}
+		snapshot, err := loadSnapshot(artifacts, artifact.ID)
+		if err != nil {
+			log.Printf("Skipping snapshot: %v", err)
+			continue
+		}
+		if (nodeName != "" && snapshot.Node != nodeName) || (podName != "" && snapshot.Pod != podName) {
+			continue
+		}
+		history = append(history, snapshot)
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].TakenAt.Before(history[j].TakenAt)
+	})
+	return history
+}
+
+// handleSnapshotCommand answers a tool call from the output recorded in a stored snapshot,
+// keeping only the lines matching filter when it is not nil, rendered in the given format
+func (s *VPPMCPServer) handleSnapshotCommand(snapshotID, podName, source, command, commandDescription, format string, transform func(string) string, filter *regexp.Regexp) (*mcp.CallToolResult, any, error) {
+	log.Printf("Reading %s %s from snapshot %s", source, command, snapshotID)
+
+	snapshot, err := loadSnapshot(s.artifacts, snapshotID)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	if podName != "" && podName != snapshot.Pod {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: Snapshot %s was taken from pod %s, not %s", snapshotID, snapshot.Pod, podName),
+				},
+			},
+		}, nil, fmt.Errorf("snapshot pod mismatch")
+	}
+
+	outputs, binary, container := snapshot.VPP, "vppctl", vppContainerName
+	if source == snapshotSourceGoBGP {
+		outputs, binary, container = snapshot.GoBGP, "gobgp", agentContainerName
+	}
+
+	output, ok := outputs[command]
+	if !ok {
+		recorded := make([]string, 0, len(outputs))
+		for recordedCommand := range outputs {
+			recorded = append(recorded, recordedCommand)
+		}
+		sort.Strings(recorded)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: '%s %s' was not recorded in snapshot %s\nRecorded commands: %s",
+						binary, command, snapshotID, strings.Join(recorded, ", ")),
+				},
+			},
+		}, nil, fmt.Errorf("command not recorded in snapshot")
+	}
+
+	if transform != nil {
+		output = transform(output)
+	}
+
+	recorded := commandOutput{
+		Binary:    binary,
+		Command:   command,
+		Node:      snapshot.Node,
+		Pod:       snapshot.Pod,
+		Container: container,
+		Output:    filterOutput(output, filter),
+		Snapshot:  snapshot.ID,
+		TakenAt:   snapshot.TakenAt.Format(time.RFC3339),
+	}
+	if filter != nil {
+		recorded.Filter = filter.String()
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: recorded.render(format, commandDescription),
+			},
+		},
+	}, nil, nil
+}
+
+// handleTakeSnapshot implements recording a snapshot of a pod on demand
+func (s *VPPMCPServer) handleTakeSnapshot(ctx context.Context, input VPPCommandInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received snapshot request for pod: %s", input.PodName)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	// Resolve the node of the pod so the snapshot joins the node history
+	var target VPPPod
+	if s.bypassesKubernetes() {
+		target = localVPPPod(input.PodName)
+	} else {
+		k8sClient, err := newKubeClient()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		pod, err := k8sClient.CoreV1().Pods(vppNamespace).Get(ctx, input.PodName, metav1.GetOptions{})
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error validating pod: %v", err),
+					},
+				},
+			}, nil, err
+		}
+		target = VPPPod{Name: pod.Name, Node: pod.Spec.NodeName, IP: pod.Status.PodIP}
+	}
+
+	snapshot := s.takeSnapshot(ctx, target)
+	if dryRunFromContext(ctx) != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("VPP Snapshot: the state of pod %s would be recorded and stored as an artifact\n", snapshot.Pod),
+				},
+			},
+		}, nil, nil
+	}
+	artifact, err := saveSnapshot(s.artifacts, snapshot)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error storing snapshot: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Snapshot recorded:\n\n- Snapshot ID: %s\n- Node: %s\n- Pod: %s\n- Size: %s\n- VPP commands recorded: %d/%d\n- gobgp commands recorded: %d/%d\n\n"+
+					"Pass snapshot_id=%s to the show tools to re-query this state later.",
+					artifact.ID, snapshot.Node, snapshot.Pod, formatBytes(artifact.Size),
+					len(snapshot.VPP), len(snapshotVPPCommands), len(snapshot.GoBGP), len(snapshotGoBGPCommands), artifact.ID),
+			},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultTraceStreamDuration = 60 * time.Second
+	maxTraceStreamDuration     = 10 * time.Minute
+	defaultTraceStreamPoll     = 5 * time.Second
+	defaultTraceStreamCount    = 100
+	// traceStreamMaxLines bounds the packets described in the message of one notification
+	traceStreamMaxLines = 20
+)
+
+// VPPTraceStreamInput represents the input for a long trace streamed while it runs
+type VPPTraceStreamInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Interface specifies the interface type whose input node is traced
+	Interface string `json:"interface,omitempty"`
+	// Count specifies the number of packets traced between two polls
+	Count int `json:"count,omitempty"`
+	// DurationSeconds specifies how long the trace runs
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// PollSeconds specifies the interval between two polls of the trace
+	PollSeconds int `json:"poll_seconds,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// traceStreamNotifier sends the packets of each poll to the client of a tool call: as progress
+// notifications when the client asked for progress, and as log messages when it set a log level
+type traceStreamNotifier struct {
+	session       *mcp.ServerSession
+	progressToken any
+}
+
+// notify sends the packets of a poll. Failing notifications don't stop the trace.
+func (n traceStreamNotifier) notify(ctx context.Context, poll, total int, records []traceRecord) {
+	if n.session == nil {
+		return
+	}
+	message := formatTraceStreamPoll(poll, records)
+	if n.progressToken != nil {
+		if err := n.session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: n.progressToken,
+			Message:       message,
+			Progress:      float64(total),
+		}); err != nil {
+			log.Printf("Failed to send trace progress notification: %v", err)
+		}
+	}
+	if err := n.session.Log(ctx, &mcp.LoggingMessageParams{
+		Level:  "info",
+		Logger: "vpp_trace_stream",
+		Data:   map[string]any{"poll": poll, "message": message, "packets": records},
+	}); err != nil {
+		log.Printf("Failed to send trace log notification: %v", err)
+	}
+}
+
+// formatTraceStreamPoll describes the packets traced during one poll, one line per packet
+func formatTraceStreamPoll(poll int, records []traceRecord) string {
+	dropped := 0
+	for _, record := range records {
+		if record.Dropped {
+			dropped++
+		}
+	}
+	lines := []string{fmt.Sprintf("Poll %d: %d packets, %d dropped", poll, len(records), dropped)}
+	for i, record := range records {
+		if i == traceStreamMaxLines {
+			lines = append(lines, fmt.Sprintf("... and %d more packets", len(records)-i))
+			break
+		}
+		line := fmt.Sprintf("- thread %d packet %d: %s", record.Thread, record.Packet, strings.Join(record.Path, " -> "))
+		if record.Dropped {
+			line += " [dropped: " + record.DropReason + "]"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleTraceStream runs a long trace, polling it every few seconds and streaming the packets of
+// each poll to the client as notifications. The trace is cleared and re-armed after every poll,
+// so each packet is reported once; the result summarizes every packet traced.
+func (s *VPPMCPServer) handleTraceStream(ctx context.Context, notifier traceStreamNotifier, input VPPTraceStreamInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received trace stream request for pod: %s, duration: %ds", input.PodName, input.DurationSeconds)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	count := input.Count
+	if count == 0 {
+		count = defaultTraceStreamCount
+	}
+	duration := defaultTraceStreamDuration
+	if input.DurationSeconds > 0 {
+		duration = time.Duration(input.DurationSeconds) * time.Second
+	}
+	poll := defaultTraceStreamPoll
+	if input.PollSeconds > 0 {
+		poll = time.Duration(input.PollSeconds) * time.Second
+	}
+	var err error
+	switch {
+	case count < 0:
+		err = fmt.Errorf("invalid count %d: must not be negative", count)
+	case input.DurationSeconds < 0 || duration > maxTraceStreamDuration:
+		err = fmt.Errorf("invalid duration_seconds %d: must be between 1 and %d", input.DurationSeconds, int(maxTraceStreamDuration.Seconds()))
+	case input.PollSeconds < 0 || poll > duration:
+		err = fmt.Errorf("invalid poll_seconds %d: must be between 1 and duration_seconds", input.PollSeconds)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	var k8sClient *KubeClient
+	if !s.bypassesKubernetes() {
+		k8sClient, err = newKubeClient()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	}
+	vppInputNode, _, err := mapInterfaceTypeToVppInputNode(k8sClient, input.Interface)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error mapping interface: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	unlock, err := s.lockCapture(ctx, input.PodName)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	defer unlock()
+
+	// Step 1: Start the trace, and clear it once done
+	traceCmd := fmt.Sprintf("trace add %s %d", vppInputNode, count)
+	_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, "clear trace")
+	if _, err := s.ExecutePodVPPCommand(ctx, input.PodName, traceCmd); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error starting trace: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	defer func() {
+		_, _ = s.ExecutePodVPPCommand(context.WithoutCancel(ctx), input.PodName, "clear trace")
+	}()
+
+	// Step 2: Poll the trace, stream its packets and re-arm it until the duration elapses
+	var all []traceRecord
+	polls, full := 0, 0
+	start := time.Now()
+	deadline := start.Add(duration)
+	for {
+		if dryRunFromContext(ctx) == nil {
+			wait := poll
+			if remaining := time.Until(deadline); remaining < wait {
+				wait = remaining
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.TextContent{
+							Text: fmt.Sprintf("Error: trace stream interrupted after %d polls: %v", polls, ctx.Err()),
+						},
+					},
+				}, nil, ctx.Err()
+			}
+		}
+
+		result, err := s.ExecutePodVPPCommand(ctx, input.PodName, fmt.Sprintf("show trace max %d", count))
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error retrieving trace after %d polls: %v", polls, err),
+					},
+				},
+			}, nil, err
+		}
+		_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, "clear trace")
+		_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, traceCmd)
+
+		polls++
+		records := parseTraceRecords(result["output"].(string))
+		if len(records) >= count {
+			full++
+		}
+		all = append(all, records...)
+		notifier.notify(ctx, polls, len(all), records)
+
+		if dryRunFromContext(ctx) != nil || !time.Now().Before(deadline) {
+			break
+		}
+	}
+
+	summary := summarizeTrace(all, defaultTraceAnalyzeExamples)
+	text := fmt.Sprintf("VPP Trace Stream Summary:\n\n%s\nCapture Parameters:\n- VPP Input Node: %s\n- Count: %d per poll\n- Polls: %d every %s\n- Capture Duration: %s\n- Pod: %s\n",
+		formatTraceSummary(summary), vppInputNode, count, polls, poll, time.Since(start).Round(time.Second), input.PodName)
+	if full > 0 {
+		text += fmt.Sprintf("\n**Note**: The trace buffer filled up during %d polls, so packets were missed. Raise count or lower poll_seconds.\n", full)
+	}
+	if notifier.session != nil && notifier.progressToken == nil {
+		text += "\n**Note**: The packets of each poll are sent as log notifications once the client sets a log level, and as progress notifications when the call carries a progress token.\n"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text,
+			},
+		},
+		StructuredContent: map[string]any{
+			"pod":        input.PodName,
+			"input_node": vppInputNode,
+			"polls":      polls,
+			"summary":    summary,
+		},
+	}, nil, nil
+}
@@ -19,7 +19,7 @@ NC='\033[0m' # No Color
 # Check if the server binary exists
 if [ ! -f "./vpp-mcp-server" ]; then
     echo -e "${RED}Error: vpp-mcp-server binary not found${NC}"
-    echo "Please run: go build -o vpp-mcp-server main.go"
+    echo "Please run: go build -o vpp-mcp-server ."
     exit 1
 fi
 
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Tool groups used by the allowlist and denylist
+const (
+	toolGroupShow       = "show"
+	toolGroupCapture    = "capture"
+	toolGroupClear      = "clear"
+	toolGroupBGP        = "bgp"
+	toolGroupKubernetes = "kubernetes"
+	toolGroupArtifacts  = "artifacts"
+	toolGroupAnalysis   = "analysis"
+	toolGroupRaw        = "raw"
+	toolGroupWrite      = "write"
+)
+
+// toolGroups maps every tool to its group
+var toolGroups = map[string]string{
+	"vpp_show_version":              toolGroupShow,
+	"vpp_show_int":                  toolGroupShow,
+	"vpp_show_int_addr":             toolGroupShow,
+	"vpp_show_errors":               toolGroupShow,
+	"vpp_show_session_verbose":      toolGroupShow,
+	"vpp_show_tcp_connections":      toolGroupShow,
+	"vpp_show_udp":                  toolGroupShow,
+	"vpp_show_npol_rules":           toolGroupShow,
+	"vpp_show_npol_policies":        toolGroupShow,
+	"vpp_show_npol_ipset":           toolGroupShow,
+	"vpp_show_npol_interfaces":      toolGroupShow,
+	"vpp_tcp_stats":                 toolGroupShow,
+	"vpp_session_stats":             toolGroupShow,
+	"vpp_get_logs":                  toolGroupShow,
+	"vpp_get_agent_logs":            toolGroupShow,
+	"vpp_get_container_logs":        toolGroupShow,
+	"vpp_get_crash_logs":            toolGroupShow,
+	"vpp_describe_pod":              toolGroupShow,
+	"vpp_node_conditions":           toolGroupShow,
+	"vpp_show_cnat_translation":     toolGroupShow,
+	"vpp_show_cnat_session":         toolGroupShow,
+	"vpp_show_run":                  toolGroupShow,
+	"vpp_show_ip_table":             toolGroupShow,
+	"vpp_show_ip6_table":            toolGroupShow,
+	"vpp_show_ip_fib":               toolGroupShow,
+	"vpp_show_ip6_fib":              toolGroupShow,
+	"vpp_show_ip_fib_prefix":        toolGroupShow,
+	"vpp_show_ip6_fib_prefix":       toolGroupShow,
+	"vpp_ip_route_lookup":           toolGroupShow,
+	"vpp_memory_trace_report":       toolGroupShow,
+	"vpp_perfmon_show":              toolGroupShow,
+	"vpp_show_ip_neighbors":         toolGroupShow,
+	"vpp_show_ip6_neighbors":        toolGroupShow,
+	"vpp_show_wireguard":            toolGroupShow,
+	"vpp_show_gre":                  toolGroupShow,
+	"vpp_show_sr_policies":          toolGroupShow,
+	"vpp_show_sr_localsids":         toolGroupShow,
+	"vpp_show_sr_steering_policies": toolGroupShow,
+	"vpp_show_interface_span":       toolGroupShow,
+	"vpp_show_virtio":               toolGroupShow,
+	"vpp_show_graph":                toolGroupShow,
+	"vpp_show_api_clients":          toolGroupShow,
+	"vpp_show_api_ring_stats":       toolGroupShow,
+	"vpp_show_lldp":                 toolGroupShow,
+	"vpp_show_bond":                 toolGroupShow,
+	"vpp_show_lacp":                 toolGroupShow,
+	"vpp_show_lcp":                  toolGroupShow,
+	"vpp_show_urpf":                 toolGroupShow,
+	"vpp_trace":                     toolGroupCapture,
+	"vpp_trace_analyze":             toolGroupCapture,
+	"vpp_trace_stream":              toolGroupCapture,
+	"vpp_pcap":                      toolGroupCapture,
+	"vpp_trace_pcap":                toolGroupCapture,
+	"vpp_cluster_capture":           toolGroupCapture,
+	"vpp_dispatch":                  toolGroupCapture,
+	"vpp_dispatch_analyze":          toolGroupCapture,
+	"vpp_capture_status":            toolGroupCapture,
+	"vpp_capture_cleanup":           toolGroupCapture,
+	"vpp_packet_journey":            toolGroupCapture,
+	"vpp_qos_preservation":          toolGroupCapture,
+	"vpp_memory_trace_start":        toolGroupCapture,
+	"vpp_memory_trace_stop":         toolGroupCapture,
+	"vpp_perfmon_start":             toolGroupCapture,
+	"vpp_perfmon_stop":              toolGroupCapture,
+	"vpp_api_trace":                 toolGroupCapture,
+	"capture_start":                 toolGroupCapture,
+	"capture_status":                toolGroupCapture,
+	"capture_stop":                  toolGroupCapture,
+	"capture_result":                toolGroupCapture,
+	"vpp_clear_errors":              toolGroupClear,
+	"vpp_clear_run":                 toolGroupClear,
+	"bgp_show_neighbors":            toolGroupBGP,
+	"bgp_show_global_info":          toolGroupBGP,
+	"bgp_show_global_rib4":          toolGroupBGP,
+	"bgp_show_global_rib6":          toolGroupBGP,
+	"bgp_show_rib_summary":          toolGroupBGP,
+	"bgp_show_ip":                   toolGroupBGP,
+	"bgp_show_prefix":               toolGroupBGP,
+	"bgp_show_neighbor":             toolGroupBGP,
+	"bgp_show_adj_rib_in":           toolGroupBGP,
+	"bgp_show_adj_rib_out":          toolGroupBGP,
+	"bgp_monitor":                   toolGroupBGP,
+	"bgp_mrt_dump":                  toolGroupBGP,
+	"bgp_peer_flaps":                toolGroupBGP,
+	"bgp_route_counts":              toolGroupBGP,
+	"bgp_graceful_restart":          toolGroupBGP,
+	"bgp_rib_divergence":            toolGroupBGP,
+	"bgp_peer_reconcile":            toolGroupBGP,
+	"bgp_pod_route":                 toolGroupBGP,
+	"bgp_block_affinities":          toolGroupBGP,
+	"vpp_get_pods":                  toolGroupKubernetes,
+	"vpp_get_felix_configuration":   toolGroupKubernetes,
+	"vpp_get_bgp_configuration":     toolGroupKubernetes,
+	"artifact_list":                 toolGroupArtifacts,
+	"vpp_snapshot":                  toolGroupArtifacts,
+	"vpp_interface_anomalies":       toolGroupAnalysis,
+	"vpp_latency_probe":             toolGroupAnalysis,
+	"vpp_interface_rates":           toolGroupAnalysis,
+	"vpp_analyze_run":               toolGroupAnalysis,
+	"pcap_decode":                   toolGroupAnalysis,
+	"pcap_analyze":                  toolGroupAnalysis,
+	"verify_service":                toolGroupAnalysis,
+	"verify_network_policy":         toolGroupAnalysis,
+	"vpp_exec":                      toolGroupRaw,
+	"bgp_exec":                      toolGroupRaw,
+	"vpp_set_interface_span":        toolGroupWrite,
+	"vpp_set_interface_mtu":         toolGroupWrite,
+	"vpp_set_interface_state":       toolGroupWrite,
+	"vpp_set_logging_level":         toolGroupWrite,
+	"vpp_clear_cnat_sessions":       toolGroupWrite,
+	"vpp_clear_sessions":            toolGroupWrite,
+	"vpp_clear_interfaces":          toolGroupWrite,
+	"vpp_pg_inject":                 toolGroupWrite,
+	"bgp_disable_neighbor":          toolGroupWrite,
+	"bgp_enable_neighbor":           toolGroupWrite,
+}
+
+// parseToolSelection splits a comma-separated list of tool and group names
+func parseToolSelection(list string) (map[string]bool, error) {
+	groups := make(map[string]bool)
+	for _, group := range toolGroups {
+		groups[group] = true
+	}
+
+	selection := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, isTool := toolGroups[name]; !isTool && !groups[name] {
+			return nil, fmt.Errorf("unknown tool or tool group: %s", name)
+		}
+		selection[name] = true
+	}
+	return selection, nil
+}
+
+// applyToolPolicy removes the tools excluded by the allowlist and denylist from the server,
+// so they are neither listed nor callable. An empty allowlist allows every tool; the
+// denylist wins over the allowlist. Both accept tool and group names.
+func applyToolPolicy(server *mcp.Server, allowList, denyList string) error {
+	allow, err := parseToolSelection(allowList)
+	if err != nil {
+		return fmt.Errorf("invalid tool allowlist: %v", err)
+	}
+	deny, err := parseToolSelection(denyList)
+	if err != nil {
+		return fmt.Errorf("invalid tool denylist: %v", err)
+	}
+
+	var disabled []string
+	for tool, group := range toolGroups {
+		allowed := len(allow) == 0 || allow[tool] || allow[group]
+		if !allowed || deny[tool] || deny[group] {
+			disabled = append(disabled, tool)
+		}
+	}
+	if len(disabled) == 0 {
+		return nil
+	}
+
+	sort.Strings(disabled)
+	server.RemoveTools(disabled...)
+	log.Printf("Disabled tools by policy: %s", strings.Join(disabled, ", "))
+	return nil
+}
@@ -0,0 +1,534 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var (
+	// traceThreadRegexp matches the thread banner of 'show trace' output, e.g.
+	// "------------------- Start of thread 1 vpp_wk_0 -------------------"
+	traceThreadRegexp = regexp.MustCompile(`Start of thread (\d+) (\S+)`)
+	// rxPlacementThreadRegexp matches a thread of 'show interface rx-placement' output, e.g. "Thread 1 (vpp_wk_0):"
+	rxPlacementThreadRegexp = regexp.MustCompile(`^Thread (\d+) `)
+	// rxPlacementQueueRegexp matches a queue of 'show interface rx-placement' output, e.g. "tap0 queue 0 (polling)"
+	rxPlacementQueueRegexp = regexp.MustCompile(`^(\S+) queue (\d+) `)
+	// traceEntryRegexp matches a graph node line of 'show trace' output with its timestamp
+	traceEntryRegexp = regexp.MustCompile(`^(\d{2}:\d{2}:\d{2}:\d+): (\S+)$`)
+)
+
+// traceNodeEntry is a graph node visited by a traced packet
+type traceNodeEntry struct {
+	Node string `json:"node"`
+	// Timestamp is the time the node processed the packet, as printed by VPP (hh:mm:ss:usec since start)
+	Timestamp string `json:"timestamp"`
+	// Details holds the lines printed by the node for the packet
+	Details []string `json:"details,omitempty"`
+}
+
+// traceRecord is a packet of 'show trace' output
+type traceRecord struct {
+	Packet     int              `json:"packet"`
+	Thread     int              `json:"thread"`
+	ThreadName string           `json:"thread_name,omitempty"`
+	InputNode  string           `json:"input_node,omitempty"`
+	Path       []string         `json:"path"`
+	Nodes      []traceNodeEntry `json:"nodes"`
+	FinalNode  string           `json:"final_node,omitempty"`
+	Dropped    bool             `json:"dropped"`
+	DropReason string           `json:"drop_reason,omitempty"`
+}
+
+// filterTraceThread keeps the packets of one thread in 'show trace' output
+func filterTraceThread(output string, thread int) string {
+	var kept []string
+	keep := false
+	for _, line := range strings.Split(output, "\n") {
+		if match := traceThreadRegexp.FindStringSubmatch(line); match != nil {
+			keep = match[1] == strconv.Itoa(thread)
+		}
+		if keep {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == 0 {
+		return fmt.Sprintf("(no trace output for thread %d)", thread)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// rxQueueThread returns the thread polling a queue of an interface from 'show interface rx-placement' output
+func rxQueueThread(output, iface string, queue int) (int, error) {
+	thread := -1
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if match := rxPlacementThreadRegexp.FindStringSubmatch(line); match != nil {
+			thread, _ = strconv.Atoi(match[1])
+			continue
+		}
+		if match := rxPlacementQueueRegexp.FindStringSubmatch(line); match != nil && thread >= 0 &&
+			match[1] == iface && match[2] == strconv.Itoa(queue) {
+			return thread, nil
+		}
+	}
+	return 0, fmt.Errorf("no thread polls %s queue %d, check the interface with vpp_show_int", iface, queue)
+}
+
+// isDropNode reports whether packets reaching the node are dropped
+func isDropNode(node string) bool {
+	return node == "error-drop" || node == "drop" || strings.HasSuffix(node, "-drop")
+}
+
+// dropReason returns the reason a dropped packet was dropped. The drop node prints the
+// counter that was incremented, e.g. "ip4-input: ip4 ttl <= 1"; error-drop only prints
+// the rx interface, so the last line naming a node is preferred.
+func (r *traceRecord) dropReason() string {
+	for i := len(r.Nodes) - 1; i >= 0 && isDropNode(r.Nodes[i].Node); i-- {
+		for _, detail := range r.Nodes[i].Details {
+			if strings.Contains(detail, ": ") {
+				return detail
+			}
+		}
+	}
+	last := r.Nodes[len(r.Nodes)-1]
+	if len(last.Details) > 0 {
+		return last.Details[0]
+	}
+	return ""
+}
+
+// parseTraceRecords converts 'show trace' output into one record per packet, with the
+// node path it took and, for dropped packets, the drop reason
+func parseTraceRecords(output string) []traceRecord {
+	var records []traceRecord
+	thread, threadName := 0, ""
+	// inPacket is false between a thread banner and its first packet
+	inPacket := false
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if match := traceThreadRegexp.FindStringSubmatch(trimmed); match != nil {
+			thread, _ = strconv.Atoi(match[1])
+			threadName = match[2]
+			inPacket = false
+			continue
+		}
+		if match := tracePacketRegexp.FindStringSubmatch(trimmed); match != nil {
+			packet, _ := strconv.Atoi(match[1])
+			records = append(records, traceRecord{Packet: packet, Thread: thread, ThreadName: threadName, Path: []string{}, Nodes: []traceNodeEntry{}})
+			inPacket = true
+			continue
+		}
+		if !inPacket || trimmed == "" {
+			continue
+		}
+		current := &records[len(records)-1]
+		if match := traceEntryRegexp.FindStringSubmatch(trimmed); match != nil {
+			current.Nodes = append(current.Nodes, traceNodeEntry{Node: match[2], Timestamp: match[1]})
+			current.Path = append(current.Path, match[2])
+			continue
+		}
+		if len(current.Nodes) > 0 {
+			node := &current.Nodes[len(current.Nodes)-1]
+			node.Details = append(node.Details, trimmed)
+		}
+	}
+
+	for i := range records {
+		record := &records[i]
+		if len(record.Nodes) == 0 {
+			continue
+		}
+		record.InputNode = record.Path[0]
+		record.FinalNode = record.Path[len(record.Path)-1]
+		if isDropNode(record.FinalNode) {
+			record.Dropped = true
+			record.DropReason = record.dropReason()
+		}
+	}
+	return records
+}
+
+const (
+	defaultTraceAnalyzeExamples = 2
+
+	// traceInlineMaxBytes is the largest 'show trace' output vpp_trace returns inline. Larger
+	// traces are stored as an artifact and summarized.
+	traceInlineMaxBytes = 32 * 1024
+)
+
+// VPPTraceAnalyzeInput represents the input for the trace summary tool
+type VPPTraceAnalyzeInput struct {
+	VPPTraceInput
+	// Examples specifies the number of example packets reported per drop reason
+	Examples int `json:"examples,omitempty"`
+}
+
+// traceDropSummary aggregates the packets dropped for the same reason
+type traceDropSummary struct {
+	Reason   string        `json:"reason"`
+	Count    int           `json:"count"`
+	Examples []traceRecord `json:"examples"`
+}
+
+// traceSummary aggregates the packets of a trace
+type traceSummary struct {
+	Packets    int                `json:"packets"`
+	Dropped    int                `json:"dropped"`
+	FinalNodes map[string]int     `json:"final_nodes"`
+	Drops      []traceDropSummary `json:"drops"`
+}
+
+// summarizeTrace counts the packets per final node and per drop reason, keeping up to
+// examples packets per reason. Drop reasons are sorted by count, most frequent first.
+func summarizeTrace(records []traceRecord, examples int) traceSummary {
+	summary := traceSummary{Packets: len(records), FinalNodes: make(map[string]int), Drops: []traceDropSummary{}}
+	drops := make(map[string]int)
+	for _, record := range records {
+		final := record.FinalNode
+		if final == "" {
+			final = "(no node)"
+		}
+		summary.FinalNodes[final]++
+		if !record.Dropped {
+			continue
+		}
+		summary.Dropped++
+		reason := record.DropReason
+		if reason == "" {
+			reason = "(unknown)"
+		}
+		i, ok := drops[reason]
+		if !ok {
+			i = len(summary.Drops)
+			drops[reason] = i
+			summary.Drops = append(summary.Drops, traceDropSummary{Reason: reason, Examples: []traceRecord{}})
+		}
+		summary.Drops[i].Count++
+		if len(summary.Drops[i].Examples) < examples {
+			summary.Drops[i].Examples = append(summary.Drops[i].Examples, record)
+		}
+	}
+	sort.SliceStable(summary.Drops, func(i, j int) bool { return summary.Drops[i].Count > summary.Drops[j].Count })
+	return summary
+}
+
+// formatTraceSummary renders a trace summary as text. Example packets show their path and
+// the lines printed by the node that sent them to the drop nodes.
+func formatTraceSummary(summary traceSummary) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("- Packets Traced: %d\n- Packets Dropped: %d\n", summary.Packets, summary.Dropped))
+
+	text.WriteString("\nPackets per Final Node:\n")
+	nodes := make([]string, 0, len(summary.FinalNodes))
+	for node := range summary.FinalNodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if summary.FinalNodes[nodes[i]] != summary.FinalNodes[nodes[j]] {
+			return summary.FinalNodes[nodes[i]] > summary.FinalNodes[nodes[j]]
+		}
+		return nodes[i] < nodes[j]
+	})
+	for _, node := range nodes {
+		text.WriteString(fmt.Sprintf("- %s: %d\n", node, summary.FinalNodes[node]))
+	}
+
+	text.WriteString("\nDrop Reasons:\n")
+	if len(summary.Drops) == 0 {
+		text.WriteString("- None\n")
+	}
+	for _, drop := range summary.Drops {
+		text.WriteString(fmt.Sprintf("- %s: %d packets\n", drop.Reason, drop.Count))
+		for _, example := range drop.Examples {
+			text.WriteString(fmt.Sprintf("  Example: packet %d (thread %d): %s\n", example.Packet, example.Thread, strings.Join(example.Path, " -> ")))
+			last := len(example.Nodes) - 1
+			for last >= 0 && isDropNode(example.Nodes[last].Node) {
+				last--
+			}
+			if last >= 0 {
+				for _, detail := range example.Nodes[last].Details {
+					text.WriteString(fmt.Sprintf("    %s: %s\n", example.Nodes[last].Node, detail))
+				}
+			}
+		}
+	}
+	return text.String()
+}
+
+// handleTraceAnalyze runs a trace like vpp_trace and returns a summary of the traced packets
+// instead of the raw traces
+func (s *VPPMCPServer) handleTraceAnalyze(ctx context.Context, input VPPTraceAnalyzeInput) (*mcp.CallToolResult, any, error) {
+	examples := input.Examples
+	if examples <= 0 {
+		examples = defaultTraceAnalyzeExamples
+	}
+
+	result, _, err := s.handleTraceCapture(ctx, input.VPPTraceInput)
+	structured, ok := result.StructuredContent.(map[string]any)
+	if err != nil || !ok {
+		return result, nil, err
+	}
+	records, _ := structured["packets"].([]traceRecord)
+	if id, ok := structured["artifact_id"].(string); ok {
+		data, err := s.artifacts.Read(id)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error reading the trace stored as artifact %s: %v", id, err),
+					},
+				},
+			}, nil, err
+		}
+		records = parseTraceRecords(string(data))
+	}
+	summary := summarizeTrace(records, examples)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("VPP Trace Analysis:\n\n%s\nCapture Parameters:\n- VPP Input Node: %s\n- Pod: %s%s\n",
+					formatTraceSummary(summary), structured["input_node"], input.PodName, traceFilterLine(input.VPPTraceInput)),
+			},
+		},
+		StructuredContent: summary,
+	}, nil, nil
+}
+
+// traceArtifactResult stores a large 'show trace' output as an artifact and returns a result
+// carrying a summary of the trace and a link to the artifact. It reports false when the trace
+// could not be stored, so the caller returns it inline.
+func (s *VPPMCPServer) traceArtifactResult(pod, inputNode, output, parameters string) (*mcp.CallToolResult, bool) {
+	artifact, err := s.artifacts.Save(artifactKindTrace, pod, "show-trace.txt", []byte(output))
+	if err != nil {
+		log.Printf("Failed to store the trace of pod %s: %v", pod, err)
+		return nil, false
+	}
+
+	uri := artifactURI(artifact.ID)
+	size := artifact.Size
+	summary := summarizeTrace(parseTraceRecords(output), defaultTraceAnalyzeExamples)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("VPP Trace Capture Summary:\n\n%s\n%s\n\n**Important**: The trace (%s) is too large to return inline. It is stored as artifact %s, available as resource %s\n",
+					formatTraceSummary(summary), parameters, formatBytes(size), artifact.ID, uri),
+			},
+			&mcp.ResourceLink{
+				URI:         uri,
+				Name:        artifact.Name,
+				Description: fmt.Sprintf("Full 'show trace' output of pod %s", pod),
+				MIMEType:    "text/plain",
+				Size:        &size,
+			},
+		},
+		StructuredContent: map[string]any{
+			"pod":         pod,
+			"input_node":  inputNode,
+			"artifact_id": artifact.ID,
+			"uri":         uri,
+			"summary":     summary,
+		},
+	}, true
+}
+
+// tracePathStat counts the packets that took the same graph node sequence
+type tracePathStat struct {
+	Path    []string `json:"path"`
+	Packets int      `json:"packets"`
+	// Unexpected names why the path deserves attention: punt, drop or icmp-error
+	Unexpected string `json:"unexpected,omitempty"`
+}
+
+// unexpectedPathReason returns why packets taking the path left the regular forwarding
+// path, or an empty string for regular paths
+func unexpectedPathReason(path []string) string {
+	for _, node := range path {
+		switch {
+		case strings.Contains(node, "punt"):
+			return "punt"
+		case strings.Contains(node, "icmp-error"):
+			return "icmp-error"
+		}
+	}
+	if len(path) > 0 && isDropNode(path[len(path)-1]) {
+		return "drop"
+	}
+	return ""
+}
+
+// tracePathStats groups the traced packets by node sequence, most frequent first
+func tracePathStats(records []traceRecord) []tracePathStat {
+	index := make(map[string]int)
+	stats := []tracePathStat{}
+	for _, record := range records {
+		key := strings.Join(record.Path, " ")
+		i, ok := index[key]
+		if !ok {
+			i = len(stats)
+			index[key] = i
+			stats = append(stats, tracePathStat{Path: record.Path, Unexpected: unexpectedPathReason(record.Path)})
+		}
+		stats[i].Packets++
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Packets > stats[j].Packets })
+	return stats
+}
+
+// formatTracePathStats renders the path statistics of a trace, listing the unexpected paths
+// separately so they stand out even when they are rare
+func formatTracePathStats(stats []tracePathStat, packets int) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("- Packets Traced: %d\n- Distinct Paths: %d\n\nPaths:\n", packets, len(stats)))
+	var unexpected []string
+	for i, stat := range stats {
+		line := fmt.Sprintf("%d packets (%.1f%%): %s", stat.Packets, 100*float64(stat.Packets)/float64(packets), strings.Join(stat.Path, " -> "))
+		if stat.Unexpected != "" {
+			unexpected = append(unexpected, fmt.Sprintf("- [%s] %s", stat.Unexpected, line))
+			line += " [" + stat.Unexpected + "]"
+		}
+		text.WriteString(fmt.Sprintf("%d. %s\n", i+1, line))
+	}
+	text.WriteString("\nUnexpected Paths:\n")
+	if len(unexpected) == 0 {
+		text.WriteString("- None\n")
+	} else {
+		text.WriteString(strings.Join(unexpected, "\n") + "\n")
+	}
+	return text.String()
+}
+
+// handleDispatchAnalyze runs a dispatch trace like vpp_dispatch and summarizes the graph node
+// sequences the packets took. The buffer traces enabled by the dispatch trace are read with
+// 'show trace', since the dispatch pcap itself carries VPP-specific records.
+func (s *VPPMCPServer) handleDispatchAnalyze(ctx context.Context, input VPPCaptureInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received dispatch analyze request for pod: %s", input.PodName)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	if input.PodName == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: "Error: PodName is required. Please specify the Kubernetes pod name running VPP.",
+				},
+			},
+		}, nil, fmt.Errorf("PodName is required")
+	}
+
+	var k8sClient *KubeClient
+	var err error
+	if !s.bypassesKubernetes() {
+		k8sClient, err = newKubeClient()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Error: Failed to create Kubernetes client: %v", err),
+					},
+				},
+			}, nil, err
+		}
+	}
+	vppInputNode, _, err := mapInterfaceTypeToVppInputNode(k8sClient, input.Interface)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error mapping interface: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	count := input.Count
+	if count == 0 {
+		count = 500
+	}
+
+	unlock, err := s.lockCapture(ctx, input.PodName)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	defer unlock()
+
+	// Step 1: Reset the dispatch trace and the buffer traces, then start the dispatch trace
+	dispatch := newCaptureCommands(captureTypeDispatch, vppInputNode, count)
+	for _, command := range append(dispatch.reset, "clear trace") {
+		_, _ = s.ExecutePodVPPCommand(ctx, input.PodName, command)
+	}
+	defer func() {
+		_, _ = s.ExecutePodVPPCommand(context.WithoutCancel(ctx), input.PodName, "clear trace")
+	}()
+	if _, err := s.ExecutePodVPPCommand(ctx, input.PodName, dispatch.start); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error starting dispatch trace: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 2: Wait for capture
+	log.Printf("Capturing packets for %s or until %d packets captured...", defaultCaptureJobDuration, count)
+	if dryRunFromContext(ctx) == nil {
+		time.Sleep(defaultCaptureJobDuration)
+	}
+
+	// Step 3: Stop the dispatch trace and read the buffer traces
+	_, stopErr := s.ExecutePodVPPCommand(ctx, input.PodName, dispatch.collect)
+	result, err := s.ExecutePodVPPCommand(ctx, input.PodName, fmt.Sprintf("show trace max %d", count))
+	if err == nil {
+		err = stopErr
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error retrieving dispatch trace: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	records := parseTraceRecords(result["output"].(string))
+	if len(records) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Dispatch Path Statistics:\n\nNo packet traced on %s within %s. Check that traffic reaches the interface.\n", vppInputNode, defaultCaptureJobDuration),
+				},
+			},
+		}, nil, nil
+	}
+	stats := tracePathStats(records)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Dispatch Path Statistics:\n\n%s\nCapture Parameters:\n- VPP Input Node: %s\n- Count: %d\n- Capture Duration: %s\n- Pod: %s\n\n**Important**: %s\n",
+					formatTracePathStats(stats, len(records)), vppInputNode, count, defaultCaptureJobDuration, input.PodName, dispatch.note),
+			},
+		},
+		StructuredContent: map[string]any{
+			"pod":        input.PodName,
+			"input_node": vppInputNode,
+			"packets":    len(records),
+			"paths":      stats,
+		},
+	}, nil, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// unsafeArgumentChars lists shell metacharacters and command separators that are never
+// valid in the arguments of the vppctl and gobgp commands run by the tools
+const unsafeArgumentChars = ";|&$`<>(){}[]\\'\"*?!#~\n\r"
+
+// podNameRegexp matches a Kubernetes object name (DNS-1123 subdomain)
+var podNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+
+// validatePodName checks that name is a valid Kubernetes pod name
+func validatePodName(name string) error {
+	if len(name) > 253 || !podNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid pod name %q: must be a valid Kubernetes object name", name)
+	}
+	return nil
+}
+
+// containerNameRegexp matches a Kubernetes container name (DNS-1123 label)
+var containerNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// validateContainerName checks that name is empty (default container) or a valid container name
+func validateContainerName(name string) error {
+	if name == "" {
+		return nil
+	}
+	if len(name) > 63 || !containerNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid container name %q: must be a valid Kubernetes container name", name)
+	}
+	return nil
+}
+
+// validateCommand rejects commands containing shell metacharacters or command separators
+func validateCommand(command string) error {
+	if strings.TrimSpace(command) == "" {
+		return fmt.Errorf("empty command")
+	}
+	if i := strings.IndexAny(command, unsafeArgumentChars); i >= 0 {
+		return fmt.Errorf("invalid command %q: character %q is not allowed", command, command[i])
+	}
+	return nil
+}
+
+// validateFibIndex checks that index is a FIB table index
+func validateFibIndex(index string) error {
+	if _, err := strconv.ParseUint(index, 10, 32); err != nil {
+		return fmt.Errorf("invalid fib_index %q: must be a non-negative integer", index)
+	}
+	return nil
+}
+
+// validateIPOrPrefix checks that value is an IP address or an IP prefix
+func validateIPOrPrefix(value string) error {
+	if _, err := netip.ParseAddr(value); err == nil {
+		return nil
+	}
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return nil
+	}
+	return fmt.Errorf("invalid address or prefix %q: expected e.g. 10.0.0.1, 10.0.0.0/24 or 2001:db8::/32", value)
+}
+
+// nodeNameRegexp matches a VPP graph node name, e.g. ip4-drop or virtio-input
+var nodeNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// validateNodeName checks that name is a valid VPP graph node name
+func validateNodeName(name string) error {
+	if len(name) > 64 || !nodeNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid node name %q: expected a VPP graph node such as ip4-drop", name)
+	}
+	return nil
+}
+
+// validateObjectName checks that name is a valid name for a Kubernetes service or namespace (DNS-1123 label)
+func validateObjectName(kind, name string) error {
+	if len(name) > 63 || !containerNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid %s name %q: must be a valid Kubernetes object name", kind, name)
+	}
+	return nil
+}
+
+// validateExecRequest validates the pod name and command of an exec request
+func validateExecRequest(podName, command string) error {
+	if err := validatePodName(podName); err != nil {
+		return err
+	}
+	return validateCommand(command)
+}
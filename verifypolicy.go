@@ -0,0 +1,552 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kinds of network policy verified by verify_network_policy
+const (
+	networkPolicyKindKubernetes = "kubernetes"
+	networkPolicyKindCalico     = "calico"
+)
+
+// kubeNetworkPolicyPort is a port of a networking.k8s.io/v1 NetworkPolicy rule
+type kubeNetworkPolicyPort struct {
+	Protocol string          `json:"protocol"`
+	Port     json.RawMessage `json:"port"`
+	EndPort  *int            `json:"endPort"`
+}
+
+// kubeNetworkPolicyPeer is a peer of a networking.k8s.io/v1 NetworkPolicy rule
+type kubeNetworkPolicyPeer struct {
+	IPBlock *struct {
+		CIDR   string   `json:"cidr"`
+		Except []string `json:"except"`
+	} `json:"ipBlock"`
+}
+
+// kubeNetworkPolicy is the subset of a networking.k8s.io/v1 NetworkPolicy read by the verification
+type kubeNetworkPolicy struct {
+	Spec struct {
+		PodSelector struct {
+			MatchLabels      map[string]string `json:"matchLabels"`
+			MatchExpressions []struct {
+				Key      string   `json:"key"`
+				Operator string   `json:"operator"`
+				Values   []string `json:"values"`
+			} `json:"matchExpressions"`
+		} `json:"podSelector"`
+		PolicyTypes []string `json:"policyTypes"`
+		Ingress     []struct {
+			From  []kubeNetworkPolicyPeer `json:"from"`
+			Ports []kubeNetworkPolicyPort `json:"ports"`
+		} `json:"ingress"`
+		Egress []struct {
+			To    []kubeNetworkPolicyPeer `json:"to"`
+			Ports []kubeNetworkPolicyPort `json:"ports"`
+		} `json:"egress"`
+	} `json:"spec"`
+}
+
+// calicoEntityRule is the source or destination of a crd.projectcalico.org/v1 NetworkPolicy rule
+type calicoEntityRule struct {
+	Nets  []string          `json:"nets"`
+	Ports []json.RawMessage `json:"ports"`
+}
+
+// calicoNetworkPolicy is the subset of a crd.projectcalico.org/v1 NetworkPolicy read by the verification
+type calicoNetworkPolicy struct {
+	Spec struct {
+		Selector string   `json:"selector"`
+		Types    []string `json:"types"`
+		Ingress  []struct {
+			Source      calicoEntityRule `json:"source"`
+			Destination calicoEntityRule `json:"destination"`
+		} `json:"ingress"`
+		Egress []struct {
+			Source      calicoEntityRule `json:"source"`
+			Destination calicoEntityRule `json:"destination"`
+		} `json:"egress"`
+	} `json:"spec"`
+}
+
+// VerifyNetworkPolicyInput represents the input for verifying that a network policy is programmed for a pod
+type VerifyNetworkPolicyInput struct {
+	// Policy specifies the name of the network policy
+	Policy string `json:"policy"`
+	// Pod specifies the name of the workload pod the policy applies to
+	Pod string `json:"pod"`
+	// Namespace specifies the namespace of the policy and the pod (default: default)
+	Namespace string `json:"namespace,omitempty"`
+	// Kind specifies the policy API: kubernetes (networking.k8s.io) or calico (projectcalico.org)
+	Kind string `json:"kind,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pod
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// policyDirection is what a network policy expects in one direction of the pod interface
+type policyDirection struct {
+	// Direction is Ingress or Egress
+	Direction string `json:"direction"`
+	// Section is the section of 'show npol interfaces' enforcing the direction: tx for Ingress, rx for Egress
+	Section string `json:"section"`
+	// Ports and CIDRs are the numeric ports and the CIDRs referenced by the rules of the direction
+	Ports []string `json:"ports,omitempty"`
+	CIDRs []string `json:"cidrs,omitempty"`
+	// Policies are the VPP policies attached to the section of the pod interface
+	Policies []string `json:"policies"`
+	Issues   []string `json:"issues,omitempty"`
+}
+
+// npolInterface is the policies of one interface in 'show npol interfaces' output
+type npolInterface struct {
+	Name    string
+	Address string
+	// Policies maps the rx, tx and profiles sections to their policy IDs
+	Policies map[string][]string
+	// Rules maps the rx, tx and profiles sections to the rules listed inline
+	Rules map[string][]string
+}
+
+var (
+	// npolInterfaceRegexp matches an interface of 'show npol interfaces', e.g. "[tap2 sw_if_index=2  addr=10.0.0.5]"
+	npolInterfaceRegexp = regexp.MustCompile(`^\[(\S+)\s+sw_if_index=\d+\s+addr=([^\]\s]+)\]`)
+	// npolSectionRegexp matches a section of an interface, e.g. "  rx:"
+	npolSectionRegexp = regexp.MustCompile(`^\s+(rx|tx|profiles):`)
+	// npolPolicyRegexp matches a policy reference, e.g. "[policy#3]"
+	npolPolicyRegexp = regexp.MustCompile(`\[policy#(\d+)\]`)
+)
+
+// parseNpolInterfaces parses 'show npol interfaces' output
+func parseNpolInterfaces(output string) []*npolInterface {
+	var interfaces []*npolInterface
+	var current *npolInterface
+	section := ""
+	for _, line := range strings.Split(output, "\n") {
+		if match := npolInterfaceRegexp.FindStringSubmatch(line); match != nil {
+			current = &npolInterface{Name: match[1], Address: match[2], Policies: make(map[string][]string), Rules: make(map[string][]string)}
+			interfaces = append(interfaces, current)
+			section = ""
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if match := npolSectionRegexp.FindStringSubmatch(line); match != nil {
+			section = match[1]
+		}
+		if section == "" {
+			continue
+		}
+		for _, match := range npolPolicyRegexp.FindAllStringSubmatch(line, -1) {
+			current.Policies[section] = append(current.Policies[section], match[1])
+		}
+		if strings.Contains(line, "[rule#") {
+			current.Rules[section] = append(current.Rules[section], strings.TrimSpace(line))
+		}
+	}
+	return interfaces
+}
+
+// parseNpolPolicies returns the rule lines of every policy of 'show npol policies' output, keyed by policy ID
+func parseNpolPolicies(output string) map[string][]string {
+	policies := make(map[string][]string)
+	current := ""
+	for _, line := range strings.Split(output, "\n") {
+		if match := npolPolicyRegexp.FindStringSubmatch(line); match != nil && !strings.Contains(line, "[rule#") {
+			current = match[1]
+			policies[current] = nil
+			continue
+		}
+		if current != "" && strings.Contains(line, "[rule#") {
+			policies[current] = append(policies[current], strings.TrimSpace(line))
+		}
+	}
+	return policies
+}
+
+// matchKubeLabelSelector evaluates the pod selector of a Kubernetes network policy against the labels of a pod
+func matchKubeLabelSelector(policy kubeNetworkPolicy, labels map[string]string) bool {
+	for key, value := range policy.Spec.PodSelector.MatchLabels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	for _, expression := range policy.Spec.PodSelector.MatchExpressions {
+		value, exists := labels[expression.Key]
+		in := false
+		for _, candidate := range expression.Values {
+			in = in || (exists && candidate == value)
+		}
+		switch expression.Operator {
+		case "In":
+			if !in {
+				return false
+			}
+		case "NotIn":
+			if in {
+				return false
+			}
+		case "Exists":
+			if !exists {
+				return false
+			}
+		case "DoesNotExist":
+			if exists {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// numericPort returns a numeric port of a policy rule, "" for a named port
+func numericPort(raw json.RawMessage) string {
+	var port int
+	if json.Unmarshal(raw, &port) == nil && port > 0 {
+		return strconv.Itoa(port)
+	}
+	// Calico ports may be strings holding a number or a range, e.g. "8080" or "8000:8100"
+	var value string
+	if json.Unmarshal(raw, &value) == nil {
+		if _, err := strconv.Atoi(strings.Split(value, ":")[0]); err == nil {
+			return strings.Split(value, ":")[0]
+		}
+	}
+	return ""
+}
+
+// addPolicyReferences adds the ports and the CIDRs of a rule to a direction, without duplicates
+func addPolicyReferences(direction *policyDirection, ports, cidrs []string) {
+	for _, port := range ports {
+		if port != "" && !containsString(direction.Ports, port) {
+			direction.Ports = append(direction.Ports, port)
+		}
+	}
+	for _, cidr := range cidrs {
+		if prefix, err := netip.ParsePrefix(cidr); err == nil && !containsString(direction.CIDRs, prefix.Masked().String()) {
+			direction.CIDRs = append(direction.CIDRs, prefix.Masked().String())
+		}
+	}
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// kubePolicyDirections returns the directions a Kubernetes network policy applies to and the
+// ports and CIDRs of their rules
+func kubePolicyDirections(policy kubeNetworkPolicy) []*policyDirection {
+	types := policy.Spec.PolicyTypes
+	if len(types) == 0 {
+		// Without policyTypes, Ingress always applies and Egress when there are egress rules
+		types = []string{"Ingress"}
+		if len(policy.Spec.Egress) > 0 {
+			types = append(types, "Egress")
+		}
+	}
+	var directions []*policyDirection
+	for _, policyType := range types {
+		direction := &policyDirection{Direction: policyType, Section: "tx"}
+		var ports, cidrs []string
+		collect := func(peers []kubeNetworkPolicyPeer, rulePorts []kubeNetworkPolicyPort) {
+			for _, port := range rulePorts {
+				ports = append(ports, numericPort(port.Port))
+			}
+			for _, peer := range peers {
+				if peer.IPBlock != nil {
+					cidrs = append(cidrs, peer.IPBlock.CIDR)
+				}
+			}
+		}
+		if policyType == "Egress" {
+			direction.Section = "rx"
+			for _, rule := range policy.Spec.Egress {
+				collect(rule.To, rule.Ports)
+			}
+		} else {
+			for _, rule := range policy.Spec.Ingress {
+				collect(rule.From, rule.Ports)
+			}
+		}
+		addPolicyReferences(direction, ports, cidrs)
+		directions = append(directions, direction)
+	}
+	return directions
+}
+
+// calicoPolicyDirections returns the directions a Calico network policy applies to and the
+// ports and CIDRs of their rules
+func calicoPolicyDirections(policy calicoNetworkPolicy) []*policyDirection {
+	types := policy.Spec.Types
+	if len(types) == 0 {
+		if len(policy.Spec.Ingress) > 0 || len(policy.Spec.Egress) == 0 {
+			types = append(types, "Ingress")
+		}
+		if len(policy.Spec.Egress) > 0 {
+			types = append(types, "Egress")
+		}
+	}
+	var directions []*policyDirection
+	for _, policyType := range types {
+		direction := &policyDirection{Direction: policyType, Section: "tx"}
+		rules := policy.Spec.Ingress
+		if policyType == "Egress" {
+			direction.Section = "rx"
+			rules = policy.Spec.Egress
+		}
+		for _, rule := range rules {
+			var ports []string
+			for _, port := range append(rule.Source.Ports, rule.Destination.Ports...) {
+				ports = append(ports, numericPort(port))
+			}
+			addPolicyReferences(direction, ports, append(rule.Source.Nets, rule.Destination.Nets...))
+		}
+		directions = append(directions, direction)
+	}
+	return directions
+}
+
+// checkPolicyDirection checks that a section of the pod interface has policies and that the
+// ports and CIDRs of the direction appear in their rules
+func checkPolicyDirection(direction *policyDirection, iface *npolInterface, policyRules map[string][]string) {
+	direction.Policies = append([]string{}, iface.Policies[direction.Section]...)
+	if len(direction.Policies) == 0 {
+		direction.Issues = append(direction.Issues, fmt.Sprintf("no policy in the %s section of interface %s: the %s policy is not programmed", direction.Section, iface.Name, strings.ToLower(direction.Direction)))
+		return
+	}
+
+	rules := append([]string{}, iface.Rules[direction.Section]...)
+	for _, policy := range direction.Policies {
+		rules = append(rules, policyRules[policy]...)
+	}
+	text := strings.Join(rules, "\n")
+	for _, port := range direction.Ports {
+		if !regexp.MustCompile(`(^|[^0-9])` + port + `([^0-9]|$)`).MatchString(text) {
+			direction.Issues = append(direction.Issues, fmt.Sprintf("port %s is not found in the rules of the %s policies", port, direction.Section))
+		}
+	}
+	for _, cidr := range direction.CIDRs {
+		if !strings.Contains(text, cidr) {
+			direction.Issues = append(direction.Issues, fmt.Sprintf("CIDR %s is not found in the rules of the %s policies", cidr, direction.Section))
+		}
+	}
+}
+
+// handleVerifyNetworkPolicy checks that a Kubernetes or Calico network policy selecting a pod is
+// programmed on the VPP interface of the pod: the sections of 'show npol interfaces' enforcing
+// the policy directions must have policies whose rules reference the ports and CIDRs of the policy
+func (s *VPPMCPServer) handleVerifyNetworkPolicy(ctx context.Context, input VerifyNetworkPolicyInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received verify network policy request for policy: %s, pod: %s/%s", input.Policy, input.Namespace, input.Pod)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	// Step 1: Read the policy and the pod
+	namespace := input.Namespace
+	if namespace == "" {
+		namespace = defaultServiceNamespace
+	}
+	kind := input.Kind
+	if kind == "" {
+		kind = networkPolicyKindKubernetes
+	}
+	err := validateObjectName("namespace", namespace)
+	if err == nil {
+		err = validatePodName(input.Pod)
+	}
+	switch {
+	case err != nil:
+	case input.Policy == "" || validatePodName(input.Policy) != nil:
+		err = fmt.Errorf("invalid policy name %q: must be a valid Kubernetes object name", input.Policy)
+	case kind != networkPolicyKindKubernetes && kind != networkPolicyKindCalico:
+		err = fmt.Errorf("invalid kind %q: must be %s or %s", input.Kind, networkPolicyKindKubernetes, networkPolicyKindCalico)
+	case s.bypassesKubernetes():
+		err = fmt.Errorf("verifying a network policy requires the Kubernetes API, not available with the %s backend", backendLocal)
+	}
+	var k8sClient *KubeClient
+	if err == nil {
+		if k8sClient, err = newKubeClient(); err != nil {
+			err = fmt.Errorf("failed to create Kubernetes client: %v", err)
+		}
+	}
+	var directions []*policyDirection
+	selected, evaluated := false, true
+	var podLabels map[string]string
+	var podIP, podNode string
+	if err == nil {
+		getCtx, cancel := context.WithTimeout(ctx, k8sClient.timeout)
+		pod, getErr := k8sClient.CoreV1().Pods(namespace).Get(getCtx, input.Pod, metav1.GetOptions{})
+		cancel()
+		if getErr != nil {
+			err = fmt.Errorf("failed to get pod %s/%s: %v", namespace, input.Pod, getErr)
+		} else {
+			podLabels, podIP, podNode = pod.Labels, pod.Status.PodIP, pod.Spec.NodeName
+		}
+	}
+	if err == nil && kind == networkPolicyKindKubernetes {
+		var policy kubeNetworkPolicy
+		err = k8sClient.getRawJSON(ctx, fmt.Sprintf("/apis/networking.k8s.io/v1/namespaces/%s/networkpolicies/%s", namespace, input.Policy), &policy)
+		if err == nil {
+			selected = matchKubeLabelSelector(policy, podLabels)
+			directions = kubePolicyDirections(policy)
+		}
+	} else if err == nil {
+		var policy calicoNetworkPolicy
+		err = k8sClient.getRawJSON(ctx, fmt.Sprintf("/apis/crd.projectcalico.org/v1/namespaces/%s/networkpolicies/%s", namespace, input.Policy), &policy)
+		if err == nil {
+			selector := policy.Spec.Selector
+			if selector == "" {
+				selector = "all()"
+			}
+			selected, evaluated = matchCalicoSelector(selector, podLabels)
+			directions = calicoPolicyDirections(policy)
+		}
+	}
+	if err == nil && podIP == "" {
+		err = fmt.Errorf("pod %s/%s has no IP yet", namespace, input.Pod)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Network Policy %s/%s (%s) on pod %s (IP %s, node %s):\n\n", namespace, input.Policy, kind, input.Pod, podIP, podNode))
+	if evaluated && !selected {
+		text.WriteString("The policy does not select the pod: it is not expected on the pod interface.\n")
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: text.String(),
+				},
+			},
+			StructuredContent: map[string]any{
+				"policy":   input.Policy,
+				"pod":      input.Pod,
+				"selected": false,
+			},
+		}, nil, nil
+	}
+	if !evaluated {
+		text.WriteString("The Calico selector of the policy is not supported: the pod is assumed to be selected.\n\n")
+	}
+
+	// Step 2: Find the interface of the pod in the npol state of its node
+	pods, err := listVPPPods(ctx, k8sClient)
+	vppPod := ""
+	for _, pod := range pods {
+		if pod.Node == podNode {
+			vppPod = pod.Name
+		}
+	}
+	if err == nil && vppPod == "" {
+		err = fmt.Errorf("no calico-vpp pod runs on node %s", podNode)
+	}
+	var interfaces, policies map[string]any
+	if err == nil {
+		interfaces, err = s.ExecutePodVPPCommand(ctx, vppPod, "show npol interfaces")
+	}
+	if err == nil {
+		policies, err = s.ExecutePodVPPCommand(ctx, vppPod, "show npol policies")
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+	if dryRunFromContext(ctx) != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Network Policy %s/%s: the npol state of pod %s would be checked on %s\n", namespace, input.Policy, input.Pod, vppPod),
+				},
+			},
+		}, nil, nil
+	}
+
+	var iface *npolInterface
+	podAddr, _ := netip.ParseAddr(podIP)
+	for _, candidate := range parseNpolInterfaces(interfaces["output"].(string)) {
+		if addr, err := netip.ParseAddr(candidate.Address); err == nil && addr.Unmap() == podAddr.Unmap() {
+			iface = candidate
+		}
+	}
+
+	// Step 3: Check every direction of the policy
+	issues := 0
+	if iface == nil {
+		issues++
+		text.WriteString(fmt.Sprintf("! No interface with address %s in 'show npol interfaces' of %s: no policy is programmed for the pod\n", podIP, vppPod))
+	} else {
+		text.WriteString(fmt.Sprintf("Interface %s on %s:\n", iface.Name, vppPod))
+		policyRules := parseNpolPolicies(policies["output"].(string))
+		for _, direction := range directions {
+			checkPolicyDirection(direction, iface, policyRules)
+			issues += len(direction.Issues)
+			text.WriteString(fmt.Sprintf("  %s (%s): policies %s", direction.Direction, direction.Section, strings.Join(direction.Policies, ", ")))
+			if len(direction.Policies) == 0 {
+				text.WriteString("none")
+			}
+			text.WriteString("\n")
+			if len(direction.Ports) > 0 || len(direction.CIDRs) > 0 {
+				sort.Strings(direction.Ports)
+				text.WriteString(fmt.Sprintf("    Expected ports: %s, CIDRs: %s\n", strings.Join(direction.Ports, " "), strings.Join(direction.CIDRs, " ")))
+			}
+			for _, issue := range direction.Issues {
+				text.WriteString(fmt.Sprintf("    ! %s\n", issue))
+			}
+		}
+	}
+	if issues == 0 {
+		text.WriteString("\nThe policy is programmed on the pod interface.\n")
+	} else {
+		text.WriteString(fmt.Sprintf("\nSummary: %d issue(s)\n", issues))
+	}
+
+	structured := map[string]any{
+		"policy":     input.Policy,
+		"pod":        input.Pod,
+		"selected":   true,
+		"node":       podNode,
+		"directions": directions,
+	}
+	if iface != nil {
+		structured["interface"] = iface.Name
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: structured,
+	}, nil, nil
+}
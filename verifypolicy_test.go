@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseNpolInterfaces(t *testing.T) {
+	output := `Interfaces with policies configured:
+[tap2 sw_if_index=2  addr=10.0.1.5]
+  rx:
+    [policy#3]
+  tx:
+    [policy#4]
+    [policy#5]
+  profiles:
+    [policy#1]
+      [rule#9;allow][proto==TCP,dst==8080,]
+[tap3 sw_if_index=3  addr=10.0.1.6]
+  tx:
+    [policy#4]
+`
+	got := parseNpolInterfaces(output)
+	want := []*npolInterface{
+		{
+			Name:     "tap2",
+			Address:  "10.0.1.5",
+			Policies: map[string][]string{"rx": {"3"}, "tx": {"4", "5"}, "profiles": {"1"}},
+			Rules:    map[string][]string{"profiles": {"[rule#9;allow][proto==TCP,dst==8080,]"}},
+		},
+		{
+			Name:     "tap3",
+			Address:  "10.0.1.6",
+			Policies: map[string][]string{"tx": {"4"}},
+			Rules:    map[string][]string{},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNpolInterfaces() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseNpolPolicies(t *testing.T) {
+	output := `[policy#3]
+  tx:[rule#7;allow][proto==TCP,dst==80,]
+  tx:[rule#8;allow][dst==10.0.2.0/24,]
+[policy#4]
+[policy#5]
+  rx:[rule#10;deny][]
+`
+	want := map[string][]string{
+		"3": {"tx:[rule#7;allow][proto==TCP,dst==80,]", "tx:[rule#8;allow][dst==10.0.2.0/24,]"},
+		"4": nil,
+		"5": {"rx:[rule#10;deny][]"},
+	}
+	if got := parseNpolPolicies(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNpolPolicies() = %q, want %q", got, want)
+	}
+}
+
+func TestMatchKubeLabelSelector(t *testing.T) {
+	labels := map[string]string{"app": "web", "tier": "frontend"}
+
+	tests := []struct {
+		name     string
+		selector string
+		want     bool
+	}{
+		{name: "empty selects every pod", selector: `{}`, want: true},
+		{name: "match labels", selector: `{"matchLabels": {"app": "web"}}`, want: true},
+		{name: "other label value", selector: `{"matchLabels": {"app": "db"}}`},
+		{name: "in", selector: `{"matchExpressions": [{"key": "tier", "operator": "In", "values": ["backend", "frontend"]}]}`, want: true},
+		{name: "not in", selector: `{"matchExpressions": [{"key": "tier", "operator": "NotIn", "values": ["frontend"]}]}`},
+		{name: "exists", selector: `{"matchExpressions": [{"key": "app", "operator": "Exists"}]}`, want: true},
+		{name: "does not exist", selector: `{"matchExpressions": [{"key": "app", "operator": "DoesNotExist"}]}`},
+		{name: "in on a missing label", selector: `{"matchExpressions": [{"key": "zone", "operator": "In", "values": [""]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var policy kubeNetworkPolicy
+			if err := json.Unmarshal([]byte(`{"spec": {"podSelector": `+tt.selector+`}}`), &policy); err != nil {
+				t.Fatal(err)
+			}
+			if got := matchKubeLabelSelector(policy, labels); got != tt.want {
+				t.Errorf("matchKubeLabelSelector(%s) = %t, want %t", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKubePolicyDirections(t *testing.T) {
+	var policy kubeNetworkPolicy
+	if err := json.Unmarshal([]byte(`{"spec": {
+		"ingress": [
+			{"from": [{"ipBlock": {"cidr": "10.0.2.7/24"}}], "ports": [{"protocol": "TCP", "port": 80}, {"port": "http"}]},
+			{"ports": [{"port": 80}, {"port": 443}]}
+		],
+		"egress": [{"to": [{"ipBlock": {"cidr": "192.0.2.0/28"}}]}]
+	}}`), &policy); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*policyDirection{
+		{Direction: "Ingress", Section: "tx", Ports: []string{"80", "443"}, CIDRs: []string{"10.0.2.0/24"}},
+		{Direction: "Egress", Section: "rx", CIDRs: []string{"192.0.2.0/28"}},
+	}
+	if got := kubePolicyDirections(policy); !reflect.DeepEqual(got, want) {
+		t.Errorf("kubePolicyDirections() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCalicoPolicyDirections(t *testing.T) {
+	var policy calicoNetworkPolicy
+	if err := json.Unmarshal([]byte(`{"spec": {
+		"selector": "app == 'web'",
+		"egress": [{"destination": {"nets": ["10.0.3.0/24"], "ports": [5432, "8000:8100", "metrics"]}}]
+	}}`), &policy); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*policyDirection{
+		{Direction: "Egress", Section: "rx", Ports: []string{"5432", "8000"}, CIDRs: []string{"10.0.3.0/24"}},
+	}
+	if got := calicoPolicyDirections(policy); !reflect.DeepEqual(got, want) {
+		t.Errorf("calicoPolicyDirections() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckPolicyDirection(t *testing.T) {
+	iface := &npolInterface{
+		Name:     "tap2",
+		Policies: map[string][]string{"tx": {"3"}},
+		Rules:    map[string][]string{"tx": {"[rule#9;allow][proto==TCP,dst==8443,]"}},
+	}
+	policyRules := map[string][]string{"3": {"tx:[rule#7;allow][proto==TCP,dst==80,src==10.0.2.0/24,]"}}
+
+	tests := []struct {
+		name      string
+		direction policyDirection
+		want      []string
+	}{
+		{
+			name:      "programmed",
+			direction: policyDirection{Direction: "Ingress", Section: "tx", Ports: []string{"80", "8443"}, CIDRs: []string{"10.0.2.0/24"}},
+		},
+		{
+			name:      "port and cidr missing",
+			direction: policyDirection{Direction: "Ingress", Section: "tx", Ports: []string{"8"}, CIDRs: []string{"10.0.9.0/24"}},
+			want: []string{
+				"port 8 is not found in the rules of the tx policies",
+				"CIDR 10.0.9.0/24 is not found in the rules of the tx policies",
+			},
+		},
+		{
+			name:      "section without policy",
+			direction: policyDirection{Direction: "Egress", Section: "rx", Ports: []string{"53"}},
+			want:      []string{"no policy in the rx section of interface tap2: the egress policy is not programmed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			direction := tt.direction
+			checkPolicyDirection(&direction, iface, policyRules)
+			if !reflect.DeepEqual(direction.Issues, tt.want) {
+				t.Errorf("checkPolicyDirection() issues = %q, want %q", direction.Issues, tt.want)
+			}
+		})
+	}
+}
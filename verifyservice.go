@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultServiceNamespace is the namespace of the verified service when none is specified
+const defaultServiceNamespace = "default"
+
+// kubeService is the subset of a Service read by the CNAT verification
+type kubeService struct {
+	Spec struct {
+		Type        string   `json:"type"`
+		ClusterIP   string   `json:"clusterIP"`
+		ClusterIPs  []string `json:"clusterIPs"`
+		ExternalIPs []string `json:"externalIPs"`
+		Ports       []struct {
+			Name     string `json:"name"`
+			Protocol string `json:"protocol"`
+			Port     int    `json:"port"`
+		} `json:"ports"`
+	} `json:"spec"`
+	Status struct {
+		LoadBalancer struct {
+			Ingress []struct {
+				IP string `json:"ip"`
+			} `json:"ingress"`
+		} `json:"loadBalancer"`
+	} `json:"status"`
+}
+
+// kubeEndpointSliceList is the subset of an EndpointSliceList read by the CNAT verification
+type kubeEndpointSliceList struct {
+	Items []struct {
+		Endpoints []struct {
+			Addresses  []string `json:"addresses"`
+			Conditions struct {
+				Ready *bool `json:"ready"`
+			} `json:"conditions"`
+		} `json:"endpoints"`
+		Ports []struct {
+			Name     *string `json:"name"`
+			Protocol *string `json:"protocol"`
+			Port     *int    `json:"port"`
+		} `json:"ports"`
+	} `json:"items"`
+}
+
+// VerifyServiceInput represents the input for verifying the CNAT translations of a Kubernetes service
+type VerifyServiceInput struct {
+	// Service specifies the name of the Kubernetes service
+	Service string `json:"service"`
+	// Namespace specifies the namespace of the service (default: default)
+	Namespace string `json:"namespace,omitempty"`
+	// PodNames lists the calico-vpp pods to check (default: every calico-vpp pod)
+	PodNames []string `json:"pod_names,omitempty"`
+	// NodeNames selects the calico-vpp pods running on Kubernetes nodes, in addition to PodNames
+	NodeNames []string `json:"node_names,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of each command run in the pods
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// serviceDiscrepancy is a difference between a service and the CNAT translations of a node
+type serviceDiscrepancy struct {
+	// Translation is the service VIP, port and protocol, e.g. "10.96.0.10;53 UDP"
+	Translation string `json:"translation"`
+	// Kind is missing_translation, missing_backend or stale_backend
+	Kind    string `json:"kind"`
+	Backend string `json:"backend,omitempty"`
+}
+
+// serviceNodeCheck is the verification of the CNAT translations of a service on one node
+type serviceNodeCheck struct {
+	Pod           string               `json:"pod"`
+	Node          string               `json:"node,omitempty"`
+	Discrepancies []serviceDiscrepancy `json:"discrepancies,omitempty"`
+	Error         string               `json:"error,omitempty"`
+}
+
+var (
+	// cnatTranslationRegexp matches the first line of a translation, e.g. "[3] 10.96.0.10;53 UDP lb:default"
+	cnatTranslationRegexp = regexp.MustCompile(`^\[\d+\]\s+(\S+);(\d+)\s+(\S+)`)
+	// cnatPathRegexp matches a translation path, e.g. "::;0->10.0.1.2;53"; the backend follows the arrow
+	cnatPathRegexp = regexp.MustCompile(`^\s+\S+;\d+->(\S+);(\d+)`)
+)
+
+// cnatEndpoint formats an address and a port the way 'show cnat translation' does, e.g. "10.0.1.2;53"
+func cnatEndpoint(address string, port int) string {
+	if addr, err := netip.ParseAddr(address); err == nil {
+		address = addr.Unmap().String()
+	}
+	return fmt.Sprintf("%s;%d", address, port)
+}
+
+// parseCnatTranslations returns the backends of every translation of 'show cnat translation'
+// output, keyed by VIP, port and protocol, e.g. "10.96.0.10;53 UDP"
+func parseCnatTranslations(output string) map[string]map[string]bool {
+	translations := make(map[string]map[string]bool)
+	var current map[string]bool
+	for _, line := range strings.Split(output, "\n") {
+		if match := cnatTranslationRegexp.FindStringSubmatch(line); match != nil {
+			port, _ := strconv.Atoi(match[2])
+			key := cnatEndpoint(match[1], port) + " " + strings.ToUpper(match[3])
+			current = translations[key]
+			if current == nil {
+				current = make(map[string]bool)
+				translations[key] = current
+			}
+			continue
+		}
+		if match := cnatPathRegexp.FindStringSubmatch(line); match != nil && current != nil {
+			port, _ := strconv.Atoi(match[2])
+			current[cnatEndpoint(match[1], port)] = true
+		}
+	}
+	return translations
+}
+
+// expectedServiceTranslations returns the backends every node should translate each VIP, port
+// and protocol of a service to: the ready endpoints of the same address family and port name
+func expectedServiceTranslations(service kubeService, slices kubeEndpointSliceList) map[string]map[string]bool {
+	var vips []string
+	clusterIPs := service.Spec.ClusterIPs
+	if len(clusterIPs) == 0 && service.Spec.ClusterIP != "" {
+		clusterIPs = []string{service.Spec.ClusterIP}
+	}
+	vips = append(vips, clusterIPs...)
+	vips = append(vips, service.Spec.ExternalIPs...)
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		vips = append(vips, ingress.IP)
+	}
+
+	expected := make(map[string]map[string]bool)
+	for _, vip := range vips {
+		vipAddr, err := netip.ParseAddr(vip)
+		if err != nil {
+			// Headless services have the ClusterIP None
+			continue
+		}
+		for _, servicePort := range service.Spec.Ports {
+			protocol := strings.ToUpper(servicePort.Protocol)
+			if protocol == "" {
+				protocol = "TCP"
+			}
+			backends := make(map[string]bool)
+			for _, slice := range slices.Items {
+				for _, port := range slice.Ports {
+					name, portProtocol := "", "TCP"
+					if port.Name != nil {
+						name = *port.Name
+					}
+					if port.Protocol != nil {
+						portProtocol = strings.ToUpper(*port.Protocol)
+					}
+					if name != servicePort.Name || portProtocol != protocol || port.Port == nil {
+						continue
+					}
+					for _, endpoint := range slice.Endpoints {
+						// A nil ready condition means ready
+						if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+							continue
+						}
+						for _, address := range endpoint.Addresses {
+							if addr, err := netip.ParseAddr(address); err == nil && addr.Unmap().Is4() == vipAddr.Unmap().Is4() {
+								backends[cnatEndpoint(address, *port.Port)] = true
+							}
+						}
+					}
+				}
+			}
+			expected[cnatEndpoint(vip, servicePort.Port)+" "+protocol] = backends
+		}
+	}
+	return expected
+}
+
+// compareServiceTranslations returns the translations of a service missing from a node and
+// the backends missing from or unexpected in its translations, sorted by translation
+func compareServiceTranslations(expected, actual map[string]map[string]bool) []serviceDiscrepancy {
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var discrepancies []serviceDiscrepancy
+	for _, key := range keys {
+		backends, ok := actual[key]
+		if !ok {
+			// Without ready endpoints there is nothing to translate to
+			if len(expected[key]) > 0 {
+				discrepancies = append(discrepancies, serviceDiscrepancy{Translation: key, Kind: "missing_translation"})
+			}
+			continue
+		}
+		var missing, stale []string
+		for backend := range expected[key] {
+			if !backends[backend] {
+				missing = append(missing, backend)
+			}
+		}
+		for backend := range backends {
+			if !expected[key][backend] {
+				stale = append(stale, backend)
+			}
+		}
+		sort.Strings(missing)
+		sort.Strings(stale)
+		for _, backend := range missing {
+			discrepancies = append(discrepancies, serviceDiscrepancy{Translation: key, Kind: "missing_backend", Backend: backend})
+		}
+		for _, backend := range stale {
+			discrepancies = append(discrepancies, serviceDiscrepancy{Translation: key, Kind: "stale_backend", Backend: backend})
+		}
+	}
+	return discrepancies
+}
+
+// handleVerifyService reads a Kubernetes service and its endpoint slices, then checks on every
+// selected node that 'show cnat translation' translates each VIP and port of the service to
+// exactly its ready endpoints
+func (s *VPPMCPServer) handleVerifyService(ctx context.Context, input VerifyServiceInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received verify service request for service: %s/%s, pods: %v", input.Namespace, input.Service, input.PodNames)
+
+	ctx = withExecTimeout(ctx, input.TimeoutSeconds)
+
+	// Step 1: Read the service and its ready endpoints
+	namespace := input.Namespace
+	if namespace == "" {
+		namespace = defaultServiceNamespace
+	}
+	err := validateObjectName("service", input.Service)
+	if err == nil {
+		err = validateObjectName("namespace", namespace)
+	}
+	if err == nil && s.bypassesKubernetes() {
+		err = fmt.Errorf("verifying a service requires the Kubernetes API, not available with the %s backend", backendLocal)
+	}
+	var k8sClient *KubeClient
+	if err == nil {
+		if k8sClient, err = newKubeClient(); err != nil {
+			err = fmt.Errorf("failed to create Kubernetes client: %v", err)
+		}
+	}
+	var service kubeService
+	if err == nil {
+		err = k8sClient.getRawJSON(ctx, fmt.Sprintf("/api/v1/namespaces/%s/services/%s", namespace, input.Service), &service)
+	}
+	var slices kubeEndpointSliceList
+	if err == nil {
+		err = k8sClient.listRawJSON(ctx, fmt.Sprintf("/apis/discovery.k8s.io/v1/namespaces/%s/endpointslices", namespace),
+			"kubernetes.io/service-name="+input.Service, &slices)
+	}
+	var expected map[string]map[string]bool
+	if err == nil {
+		expected = expectedServiceTranslations(service, slices)
+		if len(expected) == 0 {
+			err = fmt.Errorf("service %s/%s of type %s has no ClusterIP, external or load balancer IP: it has no CNAT translation", namespace, input.Service, service.Spec.Type)
+		}
+	}
+	var pods []VPPPod
+	if err == nil {
+		pods, err = s.resolveBGPPods(ctx, input.PodNames)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	// Step 2: Compare the CNAT translations of every node
+	checks := make([]*serviceNodeCheck, len(pods))
+	var wg sync.WaitGroup
+	for i, pod := range pods {
+		checks[i] = &serviceNodeCheck{Pod: pod.Name, Node: pod.Node}
+		wg.Add(1)
+		go func(check *serviceNodeCheck) {
+			defer wg.Done()
+			result, err := s.ExecutePodVPPCommand(ctx, check.Pod, "show cnat translation")
+			if err != nil {
+				check.Error = fmt.Sprintf("vppctl show cnat translation failed: %v", err)
+				return
+			}
+			check.Discrepancies = compareServiceTranslations(expected, parseCnatTranslations(result["output"].(string)))
+		}(checks[i])
+	}
+	wg.Wait()
+
+	if dryRunFromContext(ctx) != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Service %s/%s: %d translation(s) would be checked on %d pod(s)\n", namespace, input.Service, len(expected), len(pods)),
+				},
+			},
+		}, nil, nil
+	}
+
+	// Step 3: Report the discrepancies per node
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("CNAT Verification of Service %s/%s:\n\n", namespace, input.Service))
+	for _, key := range keys {
+		backends := make([]string, 0, len(expected[key]))
+		for backend := range expected[key] {
+			backends = append(backends, backend)
+		}
+		sort.Strings(backends)
+		if len(backends) == 0 {
+			text.WriteString(fmt.Sprintf("  %s -> no ready endpoint\n", key))
+			continue
+		}
+		text.WriteString(fmt.Sprintf("  %s -> %s\n", key, strings.Join(backends, ", ")))
+	}
+	text.WriteString("\n")
+
+	inconsistent, failed := 0, 0
+	for _, check := range checks {
+		label := fmt.Sprintf("Node %s (pod %s)", check.Node, check.Pod)
+		switch {
+		case check.Error != "":
+			failed++
+			text.WriteString(fmt.Sprintf("%s:\n  %s\n\n", label, check.Error))
+		case len(check.Discrepancies) == 0:
+			text.WriteString(fmt.Sprintf("%s: OK\n\n", label))
+		default:
+			inconsistent++
+			text.WriteString(label + ":\n")
+			for _, discrepancy := range check.Discrepancies {
+				switch discrepancy.Kind {
+				case "missing_translation":
+					text.WriteString(fmt.Sprintf("  ! %s: no translation\n", discrepancy.Translation))
+				case "missing_backend":
+					text.WriteString(fmt.Sprintf("  ! %s: missing backend %s\n", discrepancy.Translation, discrepancy.Backend))
+				case "stale_backend":
+					text.WriteString(fmt.Sprintf("  ! %s: stale backend %s\n", discrepancy.Translation, discrepancy.Backend))
+				}
+			}
+			text.WriteString("\n")
+		}
+	}
+	text.WriteString(fmt.Sprintf("Summary: %d node(s), %d with discrepancies, %d failed\n", len(checks), inconsistent, failed))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text.String(),
+			},
+		},
+		StructuredContent: map[string]any{
+			"service":   input.Service,
+			"namespace": namespace,
+			"nodes":     checks,
+		},
+	}, nil, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseCnatTranslations(t *testing.T) {
+	output := `[0] 10.96.0.10;53 UDP lb:default
+   Vip: 10.96.0.10;53
+  ::;0->10.0.1.2;53
+    fib-entry:54
+  ::;0->10.0.2.3;53
+    fib-entry:55
+[1] 10.96.0.10;53 TCP lb:default
+  ::;0->10.0.1.2;53
+[2] fd00:96::a;53 udp lb:default
+  ::;0->fd00:1::2;53
+[3] 10.96.0.20;80 TCP lb:maglev
+`
+	want := map[string]map[string]bool{
+		"10.96.0.10;53 UDP": {"10.0.1.2;53": true, "10.0.2.3;53": true},
+		"10.96.0.10;53 TCP": {"10.0.1.2;53": true},
+		"fd00:96::a;53 UDP": {"fd00:1::2;53": true},
+		"10.96.0.20;80 TCP": {},
+	}
+	if got := parseCnatTranslations(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCnatTranslations() = %v, want %v", got, want)
+	}
+}
+
+func TestExpectedServiceTranslations(t *testing.T) {
+	var service kubeService
+	if err := json.Unmarshal([]byte(`{
+		"spec": {
+			"type": "LoadBalancer",
+			"clusterIP": "10.96.0.10",
+			"clusterIPs": ["10.96.0.10", "fd00:96::a"],
+			"externalIPs": ["192.0.2.10"],
+			"ports": [{"name": "dns", "protocol": "UDP", "port": 53}, {"name": "metrics", "port": 9153}]
+		},
+		"status": {"loadBalancer": {"ingress": [{"ip": "198.51.100.10"}]}}
+	}`), &service); err != nil {
+		t.Fatal(err)
+	}
+	var slices kubeEndpointSliceList
+	if err := json.Unmarshal([]byte(`{"items": [
+		{
+			"endpoints": [
+				{"addresses": ["10.0.1.2"], "conditions": {"ready": true}},
+				{"addresses": ["10.0.2.3"]},
+				{"addresses": ["10.0.3.4"], "conditions": {"ready": false}}
+			],
+			"ports": [{"name": "dns", "protocol": "UDP", "port": 5353}, {"name": "metrics", "port": 9153}]
+		},
+		{
+			"endpoints": [{"addresses": ["fd00:1::2"]}],
+			"ports": [{"name": "dns", "protocol": "UDP", "port": 5353}]
+		}
+	]}`), &slices); err != nil {
+		t.Fatal(err)
+	}
+
+	ipv4DNS := map[string]bool{"10.0.1.2;5353": true, "10.0.2.3;5353": true}
+	ipv4Metrics := map[string]bool{"10.0.1.2;9153": true, "10.0.2.3;9153": true}
+	want := map[string]map[string]bool{
+		"10.96.0.10;53 UDP":      ipv4DNS,
+		"10.96.0.10;9153 TCP":    ipv4Metrics,
+		"fd00:96::a;53 UDP":      {"fd00:1::2;5353": true},
+		"fd00:96::a;9153 TCP":    {},
+		"192.0.2.10;53 UDP":      ipv4DNS,
+		"192.0.2.10;9153 TCP":    ipv4Metrics,
+		"198.51.100.10;53 UDP":   ipv4DNS,
+		"198.51.100.10;9153 TCP": ipv4Metrics,
+	}
+	if got := expectedServiceTranslations(service, slices); !reflect.DeepEqual(got, want) {
+		t.Errorf("expectedServiceTranslations() = %v, want %v", got, want)
+	}
+
+	// Headless services have no VIP to translate
+	service.Spec.ClusterIP, service.Spec.ClusterIPs, service.Spec.ExternalIPs = "None", nil, nil
+	service.Status.LoadBalancer.Ingress = nil
+	if got := expectedServiceTranslations(service, slices); len(got) != 0 {
+		t.Errorf("expectedServiceTranslations() of a headless service = %v, want none", got)
+	}
+}
+
+func TestCompareServiceTranslations(t *testing.T) {
+	expected := map[string]map[string]bool{
+		"10.96.0.10;53 UDP":   {"10.0.1.2;5353": true, "10.0.2.3;5353": true},
+		"10.96.0.10;9153 TCP": {"10.0.1.2;9153": true},
+		"10.96.0.20;80 TCP":   {"10.0.4.5;8080": true},
+		"10.96.0.30;80 TCP":   {},
+	}
+	actual := map[string]map[string]bool{
+		"10.96.0.10;53 UDP":   {"10.0.1.2;5353": true, "10.0.9.9;5353": true},
+		"10.96.0.10;9153 TCP": {"10.0.1.2;9153": true},
+	}
+
+	want := []serviceDiscrepancy{
+		{Translation: "10.96.0.10;53 UDP", Kind: "missing_backend", Backend: "10.0.2.3;5353"},
+		{Translation: "10.96.0.10;53 UDP", Kind: "stale_backend", Backend: "10.0.9.9;5353"},
+		{Translation: "10.96.0.20;80 TCP", Kind: "missing_translation"},
+	}
+	if got := compareServiceTranslations(expected, actual); !reflect.DeepEqual(got, want) {
+		t.Errorf("compareServiceTranslations() = %+v, want %+v", got, want)
+	}
+}
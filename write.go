@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// interfaceNameRegexp matches VPP interface names such as tap0, host-eth0 or GigabitEthernet0/8/0
+var interfaceNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_./:-]*$`)
+
+// validateInterfaceName checks that name is a VPP interface name
+func validateInterfaceName(name string) error {
+	if name == "" {
+		return fmt.Errorf("interface is required")
+	}
+	if len(name) > 64 || !interfaceNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid interface name %q", name)
+	}
+	return nil
+}
+
+// VPPSpanInput represents the input for configuring a SPAN mirror
+type VPPSpanInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Interface specifies the interface whose traffic is mirrored
+	Interface string `json:"interface"`
+	// Destination specifies the interface receiving the mirrored traffic
+	Destination string `json:"destination,omitempty"`
+	// Direction specifies the mirrored direction: rx, tx or both (default: both)
+	Direction string `json:"direction,omitempty"`
+	// Disable removes the mirror of Interface instead of configuring it
+	Disable bool `json:"disable,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// handleSetInterfaceSpan configures or removes the SPAN mirror of an interface
+func (s *VPPMCPServer) handleSetInterfaceSpan(ctx context.Context, input VPPSpanInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received SPAN request for pod: %s, interface: %s, destination: %s, disable: %v",
+		input.PodName, input.Interface, input.Destination, input.Disable)
+
+	command, err := spanCommand(input)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	return s.handleVPPCommand(ctx, VPPCommandInput{PodName: input.PodName, TimeoutSeconds: input.TimeoutSeconds}, command, "VPP Interface SPAN Configuration")
+}
+
+// spanCommand builds the 'set interface span' command of a SPAN request
+func spanCommand(input VPPSpanInput) (string, error) {
+	if err := validateInterfaceName(input.Interface); err != nil {
+		return "", err
+	}
+	if input.Disable {
+		return fmt.Sprintf("set interface span %s disable", input.Interface), nil
+	}
+
+	if input.Destination == "" {
+		return "", fmt.Errorf("destination is required unless disable is set")
+	}
+	if err := validateInterfaceName(input.Destination); err != nil {
+		return "", err
+	}
+	if input.Destination == input.Interface {
+		return "", fmt.Errorf("invalid destination %q: an interface cannot be mirrored to itself", input.Destination)
+	}
+
+	direction := input.Direction
+	if direction == "" {
+		direction = "both"
+	}
+	if direction != "rx" && direction != "tx" && direction != "both" {
+		return "", fmt.Errorf("invalid direction '%s'. Use 'rx', 'tx' or 'both'", direction)
+	}
+	return fmt.Sprintf("set interface span %s destination %s %s", input.Interface, input.Destination, direction), nil
+}
+
+// VPPInterfaceMTUInput represents the input for changing the MTU of an interface
+type VPPInterfaceMTUInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Interface specifies the interface to resize
+	Interface string `json:"interface"`
+	// MTU specifies the new MTU in bytes
+	MTU int `json:"mtu"`
+	// Layer specifies the MTU to change: packet, ip4, ip6 or mpls (default: packet)
+	Layer string `json:"layer,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// VPPInterfaceStateInput represents the input for changing the admin state of an interface
+type VPPInterfaceStateInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Interface specifies the interface to change
+	Interface string `json:"interface"`
+	// State specifies the new admin state: up, down or bounce (down then up)
+	State string `json:"state"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// handleSetInterfaceMTU changes the MTU of an interface
+func (s *VPPMCPServer) handleSetInterfaceMTU(ctx context.Context, input VPPInterfaceMTUInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received MTU change request for pod: %s, interface: %s, mtu: %d", input.PodName, input.Interface, input.MTU)
+
+	layer := input.Layer
+	if layer == "" {
+		layer = "packet"
+	}
+	err := validateInterfaceName(input.Interface)
+	if err == nil && (input.MTU < 64 || input.MTU > 9216) {
+		err = fmt.Errorf("invalid mtu %d: must be between 64 and 9216", input.MTU)
+	}
+	if err == nil && layer != "packet" && layer != "ip4" && layer != "ip6" && layer != "mpls" {
+		err = fmt.Errorf("invalid layer '%s'. Use 'packet', 'ip4', 'ip6' or 'mpls'", layer)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	command := fmt.Sprintf("set interface mtu %s %d %s", layer, input.MTU, input.Interface)
+	return s.handleVPPCommand(ctx, VPPCommandInput{PodName: input.PodName, TimeoutSeconds: input.TimeoutSeconds}, command, "VPP Interface MTU Change")
+}
+
+// handleSetInterfaceState changes the admin state of an interface. Bouncing sets it down, then up.
+func (s *VPPMCPServer) handleSetInterfaceState(ctx context.Context, input VPPInterfaceStateInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received state change request for pod: %s, interface: %s, state: %s", input.PodName, input.Interface, input.State)
+
+	err := validateInterfaceName(input.Interface)
+	if err == nil && input.State != "up" && input.State != "down" && input.State != "bounce" {
+		err = fmt.Errorf("invalid state '%s'. Use 'up', 'down' or 'bounce'", input.State)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	commandInput := VPPCommandInput{PodName: input.PodName, TimeoutSeconds: input.TimeoutSeconds}
+	if input.State != "bounce" {
+		command := fmt.Sprintf("set interface state %s %s", input.Interface, input.State)
+		return s.handleVPPCommand(ctx, commandInput, command, "VPP Interface State Change")
+	}
+
+	result, _, err := s.handleVPPCommand(ctx, commandInput, fmt.Sprintf("set interface state %s down", input.Interface), "VPP Interface State Change")
+	if err != nil || result.IsError {
+		return result, nil, err
+	}
+	if text, ok := result.Content[0].(*mcp.TextContent); ok && strings.HasPrefix(text.Text, "Error") {
+		return result, nil, nil
+	}
+	return s.handleVPPCommand(ctx, commandInput, fmt.Sprintf("set interface state %s up", input.Interface), "VPP Interface State Change (bounced)")
+}
+
+// VPPLoggingLevelInput represents the input for changing the log level of a class
+type VPPLoggingLevelInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Class specifies the log class, e.g. "dpdk" or "linux-cp/nl"
+	Class string `json:"class"`
+	// Level specifies the new level logged to the VPP log buffer
+	Level string `json:"level"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// handleSetLoggingLevel changes the level logged for a log class
+func (s *VPPMCPServer) handleSetLoggingLevel(ctx context.Context, input VPPLoggingLevelInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received logging level change request for pod: %s, class: %s, level: %s", input.PodName, input.Class, input.Level)
+
+	level := input.Level
+	err := validateLogClass(input.Class)
+	if err == nil && level != "disabled" {
+		if rank := logLevelRank(level); rank >= 0 {
+			level = vppLogLevels[rank]
+		} else {
+			err = fmt.Errorf("invalid level %q. Use one of %s or disabled", input.Level, strings.Join(vppLogLevels, ", "))
+		}
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	command := fmt.Sprintf("set logging class %s level %s", input.Class, level)
+	return s.handleVPPCommand(ctx, VPPCommandInput{PodName: input.PodName, TimeoutSeconds: input.TimeoutSeconds}, command, "VPP Logging Level Change")
+}
+
+// VPPClearSessionsInput represents the input for clearing host stack sessions
+type VPPClearSessionsInput struct {
+	// PodName specifies the name of the Kubernetes pod running VPP
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Thread and Session identify a single session, as in the "[thread:session]" prefix of 'show session'
+	Thread  *int `json:"thread,omitempty"`
+	Session *int `json:"session,omitempty"`
+	// All clears every session
+	All bool `json:"all,omitempty"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// handleClearSessions closes one host stack session, or all of them
+func (s *VPPMCPServer) handleClearSessions(ctx context.Context, input VPPClearSessionsInput) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received session clear request for pod: %s, all: %t", input.PodName, input.All)
+
+	var command string
+	var err error
+	switch {
+	case input.All && (input.Thread != nil || input.Session != nil):
+		err = fmt.Errorf("all and thread/session are exclusive")
+	case input.All:
+		command = "clear session all"
+	case input.Thread == nil || input.Session == nil:
+		err = fmt.Errorf("thread and session are required unless all is set")
+	case *input.Thread < 0 || *input.Session < 0:
+		err = fmt.Errorf("thread and session must not be negative")
+	default:
+		command = fmt.Sprintf("clear session thread %d session %d", *input.Thread, *input.Session)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	return s.handleVPPCommand(ctx, VPPCommandInput{PodName: input.PodName, TimeoutSeconds: input.TimeoutSeconds}, command, "VPP Session Clear")
+}
+
+// BGPNeighborStateInput represents the input for changing the admin state of a BGP neighbor
+type BGPNeighborStateInput struct {
+	// PodName specifies the name of the Kubernetes pod running the agent container with gobgp
+	PodName string `json:"pod_name,omitempty"`
+	// NodeName selects the calico-vpp pod running on a Kubernetes node instead of PodName
+	NodeName string `json:"node_name,omitempty"`
+	// Neighbor specifies the IP address of the BGP neighbor
+	Neighbor string `json:"neighbor"`
+	// TimeoutSeconds overrides the server default timeout of the command
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// DryRun returns the commands the call would execute without running them
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// handleSetBGPNeighborState administratively disables or enables the peering with a BGP neighbor
+func (s *VPPMCPServer) handleSetBGPNeighborState(ctx context.Context, input BGPNeighborStateInput, enable bool) (*mcp.CallToolResult, any, error) {
+	log.Printf("Received BGP neighbor state change request for pod: %s, neighbor: %s, enable: %t", input.PodName, input.Neighbor, enable)
+
+	var err error
+	if input.Neighbor == "" {
+		err = fmt.Errorf("neighbor is required")
+	} else if _, parseErr := netip.ParseAddr(input.Neighbor); parseErr != nil {
+		err = fmt.Errorf("invalid neighbor %q: must be an IP address", input.Neighbor)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil, err
+	}
+
+	commandInput := BGPParameterCommandInput{PodName: input.PodName, Parameter: input.Neighbor, TimeoutSeconds: input.TimeoutSeconds}
+	if enable {
+		return s.HandleGoBGPParameterCommand(ctx, commandInput, "neighbor %s enable", "BGP Neighbor Enable")
+	}
+	return s.HandleGoBGPParameterCommand(ctx, commandInput, "neighbor %s disable", "BGP Neighbor Disable")
+}